@@ -167,6 +167,41 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "alerting",
+		Usage: "Runs alerting commands",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "convert-prometheus-rules",
+				Usage:  "convert-prometheus-rules --datasource-uid <uid> --folder <title> <rule file>. Converts a Prometheus rule file to a Grafana alert rule provisioning file.",
+				Action: runPluginCommand(convertPrometheusRulesCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "datasource-uid",
+						Usage: "The UID of the Prometheus data source that the converted rules query",
+					},
+					&cli.StringFlag{
+						Name:  "folder",
+						Usage: "The title of the folder to provision the rules in",
+					},
+					&cli.StringFlag{
+						Name:  "base-interval",
+						Usage: "The base interval of the scheduler. Group intervals must be a multiple of it",
+						Value: "10s",
+					},
+					&cli.StringFlag{
+						Name:  "default-interval",
+						Usage: "The evaluation interval of groups that do not set one",
+						Value: "1m",
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "Write the provisioning file to this path instead of stdout",
+					},
+				},
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
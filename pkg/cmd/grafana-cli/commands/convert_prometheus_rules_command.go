@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	errMissingRuleFile      = errors.New("path to the Prometheus rule file is required")
+	errMissingDatasourceUID = errors.New("--datasource-uid is required")
+	errMissingFolder        = errors.New("--folder is required")
+)
+
+// convertPrometheusRulesCommand converts a Prometheus rule file into a Grafana alert rule provisioning file.
+func convertPrometheusRulesCommand(c utils.CommandLine) error {
+	if c.Args().Len() != 1 {
+		return errMissingRuleFile
+	}
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return errMissingDatasourceUID
+	}
+	folder := c.String("folder")
+	if folder == "" {
+		return errMissingFolder
+	}
+	baseInterval, err := durationFlag(c, "base-interval", setting.SchedulerBaseInterval)
+	if err != nil {
+		return err
+	}
+	defaultInterval, err := durationFlag(c, "default-interval", setting.DefaultRuleEvaluationInterval)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(c.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to read rule file: %w", err)
+	}
+	groups, err := prom.ParseRules(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse rule file: %w", err)
+	}
+
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:   datasourceUID,
+		BaseInterval:    baseInterval,
+		DefaultInterval: defaultInterval,
+	})
+	if err != nil {
+		return err
+	}
+	const orgID = 1
+	converted, convErrs := converter.PrometheusRulesToGrafana(orgID, "", groups)
+	if len(convErrs) > 0 {
+		for _, e := range convErrs {
+			logger.Errorf("%s\n", e.Error())
+		}
+		return fmt.Errorf("failed to convert %d groups or rules", len(convErrs))
+	}
+
+	export := make([]ngmodels.AlertRuleGroupWithFolderFullpath, 0, len(converted))
+	for _, group := range converted {
+		for i := range group.Rules {
+			// Provisioned rules need a UID. It is derived from the location of the rule
+			// so that provisioning the converted file again updates the same rules.
+			group.Rules[i].UID = prometheusRuleUID(folder, group.Title, group.Rules[i].Title)
+		}
+		groupKey := ngmodels.AlertRuleGroupKey{OrgID: orgID, RuleGroup: group.Title}
+		export = append(export, ngmodels.NewAlertRuleGroupWithFolderFullpath(groupKey, group.Rules, folder))
+	}
+	file, err := api.AlertingFileExportFromAlertRuleGroupWithFolderFullpath(export)
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if output := c.String("output"); output != "" {
+		if err := os.WriteFile(output, out, 0600); err != nil {
+			return fmt.Errorf("failed to write provisioning file: %w", err)
+		}
+		logger.Infof("Converted %d rule groups to %s\n", len(converted), output)
+		return nil
+	}
+	logger.Info(string(out))
+	return nil
+}
+
+func durationFlag(c utils.CommandLine, name string, defaultValue time.Duration) (time.Duration, error) {
+	value := c.String(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := model.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --%s: %w", name, err)
+	}
+	return time.Duration(d), nil
+}
+
+func prometheusRuleUID(folder, group, title string) string {
+	sum := sha256.Sum256([]byte(folder + "\x00" + group + "\x00" + title))
+	return hex.EncodeToString(sum[:])[:14]
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const prometheusRuleFile = `
+groups:
+  - name: node
+    interval: 1m
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        labels:
+          severity: critical
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`
+
+func TestConvertPrometheusRulesCommand(t *testing.T) {
+	newContext := func(t *testing.T, flags map[string]string, args ...string) utils.CommandLine {
+		flagSet := flag.NewFlagSet("Test", 0)
+		for name, value := range flags {
+			flagSet.String(name, value, "")
+		}
+		require.NoError(t, flagSet.Parse(args))
+		return &utils.ContextCommandLine{Context: cli.NewContext(&cli.App{Name: "Test"}, flagSet, nil)}
+	}
+	writeRules := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "rules.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	t.Run("writes a provisioning file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "alerting.yaml")
+		c := newContext(t, map[string]string{"datasource-uid": "prom", "folder": "Imported", "output": output}, writeRules(t, prometheusRuleFile))
+
+		require.NoError(t, convertPrometheusRulesCommand(c))
+
+		content, err := os.ReadFile(output)
+		require.NoError(t, err)
+		var file definitions.AlertingFileExport
+		require.NoError(t, yaml.Unmarshal(content, &file))
+		require.Len(t, file.Groups, 1)
+		group := file.Groups[0]
+		require.Equal(t, "node", group.Name)
+		require.Equal(t, "Imported", group.Folder)
+		require.Len(t, group.Rules, 2)
+		require.Equal(t, "InstanceDown", group.Rules[0].Title)
+		require.Equal(t, prometheusRuleUID("Imported", "node", "InstanceDown"), group.Rules[0].UID)
+		require.Equal(t, "prom", group.Rules[0].Data[0].DatasourceUID)
+		require.NotNil(t, group.Rules[1].Record)
+		require.Equal(t, "job:up:sum", group.Rules[1].Record.Metric)
+	})
+
+	t.Run("fails if a rule cannot be converted", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "alerting.yaml")
+		rules := writeRules(t, "groups:\n  - name: node\n    rules:\n      - alert: Broken\n        expr: up ==\n")
+		c := newContext(t, map[string]string{"datasource-uid": "prom", "folder": "Imported", "output": output}, rules)
+
+		require.Error(t, convertPrometheusRulesCommand(c))
+		require.NoFileExists(t, output)
+	})
+
+	t.Run("requires flags", func(t *testing.T) {
+		rules := writeRules(t, prometheusRuleFile)
+		require.ErrorIs(t, convertPrometheusRulesCommand(newContext(t, map[string]string{"folder": "Imported"}, rules)), errMissingDatasourceUID)
+		require.ErrorIs(t, convertPrometheusRulesCommand(newContext(t, map[string]string{"datasource-uid": "prom"}, rules)), errMissingFolder)
+		require.ErrorIs(t, convertPrometheusRulesCommand(newContext(t, map[string]string{"datasource-uid": "prom", "folder": "Imported"})), errMissingRuleFile)
+	})
+}
@@ -0,0 +1,174 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/anomaly"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// The output of the anomaly detection command
+// +enum
+type AnomalyOutput string
+
+const (
+	// 1 if the last point of the series is an anomaly, 0 otherwise
+	AnomalyOutputAnomaly AnomalyOutput = "anomaly"
+
+	// The anomaly score of the last point of the series
+	AnomalyOutputScore AnomalyOutput = "score"
+
+	// The lower bound, upper bound and anomaly score series
+	AnomalyOutputBands AnomalyOutput = "bands"
+)
+
+// anomalyLabel is the label added to the series returned by the bands output
+// to tell the lower bound, upper bound and score apart.
+const anomalyLabel = "anomaly"
+
+var supportedAnomalyOutputs = []string{
+	string(AnomalyOutputAnomaly),
+	string(AnomalyOutputScore),
+	string(AnomalyOutputBands),
+}
+
+// AnomalyCommand is an expression command that detects anomalies in series using
+// algorithms that run in-process.
+type AnomalyCommand struct {
+	RefID        string
+	ReferenceVar string
+	Config       anomaly.Config
+	Output       AnomalyOutput
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, referenceVar string, cfg anomaly.Config, output AnomalyOutput) (*AnomalyCommand, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch output {
+	case "":
+		output = AnomalyOutputAnomaly
+	case AnomalyOutputAnomaly, AnomalyOutputScore, AnomalyOutputBands:
+	default:
+		return nil, fmt.Errorf("expected output to be one of [%s], got %s", strings.Join(supportedAnomalyOutputs, ", "), output)
+	}
+	return &AnomalyCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Config:       cfg.WithDefaults(),
+		Output:       output,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewAnomalyCommand(rn.RefID, referenceVar, q.config(), q.Output)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("algorithm", string(ac.Config.Algorithm)),
+		attribute.String("output", string(ac.Output)),
+	)
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			values, err := ac.detect(v)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, values...)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) detect(s mathexp.Series) ([]mathexp.Value, error) {
+	points := make([]*float64, s.Len())
+	for i := range points {
+		_, points[i] = s.GetPoint(i)
+	}
+	res, err := anomaly.Detect(points, ac.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if ac.Output == AnomalyOutputBands {
+		result := make([]mathexp.Value, 0, 3)
+		for _, band := range []struct {
+			name   string
+			values []*float64
+		}{
+			{"lower", res.Lower},
+			{"upper", res.Upper},
+			{"score", res.Score},
+		} {
+			labels := s.GetLabels().Copy()
+			if labels == nil {
+				labels = data.Labels{}
+			}
+			labels[anomalyLabel] = band.name
+			bandSeries := mathexp.NewSeries(ac.RefID, labels, s.Len())
+			for i := 0; i < s.Len(); i++ {
+				bandSeries.SetPoint(i, s.GetTime(i), band.values[i])
+			}
+			result = append(result, bandSeries)
+		}
+		return result, nil
+	}
+
+	// The anomaly and score outputs use the last scored point, so they can be used as an alert condition.
+	n := mathexp.NewNumber(ac.RefID, s.GetLabels())
+	for i := len(res.Score) - 1; i >= 0; i-- {
+		if res.Score[i] == nil {
+			continue
+		}
+		if ac.Output == AnomalyOutputScore {
+			n.SetValue(res.Score[i])
+		} else if res.Anomaly[i] {
+			n.SetValue(util.Pointer(float64(1)))
+		} else {
+			n.SetValue(util.Pointer(float64(0)))
+		}
+		break
+	}
+	return []mathexp.Value{n}, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
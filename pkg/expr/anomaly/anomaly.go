@@ -0,0 +1,322 @@
+// Package anomaly implements anomaly detection algorithms that run in-process over
+// the values of a time series, so they do not depend on any external service.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// The anomaly detection algorithm
+// +enum
+type Algorithm string
+
+const (
+	// Rolling mean and standard deviation of the previous points
+	AlgorithmZScore Algorithm = "zscore"
+
+	// Rolling median and median absolute deviation of the previous points
+	AlgorithmMAD Algorithm = "mad"
+
+	// Holt-Winters (triple exponential smoothing) forecast with seasonal decomposition
+	AlgorithmHoltWinters Algorithm = "holt_winters"
+)
+
+const (
+	defaultWindow      = 10
+	defaultSensitivity = 3
+	defaultAlpha       = 0.5
+	defaultBeta        = 0.1
+	defaultGamma       = 0.1
+
+	// madScale makes the median absolute deviation a consistent estimator of
+	// the standard deviation for normally distributed data.
+	madScale = 1.4826
+)
+
+var supportedAlgorithms = []string{
+	string(AlgorithmZScore),
+	string(AlgorithmMAD),
+	string(AlgorithmHoltWinters),
+}
+
+// Config holds the parameters of an anomaly detection algorithm.
+// Zero values are replaced with defaults, except for the smoothing factors which are
+// only replaced when nil, since 0 is a valid smoothing factor.
+type Config struct {
+	Algorithm Algorithm
+
+	// Window is the number of previous points used to calculate the expected value and
+	// the spread for zscore and mad, or the spread of the forecast errors for holt_winters.
+	Window int
+
+	// Sensitivity is the number of spreads (standard deviations) a point can deviate from
+	// the expected value before it is considered an anomaly.
+	Sensitivity float64
+
+	// Season is the number of points in a season for holt_winters. When 0 no seasonality is
+	// modelled and the algorithm falls back to double exponential smoothing.
+	Season int
+
+	// Alpha, Beta and Gamma are the smoothing factors of the level, trend and seasonal
+	// components for holt_winters.
+	Alpha *float64
+	Beta  *float64
+	Gamma *float64
+}
+
+// WithDefaults returns a copy of the config where unset parameters are replaced with defaults.
+func (c Config) WithDefaults() Config {
+	if c.Window == 0 {
+		c.Window = defaultWindow
+	}
+	if c.Sensitivity == 0 {
+		c.Sensitivity = defaultSensitivity
+	}
+	if c.Alpha == nil {
+		c.Alpha = pointer(defaultAlpha)
+	}
+	if c.Beta == nil {
+		c.Beta = pointer(defaultBeta)
+	}
+	if c.Gamma == nil {
+		c.Gamma = pointer(defaultGamma)
+	}
+	return c
+}
+
+func pointer(v float64) *float64 {
+	return &v
+}
+
+// Validate returns an error if the config, after defaults are applied, is not valid.
+func (c Config) Validate() error {
+	c = c.WithDefaults()
+	switch c.Algorithm {
+	case AlgorithmZScore, AlgorithmMAD:
+		if c.Season != 0 {
+			return fmt.Errorf("season is only supported by the %s algorithm", AlgorithmHoltWinters)
+		}
+	case AlgorithmHoltWinters:
+		if c.Season < 0 || c.Season == 1 {
+			return fmt.Errorf("season must be 0 or at least 2, got %d", c.Season)
+		}
+		for name, v := range map[string]float64{"alpha": *c.Alpha, "beta": *c.Beta, "gamma": *c.Gamma} {
+			if v < 0 || v > 1 {
+				return fmt.Errorf("%s must be in the range [0, 1], got %v", name, v)
+			}
+		}
+	default:
+		return fmt.Errorf("expected algorithm to be one of [%s], got %s", strings.Join(supportedAlgorithms, ", "), c.Algorithm)
+	}
+	if c.Window < 2 {
+		return fmt.Errorf("window must be at least 2, got %d", c.Window)
+	}
+	if c.Sensitivity < 0 || math.IsNaN(c.Sensitivity) || math.IsInf(c.Sensitivity, 0) {
+		return fmt.Errorf("sensitivity must be a positive number, got %v", c.Sensitivity)
+	}
+	return nil
+}
+
+// Result is the output of Detect. Each slice has one element per input value, which is nil
+// for the points that could not be scored, either because the value is null or NaN or because
+// there is not enough history before it.
+type Result struct {
+	// Expected is the value predicted by the algorithm.
+	Expected []*float64
+	// Lower and Upper are the bounds of the band of normal values.
+	Lower []*float64
+	Upper []*float64
+	// Score is the distance of the value to the expected value in units of spread.
+	// A point is an anomaly when its score is greater than the sensitivity.
+	Score []*float64
+	// Anomaly is true for the points outside the band of normal values.
+	Anomaly []bool
+}
+
+func newResult(size int) Result {
+	return Result{
+		Expected: make([]*float64, size),
+		Lower:    make([]*float64, size),
+		Upper:    make([]*float64, size),
+		Score:    make([]*float64, size),
+		Anomaly:  make([]bool, size),
+	}
+}
+
+// set records the expected value and the spread for the point at index i, and scores the value.
+func (r Result) set(i int, value, expected, spread, sensitivity float64) {
+	lower := expected - sensitivity*spread
+	upper := expected + sensitivity*spread
+	var score float64
+	switch {
+	case value == expected:
+		score = 0
+	case spread == 0:
+		score = math.Inf(1)
+	default:
+		score = math.Abs(value-expected) / spread
+	}
+	r.Expected[i] = &expected
+	r.Lower[i] = &lower
+	r.Upper[i] = &upper
+	r.Score[i] = &score
+	r.Anomaly[i] = score > sensitivity
+}
+
+// Detect runs the anomaly detection algorithm of the config over the values, which must be
+// ordered by time and evenly spaced for holt_winters.
+func Detect(values []*float64, cfg Config) (Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return Result{}, err
+	}
+	cfg = cfg.WithDefaults()
+	switch cfg.Algorithm {
+	case AlgorithmZScore:
+		return rolling(values, cfg, meanStdDev), nil
+	case AlgorithmMAD:
+		return rolling(values, cfg, medianMAD), nil
+	default:
+		return holtWinters(values, cfg), nil
+	}
+}
+
+// rolling scores each value against the expected value and spread that the estimate function
+// returns for the previous cfg.Window valid values.
+func rolling(values []*float64, cfg Config, estimate func([]float64) (float64, float64)) Result {
+	res := newResult(len(values))
+	history := make([]float64, 0, cfg.Window)
+	for i, v := range values {
+		if !isValid(v) {
+			continue
+		}
+		if len(history) == cfg.Window {
+			expected, spread := estimate(history)
+			res.set(i, *v, expected, spread, cfg.Sensitivity)
+			history = history[1:]
+		}
+		history = append(history, *v)
+	}
+	return res
+}
+
+func meanStdDev(vals []float64) (float64, float64) {
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	mean := sum / float64(len(vals))
+	var sq float64
+	for _, v := range vals {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(vals)))
+}
+
+func medianMAD(vals []float64) (float64, float64) {
+	m := median(vals)
+	deviations := make([]float64, len(vals))
+	for i, v := range vals {
+		deviations[i] = math.Abs(v - m)
+	}
+	return m, madScale * median(deviations)
+}
+
+func median(vals []float64) float64 {
+	sorted := make([]float64, len(vals))
+	copy(sorted, vals)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// holtWinters fits an additive Holt-Winters model and scores each value against the one step ahead
+// forecast, using the standard deviation of the previous cfg.Window forecast errors as the spread.
+// The first two seasons (or two points without seasonality) initialize the model, and the following
+// cfg.Window points are only used to collect forecast errors, so none of them are scored.
+// Null values are replaced with the forecast so that they do not break the seasonal pattern.
+func holtWinters(values []*float64, cfg Config) Result {
+	res := newResult(len(values))
+	m := cfg.Season
+	initLen := 2
+	if m > 0 {
+		initLen = 2 * m
+	}
+
+	// The model is initialized from the first valid values.
+	var init []float64
+	start := 0
+	for ; start < len(values) && len(init) < initLen; start++ {
+		if isValid(values[start]) {
+			init = append(init, *values[start])
+		}
+	}
+	if len(init) < initLen {
+		return res
+	}
+
+	var level, trend float64
+	seasonal := make([]float64, max(m, 1))
+	if m > 0 {
+		first, _ := meanStdDev(init[:m])
+		second, _ := meanStdDev(init[m:])
+		level = first
+		trend = (second - first) / float64(m)
+		for i := 0; i < m; i++ {
+			seasonal[i] = init[i] - first
+		}
+		// Run the model over the second season so the components reflect the latest values.
+		for i := m; i < 2*m; i++ {
+			level, trend = smooth(init[i], level, trend, seasonal, i, cfg)
+		}
+	} else {
+		level = init[1]
+		trend = init[1] - init[0]
+	}
+
+	residuals := make([]float64, 0, cfg.Window)
+	for i, t := start, initLen; i < len(values); i, t = i+1, t+1 {
+		forecast := level + trend + seasonal[seasonIndex(t, m)]
+		v := forecast
+		if isValid(values[i]) {
+			v = *values[i]
+			if len(residuals) == cfg.Window {
+				_, spread := meanStdDev(residuals)
+				res.set(i, v, forecast, spread, cfg.Sensitivity)
+				residuals = residuals[1:]
+			}
+			residuals = append(residuals, v-forecast)
+		}
+		level, trend = smooth(v, level, trend, seasonal, t, cfg)
+	}
+	return res
+}
+
+// smooth updates the level, trend and seasonal components with the value observed at step t.
+func smooth(v, level, trend float64, seasonal []float64, t int, cfg Config) (float64, float64) {
+	idx := seasonIndex(t, cfg.Season)
+	s := seasonal[idx]
+	alpha, beta, gamma := *cfg.Alpha, *cfg.Beta, *cfg.Gamma
+	newLevel := alpha*(v-s) + (1-alpha)*(level+trend)
+	newTrend := beta*(newLevel-level) + (1-beta)*trend
+	if cfg.Season > 0 {
+		seasonal[idx] = gamma*(v-newLevel) + (1-gamma)*s
+	}
+	return newLevel, newTrend
+}
+
+func seasonIndex(t, season int) int {
+	if season == 0 {
+		return 0
+	}
+	return t % season
+}
+
+func isValid(v *float64) bool {
+	return v != nil && !math.IsNaN(*v) && !math.IsInf(*v, 0)
+}
@@ -0,0 +1,167 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func floats(vals ...float64) []*float64 {
+	result := make([]*float64, len(vals))
+	for i := range vals {
+		result[i] = &vals[i]
+	}
+	return result
+}
+
+// seasonal returns n points of a sine wave with the given period and a small deterministic noise.
+func seasonal(n, period int) []float64 {
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = 100 + 10*math.Sin(2*math.Pi*float64(i)/float64(period)) + float64(i%3)*0.1
+	}
+	return vals
+}
+
+func anomalies(r Result) []int {
+	var idx []int
+	for i, a := range r.Anomaly {
+		if a {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		errMsg string
+	}{
+		{name: "zscore with defaults", cfg: Config{Algorithm: AlgorithmZScore}},
+		{name: "holt_winters with season", cfg: Config{Algorithm: AlgorithmHoltWinters, Season: 24}},
+		{name: "unknown algorithm", cfg: Config{Algorithm: "prophet"}, errMsg: "expected algorithm to be one of"},
+		{name: "window too small", cfg: Config{Algorithm: AlgorithmMAD, Window: 1}, errMsg: "window must be at least 2"},
+		{name: "negative sensitivity", cfg: Config{Algorithm: AlgorithmMAD, Sensitivity: -1}, errMsg: "sensitivity must be a positive number"},
+		{name: "season for zscore", cfg: Config{Algorithm: AlgorithmZScore, Season: 24}, errMsg: "season is only supported"},
+		{name: "season of one", cfg: Config{Algorithm: AlgorithmHoltWinters, Season: 1}, errMsg: "season must be 0 or at least 2"},
+		{name: "alpha out of range", cfg: Config{Algorithm: AlgorithmHoltWinters, Alpha: pointer(1.5)}, errMsg: "alpha must be in the range"},
+		{name: "negative gamma", cfg: Config{Algorithm: AlgorithmHoltWinters, Gamma: pointer(-0.1)}, errMsg: "gamma must be in the range"},
+		{name: "zero smoothing factors", cfg: Config{Algorithm: AlgorithmHoltWinters, Alpha: pointer(0), Beta: pointer(0), Gamma: pointer(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestConfigWithDefaults(t *testing.T) {
+	cfg := Config{Algorithm: AlgorithmHoltWinters}.WithDefaults()
+	require.Equal(t, defaultAlpha, *cfg.Alpha)
+	require.Equal(t, defaultBeta, *cfg.Beta)
+	require.Equal(t, defaultGamma, *cfg.Gamma)
+
+	// Only missing smoothing factors are replaced, 0 is a valid value.
+	cfg = Config{Algorithm: AlgorithmHoltWinters, Alpha: pointer(0.3), Beta: pointer(0), Gamma: pointer(0)}.WithDefaults()
+	require.Equal(t, 0.3, *cfg.Alpha)
+	require.Equal(t, 0.0, *cfg.Beta)
+	require.Equal(t, 0.0, *cfg.Gamma)
+}
+
+func TestDetectZScore(t *testing.T) {
+	vals := floats(10, 11, 9, 10, 11, 9, 10, 30, 10)
+	res, err := Detect(vals, Config{Algorithm: AlgorithmZScore, Window: 6})
+	require.NoError(t, err)
+
+	for i := 0; i < 6; i++ {
+		require.Nil(t, res.Score[i], "point %d has no history and should not be scored", i)
+	}
+	require.InDelta(t, 10, *res.Expected[6], 1e-9)
+	require.InDelta(t, 10-3*math.Sqrt(2.0/3), *res.Lower[6], 1e-9)
+	require.InDelta(t, 10+3*math.Sqrt(2.0/3), *res.Upper[6], 1e-9)
+	require.InDelta(t, 0, *res.Score[6], 1e-9)
+	assert.Equal(t, []int{7}, anomalies(res))
+}
+
+func TestDetectMAD(t *testing.T) {
+	t.Run("is robust to previous outliers", func(t *testing.T) {
+		vals := floats(10, 11, 9, 1000, 10, 11, 9, 10, 20)
+		res, err := Detect(vals, Config{Algorithm: AlgorithmMAD, Window: 5})
+		require.NoError(t, err)
+		// The outlier at index 3 does not move the median of the window ending at index 8.
+		require.InDelta(t, 10, *res.Expected[8], 1e-9)
+		assert.Equal(t, []int{8}, anomalies(res))
+	})
+
+	t.Run("constant values only flag changes", func(t *testing.T) {
+		vals := floats(5, 5, 5, 5, 5, 6)
+		res, err := Detect(vals, Config{Algorithm: AlgorithmMAD, Window: 3})
+		require.NoError(t, err)
+		require.Equal(t, 0.0, *res.Score[4])
+		require.True(t, math.IsInf(*res.Score[5], 1))
+		assert.Equal(t, []int{5}, anomalies(res))
+	})
+}
+
+func TestDetectSkipsNullValues(t *testing.T) {
+	vals := floats(10, 11, 9, 10, 10)
+	vals[2] = nil
+	nan := math.NaN()
+	vals[3] = &nan
+	res, err := Detect(vals, Config{Algorithm: AlgorithmZScore, Window: 2})
+	require.NoError(t, err)
+	require.Nil(t, res.Score[2])
+	require.Nil(t, res.Score[3])
+	require.NotNil(t, res.Score[4])
+	require.InDelta(t, 10.5, *res.Expected[4], 1e-9)
+}
+
+func TestDetectHoltWinters(t *testing.T) {
+	t.Run("seasonal series without anomalies", func(t *testing.T) {
+		res, err := Detect(floats(seasonal(96, 12)...), Config{Algorithm: AlgorithmHoltWinters, Season: 12, Window: 24})
+		require.NoError(t, err)
+		for i := 0; i < 48; i++ {
+			require.Nil(t, res.Score[i], "point %d is used for initialization and should not be scored", i)
+		}
+		require.NotNil(t, res.Score[48])
+		assert.Empty(t, anomalies(res))
+	})
+
+	t.Run("spike in a seasonal series", func(t *testing.T) {
+		vals := seasonal(96, 12)
+		vals[80] += 15
+		res, err := Detect(floats(vals...), Config{Algorithm: AlgorithmHoltWinters, Season: 12, Window: 24})
+		require.NoError(t, err)
+		assert.Contains(t, anomalies(res), 80)
+		require.Greater(t, *res.Upper[80], *res.Expected[80])
+		require.Less(t, *res.Lower[80], *res.Expected[80])
+	})
+
+	t.Run("linear trend without season", func(t *testing.T) {
+		vals := make([]float64, 40)
+		for i := range vals {
+			vals[i] = float64(2*i) + float64(i%2)*0.5
+		}
+		vals[35] = 200
+		res, err := Detect(floats(vals...), Config{Algorithm: AlgorithmHoltWinters})
+		require.NoError(t, err)
+		assert.Equal(t, 35, anomalies(res)[0])
+	})
+
+	t.Run("not enough points", func(t *testing.T) {
+		res, err := Detect(floats(seasonal(20, 12)...), Config{Algorithm: AlgorithmHoltWinters, Season: 12})
+		require.NoError(t, err)
+		for _, s := range res.Score {
+			require.Nil(t, s)
+		}
+	})
+}
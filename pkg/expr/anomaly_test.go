@@ -0,0 +1,184 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/anomaly"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected *AnomalyCommand
+		errMsg   string
+	}{
+		{
+			name:  "defaults",
+			query: `{"expression": "$A", "algorithm": "zscore"}`,
+			expected: &AnomalyCommand{
+				RefID:        "B",
+				ReferenceVar: "A",
+				Config:       anomaly.Config{Algorithm: anomaly.AlgorithmZScore}.WithDefaults(),
+				Output:       AnomalyOutputAnomaly,
+			},
+		},
+		{
+			name:  "holt_winters with bands",
+			query: `{"expression": "A", "algorithm": "holt_winters", "season": 24, "window": 48, "alpha": 0.3, "output": "bands"}`,
+			expected: &AnomalyCommand{
+				RefID:        "B",
+				ReferenceVar: "A",
+				Config: anomaly.Config{
+					Algorithm: anomaly.AlgorithmHoltWinters,
+					Season:    24,
+					Window:    48,
+					Alpha:     util.Pointer(0.3),
+				}.WithDefaults(),
+				Output: AnomalyOutputBands,
+			},
+		},
+		{
+			name:  "holt_winters with smoothing factors of zero",
+			query: `{"expression": "$A", "algorithm": "holt_winters", "alpha": 0.2, "beta": 0, "gamma": 0}`,
+			expected: &AnomalyCommand{
+				RefID:        "B",
+				ReferenceVar: "A",
+				Config: anomaly.Config{
+					Algorithm: anomaly.AlgorithmHoltWinters,
+					Alpha:     util.Pointer(0.2),
+					Beta:      util.Pointer(0.0),
+					Gamma:     util.Pointer(0.0),
+				}.WithDefaults(),
+				Output: AnomalyOutputAnomaly,
+			},
+		},
+		{
+			name:   "missing expression",
+			query:  `{"algorithm": "mad"}`,
+			errMsg: "no variable specified",
+		},
+		{
+			name:   "unknown output",
+			query:  `{"expression": "$A", "algorithm": "mad", "output": "forecast"}`,
+			errMsg: "expected output to be one of",
+		},
+		{
+			name:   "invalid algorithm config",
+			query:  `{"expression": "$A", "algorithm": "mad", "season": 24}`,
+			errMsg: "season is only supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(tt.query), &q))
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{
+				RefID:    "B",
+				Query:    q,
+				QueryRaw: []byte(tt.query),
+			})
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cmd)
+		})
+	}
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	start := time.Unix(0, 0)
+	series := func(labels data.Labels, vals ...float64) mathexp.Series {
+		s := mathexp.NewSeries("A", labels, len(vals))
+		for i := range vals {
+			s.SetPoint(i, start.Add(time.Duration(i)*time.Minute), &vals[i])
+		}
+		return s
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			series(data.Labels{"host": "a"}, 10, 11, 9, 10, 11, 9, 10, 30),
+			series(data.Labels{"host": "b"}, 10, 11, 9, 10, 11, 9, 10, 10),
+		}},
+	}
+	cfg := anomaly.Config{Algorithm: anomaly.AlgorithmZScore, Window: 6}
+
+	t.Run("anomaly output returns 1 for anomalous series", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", cfg, "")
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		require.Equal(t, data.Labels{"host": "a"}, res.Values[0].GetLabels())
+		require.Equal(t, 1.0, *res.Values[0].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, 0.0, *res.Values[1].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("score output returns the score of the last point", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", cfg, AnomalyOutputScore)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		require.Greater(t, *res.Values[0].(mathexp.Number).GetFloat64Value(), 3.0)
+		require.Less(t, *res.Values[1].(mathexp.Number).GetFloat64Value(), 3.0)
+	})
+
+	t.Run("bands output returns lower, upper and score series", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", cfg, AnomalyOutputBands)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 6)
+		for i, band := range []string{"lower", "upper", "score"} {
+			s := res.Values[i].(mathexp.Series)
+			require.Equal(t, data.Labels{"host": "a", "anomaly": band}, s.GetLabels())
+			require.Equal(t, 8, s.Len())
+			require.Nil(t, s.GetValue(0))
+			require.NotNil(t, s.GetValue(7))
+		}
+		lower := res.Values[0].(mathexp.Series).GetValue(7)
+		upper := res.Values[1].(mathexp.Series).GetValue(7)
+		require.Less(t, *upper, 30.0)
+		require.Greater(t, *lower, 0.0)
+	})
+
+	t.Run("not enough points returns a number without value", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", anomaly.Config{Algorithm: anomaly.AlgorithmZScore, Window: 20}, "")
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Nil(t, res.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("no data returns no data", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", cfg, "")
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}},
+		}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.NoData{}, res.Values[0])
+	})
+
+	t.Run("numbers are not supported", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", cfg, "")
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "can only detect anomalies in type series")
+	})
+}
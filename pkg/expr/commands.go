@@ -2,6 +2,7 @@ package expr
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -83,20 +84,22 @@ func (gm *MathCommand) Type() string {
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
 	Reducer      mathexp.ReducerID
+	ReducerArgs  mathexp.ReducerArgs
 	VarToReduce  string
 	refID        string
 	seriesMapper mathexp.ReduceMapper
 }
 
 // NewReduceCommand creates a new ReduceCMD.
-func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+func NewReduceCommand(refID string, reducer mathexp.ReducerID, args mathexp.ReducerArgs, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	err := mathexp.ValidateReducer(reducer, args)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReduceCommand{
 		Reducer:      reducer,
+		ReducerArgs:  args,
 		VarToReduce:  varToReduce,
 		refID:        refID,
 		seriesMapper: mapper,
@@ -126,10 +129,20 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	redFunc := mathexp.ReducerID(strings.ToLower(redString))
 
 	var mapper mathexp.ReduceMapper = nil
+	var args mathexp.ReducerArgs
+	percentileSet := false
 	settings, ok := rn.Query["settings"]
 	if ok {
 		switch s := settings.(type) {
 		case map[string]any:
+			if rawPercentile, ok := s["percentile"]; ok {
+				percentile, ok := rawPercentile.(float64)
+				if !ok {
+					return nil, fmt.Errorf("setting percentile must be a number, got %T", rawPercentile)
+				}
+				args.Percentile = percentile
+				percentileSet = true
+			}
 			mode, ok := s["mode"]
 			if ok && mode != "" {
 				switch mode {
@@ -154,7 +167,10 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	if redFunc == mathexp.ReducerPercentile && !percentileSet {
+		return nil, fmt.Errorf("setting percentile must be specified when reducer is '%s'", redFunc)
+	}
+	return NewReduceCommand(rn.RefID, redFunc, args, varToReduce, mapper)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -170,12 +186,15 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	defer span.End()
 
 	span.SetAttributes(attribute.String("reducer", string(gr.Reducer)))
+	if gr.Reducer == mathexp.ReducerPercentile {
+		span.SetAttributes(attribute.Float64("percentile", gr.ReducerArgs.Percentile))
+	}
 
 	newRes := mathexp.Results{}
 	for i, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			num, err := v.ReduceWithArgs(gr.refID, gr.Reducer, gr.ReducerArgs, gr.seriesMapper)
 			if err != nil {
 				return newRes, err
 			}
@@ -216,12 +235,13 @@ type ResampleCommand struct {
 	VarToResample string
 	Downsampler   mathexp.ReducerID
 	Upsampler     mathexp.Upsampler
+	Options       mathexp.ResampleOptions
 	TimeRange     TimeRange
 	refID         string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
-func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
+func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, opts mathexp.ResampleOptions, tr TimeRange) (*ResampleCommand, error) {
 	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
@@ -232,6 +252,7 @@ func NewResampleCommand(refID, rawWindow, varToResample string, downsampler math
 		VarToResample: varToResample,
 		Downsampler:   downsampler,
 		Upsampler:     upsampler,
+		Options:       opts,
 		TimeRange:     tr,
 		refID:         refID,
 	}, nil
@@ -280,10 +301,20 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
+	q := ResampleQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the resample settings: %w", err)
+	}
+	opts, err := q.Settings.options()
+	if err != nil {
+		return nil, err
+	}
+
 	return NewResampleCommand(rn.RefID, window,
 		varToResample,
 		mathexp.ReducerID(downsampler),
 		mathexp.Upsampler(upsampler),
+		opts,
 		rn.TimeRange)
 }
 
@@ -306,7 +337,7 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			num, err := v.ResampleWithOptions(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To, gr.Options)
 			if err != nil {
 				return newRes, err
 			}
@@ -343,6 +374,10 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in series
+	TypeAnomaly
+	// TypeForecast is the CMDType for projecting series into the future
+	TypeForecast
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +394,10 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
 	default:
 		return "unknown"
 	}
@@ -379,6 +418,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -99,11 +99,39 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_Percentile(t *testing.T) {
+	t.Run("should read percentile from settings", func(t *testing.T) {
+		var qmap = make(map[string]any)
+		require.NoError(t, json.Unmarshal([]byte(`{ "expression" : "$A", "reducer": "percentile", "settings": { "percentile": 95 } }`), &qmap))
+
+		cmd, err := UnmarshalReduceCommand(&rawNode{RefID: "A", Query: qmap})
+		require.NoError(t, err)
+		require.Equal(t, mathexp.ReducerPercentile, cmd.Reducer)
+		require.Equal(t, mathexp.ReducerArgs{Percentile: 95}, cmd.ReducerArgs)
+	})
+
+	t.Run("should fail if percentile is not specified", func(t *testing.T) {
+		var qmap = make(map[string]any)
+		require.NoError(t, json.Unmarshal([]byte(`{ "expression" : "$A", "reducer": "percentile" }`), &qmap))
+
+		_, err := UnmarshalReduceCommand(&rawNode{RefID: "A", Query: qmap})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if percentile is out of range", func(t *testing.T) {
+		var qmap = make(map[string]any)
+		require.NoError(t, json.Unmarshal([]byte(`{ "expression" : "$A", "reducer": "percentile", "settings": { "percentile": -1 } }`), &qmap))
+
+		_, err := UnmarshalReduceCommand(&rawNode{RefID: "A", Query: qmap})
+		require.Error(t, err)
+	})
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
 	t.Run("when mapper is nil", func(t *testing.T) {
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReducerArgs{}, varToReduce, nil)
 		require.NoError(t, err)
 
 		t.Run("should noop if Number", func(t *testing.T) {
@@ -161,7 +189,7 @@ func TestReduceExecute(t *testing.T) {
 		}
 
 		t.Run("drop all non numbers if mapper is DropNonNumber", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.DropNonNumber{})
+			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReducerArgs{}, varToReduce, &mathexp.DropNonNumber{})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -169,7 +197,7 @@ func TestReduceExecute(t *testing.T) {
 		})
 
 		t.Run("replace all non numbers if mapper is ReplaceNonNumberWithValue", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
+			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReducerArgs{}, varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -192,7 +220,7 @@ func TestReduceExecute(t *testing.T) {
 				Values: noData,
 			},
 		}
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), mathexp.ReducerArgs{}, varToReduce, nil)
 		require.NoError(t, err)
 		results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 		require.NoError(t, err)
@@ -221,7 +249,7 @@ func TestResampleCommand_Execute(t *testing.T) {
 		From: -10 * time.Second,
 		To:   0,
 	}
-	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr)
+	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", mathexp.ResampleOptions{}, tr)
 	require.NoError(t, err)
 
 	var tests = []struct {
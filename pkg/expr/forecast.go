@@ -0,0 +1,192 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/forecast"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// What the forecast command returns
+// +enum
+type ForecastMode string
+
+const (
+	// The projected value at the horizon
+	ForecastModeValue ForecastMode = "value"
+
+	// The number of seconds until the projected value reaches the threshold
+	ForecastModeTimeToThreshold ForecastMode = "time_to_threshold"
+)
+
+var supportedForecastModes = []string{
+	string(ForecastModeValue),
+	string(ForecastModeTimeToThreshold),
+}
+
+// ForecastCommand is an expression command that fits a model over each series and
+// returns a number projected from it, like predict_linear in PromQL.
+type ForecastCommand struct {
+	RefID        string
+	ReferenceVar string
+	Model        forecast.Model
+	Season       time.Duration
+	Mode         ForecastMode
+	Horizon      time.Duration
+	Threshold    float64
+}
+
+// NewForecastCommand creates a new ForecastCommand.
+func NewForecastCommand(refID, referenceVar string, model forecast.Model, rawSeason string, mode ForecastMode, rawHorizon string, threshold *float64) (*ForecastCommand, error) {
+	cmd := &ForecastCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Model:        model,
+		Mode:         mode,
+	}
+	if cmd.Model == "" {
+		cmd.Model = forecast.ModelLinear
+	}
+
+	var err error
+	if rawSeason != "" {
+		cmd.Season, err = gtime.ParseDuration(rawSeason)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "season" duration field %q: %w`, rawSeason, err)
+		}
+	}
+	switch cmd.Model {
+	case forecast.ModelLinear:
+		if cmd.Season != 0 {
+			return nil, fmt.Errorf("season is only supported by the %s model", forecast.ModelSeasonal)
+		}
+	case forecast.ModelSeasonal:
+		if cmd.Season <= 0 {
+			return nil, fmt.Errorf("season is required by the %s model", forecast.ModelSeasonal)
+		}
+	default:
+		return nil, fmt.Errorf("expected model to be one of [linear, seasonal], got %s", cmd.Model)
+	}
+
+	if rawHorizon != "" {
+		cmd.Horizon, err = gtime.ParseDuration(rawHorizon)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, rawHorizon, err)
+		}
+		if cmd.Horizon <= 0 {
+			return nil, fmt.Errorf("horizon must be positive, got %s", rawHorizon)
+		}
+	}
+	switch cmd.Mode {
+	case ForecastModeValue:
+		if cmd.Horizon == 0 {
+			return nil, errors.New("horizon is required to forecast a value")
+		}
+	case ForecastModeTimeToThreshold:
+		if threshold == nil {
+			return nil, errors.New("threshold is required to forecast the time to threshold")
+		}
+		if cmd.Model == forecast.ModelSeasonal && cmd.Horizon == 0 {
+			return nil, fmt.Errorf("horizon is required to forecast the time to threshold with the %s model", forecast.ModelSeasonal)
+		}
+		cmd.Threshold = *threshold
+	default:
+		return nil, fmt.Errorf("expected mode to be one of [%s], got %s", strings.Join(supportedForecastModes, ", "), cmd.Mode)
+	}
+	return cmd, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	q := ForecastQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the forecast command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewForecastCommand(rn.RefID, referenceVar, q.Model, q.Season, q.Mode, q.Horizon, q.Threshold)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. The projection starts at now, the time of the evaluation.
+func (fc *ForecastCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteForecast")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("model", string(fc.Model)),
+		attribute.String("mode", string(fc.Mode)),
+	)
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			n, err := fc.forecast(v, now)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// forecast returns a number with the projection of the series. The number has no value
+// if the series does not have enough points to fit the model.
+func (fc *ForecastCommand) forecast(s mathexp.Series, now time.Time) (mathexp.Number, error) {
+	n := mathexp.NewNumber(fc.RefID, s.GetLabels())
+	times := make([]time.Time, s.Len())
+	values := make([]*float64, s.Len())
+	for i := range times {
+		times[i], values[i] = s.GetPoint(i)
+	}
+
+	fitted, err := forecast.Fit(fc.Model, times, values, fc.Season)
+	if errors.Is(err, forecast.ErrNotEnoughPoints) {
+		return n, nil
+	}
+	if err != nil {
+		return n, err
+	}
+
+	switch fc.Mode {
+	case ForecastModeValue:
+		n.SetValue(util.Pointer(fitted.Predict(now.Add(fc.Horizon))))
+	case ForecastModeTimeToThreshold:
+		// A threshold that is never reached is infinitely far away, so that conditions such as
+		// "less than 4 hours" are false rather than missing a value.
+		seconds := math.Inf(1)
+		if d, ok := fitted.TimeToThreshold(now, fc.Threshold, fc.Horizon); ok {
+			seconds = d.Seconds()
+		}
+		n.SetValue(&seconds)
+	}
+	return n, nil
+}
+
+func (fc *ForecastCommand) Type() string {
+	return TypeForecast.String()
+}
@@ -0,0 +1,245 @@
+// Package forecast fits models over the points of a time series to project its
+// values into the future.
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The model used to forecast values
+// +enum
+type Model string
+
+const (
+	// Least squares linear regression over time
+	ModelLinear Model = "linear"
+
+	// Linear regression with an additive seasonal component
+	ModelSeasonal Model = "seasonal"
+)
+
+var supportedModels = []string{
+	string(ModelLinear),
+	string(ModelSeasonal),
+}
+
+// ErrNotEnoughPoints is returned by Fit when the series does not have enough valid points to fit the model.
+var ErrNotEnoughPoints = errors.New("not enough points to fit the model")
+
+// Fitted is a model fitted over the points of a series.
+type Fitted struct {
+	origin    time.Time
+	intercept float64
+	slope     float64 // per second
+
+	season  time.Duration
+	step    time.Duration
+	offsets []float64
+}
+
+// Fit fits the model over the points of a series. Null, NaN and infinite values are ignored.
+// The seasonal model requires season to be set and the points to cover at least one full season.
+func Fit(model Model, times []time.Time, values []*float64, season time.Duration) (*Fitted, error) {
+	switch model {
+	case ModelLinear:
+	case ModelSeasonal:
+		if season <= 0 {
+			return nil, errors.New("season must be set for the seasonal model")
+		}
+	default:
+		return nil, fmt.Errorf("expected model to be one of [%s], got %s", strings.Join(supportedModels, ", "), model)
+	}
+
+	var ts []time.Time
+	var vs []float64
+	for i, v := range values {
+		if v == nil || math.IsNaN(*v) || math.IsInf(*v, 0) {
+			continue
+		}
+		ts = append(ts, times[i])
+		vs = append(vs, *v)
+	}
+	if len(ts) < 2 {
+		return nil, ErrNotEnoughPoints
+	}
+
+	f := &Fitted{origin: ts[0]}
+	xs := make([]float64, len(ts))
+	for i, t := range ts {
+		xs[i] = t.Sub(f.origin).Seconds()
+	}
+	f.intercept, f.slope = leastSquares(xs, vs)
+	if model == ModelLinear {
+		return f, nil
+	}
+
+	if ts[len(ts)-1].Sub(ts[0]) < season {
+		return nil, fmt.Errorf("%w: the seasonal model requires at least one season of points", ErrNotEnoughPoints)
+	}
+	// The season is split in buckets of the typical interval between points, and the seasonal
+	// component of each bucket is the mean residual of the linear fit of the points that fall in it.
+	// As the season also skews the linear fit, the trend and the seasonal component are fitted
+	// alternately a few times, each on the values without the other.
+	f.season = season
+	f.step = medianInterval(ts)
+	if f.step <= 0 {
+		return nil, errors.New("the seasonal model requires points with distinct timestamps")
+	}
+	buckets := int(math.Round(float64(season) / float64(f.step)))
+	if buckets < 2 {
+		return nil, fmt.Errorf("season %v must be at least twice the interval between points %v", season, f.step)
+	}
+	if buckets > maxSeasonBuckets {
+		return nil, fmt.Errorf("season %v must be at most %d times the interval between points %v", season, maxSeasonBuckets, f.step)
+	}
+	f.offsets = make([]float64, buckets)
+	deseasonalized := make([]float64, len(vs))
+	for iteration := 0; iteration < seasonalIterations; iteration++ {
+		f.fitOffsets(ts, vs)
+		for i, t := range ts {
+			deseasonalized[i] = vs[i] - f.offsets[f.bucket(t)]
+		}
+		f.intercept, f.slope = leastSquares(xs, deseasonalized)
+	}
+	f.fitOffsets(ts, vs)
+	return f, nil
+}
+
+// seasonalIterations is the number of times the trend is refitted without the seasonal component.
+const seasonalIterations = 5
+
+// maxSeasonBuckets is the maximum number of intervals between points in a season. It is a week of points every
+// 10 seconds.
+const maxSeasonBuckets = 60480
+
+// fitOffsets sets the seasonal component of each bucket to the mean residual of the trend.
+func (f *Fitted) fitOffsets(ts []time.Time, vs []float64) {
+	counts := make([]int, len(f.offsets))
+	for b := range f.offsets {
+		f.offsets[b] = 0
+	}
+	for i, t := range ts {
+		b := f.bucket(t)
+		f.offsets[b] += vs[i] - f.trend(t)
+		counts[b]++
+	}
+	for b := range f.offsets {
+		if counts[b] > 0 {
+			f.offsets[b] /= float64(counts[b])
+		}
+	}
+}
+
+// Predict returns the projected value at time t.
+func (f *Fitted) Predict(t time.Time) float64 {
+	v := f.trend(t)
+	if f.offsets != nil {
+		v += f.offsets[f.bucket(t)]
+	}
+	return v
+}
+
+// TimeToThreshold returns how long after from the projected values reach the threshold, and false
+// if they never do. The threshold is reached when the projection crosses it from the side of the
+// projected value at from, so 0 is returned when that value is equal to the threshold. If limit is
+// greater than 0, thresholds that are reached after limit are treated as never reached. For the
+// seasonal model the projection is checked at every interval between points, and limit is required.
+// As the seasonal component repeats every season, the projection at d plus a number of seasons differs
+// from the one at d only by the trend over these seasons, so the first crossing is computed directly
+// for each interval of one season.
+func (f *Fitted) TimeToThreshold(from time.Time, threshold float64, limit time.Duration) (time.Duration, bool) {
+	current := f.Predict(from)
+	if current == threshold {
+		return 0, true
+	}
+	below := current < threshold
+
+	if f.offsets == nil {
+		if f.slope == 0 || (f.slope > 0) != below {
+			return 0, false
+		}
+		secs := (threshold - current) / f.slope
+		// A nearly flat trend reaches the threshold after more than time.Duration can hold.
+		if math.IsNaN(secs) || math.IsInf(secs, 0) || secs > float64(math.MaxInt64)/float64(time.Second) {
+			return 0, false
+		}
+		d := time.Duration(secs * float64(time.Second))
+		if limit > 0 && d > limit {
+			return 0, false
+		}
+		return d, true
+	}
+
+	if limit <= 0 {
+		return 0, false
+	}
+	perSeason := f.slope * f.season.Seconds()
+	var result time.Duration
+	found := false
+	for d := f.step; d <= f.season && d <= limit; d += f.step {
+		v := f.Predict(from.Add(d))
+		seasons := 0.0
+		if (below && v < threshold) || (!below && v > threshold) {
+			if perSeason == 0 || (perSeason > 0) != below {
+				continue
+			}
+			seasons = math.Ceil((threshold - v) / perSeason)
+		}
+		if seasons > float64(limit-d)/float64(f.season) {
+			continue
+		}
+		at := d + time.Duration(seasons)*f.season
+		if !found || at < result {
+			result, found = at, true
+		}
+	}
+	return result, found
+}
+
+func (f *Fitted) trend(t time.Time) float64 {
+	return f.intercept + f.slope*t.Sub(f.origin).Seconds()
+}
+
+// bucket returns the index of the seasonal bucket that t falls in.
+func (f *Fitted) bucket(t time.Time) int {
+	phase := t.Sub(f.origin) % f.season
+	if phase < 0 {
+		phase += f.season
+	}
+	return int(phase/f.step) % len(f.offsets)
+}
+
+// leastSquares returns the intercept and slope of the line that best fits the points.
+func leastSquares(xs, ys []float64) (float64, float64) {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var cov, varX float64
+	for i := range xs {
+		cov += (xs[i] - meanX) * (ys[i] - meanY)
+		varX += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if varX == 0 {
+		return meanY, 0
+	}
+	slope := cov / varX
+	return meanY - slope*meanX, slope
+}
+
+func medianInterval(ts []time.Time) time.Duration {
+	intervals := make([]time.Duration, 0, len(ts)-1)
+	for i := 1; i < len(ts); i++ {
+		intervals = append(intervals, ts[i].Sub(ts[i-1]))
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
@@ -0,0 +1,145 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Unix(1700000000, 0)
+
+// points returns one point per step, with the value of the function at the number of steps from start.
+func points(n int, step time.Duration, f func(i int) float64) ([]time.Time, []*float64) {
+	times := make([]time.Time, n)
+	values := make([]*float64, n)
+	for i := range times {
+		v := f(i)
+		times[i] = start.Add(time.Duration(i) * step)
+		values[i] = &v
+	}
+	return times, values
+}
+
+func TestFitLinear(t *testing.T) {
+	// 50 + 2 per minute
+	times, values := points(30, time.Minute, func(i int) float64 { return 50 + 2*float64(i) })
+	values[5] = nil
+	nan := math.NaN()
+	values[6] = &nan
+
+	f, err := Fit(ModelLinear, times, values, 0)
+	require.NoError(t, err)
+	require.InDelta(t, 50, f.Predict(start), 1e-9)
+	require.InDelta(t, 50+2*120, f.Predict(start.Add(2*time.Hour)), 1e-6)
+
+	now := start.Add(30 * time.Minute) // projected value 110
+	d, ok := f.TimeToThreshold(now, 150, 0)
+	require.True(t, ok)
+	require.Equal(t, 20*time.Minute, d.Round(time.Second))
+
+	_, ok = f.TimeToThreshold(now, 150, 10*time.Minute)
+	require.False(t, ok, "threshold is reached after the limit")
+
+	_, ok = f.TimeToThreshold(now, 50, 0)
+	require.False(t, ok, "a rising series never goes back down to the threshold")
+
+	d, ok = f.TimeToThreshold(now, 110, 0)
+	require.True(t, ok)
+	require.Equal(t, time.Duration(0), d.Round(time.Second))
+}
+
+func TestTimeToThresholdNearlyFlat(t *testing.T) {
+	// A slope this small reaches the threshold after more than time.Duration can hold.
+	f := &Fitted{origin: start, intercept: 100, slope: 1e-12}
+	d, ok := f.TimeToThreshold(start, 200, 0)
+	require.False(t, ok)
+	require.Equal(t, time.Duration(0), d)
+
+	f = &Fitted{origin: start, intercept: 100, slope: -1e-12}
+	_, ok = f.TimeToThreshold(start, math.Inf(-1), 0)
+	require.False(t, ok, "an infinite threshold is never reached")
+}
+
+func TestFitLinearFalling(t *testing.T) {
+	// free space going down by 1 per minute
+	times, values := points(10, time.Minute, func(i int) float64 { return 100 - float64(i) })
+	f, err := Fit(ModelLinear, times, values, 0)
+	require.NoError(t, err)
+	d, ok := f.TimeToThreshold(start.Add(10*time.Minute), 0, 0)
+	require.True(t, ok)
+	require.Equal(t, 90*time.Minute, d.Round(time.Second))
+}
+
+func TestFitSeasonal(t *testing.T) {
+	season := 24 * time.Hour
+	// hourly points over 3 days with a daily cycle and a slow upward trend
+	f := func(i int) float64 {
+		return 100 + 0.5*float64(i) + 20*math.Sin(2*math.Pi*float64(i)/24)
+	}
+	times, values := points(72, time.Hour, f)
+
+	fitted, err := Fit(ModelSeasonal, times, values, season)
+	require.NoError(t, err)
+	for _, i := range []int{78, 84, 90} {
+		require.InDelta(t, f(i), fitted.Predict(start.Add(time.Duration(i)*time.Hour)), 2)
+	}
+
+	linear, err := Fit(ModelLinear, times, values, 0)
+	require.NoError(t, err)
+	require.Greater(t, math.Abs(f(78)-linear.Predict(start.Add(78*time.Hour))), 10.0, "the linear model does not capture the season")
+
+	// the peak of the next cycle crosses 150 before the trend alone does
+	now := start.Add(72 * time.Hour)
+	d, ok := fitted.TimeToThreshold(now, 150, 48*time.Hour)
+	require.True(t, ok)
+	require.Less(t, d, 12*time.Hour)
+
+	_, ok = fitted.TimeToThreshold(now, 1000, 48*time.Hour)
+	require.False(t, ok)
+
+	// the crossing is computed directly for the following seasons, the same as checking every interval
+	for _, threshold := range []float64{150, 200, 1000} {
+		limit := 100 * 24 * time.Hour
+		expected, expectedOk := time.Duration(0), false
+		for d := time.Hour; d <= limit; d += time.Hour {
+			if fitted.Predict(now.Add(d)) >= threshold {
+				expected, expectedOk = d, true
+				break
+			}
+		}
+		d, ok := fitted.TimeToThreshold(now, threshold, limit)
+		require.Equal(t, expectedOk, ok)
+		require.Equal(t, expected, d)
+	}
+
+	// a limit of many intervals does not check all of them
+	d, ok = fitted.TimeToThreshold(now, 1e9, 100*365*24*time.Hour)
+	require.False(t, ok)
+	require.Equal(t, time.Duration(0), d)
+}
+
+func TestFitErrors(t *testing.T) {
+	times, values := points(10, time.Hour, func(i int) float64 { return float64(i) })
+
+	_, err := Fit("prophet", times, values, 0)
+	require.ErrorContains(t, err, "expected model to be one of")
+
+	_, err = Fit(ModelSeasonal, times, values, 0)
+	require.ErrorContains(t, err, "season must be set")
+
+	_, err = Fit(ModelSeasonal, times, values, 24*time.Hour)
+	require.ErrorIs(t, err, ErrNotEnoughPoints)
+
+	_, err = Fit(ModelLinear, times[:1], values[:1], 0)
+	require.ErrorIs(t, err, ErrNotEnoughPoints)
+
+	_, err = Fit(ModelSeasonal, times, values, time.Hour)
+	require.ErrorContains(t, err, "at least twice the interval")
+
+	// the median interval is a second, a day has too many of them
+	times = []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second), start.Add(24 * time.Hour)}
+	_, err = Fit(ModelSeasonal, times, values[:4], 24*time.Hour)
+	require.ErrorContains(t, err, "at most 60480 times the interval")
+}
@@ -0,0 +1,177 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/forecast"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalForecastCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected *ForecastCommand
+		errMsg   string
+	}{
+		{
+			name:  "linear value",
+			query: `{"expression": "$A", "mode": "value", "horizon": "4h"}`,
+			expected: &ForecastCommand{
+				RefID:        "B",
+				ReferenceVar: "A",
+				Model:        forecast.ModelLinear,
+				Mode:         ForecastModeValue,
+				Horizon:      4 * time.Hour,
+			},
+		},
+		{
+			name:  "seasonal time to threshold",
+			query: `{"expression": "A", "model": "seasonal", "season": "1d", "mode": "time_to_threshold", "horizon": "7d", "threshold": 95}`,
+			expected: &ForecastCommand{
+				RefID:        "B",
+				ReferenceVar: "A",
+				Model:        forecast.ModelSeasonal,
+				Season:       24 * time.Hour,
+				Mode:         ForecastModeTimeToThreshold,
+				Horizon:      7 * 24 * time.Hour,
+				Threshold:    95,
+			},
+		},
+		{
+			name:   "value without horizon",
+			query:  `{"expression": "$A", "mode": "value"}`,
+			errMsg: "horizon is required",
+		},
+		{
+			name:   "time to threshold without threshold",
+			query:  `{"expression": "$A", "mode": "time_to_threshold"}`,
+			errMsg: "threshold is required",
+		},
+		{
+			name:   "seasonal without season",
+			query:  `{"expression": "$A", "model": "seasonal", "mode": "value", "horizon": "1h"}`,
+			errMsg: "season is required",
+		},
+		{
+			name:   "seasonal time to threshold without horizon",
+			query:  `{"expression": "$A", "model": "seasonal", "season": "1d", "mode": "time_to_threshold", "threshold": 1}`,
+			errMsg: "horizon is required",
+		},
+		{
+			name:   "unknown mode",
+			query:  `{"expression": "$A", "mode": "range", "horizon": "1h"}`,
+			errMsg: "expected mode to be one of",
+		},
+		{
+			name:   "invalid horizon",
+			query:  `{"expression": "$A", "mode": "value", "horizon": "soon"}`,
+			errMsg: `failed to parse forecast "horizon" duration`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(tt.query), &q))
+			cmd, err := UnmarshalForecastCommand(&rawNode{
+				RefID:    "B",
+				Query:    q,
+				QueryRaw: []byte(tt.query),
+			})
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cmd)
+		})
+	}
+}
+
+func TestForecastCommandExecute(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start.Add(time.Hour)
+	// disk usage in percent, growing by 10% per hour
+	series := func(labels data.Labels, n int) mathexp.Series {
+		s := mathexp.NewSeries("A", labels, n)
+		for i := 0; i < n; i++ {
+			s.SetPoint(i, start.Add(time.Duration(i)*time.Minute), util.Pointer(50+float64(i)/6))
+		}
+		return s
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			series(data.Labels{"device": "sda"}, 60),
+			series(data.Labels{"device": "sdb"}, 1),
+		}},
+	}
+	tracer := tracing.InitializeTracerForTest()
+
+	t.Run("value at horizon", func(t *testing.T) {
+		cmd, err := NewForecastCommand("B", "A", forecast.ModelLinear, "", ForecastModeValue, "4h", nil)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), now, vars, tracer)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		require.Equal(t, data.Labels{"device": "sda"}, res.Values[0].GetLabels())
+		require.InDelta(t, 100, *res.Values[0].(mathexp.Number).GetFloat64Value(), 1e-6)
+		require.Nil(t, res.Values[1].(mathexp.Number).GetFloat64Value(), "a single point can not be projected")
+	})
+
+	t.Run("time to threshold", func(t *testing.T) {
+		cmd, err := NewForecastCommand("B", "A", forecast.ModelLinear, "", ForecastModeTimeToThreshold, "", util.Pointer(100.0))
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), now, vars, tracer)
+		require.NoError(t, err)
+		require.InDelta(t, 4*time.Hour.Seconds(), *res.Values[0].(mathexp.Number).GetFloat64Value(), 1e-3)
+	})
+
+	t.Run("threshold that is never reached", func(t *testing.T) {
+		cmd, err := NewForecastCommand("B", "A", forecast.ModelLinear, "", ForecastModeTimeToThreshold, "", util.Pointer(10.0))
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), now, vars, tracer)
+		require.NoError(t, err)
+		require.True(t, math.IsInf(*res.Values[0].(mathexp.Number).GetFloat64Value(), 1))
+	})
+
+	t.Run("composes with threshold", func(t *testing.T) {
+		cmd, err := NewForecastCommand("B", "A", forecast.ModelLinear, "", ForecastModeTimeToThreshold, "", util.Pointer(100.0))
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), now, vars, tracer)
+		require.NoError(t, err)
+
+		threshold, err := NewThresholdCommand("C", "B", ThresholdIsBelow, []float64{5 * time.Hour.Seconds()})
+		require.NoError(t, err)
+		res, err = threshold.Execute(context.Background(), now, mathexp.Vars{"B": res}, tracer)
+		require.NoError(t, err)
+		require.Equal(t, 1.0, *res.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("no data returns no data", func(t *testing.T) {
+		cmd, err := NewForecastCommand("B", "A", forecast.ModelLinear, "", ForecastModeValue, "1h", nil)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), now, mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}},
+		}, tracer)
+		require.NoError(t, err)
+		require.IsType(t, mathexp.NoData{}, res.Values[0])
+	})
+
+	t.Run("numbers are not supported", func(t *testing.T) {
+		cmd, err := NewForecastCommand("B", "A", forecast.ModelLinear, "", ForecastModeValue, "1h", nil)
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), now, mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracer)
+		require.ErrorContains(t, err, "can only forecast type series")
+	})
+}
@@ -0,0 +1,277 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// aggregateGroup is the set of items of an aggregation that share the same grouping labels.
+type aggregateGroup struct {
+	labels data.Labels
+	values []Value
+}
+
+// walkAggregate evaluates an aggregation operator such as sum by (host) ($A).
+// The items of the argument are split into groups by their labels, and each group
+// results in a single item, or in the case of topk and bottomk in at most k items.
+func (e *State) walkAggregate(node *parse.AggregateNode) (Results, error) {
+	newRes := Results{}
+	res, err := e.walk(node.Arg)
+	if err != nil {
+		return newRes, err
+	}
+
+	var k int
+	if node.Param != nil {
+		param, err := e.walk(node.Param)
+		if err != nil {
+			return newRes, err
+		}
+		f, err := scalarArg(node.Operator, param)
+		if err != nil {
+			return newRes, err
+		}
+		if f < 1 || f != math.Trunc(f) {
+			return newRes, fmt.Errorf("%s: parameter must be a positive integer, got %v", node.Operator, f)
+		}
+		k = int(f)
+	}
+
+	groups, err := groupValues(res, node.Grouping, node.Without)
+	if err != nil {
+		return newRes, fmt.Errorf("%s: %w", node.Operator, err)
+	}
+	if len(groups) == 0 {
+		newRes.Values = append(newRes.Values, NewNoData())
+		return newRes, nil
+	}
+
+	for _, g := range groups {
+		switch node.Operator {
+		case "topk", "bottomk":
+			newRes.Values = append(newRes.Values, e.selectK(g, k, node.Operator == "topk")...)
+		default:
+			value, err := e.aggregateGroup(g, node.Operator)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, value)
+		}
+	}
+	return newRes, nil
+}
+
+// groupValues splits the numbers or series of the results into groups. With by, items are grouped by the
+// values of the grouping labels. With without, items are grouped by all their labels except the grouping labels.
+// Without a grouping clause, all items fall into a single group. NoData items are ignored.
+func groupValues(res Results, grouping []string, without bool) ([]*aggregateGroup, error) {
+	var groups []*aggregateGroup
+	byFingerprint := make(map[data.Fingerprint]*aggregateGroup)
+	var valueType parse.ReturnType
+	for _, val := range res.Values {
+		switch val.Type() {
+		case parse.TypeNoData:
+			continue
+		case parse.TypeNumberSet, parse.TypeSeriesSet:
+		default:
+			return nil, fmt.Errorf("can only aggregate numbers or series, got type %v", val.Type())
+		}
+		if valueType != 0 && valueType != val.Type() {
+			return nil, fmt.Errorf("can not aggregate a mix of %v and %v", valueType, val.Type())
+		}
+		valueType = val.Type()
+
+		labels := groupLabels(val.GetLabels(), grouping, without)
+		fp := labels.Fingerprint()
+		g, ok := byFingerprint[fp]
+		if !ok {
+			g = &aggregateGroup{labels: labels}
+			byFingerprint[fp] = g
+			groups = append(groups, g)
+		}
+		g.values = append(g.values, val)
+	}
+	return groups, nil
+}
+
+// groupLabels returns the labels that identify the group the labels belong to.
+func groupLabels(labels data.Labels, grouping []string, without bool) data.Labels {
+	result := data.Labels{}
+	if without {
+		for k, v := range labels {
+			result[k] = v
+		}
+		for _, name := range grouping {
+			delete(result, name)
+		}
+		return result
+	}
+	for _, name := range grouping {
+		if v, ok := labels[name]; ok {
+			result[name] = v
+		}
+	}
+	return result
+}
+
+// aggregateGroup combines the items of the group into a single Number or Series. Series are combined
+// point by point, using the points that share the same timestamp.
+func (e *State) aggregateGroup(g *aggregateGroup, op string) (Value, error) {
+	if _, ok := g.values[0].(Number); ok {
+		vals := make([]*float64, 0, len(g.values))
+		for _, v := range g.values {
+			vals = append(vals, v.(Number).GetFloat64Value())
+		}
+		f, err := aggregateFloats(op, vals)
+		if err != nil {
+			return nil, err
+		}
+		n := NewNumber(e.RefID, g.labels)
+		n.SetValue(f)
+		return n, nil
+	}
+
+	pointsByTime := make(map[time.Time][]*float64)
+	var times []time.Time
+	for _, v := range g.values {
+		s := v.(Series)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			t = t.UTC()
+			if _, ok := pointsByTime[t]; !ok {
+				times = append(times, t)
+			}
+			pointsByTime[t] = append(pointsByTime[t], f)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	newSeries := NewSeries(e.RefID, g.labels, len(times))
+	for i, t := range times {
+		f, err := aggregateFloats(op, pointsByTime[t])
+		if err != nil {
+			return nil, err
+		}
+		newSeries.SetPoint(i, t, f)
+	}
+	return newSeries, nil
+}
+
+// aggregateFloats applies the aggregation operator to the values. Null values are ignored,
+// and nil is returned if there are no other values, except for count that returns 0.
+func aggregateFloats(op string, vals []*float64) (*float64, error) {
+	var result float64
+	count := 0
+	for _, v := range vals {
+		if v == nil {
+			continue
+		}
+		switch {
+		case op == "count":
+		case count == 0:
+			result = *v
+		case op == "sum", op == "avg":
+			result += *v
+		case op == "min":
+			result = math.Min(result, *v)
+		case op == "max":
+			result = math.Max(result, *v)
+		default:
+			return nil, fmt.Errorf("aggregation %v not implemented", op)
+		}
+		count++
+	}
+	switch {
+	case op == "count":
+		result = float64(count)
+	case count == 0:
+		return nil, nil
+	case op == "avg":
+		result /= float64(count)
+	}
+	return &result, nil
+}
+
+// selectK returns the k items of the group with the largest (or smallest if top is false) values.
+// For series, the comparison is done point by point, and each series only keeps the points where it
+// is among the k largest (or smallest) values. Series that are never selected are dropped.
+// Null and NaN values are never selected.
+func (e *State) selectK(g *aggregateGroup, k int, top bool) []Value {
+	less := func(a, b float64) bool {
+		if top {
+			return a > b
+		}
+		return a < b
+	}
+	valid := func(f *float64) bool {
+		return f != nil && !math.IsNaN(*f)
+	}
+
+	if _, ok := g.values[0].(Number); ok {
+		numbers := make([]Number, 0, len(g.values))
+		for _, v := range g.values {
+			if n := v.(Number); valid(n.GetFloat64Value()) {
+				numbers = append(numbers, n)
+			}
+		}
+		sort.SliceStable(numbers, func(i, j int) bool {
+			return less(*numbers[i].GetFloat64Value(), *numbers[j].GetFloat64Value())
+		})
+		result := make([]Value, 0, k)
+		for _, n := range numbers[:min(k, len(numbers))] {
+			copyN := NewNumber(e.RefID, n.GetLabels())
+			copyN.SetValue(n.GetFloat64Value())
+			result = append(result, copyN)
+		}
+		return result
+	}
+
+	type point struct {
+		series int
+		value  float64
+	}
+	pointsByTime := make(map[time.Time][]point)
+	for i, v := range g.values {
+		s := v.(Series)
+		for j := 0; j < s.Len(); j++ {
+			t, f := s.GetPoint(j)
+			if !valid(f) {
+				continue
+			}
+			pointsByTime[t.UTC()] = append(pointsByTime[t.UTC()], point{series: i, value: *f})
+		}
+	}
+	selected := make([]map[time.Time]bool, len(g.values))
+	for t, points := range pointsByTime {
+		sort.SliceStable(points, func(i, j int) bool { return less(points[i].value, points[j].value) })
+		for _, p := range points[:min(k, len(points))] {
+			if selected[p.series] == nil {
+				selected[p.series] = make(map[time.Time]bool)
+			}
+			selected[p.series][t] = true
+		}
+	}
+
+	var result []Value
+	for i, v := range g.values {
+		if selected[i] == nil {
+			continue
+		}
+		s := v.(Series)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+		for j := 0; j < s.Len(); j++ {
+			t, f := s.GetPoint(j)
+			if selected[i][t.UTC()] {
+				newSeries.AppendPoint(t, f)
+			}
+		}
+		result = append(result, newSeries)
+	}
+	return result
+}
@@ -0,0 +1,193 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestAggregate(t *testing.T) {
+	numbers := Vars{
+		"A": resultValuesNoErr(
+			makeNumber("", data.Labels{"cluster": "a", "pod": "1"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"cluster": "a", "pod": "2"}, float64Pointer(4)),
+			makeNumber("", data.Labels{"cluster": "b", "pod": "1"}, float64Pointer(2)),
+			makeNumber("", data.Labels{"cluster": "b", "pod": "2"}, nil),
+		),
+	}
+	series := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", data.Labels{"cluster": "a", "pod": "1"},
+				tp{time.Unix(5, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(5)}),
+			makeSeries("", data.Labels{"cluster": "a", "pod": "2"},
+				tp{time.Unix(5, 0), float64Pointer(3)},
+				tp{time.Unix(10, 0), float64Pointer(2)},
+				tp{time.Unix(15, 0), float64Pointer(7)}),
+		),
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "sum by on numbers",
+			expr:      "sum by (cluster) ($A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(5)),
+				makeNumber("", data.Labels{"cluster": "b"}, float64Pointer(2)),
+			),
+		},
+		{
+			name:      "grouping after the argument",
+			expr:      "max($A) by (cluster)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"cluster": "b"}, float64Pointer(2)),
+			),
+		},
+		{
+			name:      "count without on numbers",
+			expr:      "count without (pod) ($A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"cluster": "b"}, float64Pointer(1)),
+			),
+		},
+		{
+			name:      "avg without grouping on numbers",
+			expr:      "avg($A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{}, float64Pointer(7.0/3)),
+			),
+		},
+		{
+			name:      "aggregation used in a binary operation",
+			expr:      "min by (cluster) ($A) * 10",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"cluster": "b"}, float64Pointer(20)),
+			),
+		},
+		{
+			name:      "topk on numbers",
+			expr:      "topk(2, $A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a", "pod": "2"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"cluster": "b", "pod": "1"}, float64Pointer(2)),
+			),
+		},
+		{
+			name:      "bottomk by on numbers",
+			expr:      "bottomk by (cluster) (1, $A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a", "pod": "1"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"cluster": "b", "pod": "1"}, float64Pointer(2)),
+			),
+		},
+		{
+			name:      "sum on series aligns points by time",
+			expr:      "sum by (cluster) ($A)",
+			vars:      series,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"cluster": "a"},
+					tp{time.Unix(5, 0).UTC(), float64Pointer(4)},
+					tp{time.Unix(10, 0).UTC(), float64Pointer(7)},
+					tp{time.Unix(15, 0).UTC(), float64Pointer(7)}),
+			),
+		},
+		{
+			name:      "topk on series selects per point",
+			expr:      "topk(1, $A)",
+			vars:      series,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"cluster": "a", "pod": "1"},
+					tp{time.Unix(10, 0), float64Pointer(5)}),
+				makeSeries("", data.Labels{"cluster": "a", "pod": "2"},
+					tp{time.Unix(5, 0), float64Pointer(3)},
+					tp{time.Unix(15, 0), float64Pointer(7)}),
+			),
+		},
+		{
+			name:      "topk with a parameter that is not a positive integer",
+			expr:      "topk(0, $A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:     "topk without a parameter",
+			expr:     "topk($A)",
+			vars:     numbers,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "aggregation of a scalar",
+			expr:     "sum(1)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "unterminated grouping",
+			expr:     "sum by (cluster ($A)",
+			newErrIs: require.Error,
+		},
+		{
+			name:      "aggregation of no data",
+			expr:      "sum by (cluster) ($A)",
+			vars:      Vars{"A": resultValuesNoErr(NewNoData())},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(NewNoData()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			tt.execErrIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
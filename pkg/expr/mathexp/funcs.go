@@ -1,7 +1,9 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"time"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +70,36 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clamp,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeDuration},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"diff": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      diff,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +339,166 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// clamp limits the value for each result in NumberSet, SeriesSet, or Scalar to the range [min, max].
+func clamp(e *State, varSet Results, minRes, maxRes Results) (Results, error) {
+	newRes := Results{}
+	minV, err := scalarArg("clamp", minRes)
+	if err != nil {
+		return newRes, err
+	}
+	maxV, err := scalarArg("clamp", maxRes)
+	if err != nil {
+		return newRes, err
+	}
+	if minV > maxV {
+		return newRes, fmt.Errorf("clamp: min %v must not be greater than max %v", minV, maxV)
+	}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Max(minV, math.Min(maxV, f))
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// movingAvg returns for each point of each series the average of the non-null values of the
+// last n points, including the point itself. Points at the start of the series average the available points.
+func movingAvg(e *State, varSet Results, nRes Results) (Results, error) {
+	n, err := scalarArg("moving_avg", nRes)
+	if err != nil {
+		return Results{}, err
+	}
+	if n < 1 || n != math.Trunc(n) {
+		return Results{}, fmt.Errorf("moving_avg: window must be a positive integer, got %v", n)
+	}
+	window := int(n)
+	return perSeries(e, "moving_avg", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			var sum float64
+			var count int
+			for j := max(0, i-window+1); j <= i; j++ {
+				if v := s.GetValue(j); v != nil {
+					sum += *v
+					count++
+				}
+			}
+			var value *float64
+			if count > 0 {
+				avg := sum / float64(count)
+				value = &avg
+			}
+			newSeries.SetPoint(i, s.GetTime(i), value)
+		}
+		return newSeries
+	})
+}
+
+// shift moves the timestamps of each series forward by the duration, so that the series can be
+// compared with itself at an earlier time, e.g. $A / shift($A, 1w).
+func shift(e *State, varSet Results, d time.Duration) (Results, error) {
+	return perSeries(e, "shift", varSet, func(s Series) Series {
+		return s.Shift(e.RefID, d)
+	})
+}
+
+// diff returns for each series the difference between each point and the previous one.
+// The first point of the series is dropped.
+func diff(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "diff", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+		for i := 1; i < s.Len(); i++ {
+			t, v := s.GetPoint(i)
+			prev := s.GetValue(i - 1)
+			if v == nil || prev == nil {
+				newSeries.AppendPoint(t, nil)
+				continue
+			}
+			d := *v - *prev
+			newSeries.AppendPoint(t, &d)
+		}
+		return newSeries
+	})
+}
+
+// cumsum returns for each series the cumulative sum of its values. Null values are kept as
+// null and do not contribute to the sum.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, v := s.GetPoint(i)
+			if v == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *v
+			total := sum
+			newSeries.SetPoint(i, t, &total)
+		}
+		return newSeries
+	})
+}
+
+// rate returns for each series the per-second rate of increase between each point and the previous one.
+// A value lower than the previous one is treated as a counter reset. The first point of the series is dropped.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+		for i := 1; i < s.Len(); i++ {
+			t, v := s.GetPoint(i)
+			prevT, prev := s.GetPoint(i - 1)
+			elapsed := t.Sub(prevT).Seconds()
+			if v == nil || prev == nil || elapsed <= 0 {
+				newSeries.AppendPoint(t, nil)
+				continue
+			}
+			increase := *v - *prev
+			if *v < *prev {
+				increase = *v
+			}
+			r := increase / elapsed
+			newSeries.AppendPoint(t, &r)
+		}
+		return newSeries
+	})
+}
+
+// perSeries passes each Series of the results to seriesF. NoData is passed through,
+// any other type results in an error since the function only applies to time series.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newRes.Values = append(newRes.Values, seriesF(v))
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s: can only be applied to series, got type %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(name string, res Results) (float64, error) {
+	if len(res.Values) != 1 {
+		return 0, fmt.Errorf("%s: expected a single scalar argument", name)
+	}
+	scalar, ok := res.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("%s: expected a scalar argument, got type %v", name, res.Values[0].Type())
+	}
+	f := scalar.GetFloat64Value()
+	if f == nil || math.IsNaN(*f) {
+		return 0, fmt.Errorf("%s: scalar argument must be a number", name)
+	}
+	return *f, nil
+}
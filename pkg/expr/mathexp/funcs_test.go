@@ -140,3 +140,167 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesFuncs(t *testing.T) {
+	counter := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(2)},
+				tp{time.Unix(10, 0), float64Pointer(4)},
+				tp{time.Unix(20, 0), nil},
+				tp{time.Unix(30, 0), float64Pointer(1)},
+				tp{time.Unix(40, 0), float64Pointer(6)}),
+		),
+	}
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "moving_avg on series",
+			expr:      "moving_avg($A, 2)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), float64Pointer(4)},
+					tp{time.Unix(30, 0), float64Pointer(1)},
+					tp{time.Unix(40, 0), float64Pointer(3.5)}),
+			),
+		},
+		{
+			name:      "moving_avg with a window that is not a positive integer",
+			expr:      "moving_avg($A, 1.5)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "moving_avg on number",
+			expr:      "moving_avg($A, 2)",
+			vars:      Vars{"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(1)))},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "shift on series",
+			expr:      "shift($A, 1m)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(60, 0), float64Pointer(2)},
+					tp{time.Unix(70, 0), float64Pointer(4)},
+					tp{time.Unix(80, 0), nil},
+					tp{time.Unix(90, 0), float64Pointer(1)},
+					tp{time.Unix(100, 0), float64Pointer(6)}),
+			),
+		},
+		{
+			name:     "shift with a number instead of a duration",
+			expr:     "shift($A, 60)",
+			vars:     counter,
+			newErrIs: require.Error,
+		},
+		{
+			name:      "diff on series",
+			expr:      "diff($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(5)}),
+			),
+		},
+		{
+			name:      "cumsum on series",
+			expr:      "cumsum($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(6)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(7)},
+					tp{time.Unix(40, 0), float64Pointer(13)}),
+			),
+		},
+		{
+			name: "rate on series handles counter reset",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(10)},
+						tp{time.Unix(10, 0), float64Pointer(30)},
+						tp{time.Unix(20, 0), float64Pointer(5)}),
+				),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(0.5)}),
+			),
+		},
+		{
+			name:      "clamp on number",
+			expr:      "clamp($A, 0, 5)",
+			vars:      Vars{"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(7)))},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(makeNumber("", nil, float64Pointer(5))),
+		},
+		{
+			name:      "clamp with min greater than max",
+			expr:      "clamp($A, 5, 0)",
+			vars:      Vars{"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(7)))},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "comparison with a shifted series",
+			expr:      "$A / shift($A, 10s)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(6)}),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			tt.execErrIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package mathexp
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// matchUnion creates Union objects for a binary operation that has a vector matching clause,
+// such as $A / on(host) $B or $A / ignoring(instance) group_left $B. Unlike union, items are only
+// matched when their matching labels are equal, and an error is returned when the matching is ambiguous.
+func (e *State) matchUnion(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	unions := []*Union{}
+	if len(aResults.Values) == 0 || len(bResults.Values) == 0 {
+		return unions, nil
+	}
+	// Matching labels only apply to sets of labelled items, so a scalar or no data on either side is
+	// combined as it would be without the matching clause.
+	if isScalarOrNoData(aResults) || isScalarOrNoData(bResults) {
+		return e.union(aResults, bResults, biNode), nil
+	}
+
+	m := biNode.Matching
+	_, err := matchingKeys(aResults, m, biNode, "left", m.Card != parse.CardManyToOne)
+	if err != nil {
+		return nil, err
+	}
+	bKeys, err := matchingKeys(bResults, m, biNode, "right", m.Card != parse.CardOneToMany)
+	if err != nil {
+		return nil, err
+	}
+
+	aMatched := make([]bool, len(aResults.Values))
+	bMatched := make([]bool, len(bResults.Values))
+	resultLabels := make(map[data.Fingerprint]struct{})
+	for aIdx, a := range aResults.Values {
+		if a.Type() == parse.TypeNoData {
+			continue
+		}
+		for _, bIdx := range bKeys[matchingLabels(a.GetLabels(), m).Fingerprint()] {
+			b := bResults.Values[bIdx]
+			labels := matchResultLabels(a.GetLabels(), b.GetLabels(), m)
+			lfp := labels.Fingerprint()
+			if _, ok := resultLabels[lfp]; ok {
+				return nil, fmt.Errorf("multiple matches for labels %s in %q: grouping labels must ensure unique matches", labels, biNode)
+			}
+			resultLabels[lfp] = struct{}{}
+			unions = append(unions, &Union{
+				Labels: labels,
+				A:      a,
+				B:      b,
+			})
+			aMatched[aIdx] = true
+			bMatched[bIdx] = true
+		}
+	}
+
+	e.addMatchDrops(biNode, biNode.Args[0].String(), aMatched, aResults)
+	e.addMatchDrops(biNode, biNode.Args[1].String(), bMatched, bResults)
+	return unions, nil
+}
+
+// matchingKeys groups the indexes of the items of the results by the fingerprint of their matching labels.
+// If unique is true, an error is returned when more than one item has the same matching labels.
+func matchingKeys(res Results, m *parse.VectorMatching, biNode *parse.BinaryNode, side string, unique bool) (map[data.Fingerprint][]int, error) {
+	keys := make(map[data.Fingerprint][]int, len(res.Values))
+	for i, val := range res.Values {
+		if val.Type() == parse.TypeNoData {
+			continue
+		}
+		labels := matchingLabels(val.GetLabels(), m)
+		fp := labels.Fingerprint()
+		if unique && len(keys[fp]) > 0 {
+			if m.Card == parse.CardOneToOne {
+				return nil, fmt.Errorf("found multiple items matching labels %s on the %s side of %q: use group_left or group_right to match many to one", labels, side, biNode)
+			}
+			return nil, fmt.Errorf("found multiple items matching labels %s on the %s side of %q: many-to-many matching is not supported", labels, side, biNode)
+		}
+		keys[fp] = append(keys[fp], i)
+	}
+	return keys, nil
+}
+
+// matchingLabels returns the subset of the labels that is used to match items.
+func matchingLabels(labels data.Labels, m *parse.VectorMatching) data.Labels {
+	if m.On {
+		return groupLabels(labels, m.MatchingLabels, false)
+	}
+	return groupLabels(labels, m.MatchingLabels, true)
+}
+
+// matchResultLabels returns the labels of the result of matching the items with labels a and b.
+// For one-to-one matching these are the matching labels. Otherwise, these are the labels of the "many"
+// side with the included labels copied from the "one" side.
+func matchResultLabels(a, b data.Labels, m *parse.VectorMatching) data.Labels {
+	var many, one data.Labels
+	switch m.Card {
+	case parse.CardManyToOne:
+		many, one = a, b
+	case parse.CardOneToMany:
+		many, one = b, a
+	default:
+		return matchingLabels(a, m)
+	}
+	result := many.Copy()
+	if result == nil {
+		result = data.Labels{}
+	}
+	for _, name := range m.Include {
+		if v, ok := one[name]; ok {
+			result[name] = v
+		} else {
+			delete(result, name)
+		}
+	}
+	return result
+}
+
+// addMatchDrops records the items of one side of the binary operation that did not match any item.
+func (e *State) addMatchDrops(biNode *parse.BinaryNode, side string, matched []bool, res Results) {
+	for i, ok := range matched {
+		if ok || res.Values[i].Type() == parse.TypeNoData {
+			continue
+		}
+		if e.Drops == nil {
+			e.Drops = make(map[string]map[string][]data.Labels)
+		}
+		if e.Drops[biNode.String()] == nil {
+			e.Drops[biNode.String()] = make(map[string][]data.Labels)
+		}
+		e.DropCount++
+		e.Drops[biNode.String()][side] = append(e.Drops[biNode.String()][side], res.Values[i].GetLabels())
+	}
+}
+
+func isScalarOrNoData(res Results) bool {
+	return len(res.Values) == 1 && (res.Values[0].Type() == parse.TypeScalar || res.Values[0].Type() == parse.TypeNoData)
+}
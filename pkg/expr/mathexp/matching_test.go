@@ -0,0 +1,153 @@
+package mathexp
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestVectorMatching(t *testing.T) {
+	errorsAndRequests := Vars{
+		"A": resultValuesNoErr(
+			makeNumber("", data.Labels{"host": "a", "instance": "1", "code": "500"}, float64Pointer(2)),
+			makeNumber("", data.Labels{"host": "a", "instance": "1", "code": "404"}, float64Pointer(4)),
+			makeNumber("", data.Labels{"host": "b", "instance": "2", "code": "500"}, float64Pointer(3)),
+		),
+		"B": resultValuesNoErr(
+			makeNumber("", data.Labels{"host": "a", "dc": "east"}, float64Pointer(10)),
+			makeNumber("", data.Labels{"host": "b", "dc": "west"}, float64Pointer(30)),
+			makeNumber("", data.Labels{"host": "c", "dc": "west"}, float64Pointer(50)),
+		),
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name: "on matches one to one on the given labels",
+			expr: "$A / on(host) $B",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"host": "a", "instance": "1"}, float64Pointer(2)),
+					makeNumber("", data.Labels{"host": "b", "instance": "2"}, float64Pointer(3)),
+				),
+				"B": errorsAndRequests["B"],
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(0.2)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(0.1)),
+			),
+		},
+		{
+			name: "ignoring matches one to one on the other labels",
+			expr: "$A - ignoring(instance) $B",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"host": "a", "instance": "1"}, float64Pointer(2)),
+				),
+				"B": resultValuesNoErr(
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+					makeNumber("", data.Labels{"host": "b"}, float64Pointer(1)),
+				),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(1)),
+			),
+		},
+		{
+			name:      "one to one with several items on one side is an error",
+			expr:      "$A / on(host) $B",
+			vars:      errorsAndRequests,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "group_left matches many to one and includes labels",
+			expr:      "$A / on(host) group_left(dc) $B",
+			vars:      errorsAndRequests,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a", "instance": "1", "code": "500", "dc": "east"}, float64Pointer(0.2)),
+				makeNumber("", data.Labels{"host": "a", "instance": "1", "code": "404", "dc": "east"}, float64Pointer(0.4)),
+				makeNumber("", data.Labels{"host": "b", "instance": "2", "code": "500", "dc": "west"}, float64Pointer(0.1)),
+			),
+		},
+		{
+			name:      "group_right matches one to many",
+			expr:      "$B * on(host) group_right $A",
+			vars:      errorsAndRequests,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a", "instance": "1", "code": "500"}, float64Pointer(20)),
+				makeNumber("", data.Labels{"host": "a", "instance": "1", "code": "404"}, float64Pointer(40)),
+				makeNumber("", data.Labels{"host": "b", "instance": "2", "code": "500"}, float64Pointer(90)),
+			),
+		},
+		{
+			name:      "group_left with several items on the one side is an error",
+			expr:      "$B / on(host) group_left $A",
+			vars:      errorsAndRequests,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name: "matching with a scalar applies to each item",
+			expr: "$A * on(host) 2",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"host": "a"}, float64Pointer(2)),
+				),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(4)),
+			),
+		},
+		{
+			name:     "label in both on and group_left is an error",
+			expr:     "$A / on(host) group_left(host) $B",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "group_left without on or ignoring is an error",
+			expr:     "$A / group_left $B",
+			newErrIs: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			tt.execErrIs(t, err)
+			if err != nil {
+				return
+			}
+			// Unmatched items are reported in notices, so only labels and values are compared.
+			require.Len(t, res.Values, len(tt.results.Values))
+			for i, expected := range tt.results.Values {
+				require.Equal(t, expected.GetLabels(), res.Values[i].GetLabels())
+				require.InDelta(t, *expected.(Number).GetFloat64Value(), *res.Values[i].(Number).GetFloat64Value(), 1e-9)
+			}
+		})
+	}
+}
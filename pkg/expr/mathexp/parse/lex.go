@@ -56,8 +56,9 @@ const (
 	itemRightParen
 	itemString
 	itemFunc
-	itemVar // e.g. $A
-	itemPow // '**'
+	itemVar      // e.g. $A
+	itemPow      // '**'
+	itemDuration // e.g. 1d, 5m
 )
 
 const eof = -1
@@ -89,7 +90,6 @@ func (l *lexer) next() rune {
 }
 
 // peek returns but does not consume the next rune in the input.
-// nolint:unused
 func (l *lexer) peek() rune {
 	r := l.next()
 	l.backup()
@@ -180,7 +180,7 @@ Loop:
 		case isNumber(r):
 			l.backup()
 			return lexNumber
-		case unicode.IsLetter(r):
+		case unicode.IsLetter(r) || r == '_':
 			return lexFunc
 		case r == '(':
 			l.emit(itemLeftParen)
@@ -210,6 +210,14 @@ func lexNumber(l *lexer) stateFn {
 	if !l.scanNumber() {
 		return l.errorf("bad number syntax: %q", l.input[l.start:l.pos])
 	}
+	// A number directly followed by a unit is a duration, e.g. 1d or 15m.
+	if r := l.peek(); unicode.IsLetter(r) {
+		for r := l.next(); unicode.IsLetter(r) || isNumber(r); r = l.next() {
+		}
+		l.backup()
+		l.emit(itemDuration)
+		return lexItem
+	}
 	l.emit(itemNumber)
 	return lexItem
 }
@@ -276,7 +284,7 @@ func lexSymbol(l *lexer) stateFn {
 func lexFunc(l *lexer) stateFn {
 	for {
 		switch r := l.next(); {
-		case unicode.IsLetter(r) || r == '_':
+		case isVarchar(r):
 			// absorb
 		default:
 			l.backup()
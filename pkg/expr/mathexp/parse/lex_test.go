@@ -33,6 +33,7 @@ var itemName = map[itemType]string{
 	itemRightParen: ")",
 	itemString:     "string",
 	itemFunc:       "func",
+	itemDuration:   "duration",
 }
 
 func (i itemType) String() string {
@@ -97,6 +98,23 @@ var lexTests = []lexTest{
 		{itemNumber, 0, "1.2e-4"},
 		tEOF,
 	}},
+	{"durations", "1d 15m 500ms 1.5h 1h30m", []item{
+		{itemDuration, 0, "1d"},
+		{itemDuration, 0, "15m"},
+		{itemDuration, 0, "500ms"},
+		{itemDuration, 0, "1.5h"},
+		{itemDuration, 0, "1h30m"},
+		tEOF,
+	}},
+	{"func with duration argument", "shift($A, 1w)", []item{
+		{itemFunc, 0, "shift"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemComma, 0, ","},
+		{itemDuration, 0, "1w"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
 	{"curly brace var", "${My Var}", []item{
 		{itemVar, 0, "${My Var}"},
 		tEOF,
@@ -113,6 +131,50 @@ var lexTests = []lexTest{
 		{itemVar, 0, "$A"},
 		tEOF,
 	}},
+	// Identifiers may start with an underscore and contain digits, function
+	// names without them lex the same as before.
+	{"funcs with underscores", "is_nan($A) || is_null(${B})", []item{
+		{itemFunc, 0, "is_nan"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemRightParen, 0, ")"},
+		tOr,
+		{itemFunc, 0, "is_null"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "${B}"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
+	{"funcs next to operators", "abs($A)*round(-$B)/infn()", []item{
+		{itemFunc, 0, "abs"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemRightParen, 0, ")"},
+		tMult,
+		{itemFunc, 0, "round"},
+		{itemLeftParen, 0, "("},
+		tMinus,
+		{itemVar, 0, "$B"},
+		{itemRightParen, 0, ")"},
+		tDiv,
+		{itemFunc, 0, "infn"},
+		{itemLeftParen, 0, "("},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
+	{"labels with underscores and digits", "sum by (_host, zone2) ($A)", []item{
+		{itemFunc, 0, "sum"},
+		{itemFunc, 0, "by"},
+		{itemLeftParen, 0, "("},
+		{itemFunc, 0, "_host"},
+		{itemComma, 0, ","},
+		{itemFunc, 0, "zone2"},
+		{itemRightParen, 0, ")"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
 	// errors
 	{"unclosed quote", "\"", []item{
 		{itemError, 0, "unterminated string"},
@@ -9,6 +9,10 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -62,6 +66,10 @@ const (
 	NodeNumber
 	// NodeVar is variable: $A
 	NodeVar
+	// NodeDuration is a duration constant: 1d
+	NodeDuration
+	// NodeAggregate is an aggregation operator: sum by (host) ($A)
+	NodeAggregate
 )
 
 // String returns the string representation of the NodeType
@@ -79,6 +87,10 @@ func (t NodeType) String() string {
 		return "NodeNumber"
 	case NodeVar:
 		return "NodeVar"
+	case NodeDuration:
+		return "NodeDuration"
+	case NodeAggregate:
+		return "NodeAggregate"
 	default:
 		return "NodeUnknown"
 	}
@@ -293,6 +305,158 @@ func (s *StringNode) Return() ReturnType {
 	return TypeString
 }
 
+// DurationNode holds a duration constant such as 1d or 15m.
+type DurationNode struct {
+	NodeType
+	Pos
+	Duration time.Duration // The parsed duration.
+	Text     string        // The original textual representation from the input.
+}
+
+func newDuration(pos Pos, text string) (*DurationNode, error) {
+	d, err := gtime.ParseDuration(text)
+	if err != nil {
+		return nil, fmt.Errorf("illegal duration syntax: %q", text)
+	}
+	return &DurationNode{NodeType: NodeDuration, Pos: pos, Duration: d, Text: text}, nil
+}
+
+// String returns the string representation of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) String() string {
+	return d.Text
+}
+
+// StringAST returns the string representation of abstract syntax tree of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) StringAST() string {
+	return d.String()
+}
+
+// Check performs parse time checking on the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) Check(*Tree) error {
+	return nil
+}
+
+// Return returns the result type of the DurationNode so it fulfills the Node interface.
+func (d *DurationNode) Return() ReturnType {
+	return TypeDuration
+}
+
+// AggregateOps are the aggregation operators, mapped to whether they take a parameter (e.g. the k of topk).
+var AggregateOps = map[string]bool{
+	"sum":     false,
+	"avg":     false,
+	"min":     false,
+	"max":     false,
+	"count":   false,
+	"topk":    true,
+	"bottomk": true,
+}
+
+// AggregateNode holds an aggregation operator that combines the items of a set
+// into groups of items that share the same labels, e.g. sum by (cluster) ($A).
+type AggregateNode struct {
+	NodeType
+	Pos
+	Operator string
+	Grouping []string // The label names of the by or without clause.
+	Without  bool     // Whether the grouping labels are excluded instead of kept.
+	Param    Node     // The parameter of the operator, e.g. the k of topk. Nil if the operator takes none.
+	Arg      Node
+}
+
+func newAggregate(pos Pos, operator string) *AggregateNode {
+	return &AggregateNode{NodeType: NodeAggregate, Pos: pos, Operator: operator}
+}
+
+func (a *AggregateNode) grouping() string {
+	if a.Grouping == nil {
+		return ""
+	}
+	clause := "by"
+	if a.Without {
+		clause = "without"
+	}
+	return fmt.Sprintf(" %s (%s) ", clause, strings.Join(a.Grouping, ", "))
+}
+
+// String returns the string representation of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) String() string {
+	if a.Param != nil {
+		return fmt.Sprintf("%s%s(%s, %s)", a.Operator, a.grouping(), a.Param, a.Arg)
+	}
+	return fmt.Sprintf("%s%s(%s)", a.Operator, a.grouping(), a.Arg)
+}
+
+// StringAST returns the string representation of abstract syntax tree of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) StringAST() string {
+	if a.Param != nil {
+		return fmt.Sprintf("%s%s(%s, %s)", a.Operator, a.grouping(), a.Param.StringAST(), a.Arg.StringAST())
+	}
+	return fmt.Sprintf("%s%s(%s)", a.Operator, a.grouping(), a.Arg.StringAST())
+}
+
+// Check performs parse time checking on the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Check(t *Tree) error {
+	if a.Param != nil {
+		if rt := a.Param.Return(); rt != TypeScalar {
+			return fmt.Errorf("parse: expected %v for the parameter of %s, got %v", TypeScalar, a.Operator, rt)
+		}
+		if err := a.Param.Check(t); err != nil {
+			return err
+		}
+	}
+	switch rt := a.Arg.Return(); rt {
+	case TypeNumberSet, TypeSeriesSet:
+		return a.Arg.Check(t)
+	default:
+		return fmt.Errorf("parse: expected %v or %v for the argument of %s, got %v", TypeNumberSet, TypeSeriesSet, a.Operator, rt)
+	}
+}
+
+// Return returns the result type of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Return() ReturnType {
+	return a.Arg.Return()
+}
+
+// MatchCardinality describes the cardinality of the relationship between the items of the two sides of a binary operation.
+type MatchCardinality int
+
+const (
+	// CardOneToOne matches each item of a side with at most one item of the other side.
+	CardOneToOne MatchCardinality = iota
+	// CardManyToOne matches many items of the left side with one item of the right side (group_left).
+	CardManyToOne
+	// CardOneToMany matches one item of the left side with many items of the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching describes how the items of the two sides of a binary operation are matched,
+// e.g. $A / on(host) group_left(dc) $B.
+type VectorMatching struct {
+	Card MatchCardinality
+	// On is whether the items are matched on MatchingLabels (on) or on all labels but MatchingLabels (ignoring).
+	On             bool
+	MatchingLabels []string
+	// Include are the labels of the "one" side that are added to the result of a many-to-one or one-to-many match.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching.
+func (m *VectorMatching) String() string {
+	s := "ignoring"
+	if m.On {
+		s = "on"
+	}
+	s += "(" + strings.Join(m.MatchingLabels, ", ") + ")"
+	switch m.Card {
+	case CardManyToOne:
+		s += " group_left(" + strings.Join(m.Include, ", ") + ")"
+	case CardOneToMany:
+		s += " group_right(" + strings.Join(m.Include, ", ") + ")"
+	}
+	return s
+}
+
 // BinaryNode holds two arguments and an operator.
 type BinaryNode struct {
 	NodeType
@@ -300,6 +464,7 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	Matching *VectorMatching // Nil if the operation has no vector matching clause.
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -308,11 +473,17 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
@@ -380,10 +551,15 @@ func Walk(n Node, f func(Node)) {
 		for _, a := range n.Args {
 			Walk(a, f)
 		}
-	case *ScalarNode, *StringNode:
+	case *ScalarNode, *StringNode, *DurationNode:
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
+	case *AggregateNode:
+		if n.Param != nil {
+			Walk(n.Param, f)
+		}
+		Walk(n.Arg, f)
 	default:
 		panic(fmt.Errorf("other type: %T", n))
 	}
@@ -407,6 +583,8 @@ const (
 	TypeNoData
 	// TypeTableData is a tabular data response.
 	TypeTableData
+	// TypeDuration is a single duration constant.
+	TypeDuration
 )
 
 // String returns a string representation of the ReturnType.
@@ -426,6 +604,8 @@ func (f ReturnType) String() string {
 		return "noData"
 	case TypeTableData:
 		return "tableData"
+	case TypeDuration:
+		return "duration"
 	default:
 		return "unknown"
 	}
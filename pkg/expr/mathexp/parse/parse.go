@@ -165,16 +165,19 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [matching] A}
+A -> C {"&&" [matching] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [matching] P}
+P -> M {( "+" | "-" ) [matching] M}
+M -> E {( "*" | "/" ) [matching] F}
+E -> F {( "**" ) [matching] F}
 F -> v | "(" O ")" | "!" O | "-" O
-v -> number | func(..) | queryVar
+v -> number | func(..) | aggregate(..) | queryVar
 Func -> name "(" param {"," param} ")"
-param -> number | "string" | queryVar
+param -> number | "string" | duration | queryVar
+Aggregate -> op [grouping] "(" [O ","] O ")" [grouping]
+grouping -> ( "by" | "without" ) "(" label {"," label} ")"
+matching -> ( "on" | "ignoring" ) "(" label {"," label} ")" [( "group_left" | "group_right" ) ["(" label {"," label} ")"]]
 */
 
 // expr:
@@ -185,7 +188,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +201,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +214,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +227,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +240,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +253,77 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary parses the operator and the optional vector matching clause of a binary
+// operation. The left side is lhs and the right side is parsed with rhs.
+func (t *Tree) binary(lhs Node, rhs func() Node) Node {
+	operator := t.next()
+	matching := t.matching()
+	b := newBinary(operator, lhs, rhs())
+	b.Matching = matching
+	return b
+}
+
+// matching parses the optional vector matching clause of a binary operation.
+func (t *Tree) matching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{
+		On:             token.val == "on",
+		MatchingLabels: t.labels("matching"),
+	}
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return m
+	}
+	t.next()
+	m.Card = CardManyToOne
+	if token.val == "group_right" {
+		m.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		m.Include = t.labels("group")
+	}
+	if m.On {
+		for _, include := range m.Include {
+			for _, label := range m.MatchingLabels {
+				if include == label {
+					t.errorf("label %q must not occur in on and group clause at once", label)
+				}
+			}
+		}
+	}
+	return m
+}
+
+// labels parses a parenthesized list of label names.
+func (t *Tree) labels(context string) []string {
+	labels := []string{}
+	t.expect(itemLeftParen, context)
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+			if t.peek().typ == itemComma {
+				t.next()
+			}
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -286,6 +353,9 @@ func (t *Tree) v() Node {
 		return n
 	case itemFunc:
 		t.backup()
+		if _, ok := AggregateOps[token.val]; ok {
+			return t.Aggregate()
+		}
 		return t.Func()
 	case itemVar:
 		t.backup()
@@ -296,6 +366,36 @@ func (t *Tree) v() Node {
 	return nil
 }
 
+// Aggregate parses an AggregateNode. The grouping clause may come either
+// before or after the arguments, e.g. sum by (host) ($A) or sum($A) by (host).
+func (t *Tree) Aggregate() (a *AggregateNode) {
+	token := t.next()
+	a = newAggregate(token.pos, token.val)
+	t.grouping(a)
+	t.expect(itemLeftParen, "aggregation")
+	if AggregateOps[a.Operator] {
+		a.Param = t.O()
+		t.expect(itemComma, "aggregation")
+	}
+	a.Arg = t.O()
+	t.expect(itemRightParen, "aggregation")
+	if a.Grouping == nil {
+		t.grouping(a)
+	}
+	return a
+}
+
+// grouping parses the optional by or without clause of an aggregation.
+func (t *Tree) grouping(a *AggregateNode) {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "by" && token.val != "without") {
+		return
+	}
+	t.next()
+	a.Without = token.val == "without"
+	a.Grouping = t.labels("grouping")
+}
+
 // Var is queryVar in the grammar.
 func (t *Tree) Var() (v *VarNode) {
 	token := t.next()
@@ -329,6 +429,16 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemDuration:
+			d, err := newDuration(token.pos, token.val)
+			if err != nil {
+				t.error(err)
+			}
+			f.append(d)
+		case itemComma:
+			if len(f.Args) == 0 {
+				t.unexpected(token, "func")
+			}
 		case itemRightParen:
 			return
 		}
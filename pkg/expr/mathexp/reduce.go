@@ -3,6 +3,8 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -20,11 +22,33 @@ const (
 	ReducerMax   ReducerID = "max"
 	ReducerCount ReducerID = "count"
 	ReducerLast  ReducerID = "last"
+
+	ReducerFirst      ReducerID = "first"
+	ReducerMedian     ReducerID = "median"
+	ReducerPercentile ReducerID = "percentile"
+	ReducerStdDev     ReducerID = "stddev"
+	ReducerVariance   ReducerID = "variance"
+	ReducerRange      ReducerID = "range"
+	ReducerDelta      ReducerID = "delta"
+	ReducerIncrease   ReducerID = "increase"
+	ReducerRate       ReducerID = "rate"
+
+	ReducerTimeWeightedMean ReducerID = "time_weighted_mean"
 )
 
+// ReducerArgs holds the arguments of parameterised reducers.
+type ReducerArgs struct {
+	// Percentile is the percentile, in the range [0, 100], computed by ReducerPercentile.
+	Percentile float64
+}
+
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast,
+		ReducerFirst, ReducerMedian, ReducerPercentile, ReducerStdDev, ReducerVariance,
+		ReducerRange, ReducerDelta, ReducerIncrease, ReducerRate, ReducerTimeWeightedMean,
+	}
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -98,7 +122,190 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
-func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// Percentile returns a reducer that computes the p-th percentile (0 <= p <= 100) of the values,
+// linearly interpolating between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		vals, ok := sortedValues(fv)
+		if !ok || len(vals) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		rank := p / 100 * float64(len(vals)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Variance returns the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	mean := Avg(fv)
+	if math.IsNaN(*mean) {
+		return mean
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *mean
+		sum += d * d
+	}
+	f := sum / float64(fv.Len())
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+// Range returns the difference between the largest and the smallest value.
+func Range(fv *Float64Field) *float64 {
+	minV, maxV := Min(fv), Max(fv)
+	f := *maxV - *minV
+	return &f
+}
+
+// Delta returns the difference between the last and the first value.
+func Delta(fv *Float64Field) *float64 {
+	first, last := First(fv), Last(fv)
+	if first == nil || last == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *last - *first
+	return &f
+}
+
+// Increase returns the total increase of a counter. A value lower than the previous one
+// is treated as a counter reset, and the counter is assumed to restart from zero.
+func Increase(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	var prev float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			nan := math.NaN()
+			return &nan
+		}
+		switch {
+		case i == 0:
+		case *v < prev:
+			f += *v
+		default:
+			f += *v - prev
+		}
+		prev = *v
+	}
+	return &f
+}
+
+// Rate returns the per-second average rate of increase of a counter over the time span of the series.
+// Counter resets are handled like in Increase.
+func Rate(s Series) *float64 {
+	if s.Len() < 2 {
+		nan := math.NaN()
+		return &nan
+	}
+	elapsed := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds()
+	if elapsed <= 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	fv := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+	f := *Increase(&fv) / elapsed
+	return &f
+}
+
+// TimeWeightedMean returns the mean of the series where each value is weighted by the time until
+// the next point, that is the mean of the step function through the points.
+func TimeWeightedMean(s Series) *float64 {
+	if s.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	times := make([]time.Time, s.Len())
+	vals := make([]*float64, s.Len())
+	for i := range times {
+		times[i], vals[i] = s.GetPoint(i)
+	}
+	return timeWeightedMean(times, vals, times[len(times)-1])
+}
+
+// timeWeightedMean returns the mean of the step function through the points, from the first point
+// to end. When the points do not span any time, the value of the last point is returned.
+func timeWeightedMean(times []time.Time, vals []*float64, end time.Time) *float64 {
+	var sum, total float64
+	for i, v := range vals {
+		if v == nil || math.IsNaN(*v) {
+			nan := math.NaN()
+			return &nan
+		}
+		until := end
+		if i+1 < len(times) {
+			until = times[i+1]
+		}
+		d := until.Sub(times[i]).Seconds()
+		sum += *v * d
+		total += d
+	}
+	if total <= 0 {
+		last := *vals[len(vals)-1]
+		return &last
+	}
+	f := sum / total
+	return &f
+}
+
+// sortedValues returns the values of the field in ascending order.
+// It returns false if any of the values is nil or NaN.
+func sortedValues(fv *Float64Field) ([]float64, bool) {
+	vals := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		vals = append(vals, *v)
+	}
+	sort.Float64s(vals)
+	return vals, true
+}
+
+// ValidateReducer returns an error if the reducer is not supported or its arguments are not valid.
+func ValidateReducer(rFunc ReducerID, args ReducerArgs) error {
+	if rFunc == ReducerRate || rFunc == ReducerTimeWeightedMean {
+		return nil
+	}
+	_, err := GetReduceFunc(rFunc, args)
+	return err
+}
+
+// GetReduceFunc returns the function that implements the reducer. Reducers that depend on the
+// timestamps of the points, such as ReducerRate, are not supported and can only be applied through Series.Reduce.
+func GetReduceFunc(rFunc ReducerID, args ReducerArgs) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
 		return Sum, nil
@@ -112,6 +319,27 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Count, nil
 	case ReducerLast:
 		return Last, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerMedian:
+		return Median, nil
+	case ReducerPercentile:
+		if args.Percentile < 0 || args.Percentile > 100 || math.IsNaN(args.Percentile) {
+			return nil, fmt.Errorf("percentile must be in the range [0, 100], got %v", args.Percentile)
+		}
+		return Percentile(args.Percentile), nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerVariance:
+		return Variance, nil
+	case ReducerRange:
+		return Range, nil
+	case ReducerDelta:
+		return Delta, nil
+	case ReducerIncrease:
+		return Increase, nil
+	case ReducerRate, ReducerTimeWeightedMean:
+		return nil, fmt.Errorf("reduction %v depends on the timestamps and can only be applied to a series", rFunc)
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
@@ -121,6 +349,11 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
 func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Number, error) {
+	return s.ReduceWithArgs(refID, rFunc, ReducerArgs{}, mapper)
+}
+
+// ReduceWithArgs is like Reduce but accepts the arguments of parameterised reducers such as ReducerPercentile.
+func (s Series) ReduceWithArgs(refID string, rFunc ReducerID, args ReducerArgs, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -131,13 +364,20 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
-	if err != nil {
-		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
+	switch rFunc {
+	case ReducerRate:
+		f = Rate(series)
+	case ReducerTimeWeightedMean:
+		f = TimeWeightedMean(series)
+	default:
+		fVec := series.Frame.Fields[seriesTypeValIdx]
+		floatField := Float64Field(*fVec)
+		reduceFunc, err := GetReduceFunc(rFunc, args)
+		if err != nil {
+			return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
+		}
+		f = reduceFunc(&floatField)
 	}
-	f = reduceFunc(&floatField)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
@@ -204,6 +204,167 @@ func TestSeriesReduce(t *testing.T) {
 	}
 }
 
+var counterSeries = Vars{
+	"A": resultValuesNoErr(
+		makeSeries("temp", nil,
+			tp{time.Unix(0, 0), float64Pointer(3)},
+			tp{time.Unix(10, 0), float64Pointer(7)},
+			tp{time.Unix(20, 0), float64Pointer(1)},
+			tp{time.Unix(30, 0), float64Pointer(9)},
+			tp{time.Unix(40, 0), float64Pointer(10)}),
+	),
+}
+
+func TestSeriesReduceWithArgs(t *testing.T) {
+	var tests = []struct {
+		name    string
+		red     ReducerID
+		args    ReducerArgs
+		vars    Vars
+		errIs   require.ErrorAssertionFunc
+		results Results
+	}{
+		{
+			name:    "first series",
+			red:     ReducerFirst,
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(3))),
+		},
+		{
+			name:    "first empty series",
+			red:     ReducerFirst,
+			vars:    seriesEmpty,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:    "median series",
+			red:     ReducerMedian,
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(7))),
+		},
+		{
+			name:    "median series with a nil value",
+			red:     ReducerMedian,
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:    "percentile series interpolates between ranks",
+			red:     ReducerPercentile,
+			args:    ReducerArgs{Percentile: 90},
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(9.6))),
+		},
+		{
+			name:  "percentile out of range will error",
+			red:   ReducerPercentile,
+			args:  ReducerArgs{Percentile: 101},
+			vars:  counterSeries,
+			errIs: require.Error,
+		},
+		{
+			name:    "variance series",
+			red:     ReducerVariance,
+			vars:    aSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(0.25))),
+		},
+		{
+			name:    "stddev series",
+			red:     ReducerStdDev,
+			vars:    aSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(0.5))),
+		},
+		{
+			name:    "stddev empty series",
+			red:     ReducerStdDev,
+			vars:    seriesEmpty,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:    "range series",
+			red:     ReducerRange,
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(9))),
+		},
+		{
+			name:    "delta series",
+			red:     ReducerDelta,
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(7))),
+		},
+		{
+			name:    "increase series handles counter reset",
+			red:     ReducerIncrease,
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(14))),
+		},
+		{
+			name:    "rate series handles counter reset",
+			red:     ReducerRate,
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(0.35))),
+		},
+		{
+			name:    "rate series with a single point",
+			red:     ReducerRate,
+			vars:    seriesWithNil,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:    "time weighted mean series",
+			red:     ReducerTimeWeightedMean,
+			vars:    counterSeries,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(5))),
+		},
+		{
+			name: "time weighted mean series with uneven intervals",
+			red:  ReducerTimeWeightedMean,
+			vars: Vars{"A": resultValuesNoErr(makeSeries("temp", nil,
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(30, 0), float64Pointer(4)},
+				tp{time.Unix(40, 0), float64Pointer(100)}),
+			)},
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(1.75))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Results{}
+			for _, series := range tt.vars["A"].Values {
+				ns, err := series.Value().(*Series).ReduceWithArgs("", tt.red, tt.args, nil)
+				tt.errIs(t, err)
+				if err != nil {
+					return
+				}
+				results.Values = append(results.Values, ns)
+			}
+			opt := cmp.Comparer(func(x, y float64) bool {
+				return (math.IsNaN(x) && math.IsNaN(y)) || math.Abs(x-y) < 1e-9
+			})
+			options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+			if diff := cmp.Diff(tt.results, results, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 var seriesNonNumbers = Vars{
 	"A": resultValuesNoErr(
 		makeSeries("temp", nil,
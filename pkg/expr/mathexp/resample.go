@@ -20,80 +20,219 @@ const (
 
 	// Do not fill values (nill)
 	UpsamplerFillNA Upsampler = "fillna"
+
+	// Interpolate linearly between the points before and after
+	UpsamplerLinear Upsampler = "linear"
+
+	// Use the value of the closest point in time
+	UpsamplerNearest Upsampler = "nearest"
+)
+
+// How the points of the resampled series are aligned
+// +enum
+type ResampleAlignment string
+
+const (
+	// The first point is at the start of the time range
+	AlignTimeRange ResampleAlignment = "range"
+
+	// The first point is at the start of the hour
+	AlignHour ResampleAlignment = "hour"
+
+	// The first point is at the start of the day. Windows of whole days step by calendar days
+	AlignDay ResampleAlignment = "day"
 )
 
+// ResampleOptions are the optional settings of a resampling.
+type ResampleOptions struct {
+	// MaxGap is the longest time between a resampled point and the point of the series that the
+	// upsampler fills it from. Longer gaps are not filled. For linear interpolation, it is the longest
+	// time between the two points interpolated. Zero means no limit.
+	MaxGap time.Duration
+
+	// Align is how the points of the resampled series are aligned. The default is AlignTimeRange.
+	Align ResampleAlignment
+
+	// Location is the time zone of the hours and days used by Align. The default is UTC.
+	Location *time.Location
+}
+
 // Resample turns the Series into a Number based on the given reduction function
 func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
-	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
-	if newSeriesLength <= 0 {
-		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
+	return s.ResampleWithOptions(refID, interval, downsampler, upsampler, from, to, ResampleOptions{})
+}
+
+// ResampleWithOptions is like Resample but accepts options for the gaps to fill and the alignment of the points.
+func (s Series) ResampleWithOptions(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time, opts ResampleOptions) (Series, error) {
+	times, err := resampleTimes(interval, from, to, opts)
+	if err != nil {
+		return s, err
 	}
-	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
+	resampled := NewSeries(refID, s.GetLabels(), len(times))
 	bookmark := 0
-	var lastSeen *float64
-	idx := 0
-	t := from
-	for !t.After(to) && idx <= newSeriesLength {
-		vals := make([]*float64, 0)
-		sIdx := bookmark
-		for {
-			if sIdx == s.Len() {
-				break
-			}
-			st, v := s.GetPoint(sIdx)
-			if st.After(t) {
-				break
-			}
+	bucketStart := times[0].Add(-interval)
+	for idx, t := range times {
+		first := bookmark
+		for bookmark < s.Len() && !s.GetTime(bookmark).After(t) {
 			bookmark++
-			sIdx++
-			lastSeen = v
-			vals = append(vals, v)
 		}
 		var value *float64
-		if len(vals) == 0 { // upsampling
-			switch upsampler {
-			case UpsamplerPad:
-				if lastSeen != nil {
-					value = lastSeen
-				} else {
-					value = nil
-				}
-			case UpsamplerBackfill:
-				if sIdx == s.Len() { // no vals left
-					value = nil
-				} else {
-					_, value = s.GetPoint(sIdx)
-				}
-			case UpsamplerFillNA:
-				value = nil
-			default:
-				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
-			}
-		} else if len(vals) == 1 {
-			value = vals[0]
-		} else { // downsampling
-			fVec := data.NewField("", s.GetLabels(), vals)
-			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
-				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
-			}
-			value = tmp
+		switch {
+		case bookmark == first: // upsampling
+			value, err = s.upsample(upsampler, t, bookmark, opts.MaxGap)
+		case bookmark-first == 1 && !bucketDependent(downsampler):
+			value = s.GetValue(first)
+		default: // downsampling
+			value, err = s.downsample(downsampler, first, bookmark, bucketStart, t)
+		}
+		if err != nil {
+			return s, err
 		}
 		resampled.SetPoint(idx, t, value)
-		t = t.Add(interval)
-		idx++
+		bucketStart = t
 	}
 	return resampled, nil
 }
+
+// resampleTimes returns the times of the points of the resampled series.
+func resampleTimes(interval time.Duration, from, to time.Time, opts ResampleOptions) ([]time.Time, error) {
+	if interval <= 0 || to.Sub(from) < interval {
+		return nil, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	start := from
+	next := func(t time.Time) time.Time { return t.Add(interval) }
+	switch opts.Align {
+	case "", AlignTimeRange:
+	case AlignHour:
+		l := from.In(loc)
+		start = time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), 0, 0, 0, loc)
+	case AlignDay:
+		l := from.In(loc)
+		start = time.Date(l.Year(), l.Month(), l.Day(), 0, 0, 0, 0, loc)
+		if day := 24 * time.Hour; interval%day == 0 {
+			days := int(interval / day)
+			next = func(t time.Time) time.Time { return t.AddDate(0, 0, days) }
+		}
+	default:
+		return nil, fmt.Errorf("resample alignment %v not implemented", opts.Align)
+	}
+	var times []time.Time
+	for t := start; !t.After(to); t = next(t) {
+		times = append(times, t.In(from.Location()))
+	}
+	return times, nil
+}
+
+// bucketDependent returns true for the downsamplers whose result for a single point is not the
+// value of the point.
+func bucketDependent(downsampler ReducerID) bool {
+	switch downsampler {
+	case ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerLast, ReducerFirst, ReducerMedian:
+		return false
+	}
+	return true
+}
+
+// upsample returns the value at t, where the series has no point, from the points around it.
+// next is the index of the first point after t.
+func (s Series) upsample(upsampler Upsampler, t time.Time, next int, maxGap time.Duration) (*float64, error) {
+	prev := next - 1
+	hasPrev, hasNext := prev >= 0, next < s.Len()
+	within := func(d time.Duration) bool {
+		return maxGap <= 0 || d <= maxGap
+	}
+	switch upsampler {
+	case UpsamplerPad:
+		if hasPrev && within(t.Sub(s.GetTime(prev))) {
+			return s.GetValue(prev), nil
+		}
+	case UpsamplerBackfill:
+		if hasNext && within(s.GetTime(next).Sub(t)) {
+			return s.GetValue(next), nil
+		}
+	case UpsamplerFillNA:
+	case UpsamplerLinear:
+		if !hasPrev || !hasNext {
+			return nil, nil
+		}
+		pt, pv := s.GetPoint(prev)
+		nt, nv := s.GetPoint(next)
+		if pv == nil || nv == nil || !within(nt.Sub(pt)) {
+			return nil, nil
+		}
+		ratio := float64(t.Sub(pt)) / float64(nt.Sub(pt))
+		v := *pv + (*nv-*pv)*ratio
+		return &v, nil
+	case UpsamplerNearest:
+		closest := -1
+		switch {
+		case hasPrev && hasNext:
+			closest = next
+			if t.Sub(s.GetTime(prev)) <= s.GetTime(next).Sub(t) {
+				closest = prev
+			}
+		case hasPrev:
+			closest = prev
+		case hasNext:
+			closest = next
+		}
+		if closest < 0 {
+			return nil, nil
+		}
+		d := t.Sub(s.GetTime(closest))
+		if d < 0 {
+			d = -d
+		}
+		if within(d) {
+			return s.GetValue(closest), nil
+		}
+	default:
+		return nil, fmt.Errorf("upsampling %v not implemented", upsampler)
+	}
+	return nil, nil
+}
+
+// downsample reduces the points of the series in [first, end) to the value of the bucket (bucketStart, t].
+func (s Series) downsample(downsampler ReducerID, first, end int, bucketStart, t time.Time) (*float64, error) {
+	switch downsampler {
+	case ReducerRate:
+		bucket := NewSeries("", nil, end-first)
+		for i := first; i < end; i++ {
+			bucket.SetPoint(i-first, s.GetTime(i), s.GetValue(i))
+		}
+		return Rate(bucket), nil
+	case ReducerTimeWeightedMean:
+		// The value of the last point before the bucket holds until the first point of the bucket.
+		if first > 0 {
+			first--
+		}
+		times := make([]time.Time, 0, end-first)
+		vals := make([]*float64, 0, end-first)
+		for i := first; i < end; i++ {
+			st, v := s.GetPoint(i)
+			if st.Before(bucketStart) {
+				st = bucketStart
+			}
+			times = append(times, st)
+			vals = append(vals, v)
+		}
+		return timeWeightedMean(times, vals, t), nil
+	case ReducerPercentile:
+		return nil, fmt.Errorf("downsampling %v is not supported", downsampler)
+	}
+	reduce, err := GetReduceFunc(downsampler, ReducerArgs{})
+	if err != nil {
+		return nil, fmt.Errorf("downsampling %v not implemented", downsampler)
+	}
+	vals := make([]*float64, 0, end-first)
+	for i := first; i < end; i++ {
+		vals = append(vals, s.GetValue(i))
+	}
+	fVec := data.NewField("", s.GetLabels(), vals)
+	ff := Float64Field(*fVec)
+	return reduce(&ff), nil
+}
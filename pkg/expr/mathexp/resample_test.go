@@ -290,3 +290,202 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestResampleSeriesWithOptions(t *testing.T) {
+	var tests = []struct {
+		name             string
+		interval         time.Duration
+		downsampler      ReducerID
+		upsampler        Upsampler
+		opts             ResampleOptions
+		seriesToResample Series
+		series           Series
+	}{
+		{
+			name:        "upsampling (linear)",
+			interval:    time.Second * 5,
+			downsampler: ReducerMean,
+			upsampler:   UpsamplerLinear,
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(20, 0), float64Pointer(20),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(10),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(15),
+			}, tp{
+				time.Unix(20, 0), float64Pointer(20),
+			}),
+		},
+		{
+			name:        "upsampling (linear) does not interpolate over gaps longer than max gap",
+			interval:    time.Second * 5,
+			downsampler: ReducerMean,
+			upsampler:   UpsamplerLinear,
+			opts:        ResampleOptions{MaxGap: 10 * time.Second},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(20, 0), float64Pointer(20),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(5, 0), nil,
+			}, tp{
+				time.Unix(10, 0), nil,
+			}, tp{
+				time.Unix(15, 0), nil,
+			}, tp{
+				time.Unix(20, 0), float64Pointer(20),
+			}),
+		},
+		{
+			name:        "upsampling (nearest)",
+			interval:    time.Second * 5,
+			downsampler: ReducerMean,
+			upsampler:   UpsamplerNearest,
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(14, 0), float64Pointer(14),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(14),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(14),
+			}),
+		},
+		{
+			name:        "upsampling (pad) stops after max gap",
+			interval:    time.Second * 5,
+			downsampler: ReducerMean,
+			upsampler:   UpsamplerPad,
+			opts:        ResampleOptions{MaxGap: 6 * time.Second},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(1),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(10, 0), nil,
+			}, tp{
+				time.Unix(15, 0), nil,
+			}),
+		},
+		{
+			name:        "downsampling (time weighted mean)",
+			interval:    time.Second * 5,
+			downsampler: ReducerTimeWeightedMean,
+			upsampler:   UpsamplerFillNA,
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(10),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(20),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(0),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(12.5),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(4),
+			}),
+		},
+		{
+			name:        "downsampling (count)",
+			interval:    time.Second * 5,
+			downsampler: ReducerCount,
+			upsampler:   UpsamplerFillNA,
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(10),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(20),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(0),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := time.Unix(0, 0), tt.series.GetTime(tt.series.Len()-1)
+			series, err := tt.seriesToResample.ResampleWithOptions("", tt.interval, tt.downsampler, tt.upsampler, from, to, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.series, series)
+		})
+	}
+}
+
+func TestResampleAlignment(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name     string
+		interval time.Duration
+		opts     ResampleOptions
+		from, to time.Time
+		times    []time.Time
+	}{
+		{
+			name:     "calendar days across a daylight saving time change",
+			interval: 24 * time.Hour,
+			opts:     ResampleOptions{Align: AlignDay, Location: berlin},
+			from:     time.Date(2024, 3, 30, 10, 0, 0, 0, time.UTC),
+			to:       time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+			times: []time.Time{
+				time.Date(2024, 3, 29, 23, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 30, 23, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "hours in a time zone with a half hour offset",
+			interval: 15 * time.Minute,
+			opts:     ResampleOptions{Align: AlignHour, Location: kolkata},
+			from:     time.Date(2024, 1, 1, 10, 17, 0, 0, time.UTC),
+			to:       time.Date(2024, 1, 1, 10, 50, 0, 0, time.UTC),
+			times: []time.Time{
+				time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 9, 45, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+				time.Date(2024, 1, 1, 10, 45, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := makeSeries("", nil, tp{tt.from, float64Pointer(1)})
+			series, err := s.ResampleWithOptions("", tt.interval, ReducerMean, UpsamplerPad, tt.from, tt.to, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.times), series.Len())
+			for i, expected := range tt.times {
+				assert.True(t, expected.Equal(series.GetTime(i)), "expected %v, got %v", expected, series.GetTime(i))
+			}
+		})
+	}
+}
@@ -246,6 +246,16 @@ func (s Series) GetValue(pointIdx int) *float64 {
 	return s.Frame.Fields[seriesTypeValIdx].At(pointIdx).(*float64)
 }
 
+// Shift returns a copy of the series where the timestamp of every point is moved by the duration.
+func (s Series) Shift(refID string, d time.Duration) Series {
+	newSeries := NewSeries(refID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		newSeries.SetPoint(i, t.Add(d), f)
+	}
+	return newSeries
+}
+
 // SortByTime sorts the series by the time from oldest to newest.
 // If desc is true, it will sort from newest to oldest.
 // If any time values are nil, it will panic.
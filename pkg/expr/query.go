@@ -2,8 +2,14 @@ package expr
 
 import (
 	"embed"
+	"fmt"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/anomaly"
 	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/forecast"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
@@ -27,8 +33,14 @@ const (
 	// Threshold
 	QueryTypeThreshold QueryType = "threshold"
 
-	// SQL query via DuckDB
+	// SQL query over the results of other queries
 	QueryTypeSQL QueryType = "sql"
+
+	// Anomaly detection
+	QueryTypeAnomaly QueryType = "anomaly"
+
+	// Forecast
+	QueryTypeForecast QueryType = "forecast"
 )
 
 type MathQuery struct {
@@ -60,6 +72,9 @@ type ResampleQuery struct {
 
 	// The upsample function
 	Upsampler mathexp.Upsampler `json:"upsampler"`
+
+	// Resample options
+	Settings *ResampleSettings `json:"settings,omitempty"`
 }
 
 type ThresholdQuery struct {
@@ -68,6 +83,72 @@ type ThresholdQuery struct {
 
 	// Threshold Conditions
 	Conditions []ThresholdConditionJSON `json:"conditions"`
+
+	// Severity levels, from the lowest to the highest, used instead of the conditions.
+	// The result is the position (starting at 1) of the highest level whose condition is met, or 0
+	Levels []ThresholdLevelJSON `json:"levels,omitempty"`
+}
+
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The anomaly detection algorithm
+	Algorithm anomaly.Algorithm `json:"algorithm"`
+
+	// The number of previous points used to calculate the expected value and spread (default 10)
+	Window int `json:"window,omitempty"`
+
+	// The number of standard deviations from the expected value before a point is an anomaly (default 3)
+	Sensitivity float64 `json:"sensitivity,omitempty"`
+
+	// The number of points in a season. Only valid for holt_winters
+	Season int `json:"season,omitempty"`
+
+	// The level smoothing factor for holt_winters (default 0.5)
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// The trend smoothing factor for holt_winters (default 0.1)
+	Beta *float64 `json:"beta,omitempty"`
+
+	// The seasonal smoothing factor for holt_winters (default 0.1)
+	Gamma *float64 `json:"gamma,omitempty"`
+
+	// What is returned for each series (default anomaly)
+	Output AnomalyOutput `json:"output,omitempty"`
+}
+
+func (q *AnomalyQuery) config() anomaly.Config {
+	return anomaly.Config{
+		Algorithm:   q.Algorithm,
+		Window:      q.Window,
+		Sensitivity: q.Sensitivity,
+		Season:      q.Season,
+		Alpha:       q.Alpha,
+		Beta:        q.Beta,
+		Gamma:       q.Gamma,
+	}
+}
+
+type ForecastQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The model fitted over each series (default linear)
+	Model forecast.Model `json:"model,omitempty"`
+
+	// The duration of a season. Required by the seasonal model
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// What is returned for each series
+	Mode ForecastMode `json:"mode"`
+
+	// How far after the evaluation time the value is projected. For time_to_threshold,
+	// thresholds that are reached later are treated as never reached
+	Horizon string `json:"horizon,omitempty" jsonschema:"example=4h,example=7d"`
+
+	// The value to reach. Required when mode is time_to_threshold
+	Threshold *float64 `json:"threshold,omitempty"`
 }
 
 type ClassicQuery struct {
@@ -89,6 +170,49 @@ type ReduceSettings struct {
 
 	// Only valid when mode is replace
 	ReplaceWithValue *float64 `json:"replaceWithValue,omitempty"`
+
+	// The percentile in the range [0, 100]. Required when the reducer is percentile
+	Percentile *float64 `json:"percentile,omitempty"`
+}
+
+type ResampleSettings struct {
+	// Gaps longer than this duration are not filled by the upsampler
+	MaxGap string `json:"maxGap,omitempty" jsonschema:"example=5m,example=1h"`
+
+	// How the points are aligned (default range)
+	Align mathexp.ResampleAlignment `json:"align,omitempty"`
+
+	// The time zone of the hours and days used for alignment (default UTC)
+	Timezone string `json:"timezone,omitempty" jsonschema:"example=Europe/Berlin"`
+}
+
+func (s *ResampleSettings) options() (mathexp.ResampleOptions, error) {
+	opts := mathexp.ResampleOptions{}
+	if s == nil {
+		return opts, nil
+	}
+	switch s.Align {
+	case "", mathexp.AlignTimeRange, mathexp.AlignHour, mathexp.AlignDay:
+		opts.Align = s.Align
+	default:
+		return opts, fmt.Errorf("resample alignment '%s' is not supported. Supported only: [%s,%s,%s]", s.Align,
+			mathexp.AlignTimeRange, mathexp.AlignHour, mathexp.AlignDay)
+	}
+	if s.MaxGap != "" {
+		maxGap, err := gtime.ParseDuration(s.MaxGap)
+		if err != nil {
+			return opts, fmt.Errorf(`failed to parse resample "maxGap" duration field %q: %w`, s.MaxGap, err)
+		}
+		opts.MaxGap = maxGap
+	}
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return opts, fmt.Errorf("invalid resample timezone %q: %w", s.Timezone, err)
+		}
+		opts.Location = loc
+	}
+	return opts, nil
 }
 
 // Non-Number behavior mode
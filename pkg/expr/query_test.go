@@ -9,8 +9,11 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/experimental/schemabuilder"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/expr/anomaly"
 	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/forecast"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestQueryTypeDefinitions(t *testing.T) {
@@ -27,6 +30,11 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(anomaly.AlgorithmZScore),
+				reflect.TypeOf(AnomalyOutputAnomaly),
+				reflect.TypeOf(forecast.ModelLinear),
+				reflect.TypeOf(ForecastModeValue),
+				reflect.TypeOf(mathexp.AlignTimeRange),
 			},
 		})
 	require.NoError(t, err)
@@ -161,6 +169,56 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Rolling z-score of A",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression:  "$A",
+						Algorithm:   anomaly.AlgorithmZScore,
+						Window:      20,
+						Sensitivity: 3,
+					}),
+				},
+				{
+					Name: "Holt-Winters bands of A with a daily season of hourly points",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Algorithm:  anomaly.AlgorithmHoltWinters,
+						Season:     24,
+						Output:     AnomalyOutputBands,
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeForecast),
+			GoType:         reflect.TypeOf(&ForecastQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Value of A in 4 hours",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Model:      forecast.ModelLinear,
+						Mode:       ForecastModeValue,
+						Horizon:    "4h",
+					}),
+				},
+				{
+					Name: "Seconds until A reaches 100 with a daily season",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Model:      forecast.ModelSeasonal,
+						Season:     "1d",
+						Mode:       ForecastModeTimeToThreshold,
+						Horizon:    "7d",
+						Threshold:  util.Pointer(100.0),
+					}),
+				},
+			},
+		},
 	)
 
 	require.NoError(t, err)
@@ -72,8 +72,10 @@ func (h *ExpressionQueryReader) ReadQuery(
 			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
 			eq.Properties = q
 		}
+		var args mathexp.ReducerArgs
 		if err == nil && q.Settings != nil {
 			switch q.Settings.Mode {
+			case "":
 			case ReduceModeDrop:
 				mapper = mathexp.DropNonNumber{}
 			case ReduceModeReplace:
@@ -85,10 +87,17 @@ func (h *ExpressionQueryReader) ReadQuery(
 				err = fmt.Errorf("unsupported reduce mode")
 			}
 		}
+		if err == nil && q.Reducer == mathexp.ReducerPercentile {
+			if q.Settings == nil || q.Settings.Percentile == nil {
+				err = fmt.Errorf("setting percentile must be specified when reducer is '%s'", q.Reducer)
+			} else {
+				args.Percentile = *q.Settings.Percentile
+			}
+		}
 		if err == nil {
 			eq.Properties = q
 			eq.Command, err = NewReduceCommand(common.RefID,
-				q.Reducer, referenceVar, mapper)
+				q.Reducer, args, referenceVar, mapper)
 		}
 
 	case QueryTypeResample:
@@ -100,6 +109,10 @@ func (h *ExpressionQueryReader) ReadQuery(
 		if err == nil {
 			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
 		}
+		var opts mathexp.ResampleOptions
+		if err == nil {
+			opts, err = q.Settings.options()
+		}
 		if err == nil {
 			tr := gtime.NewTimeRange(common.TimeRange.From, common.TimeRange.To)
 			eq.Properties = q
@@ -108,6 +121,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 				referenceVar,
 				q.Downsampler,
 				q.Upsampler,
+				opts,
 				AbsoluteTimeRange{
 					From: tr.GetFromAsTimeUTC(),
 					To:   tr.GetToAsTimeUTC(),
@@ -131,13 +145,41 @@ func (h *ExpressionQueryReader) ReadQuery(
 			eq.Command, err = NewSQLCommand(common.RefID, q.Expression)
 		}
 
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewAnomalyCommand(common.RefID, referenceVar, q.config(), q.Output)
+		}
+
+	case QueryTypeForecast:
+		q := &ForecastQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewForecastCommand(common.RefID, referenceVar, q.Model, q.Season, q.Mode, q.Horizon, q.Threshold)
+		}
+
 	case QueryTypeThreshold:
 		q := &ThresholdQuery{}
 		err = iter.ReadVal(q)
 		if err == nil {
 			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
 		}
-		if err == nil {
+		if err == nil && len(q.Levels) > 0 {
+			if len(q.Conditions) > 0 {
+				return eq, fmt.Errorf("threshold expression requires either conditions or levels, not both")
+			}
+			eq.Properties = q
+			eq.Command, err = NewThresholdLevelsCommand(common.RefID, referenceVar, q.Levels)
+		} else if err == nil {
 			// we only support one condition for now, we might want to turn this in to "OR" expressions later
 			if len(q.Conditions) != 1 {
 				return eq, fmt.Errorf("threshold expression requires exactly one condition")
@@ -0,0 +1,405 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SelectStatement is a parsed SELECT statement.
+type SelectStatement struct {
+	With     []CommonTableExpr
+	Distinct bool
+	Columns  []SelectItem
+	From     TableExpr // nil when the statement has no FROM clause
+	Where    Expr
+	GroupBy  []Expr
+	Having   Expr
+	OrderBy  []OrderItem
+	Limit    Expr
+	Offset   Expr
+}
+
+// CommonTableExpr is a named subquery of a WITH clause.
+type CommonTableExpr struct {
+	Name   string
+	Select *SelectStatement
+}
+
+// SelectItem is an item of the select list. Star is set for * and table.*.
+type SelectItem struct {
+	Expr  Expr
+	Alias string
+	Star  bool
+	Table string
+}
+
+// OrderItem is an item of an ORDER BY clause.
+type OrderItem struct {
+	Expr       Expr
+	Desc       bool
+	NullsFirst *bool
+}
+
+// TableExpr is an item of a FROM clause.
+type TableExpr interface {
+	tableExpr()
+}
+
+// TableName references a table by name, that is a refID or the name of a common table expression.
+type TableName struct {
+	Name  string
+	Alias string
+}
+
+// SubqueryTable is a subquery in a FROM clause.
+type SubqueryTable struct {
+	Select *SelectStatement
+	Alias  string
+}
+
+// JoinKind is the kind of a join.
+type JoinKind string
+
+const (
+	JoinInner JoinKind = "INNER"
+	JoinLeft  JoinKind = "LEFT"
+	JoinRight JoinKind = "RIGHT"
+	JoinFull  JoinKind = "FULL"
+	JoinCross JoinKind = "CROSS"
+)
+
+// JoinTable joins two tables. On and Using are empty for cross joins.
+type JoinTable struct {
+	Kind  JoinKind
+	Left  TableExpr
+	Right TableExpr
+	On    Expr
+	Using []string
+}
+
+func (*TableName) tableExpr()     {}
+func (*SubqueryTable) tableExpr() {}
+func (*JoinTable) tableExpr()     {}
+
+// Expr is an expression.
+type Expr interface {
+	String() string
+}
+
+// Literal is a constant: nil, int64, float64, string or bool.
+type Literal struct {
+	Value any
+}
+
+// ColumnRef references a column, optionally qualified by the table name or alias.
+type ColumnRef struct {
+	Table string
+	Name  string
+}
+
+// UnaryExpr is NOT x or -x.
+type UnaryExpr struct {
+	Op string
+	X  Expr
+}
+
+// BinaryExpr is an arithmetic, comparison, logical or string concatenation operation.
+type BinaryExpr struct {
+	Op string
+	L  Expr
+	R  Expr
+}
+
+// FuncCall is a call to a scalar, aggregate or window function.
+type FuncCall struct {
+	Name     string // lower case
+	Args     []Expr
+	Star     bool // count(*)
+	Distinct bool
+	Over     *WindowSpec
+}
+
+// WindowSpec is the OVER clause of a window function.
+type WindowSpec struct {
+	PartitionBy []Expr
+	OrderBy     []OrderItem
+	Frame       *WindowFrame
+}
+
+// WindowFrame is a ROWS BETWEEN frame clause of a window.
+type WindowFrame struct {
+	Start FrameBound
+	End   FrameBound
+}
+
+// FrameBound is a bound of a window frame. Offset is the number of rows before (negative) or after
+// (positive) the current row. For unbounded bounds only the sign of Offset is used.
+type FrameBound struct {
+	Unbounded bool
+	Offset    int
+}
+
+// CaseExpr is a CASE expression. Operand is nil for searched CASE expressions.
+type CaseExpr struct {
+	Operand Expr
+	Whens   []WhenClause
+	Else    Expr
+}
+
+// WhenClause is a WHEN ... THEN ... clause of a CASE expression.
+type WhenClause struct {
+	Cond   Expr
+	Result Expr
+}
+
+// InExpr is x [NOT] IN (list) or x [NOT] IN (subquery).
+type InExpr struct {
+	X        Expr
+	List     []Expr
+	Subquery *SelectStatement
+	Not      bool
+}
+
+// BetweenExpr is x [NOT] BETWEEN lo AND hi.
+type BetweenExpr struct {
+	X   Expr
+	Lo  Expr
+	Hi  Expr
+	Not bool
+}
+
+// IsNullExpr is x IS [NOT] NULL.
+type IsNullExpr struct {
+	X   Expr
+	Not bool
+}
+
+// LikeExpr is x [NOT] LIKE pattern, or ILIKE for case insensitive matching.
+type LikeExpr struct {
+	X               Expr
+	Pattern         Expr
+	Not             bool
+	CaseInsensitive bool
+}
+
+// CastExpr is CAST(x AS type) or x::type.
+type CastExpr struct {
+	X    Expr
+	Type string // upper case
+}
+
+// SubqueryExpr is a subquery that returns a single value.
+type SubqueryExpr struct {
+	Select *SelectStatement
+}
+
+func (e *Literal) String() string {
+	switch v := e.Value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (e *ColumnRef) String() string {
+	if e.Table != "" {
+		return e.Table + "." + e.Name
+	}
+	return e.Name
+}
+
+func (e *UnaryExpr) String() string {
+	if e.Op == "NOT" {
+		return "NOT " + e.X.String()
+	}
+	return e.Op + e.X.String()
+}
+
+func (e *BinaryExpr) String() string {
+	return fmt.Sprintf("(%s %s %s)", e.L, e.Op, e.R)
+}
+
+func (e *FuncCall) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Name)
+	sb.WriteString("(")
+	if e.Distinct {
+		sb.WriteString("DISTINCT ")
+	}
+	if e.Star {
+		sb.WriteString("*")
+	}
+	sb.WriteString(exprList(e.Args))
+	sb.WriteString(")")
+	if e.Over != nil {
+		sb.WriteString(" OVER (")
+		var parts []string
+		if len(e.Over.PartitionBy) > 0 {
+			parts = append(parts, "PARTITION BY "+exprList(e.Over.PartitionBy))
+		}
+		if len(e.Over.OrderBy) > 0 {
+			parts = append(parts, "ORDER BY "+orderList(e.Over.OrderBy))
+		}
+		if e.Over.Frame != nil {
+			parts = append(parts, fmt.Sprintf("ROWS BETWEEN %s AND %s", e.Over.Frame.Start, e.Over.Frame.End))
+		}
+		sb.WriteString(strings.Join(parts, " "))
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+func (b FrameBound) String() string {
+	switch {
+	case b.Unbounded && b.Offset < 0:
+		return "UNBOUNDED PRECEDING"
+	case b.Unbounded:
+		return "UNBOUNDED FOLLOWING"
+	case b.Offset == 0:
+		return "CURRENT ROW"
+	case b.Offset < 0:
+		return fmt.Sprintf("%d PRECEDING", -b.Offset)
+	default:
+		return fmt.Sprintf("%d FOLLOWING", b.Offset)
+	}
+}
+
+func (e *CaseExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	if e.Operand != nil {
+		sb.WriteString(" " + e.Operand.String())
+	}
+	for _, w := range e.Whens {
+		sb.WriteString(fmt.Sprintf(" WHEN %s THEN %s", w.Cond, w.Result))
+	}
+	if e.Else != nil {
+		sb.WriteString(" ELSE " + e.Else.String())
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+func (e *InExpr) String() string {
+	not := ""
+	if e.Not {
+		not = "NOT "
+	}
+	if e.Subquery != nil {
+		return fmt.Sprintf("(%s %sIN (subquery))", e.X, not)
+	}
+	return fmt.Sprintf("(%s %sIN (%s))", e.X, not, exprList(e.List))
+}
+
+func (e *BetweenExpr) String() string {
+	not := ""
+	if e.Not {
+		not = "NOT "
+	}
+	return fmt.Sprintf("(%s %sBETWEEN %s AND %s)", e.X, not, e.Lo, e.Hi)
+}
+
+func (e *IsNullExpr) String() string {
+	if e.Not {
+		return fmt.Sprintf("(%s IS NOT NULL)", e.X)
+	}
+	return fmt.Sprintf("(%s IS NULL)", e.X)
+}
+
+func (e *LikeExpr) String() string {
+	op := "LIKE"
+	if e.CaseInsensitive {
+		op = "ILIKE"
+	}
+	if e.Not {
+		op = "NOT " + op
+	}
+	return fmt.Sprintf("(%s %s %s)", e.X, op, e.Pattern)
+}
+
+func (e *CastExpr) String() string {
+	return fmt.Sprintf("CAST(%s AS %s)", e.X, e.Type)
+}
+
+func (e *SubqueryExpr) String() string {
+	return "(subquery)"
+}
+
+// visitExpr calls fn for the expression and, as long as fn returns true, for its sub-expressions.
+// Subqueries are not visited.
+func visitExpr(e Expr, fn func(Expr) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+	visitAll := func(exprs []Expr) {
+		for _, e := range exprs {
+			visitExpr(e, fn)
+		}
+	}
+	switch e := e.(type) {
+	case *UnaryExpr:
+		visitExpr(e.X, fn)
+	case *BinaryExpr:
+		visitExpr(e.L, fn)
+		visitExpr(e.R, fn)
+	case *FuncCall:
+		visitAll(e.Args)
+		if e.Over != nil {
+			visitAll(e.Over.PartitionBy)
+			for _, o := range e.Over.OrderBy {
+				visitExpr(o.Expr, fn)
+			}
+		}
+	case *CaseExpr:
+		visitExpr(e.Operand, fn)
+		for _, w := range e.Whens {
+			visitExpr(w.Cond, fn)
+			visitExpr(w.Result, fn)
+		}
+		visitExpr(e.Else, fn)
+	case *InExpr:
+		visitExpr(e.X, fn)
+		visitAll(e.List)
+	case *BetweenExpr:
+		visitExpr(e.X, fn)
+		visitExpr(e.Lo, fn)
+		visitExpr(e.Hi, fn)
+	case *IsNullExpr:
+		visitExpr(e.X, fn)
+	case *LikeExpr:
+		visitExpr(e.X, fn)
+		visitExpr(e.Pattern, fn)
+	case *CastExpr:
+		visitExpr(e.X, fn)
+	}
+}
+
+func exprList(exprs []Expr) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = e.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+func orderList(items []OrderItem) string {
+	parts := make([]string, len(items))
+	for i, o := range items {
+		parts[i] = o.Expr.String()
+		if o.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,31 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// QueryFrames executes the SELECT statement over the frames and returns the result as a frame with
+// the given name. Tables are the refIDs of the frames. Frames that share a refID form a single table.
+func QueryFrames(ctx context.Context, name, rawSQL string, frames []*data.Frame) (*data.Frame, error) {
+	stmt, err := Parse(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	byRefID := map[string][]*data.Frame{}
+	for _, frame := range frames {
+		byRefID[frame.RefID] = append(byRefID[frame.RefID], frame)
+	}
+	tables := make(map[string]*relation, len(byRefID))
+	for refID, frames := range byRefID {
+		tables[refID] = framesToRelation(frames)
+	}
+
+	rel, err := newExecutor(ctx, tables).execute(stmt)
+	if err != nil {
+		return nil, err
+	}
+	return relationToFrame(name, rel), nil
+}
@@ -0,0 +1,161 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFrames() []*data.Frame {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := func(host string, values ...float64) *data.Frame {
+		times := make([]time.Time, len(values))
+		for i := range values {
+			times[i] = t0.Add(time.Duration(i) * time.Minute)
+		}
+		f := data.NewFrame("",
+			data.NewField("Time", nil, times),
+			data.NewField("A", data.Labels{"host": host}, values),
+		)
+		f.RefID = "A"
+		return f
+	}
+	hosts := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("dc", nil, []string{"east", "west", "west"}),
+	)
+	hosts.RefID = "B"
+	return []*data.Frame{series("a", 1, 2, 3), series("b", 10, 20, 30), hosts}
+}
+
+func query(t *testing.T, rawSQL string) *data.Frame {
+	t.Helper()
+	frame, err := QueryFrames(context.Background(), "result", rawSQL, testFrames())
+	require.NoError(t, err)
+	return frame
+}
+
+func columnValues(t *testing.T, frame *data.Frame, name string) []any {
+	t.Helper()
+	field, idx := frame.FieldByName(name)
+	require.GreaterOrEqual(t, idx, 0, "field %s not found", name)
+	vals := make([]any, field.Len())
+	for i := range vals {
+		if v, ok := field.ConcreteAt(i); ok {
+			vals[i] = v
+		}
+	}
+	return vals
+}
+
+func TestQueryFrames(t *testing.T) {
+	t.Run("labels become columns of the table", func(t *testing.T) {
+		frame := query(t, `SELECT host, A FROM A WHERE A > 2 ORDER BY A DESC`)
+		assert.Equal(t, []any{"b", "b", "b", "a"}, columnValues(t, frame, "host"))
+		assert.Equal(t, []any{30.0, 20.0, 10.0, 3.0}, columnValues(t, frame, "A"))
+	})
+
+	t.Run("group by with aggregates", func(t *testing.T) {
+		frame := query(t, `SELECT host, avg(A) AS mean, count(*) AS n FROM A GROUP BY host ORDER BY host`)
+		assert.Equal(t, []any{"a", "b"}, columnValues(t, frame, "host"))
+		assert.Equal(t, []any{2.0, 20.0}, columnValues(t, frame, "mean"))
+		assert.Equal(t, []any{int64(3), int64(3)}, columnValues(t, frame, "n"))
+	})
+
+	t.Run("aggregate without group by over no rows", func(t *testing.T) {
+		frame := query(t, `SELECT count(*) AS n, sum(A) AS total FROM A WHERE A > 100`)
+		assert.Equal(t, []any{int64(0)}, columnValues(t, frame, "n"))
+		assert.Equal(t, []any{nil}, columnValues(t, frame, "total"))
+	})
+
+	t.Run("having", func(t *testing.T) {
+		frame := query(t, `SELECT host FROM A GROUP BY 1 HAVING max(A) > 5`)
+		assert.Equal(t, []any{"b"}, columnValues(t, frame, "host"))
+	})
+
+	t.Run("join", func(t *testing.T) {
+		frame := query(t, `SELECT B.dc, sum(A.A) AS total FROM A JOIN B ON A.host = B.host GROUP BY B.dc ORDER BY dc`)
+		assert.Equal(t, []any{"east", "west"}, columnValues(t, frame, "dc"))
+		assert.Equal(t, []any{6.0, 60.0}, columnValues(t, frame, "total"))
+	})
+
+	t.Run("left join with using", func(t *testing.T) {
+		frame := query(t, `SELECT host, count(A) AS n FROM B LEFT JOIN A USING (host) GROUP BY host ORDER BY host`)
+		assert.Equal(t, []any{"a", "b", "c"}, columnValues(t, frame, "host"))
+		assert.Equal(t, []any{int64(3), int64(3), int64(0)}, columnValues(t, frame, "n"))
+	})
+
+	t.Run("window functions", func(t *testing.T) {
+		frame := query(t, `SELECT host, A,
+			row_number() OVER (PARTITION BY host ORDER BY Time) AS rn,
+			sum(A) OVER (PARTITION BY host ORDER BY Time) AS running,
+			lag(A) OVER (PARTITION BY host ORDER BY Time) AS prev,
+			avg(A) OVER (PARTITION BY host ORDER BY Time ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) AS smooth
+			FROM A ORDER BY host, Time`)
+		assert.Equal(t, []any{int64(1), int64(2), int64(3), int64(1), int64(2), int64(3)}, columnValues(t, frame, "rn"))
+		assert.Equal(t, []any{1.0, 3.0, 6.0, 10.0, 30.0, 60.0}, columnValues(t, frame, "running"))
+		assert.Equal(t, []any{nil, 1.0, 2.0, nil, 10.0, 20.0}, columnValues(t, frame, "prev"))
+		assert.Equal(t, []any{1.0, 1.5, 2.5, 10.0, 15.0, 25.0}, columnValues(t, frame, "smooth"))
+	})
+
+	t.Run("rank", func(t *testing.T) {
+		frame := query(t, `SELECT dc, rank() OVER (ORDER BY dc) AS r, dense_rank() OVER (ORDER BY dc DESC) AS d FROM B ORDER BY host`)
+		assert.Equal(t, []any{int64(1), int64(2), int64(2)}, columnValues(t, frame, "r"))
+		assert.Equal(t, []any{int64(2), int64(1), int64(1)}, columnValues(t, frame, "d"))
+	})
+
+	t.Run("common table expressions and subqueries", func(t *testing.T) {
+		frame := query(t, `WITH latest AS (SELECT host, last(A) AS v FROM A GROUP BY host)
+			SELECT upper(host) AS host, v FROM latest WHERE v > (SELECT min(A) FROM A) ORDER BY v LIMIT 1`)
+		assert.Equal(t, []any{"A"}, columnValues(t, frame, "host"))
+		assert.Equal(t, []any{3.0}, columnValues(t, frame, "v"))
+	})
+
+	t.Run("distinct, in and case", func(t *testing.T) {
+		frame := query(t, `SELECT DISTINCT CASE WHEN host IN ('a') THEN 'first' ELSE 'other' END AS kind FROM A ORDER BY kind`)
+		assert.Equal(t, []any{"first", "other"}, columnValues(t, frame, "kind"))
+	})
+
+	t.Run("time values", func(t *testing.T) {
+		frame := query(t, `SELECT max(Time) AS last FROM A WHERE Time >= '2024-01-01 00:01:00'`)
+		assert.Equal(t, []any{time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC)}, columnValues(t, frame, "last"))
+	})
+
+	t.Run("fields are nullable only with nulls", func(t *testing.T) {
+		frame := query(t, `SELECT A, nullif(A, 1) AS b FROM A WHERE host = 'a' ORDER BY A`)
+		assert.Equal(t, data.FieldTypeFloat64, frame.Fields[0].Type())
+		assert.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		assert.Equal(t, []any{nil, 2.0, 3.0}, columnValues(t, frame, "b"))
+	})
+}
+
+func TestQueryFramesErrors(t *testing.T) {
+	tests := []struct {
+		sql    string
+		target any
+	}{
+		{sql: `SELECT * FROM C`, target: new(*TableNotFoundError)},
+		{sql: `SELECT nope FROM A`, target: new(*ColumnNotFoundError)},
+		{sql: `SELECT host FROM A JOIN B ON A.host = B.host`, target: new(*ColumnNotFoundError)},
+		{sql: `SELECT unknown_function(A) FROM A`, target: new(*UnsupportedError)},
+		{sql: `SELECT host, A FROM A GROUP BY host`, target: new(*ExecutionError)},
+		{sql: `SELECT FROM A`, target: new(*ParseError)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, err := QueryFrames(context.Background(), "result", tt.sql, testFrames())
+			assert.ErrorAs(t, err, tt.target)
+		})
+	}
+}
+
+func TestQueryFramesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := QueryFrames(ctx, "result", `SELECT * FROM A`, testFrames())
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,67 @@
+package sql
+
+import (
+	"fmt"
+)
+
+// ParseError is returned when the SQL statement is not valid.
+type ParseError struct {
+	// Pos is the byte offset in the statement where the error was found.
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// UnsupportedError is returned when the SQL statement is valid but uses syntax or
+// functions that the engine does not support.
+type UnsupportedError struct {
+	Feature string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("unsupported sql: %s", e.Feature)
+}
+
+// TableNotFoundError is returned when the statement references a table that does not exist.
+type TableNotFoundError struct {
+	Table string
+}
+
+func (e *TableNotFoundError) Error() string {
+	return fmt.Sprintf("table %q not found", e.Table)
+}
+
+// ColumnNotFoundError is returned when the statement references a column that does not exist,
+// or a column name that exists in more than one table without being qualified.
+type ColumnNotFoundError struct {
+	Column    string
+	Ambiguous bool
+}
+
+func (e *ColumnNotFoundError) Error() string {
+	if e.Ambiguous {
+		return fmt.Sprintf("column reference %q is ambiguous", e.Column)
+	}
+	return fmt.Sprintf("column %q not found", e.Column)
+}
+
+// ExecutionError is returned when the statement fails while it is executed, for example
+// because of a type mismatch or a wrong number of arguments to a function.
+type ExecutionError struct {
+	Msg string
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Msg
+}
+
+func unsupported(format string, args ...any) error {
+	return &UnsupportedError{Feature: fmt.Sprintf(format, args...)}
+}
+
+func execError(format string, args ...any) error {
+	return &ExecutionError{Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,789 @@
+package sql
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rowContext is what expressions are evaluated against: a row of a relation, or when the statement
+// is aggregated, a group of rows.
+type rowContext struct {
+	rel *relation
+	row []any
+
+	// group is set when the statement is aggregated. Column references must then match a
+	// grouping expression, whose values are in groupKeys and groupCols, or be used in an aggregate.
+	group     [][]any
+	groupKeys map[string]any
+	groupCols map[int]any
+
+	// windows holds the results of the window functions for this row.
+	windows map[*FuncCall]any
+}
+
+// eval evaluates the expression against the context.
+func (ex *executor) eval(e Expr, ctx *rowContext) (any, error) {
+	if ctx.groupKeys != nil {
+		if v, ok := ctx.groupKeys[e.String()]; ok {
+			return v, nil
+		}
+	}
+
+	switch e := e.(type) {
+	case *Literal:
+		return e.Value, nil
+	case *ColumnRef:
+		return ex.column(e, ctx)
+	case *UnaryExpr:
+		x, err := ex.eval(e.X, ctx)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		if e.Op == "NOT" {
+			b, err := toBool(x)
+			return !b, err
+		}
+		return arithmetic("-", int64(0), x)
+	case *BinaryExpr:
+		return ex.binary(e, ctx)
+	case *FuncCall:
+		return ex.funcCall(e, ctx)
+	case *CaseExpr:
+		return ex.caseExpr(e, ctx)
+	case *InExpr:
+		return ex.in(e, ctx)
+	case *BetweenExpr:
+		x, err := ex.eval(e.X, ctx)
+		if err != nil {
+			return nil, err
+		}
+		lo, err := ex.eval(e.Lo, ctx)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := ex.eval(e.Hi, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if x == nil || lo == nil || hi == nil {
+			return nil, nil
+		}
+		c1, err := compareValues(x, lo)
+		if err != nil {
+			return nil, err
+		}
+		c2, err := compareValues(x, hi)
+		if err != nil {
+			return nil, err
+		}
+		return (c1 >= 0 && c2 <= 0) != e.Not, nil
+	case *IsNullExpr:
+		x, err := ex.eval(e.X, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return (x == nil) != e.Not, nil
+	case *LikeExpr:
+		return ex.like(e, ctx)
+	case *CastExpr:
+		x, err := ex.eval(e.X, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return castValue(x, e.Type)
+	case *SubqueryExpr:
+		rel, err := ex.subquery(e.Select)
+		if err != nil {
+			return nil, err
+		}
+		if len(rel.columns) != 1 {
+			return nil, execError("subquery must return a single column, got %d", len(rel.columns))
+		}
+		switch len(rel.rows) {
+		case 0:
+			return nil, nil
+		case 1:
+			return rel.rows[0][0], nil
+		}
+		return nil, execError("subquery used as an expression returned more than one row")
+	}
+	return nil, unsupported("expression %s", e)
+}
+
+func (ex *executor) column(ref *ColumnRef, ctx *rowContext) (any, error) {
+	if ctx.rel == nil {
+		return nil, &ColumnNotFoundError{Column: ref.String()}
+	}
+	idx, err := ctx.rel.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.group != nil {
+		if v, ok := ctx.groupCols[idx]; ok {
+			return v, nil
+		}
+		return nil, execError("column %q must appear in the GROUP BY clause or be used in an aggregate function", ref)
+	}
+	return ctx.row[idx], nil
+}
+
+func (ex *executor) binary(e *BinaryExpr, ctx *rowContext) (any, error) {
+	l, err := ex.eval(e.L, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// AND and OR use three-valued logic, where NULL is unknown.
+	if e.Op == "AND" || e.Op == "OR" {
+		var lb bool
+		if l != nil {
+			if lb, err = toBool(l); err != nil {
+				return nil, err
+			}
+			if e.Op == "AND" && !lb {
+				return false, nil
+			}
+			if e.Op == "OR" && lb {
+				return true, nil
+			}
+		}
+		r, err := ex.eval(e.R, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, nil
+		}
+		rb, err := toBool(r)
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			if (e.Op == "AND" && !rb) || (e.Op == "OR" && rb) {
+				return rb, nil
+			}
+			return nil, nil
+		}
+		return rb, nil
+	}
+
+	r, err := ex.eval(e.R, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+	switch e.Op {
+	case "=", "<>", "<", "<=", ">", ">=":
+		c, err := compareValues(l, r)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case "=":
+			return c == 0, nil
+		case "<>":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "||":
+		return toString(l) + toString(r), nil
+	}
+	return arithmetic(e.Op, l, r)
+}
+
+func (ex *executor) caseExpr(e *CaseExpr, ctx *rowContext) (any, error) {
+	var operand any
+	var err error
+	if e.Operand != nil {
+		if operand, err = ex.eval(e.Operand, ctx); err != nil {
+			return nil, err
+		}
+	}
+	for _, w := range e.Whens {
+		cond, err := ex.eval(w.Cond, ctx)
+		if err != nil {
+			return nil, err
+		}
+		var match bool
+		if e.Operand != nil {
+			if operand != nil && cond != nil {
+				c, err := compareValues(operand, cond)
+				if err != nil {
+					return nil, err
+				}
+				match = c == 0
+			}
+		} else if match, err = toBool(cond); err != nil {
+			return nil, err
+		}
+		if match {
+			return ex.eval(w.Result, ctx)
+		}
+	}
+	if e.Else != nil {
+		return ex.eval(e.Else, ctx)
+	}
+	return nil, nil
+}
+
+func (ex *executor) in(e *InExpr, ctx *rowContext) (any, error) {
+	x, err := ex.eval(e.X, ctx)
+	if err != nil || x == nil {
+		return nil, err
+	}
+	var list []any
+	if e.Subquery != nil {
+		rel, err := ex.subquery(e.Subquery)
+		if err != nil {
+			return nil, err
+		}
+		if len(rel.columns) != 1 {
+			return nil, execError("subquery in IN must return a single column, got %d", len(rel.columns))
+		}
+		for _, row := range rel.rows {
+			list = append(list, row[0])
+		}
+	} else {
+		for _, item := range e.List {
+			v, err := ex.eval(item, ctx)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+	}
+	hasNull := false
+	for _, v := range list {
+		if v == nil {
+			hasNull = true
+			continue
+		}
+		c, err := compareValues(x, v)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			return !e.Not, nil
+		}
+	}
+	if hasNull {
+		return nil, nil
+	}
+	return e.Not, nil
+}
+
+func (ex *executor) like(e *LikeExpr, ctx *rowContext) (any, error) {
+	x, err := ex.eval(e.X, ctx)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := ex.eval(e.Pattern, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if x == nil || pattern == nil {
+		return nil, nil
+	}
+	re, err := ex.likeRegexp(toString(pattern), e.CaseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(toString(x)) != e.Not, nil
+}
+
+// likeRegexp converts a LIKE pattern, where % matches any sequence of characters and _ matches
+// any single character, to a regular expression.
+func (ex *executor) likeRegexp(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	key := pattern
+	if caseInsensitive {
+		key = "(?i)" + pattern
+	}
+	if re, ok := ex.likeCache[key]; ok {
+		return re, nil
+	}
+	var sb strings.Builder
+	if caseInsensitive {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, execError("invalid LIKE pattern %q", pattern)
+	}
+	ex.likeCache[key] = re
+	return re, nil
+}
+
+func (ex *executor) funcCall(f *FuncCall, ctx *rowContext) (any, error) {
+	if f.Over != nil {
+		v, ok := ctx.windows[f]
+		if !ok {
+			return nil, execError("window function %s is not allowed here", f.Name)
+		}
+		return v, nil
+	}
+	if _, ok := aggregates[f.Name]; ok {
+		if ctx.group == nil {
+			return nil, execError("aggregate function %s is not allowed here", f.Name)
+		}
+		return ex.aggregate(f, ctx)
+	}
+	if _, ok := windowOnly[f.Name]; ok {
+		return nil, execError("window function %s requires an OVER clause", f.Name)
+	}
+
+	fn, ok := scalarFuncs[f.Name]
+	if !ok {
+		return nil, unsupported("function %s", f.Name)
+	}
+	if f.Star || f.Distinct {
+		return nil, execError("%s is not an aggregate function", f.Name)
+	}
+	if len(f.Args) < fn.minArgs || (fn.maxArgs >= 0 && len(f.Args) > fn.maxArgs) {
+		return nil, execError("wrong number of arguments to function %s", f.Name)
+	}
+	args := make([]any, len(f.Args))
+	for i, a := range f.Args {
+		v, err := ex.eval(a, ctx)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return fn.call(args)
+}
+
+// aggregate evaluates an aggregate function over the rows of the group of the context.
+func (ex *executor) aggregate(f *FuncCall, ctx *rowContext) (any, error) {
+	vals, err := ex.aggregateArgs(f, ctx.rel, ctx.group)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateValues(f, vals)
+}
+
+// aggregateArgs evaluates the argument of the aggregate function for each row.
+// For count(*), each row is represented by true.
+func (ex *executor) aggregateArgs(f *FuncCall, rel *relation, rows [][]any) ([]any, error) {
+	vals := make([]any, 0, len(rows))
+	if f.Star {
+		if f.Name != "count" {
+			return nil, execError("%s(*) is not supported", f.Name)
+		}
+		for range rows {
+			vals = append(vals, true)
+		}
+		return vals, nil
+	}
+	if len(f.Args) != 1 {
+		return nil, execError("aggregate function %s requires exactly one argument", f.Name)
+	}
+	seen := map[string]bool{}
+	for _, row := range rows {
+		v, err := ex.eval(f.Args[0], &rowContext{rel: rel, row: row})
+		if err != nil {
+			return nil, err
+		}
+		if f.Distinct {
+			k := valueKey(v)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// aggregates are the aggregate functions, which can also be used as window functions.
+var aggregates = map[string]struct{}{
+	"count": {}, "sum": {}, "avg": {}, "mean": {}, "min": {}, "max": {}, "median": {},
+	"stddev": {}, "stddev_samp": {}, "stddev_pop": {}, "variance": {}, "var_samp": {}, "var_pop": {},
+	"first": {}, "last": {}, "string_agg": {},
+}
+
+// windowOnly are the functions that can only be used as window functions.
+var windowOnly = map[string]struct{}{
+	"row_number": {}, "rank": {}, "dense_rank": {}, "lag": {}, "lead": {}, "first_value": {}, "last_value": {},
+}
+
+// aggregateValues applies the aggregate function to the values. NULL values are ignored,
+// except by first and last.
+func aggregateValues(f *FuncCall, vals []any) (any, error) {
+	switch f.Name {
+	case "first":
+		if len(vals) == 0 {
+			return nil, nil
+		}
+		return vals[0], nil
+	case "last":
+		if len(vals) == 0 {
+			return nil, nil
+		}
+		return vals[len(vals)-1], nil
+	}
+
+	nonNull := make([]any, 0, len(vals))
+	for _, v := range vals {
+		if v != nil {
+			nonNull = append(nonNull, v)
+		}
+	}
+	if f.Name == "count" {
+		return int64(len(nonNull)), nil
+	}
+	if len(nonNull) == 0 {
+		return nil, nil
+	}
+
+	switch f.Name {
+	case "min", "max":
+		result := nonNull[0]
+		for _, v := range nonNull[1:] {
+			c, err := compareValues(v, result)
+			if err != nil {
+				return nil, err
+			}
+			if (f.Name == "min" && c < 0) || (f.Name == "max" && c > 0) {
+				result = v
+			}
+		}
+		return result, nil
+	case "string_agg":
+		parts := make([]string, len(nonNull))
+		for i, v := range nonNull {
+			parts[i] = toString(v)
+		}
+		return strings.Join(parts, ","), nil
+	}
+
+	floats := make([]float64, len(nonNull))
+	allInts := true
+	var intSum int64
+	for i, v := range nonNull {
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, execError("cannot aggregate %s values", typeName(v))
+		}
+		floats[i] = f
+		if n, ok := v.(int64); ok {
+			intSum += n
+		} else {
+			allInts = false
+		}
+	}
+	var sum float64
+	for _, v := range floats {
+		sum += v
+	}
+	n := float64(len(floats))
+	switch f.Name {
+	case "sum":
+		if allInts {
+			return intSum, nil
+		}
+		return sum, nil
+	case "avg", "mean":
+		return sum / n, nil
+	case "median":
+		sorted := append([]float64(nil), floats...)
+		sort.Float64s(sorted)
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[mid-1] + sorted[mid]) / 2, nil
+		}
+		return sorted[mid], nil
+	}
+
+	mean := sum / n
+	var sq float64
+	for _, v := range floats {
+		sq += (v - mean) * (v - mean)
+	}
+	switch f.Name {
+	case "var_pop":
+		return sq / n, nil
+	case "stddev_pop":
+		return math.Sqrt(sq / n), nil
+	}
+	if len(floats) < 2 {
+		return nil, nil
+	}
+	if f.Name == "variance" || f.Name == "var_samp" {
+		return sq / (n - 1), nil
+	}
+	return math.Sqrt(sq / (n - 1)), nil
+}
+
+type scalarFunc struct {
+	minArgs int
+	maxArgs int // -1 for any number of arguments
+	call    func(args []any) (any, error)
+}
+
+// numeric wraps a function of one number. NULL arguments return NULL.
+func numeric(fn func(float64) float64) scalarFunc {
+	return scalarFunc{minArgs: 1, maxArgs: 1, call: func(args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		f, ok := toFloat(args[0])
+		if !ok {
+			return nil, execError("expected a number, got %s", typeName(args[0]))
+		}
+		return fn(f), nil
+	}}
+}
+
+// text wraps a function of one string. NULL arguments return NULL.
+func text(fn func(string) any) scalarFunc {
+	return scalarFunc{minArgs: 1, maxArgs: 1, call: func(args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		return fn(toString(args[0])), nil
+	}}
+}
+
+func anyNull(args []any) bool {
+	for _, a := range args {
+		if a == nil {
+			return true
+		}
+	}
+	return false
+}
+
+var scalarFuncs map[string]scalarFunc
+
+func init() {
+	scalarFuncs = map[string]scalarFunc{
+		"abs": {minArgs: 1, maxArgs: 1, call: func(args []any) (any, error) {
+			if i, ok := args[0].(int64); ok {
+				if i < 0 {
+					return -i, nil
+				}
+				return i, nil
+			}
+			return numeric(math.Abs).call(args)
+		}},
+		"ceil":    numeric(math.Ceil),
+		"ceiling": numeric(math.Ceil),
+		"floor":   numeric(math.Floor),
+		"sqrt":    numeric(math.Sqrt),
+		"ln":      numeric(math.Log),
+		"log10":   numeric(math.Log10),
+		"log2":    numeric(math.Log2),
+		"exp":     numeric(math.Exp),
+		"round": {minArgs: 1, maxArgs: 2, call: func(args []any) (any, error) {
+			if anyNull(args) {
+				return nil, nil
+			}
+			if i, ok := args[0].(int64); ok && len(args) == 1 {
+				return i, nil
+			}
+			f, ok := toFloat(args[0])
+			if !ok {
+				return nil, execError("expected a number, got %s", typeName(args[0]))
+			}
+			precision := 0.0
+			if len(args) == 2 {
+				if precision, ok = toFloat(args[1]); !ok {
+					return nil, execError("expected a number for the precision, got %s", typeName(args[1]))
+				}
+			}
+			scale := math.Pow(10, precision)
+			return math.Round(f*scale) / scale, nil
+		}},
+		"power": {minArgs: 2, maxArgs: 2, call: pow},
+		"pow":   {minArgs: 2, maxArgs: 2, call: pow},
+		"lower": text(func(s string) any { return strings.ToLower(s) }),
+		"upper": text(func(s string) any { return strings.ToUpper(s) }),
+		"trim":  text(func(s string) any { return strings.TrimSpace(s) }),
+		"length": text(func(s string) any {
+			return int64(len([]rune(s)))
+		}),
+		"substring": {minArgs: 2, maxArgs: 3, call: substring},
+		"substr":    {minArgs: 2, maxArgs: 3, call: substring},
+		"replace": {minArgs: 3, maxArgs: 3, call: func(args []any) (any, error) {
+			if anyNull(args) {
+				return nil, nil
+			}
+			return strings.ReplaceAll(toString(args[0]), toString(args[1]), toString(args[2])), nil
+		}},
+		"concat": {minArgs: 1, maxArgs: -1, call: func(args []any) (any, error) {
+			var sb strings.Builder
+			for _, a := range args {
+				sb.WriteString(toString(a))
+			}
+			return sb.String(), nil
+		}},
+		"coalesce": {minArgs: 1, maxArgs: -1, call: func(args []any) (any, error) {
+			for _, a := range args {
+				if a != nil {
+					return a, nil
+				}
+			}
+			return nil, nil
+		}},
+		"nullif": {minArgs: 2, maxArgs: 2, call: func(args []any) (any, error) {
+			if args[0] == nil || args[1] == nil {
+				return args[0], nil
+			}
+			c, err := compareValues(args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			if c == 0 {
+				return nil, nil
+			}
+			return args[0], nil
+		}},
+		"greatest": {minArgs: 1, maxArgs: -1, call: func(args []any) (any, error) {
+			return extreme(args, 1)
+		}},
+		"least": {minArgs: 1, maxArgs: -1, call: func(args []any) (any, error) {
+			return extreme(args, -1)
+		}},
+		"date_trunc": {minArgs: 2, maxArgs: 2, call: dateTrunc},
+		"epoch": {minArgs: 1, maxArgs: 1, call: func(args []any) (any, error) {
+			return castValue(args[0], "DOUBLE")
+		}},
+		"epoch_ms": {minArgs: 1, maxArgs: 1, call: func(args []any) (any, error) {
+			if args[0] == nil {
+				return nil, nil
+			}
+			if t, ok := args[0].(time.Time); ok {
+				return t.UnixMilli(), nil
+			}
+			if f, ok := toFloat(args[0]); ok {
+				return time.UnixMilli(int64(f)).UTC(), nil
+			}
+			return nil, execError("expected a timestamp or a number, got %s", typeName(args[0]))
+		}},
+		"to_timestamp": {minArgs: 1, maxArgs: 1, call: func(args []any) (any, error) {
+			if args[0] == nil {
+				return nil, nil
+			}
+			f, ok := toFloat(args[0])
+			if !ok {
+				return nil, execError("expected a number of seconds, got %s", typeName(args[0]))
+			}
+			sec, frac := math.Modf(f)
+			return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC(), nil
+		}},
+	}
+}
+
+func pow(args []any) (any, error) {
+	if anyNull(args) {
+		return nil, nil
+	}
+	x, ok1 := toFloat(args[0])
+	y, ok2 := toFloat(args[1])
+	if !ok1 || !ok2 {
+		return nil, execError("expected numbers, got %s and %s", typeName(args[0]), typeName(args[1]))
+	}
+	return math.Pow(x, y), nil
+}
+
+// substring returns the characters of a string from a 1-based start position.
+func substring(args []any) (any, error) {
+	if anyNull(args) {
+		return nil, nil
+	}
+	runes := []rune(toString(args[0]))
+	start, ok := args[1].(int64)
+	if !ok {
+		return nil, execError("expected an integer start position, got %s", typeName(args[1]))
+	}
+	from := int(start) - 1
+	to := len(runes)
+	if len(args) == 3 {
+		length, ok := args[2].(int64)
+		if !ok {
+			return nil, execError("expected an integer length, got %s", typeName(args[2]))
+		}
+		to = from + int(length)
+	}
+	from = max(0, min(from, len(runes)))
+	to = max(from, min(to, len(runes)))
+	return string(runes[from:to]), nil
+}
+
+// extreme returns the largest (sign 1) or smallest (sign -1) non-null argument.
+func extreme(args []any, sign int) (any, error) {
+	var result any
+	for _, a := range args {
+		if a == nil {
+			continue
+		}
+		if result == nil {
+			result = a
+			continue
+		}
+		c, err := compareValues(a, result)
+		if err != nil {
+			return nil, err
+		}
+		if c*sign > 0 {
+			result = a
+		}
+	}
+	return result, nil
+}
+
+func dateTrunc(args []any) (any, error) {
+	if anyNull(args) {
+		return nil, nil
+	}
+	t, ok := args[1].(time.Time)
+	if !ok {
+		if t, ok = parseTime(toString(args[1])); !ok {
+			return nil, execError("expected a timestamp, got %s", typeName(args[1]))
+		}
+	}
+	t = t.UTC()
+	switch strings.ToLower(toString(args[0])) {
+	case "second":
+		return t.Truncate(time.Second), nil
+	case "minute":
+		return t.Truncate(time.Minute), nil
+	case "hour":
+		return t.Truncate(time.Hour), nil
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return nil, unsupported("date_trunc unit %s", toString(args[0]))
+}
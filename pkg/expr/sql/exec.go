@@ -0,0 +1,653 @@
+package sql
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// column is a column of a relation. Table is the name or alias the column can be qualified with.
+type column struct {
+	table string
+	name  string
+}
+
+// relation is a table of rows, that is an input table or the result of a statement.
+type relation struct {
+	columns []column
+	rows    [][]any
+}
+
+// resolve returns the index of the referenced column. Names are matched exactly first, and then
+// ignoring case.
+func (r *relation) resolve(ref *ColumnRef) (int, error) {
+	match := func(eq func(a, b string) bool) []int {
+		var found []int
+		for i, c := range r.columns {
+			if ref.Table != "" && !strings.EqualFold(ref.Table, c.table) {
+				continue
+			}
+			if eq(c.name, ref.Name) {
+				found = append(found, i)
+			}
+		}
+		return found
+	}
+	found := match(func(a, b string) bool { return a == b })
+	if len(found) == 0 {
+		found = match(strings.EqualFold)
+	}
+	switch len(found) {
+	case 0:
+		return 0, &ColumnNotFoundError{Column: ref.String()}
+	case 1:
+		return found[0], nil
+	}
+	return 0, &ColumnNotFoundError{Column: ref.String(), Ambiguous: true}
+}
+
+// qualified returns a relation with the same rows whose columns are qualified by the table name.
+func (r *relation) qualified(table string) *relation {
+	columns := make([]column, len(r.columns))
+	for i, c := range r.columns {
+		columns[i] = column{table: table, name: c.name}
+	}
+	return &relation{columns: columns, rows: r.rows}
+}
+
+// executor executes a statement against a set of tables.
+type executor struct {
+	ctx    context.Context
+	tables map[string]*relation
+
+	// ctes are the common table expressions in scope, by lower case name.
+	ctes map[string]*relation
+
+	likeCache  map[string]*regexp.Regexp
+	subqueries map[*SelectStatement]*relation
+}
+
+func newExecutor(ctx context.Context, tables map[string]*relation) *executor {
+	return &executor{
+		ctx:        ctx,
+		tables:     tables,
+		ctes:       map[string]*relation{},
+		likeCache:  map[string]*regexp.Regexp{},
+		subqueries: map[*SelectStatement]*relation{},
+	}
+}
+
+// subquery executes a subquery of an expression. Subqueries cannot reference the outer statement,
+// so the result is computed once.
+func (ex *executor) subquery(stmt *SelectStatement) (*relation, error) {
+	if rel, ok := ex.subqueries[stmt]; ok {
+		return rel, nil
+	}
+	rel, err := ex.execute(stmt)
+	if err != nil {
+		return nil, err
+	}
+	ex.subqueries[stmt] = rel
+	return rel, nil
+}
+
+// execute executes the statement.
+func (ex *executor) execute(stmt *SelectStatement) (*relation, error) {
+	if err := ex.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(stmt.With) > 0 {
+		outer := ex.ctes
+		defer func() { ex.ctes = outer }()
+		scope := make(map[string]*relation, len(outer)+len(stmt.With))
+		for k, v := range outer {
+			scope[k] = v
+		}
+		for _, cte := range stmt.With {
+			// Each common table expression can reference the ones before it.
+			ex.ctes = scope
+			rel, err := ex.execute(cte.Select)
+			if err != nil {
+				return nil, err
+			}
+			scope[strings.ToLower(cte.Name)] = rel.qualified(cte.Name)
+		}
+		ex.ctes = scope
+	}
+
+	src := &relation{rows: [][]any{{}}}
+	if stmt.From != nil {
+		var err error
+		if src, err = ex.from(stmt.From); err != nil {
+			return nil, err
+		}
+	}
+
+	if stmt.Where != nil {
+		var rows [][]any
+		for _, row := range src.rows {
+			ok, err := ex.condition(stmt.Where, &rowContext{rel: src, row: row})
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				rows = append(rows, row)
+			}
+		}
+		src = &relation{columns: src.columns, rows: rows}
+	}
+
+	groupBy, err := resolveGroupBy(stmt, src)
+	if err != nil {
+		return nil, err
+	}
+	var contexts []*rowContext
+	if len(groupBy) > 0 || stmt.Having != nil || hasAggregate(stmt) {
+		if contexts, err = ex.group(src, groupBy); err != nil {
+			return nil, err
+		}
+	} else {
+		contexts = make([]*rowContext, len(src.rows))
+		for i, row := range src.rows {
+			contexts[i] = &rowContext{rel: src, row: row}
+		}
+	}
+
+	if stmt.Having != nil {
+		var kept []*rowContext
+		for _, ctx := range contexts {
+			ok, err := ex.condition(stmt.Having, ctx)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				kept = append(kept, ctx)
+			}
+		}
+		contexts = kept
+	}
+
+	if err := ex.windows(stmt, contexts); err != nil {
+		return nil, err
+	}
+
+	out, err := ex.project(stmt, src, contexts)
+	if err != nil {
+		return nil, err
+	}
+
+	if stmt.Distinct {
+		seen := map[string]bool{}
+		var rows [][]any
+		var kept []*rowContext
+		for i, row := range out.rows {
+			k := rowKey(row)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			rows = append(rows, row)
+			kept = append(kept, contexts[i])
+		}
+		out.rows, contexts = rows, kept
+	}
+
+	if len(stmt.OrderBy) > 0 {
+		if err := ex.orderBy(stmt.OrderBy, out, contexts); err != nil {
+			return nil, err
+		}
+	}
+
+	return ex.limit(stmt, out)
+}
+
+// condition evaluates a WHERE, HAVING or join condition. NULL is false.
+func (ex *executor) condition(e Expr, ctx *rowContext) (bool, error) {
+	v, err := ex.eval(e, ctx)
+	if err != nil {
+		return false, err
+	}
+	return toBool(v)
+}
+
+func (ex *executor) from(t TableExpr) (*relation, error) {
+	switch t := t.(type) {
+	case *TableName:
+		rel, err := ex.table(t.Name)
+		if err != nil {
+			return nil, err
+		}
+		if t.Alias != "" {
+			return rel.qualified(t.Alias), nil
+		}
+		return rel.qualified(t.Name), nil
+	case *SubqueryTable:
+		rel, err := ex.execute(t.Select)
+		if err != nil {
+			return nil, err
+		}
+		return rel.qualified(t.Alias), nil
+	case *JoinTable:
+		left, err := ex.from(t.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := ex.from(t.Right)
+		if err != nil {
+			return nil, err
+		}
+		return ex.join(t, left, right)
+	}
+	return nil, unsupported("table expression %T", t)
+}
+
+// table returns a common table expression in scope, or else an input table.
+func (ex *executor) table(name string) (*relation, error) {
+	if rel, ok := ex.ctes[strings.ToLower(name)]; ok {
+		return rel, nil
+	}
+	if rel, ok := ex.tables[name]; ok {
+		return rel, nil
+	}
+	var found *relation
+	for k, rel := range ex.tables {
+		if strings.EqualFold(k, name) {
+			if found != nil {
+				return nil, &TableNotFoundError{Table: name}
+			}
+			found = rel
+		}
+	}
+	if found == nil {
+		return nil, &TableNotFoundError{Table: name}
+	}
+	return found, nil
+}
+
+// join joins two relations with nested loops. Columns listed in USING are merged into a single column.
+func (ex *executor) join(j *JoinTable, left, right *relation) (*relation, error) {
+	combined := &relation{columns: append(append([]column{}, left.columns...), right.columns...)}
+	width := len(left.columns)
+
+	var usingLeft, usingRight []int
+	for _, name := range j.Using {
+		l, err := left.resolve(&ColumnRef{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		r, err := right.resolve(&ColumnRef{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		usingLeft = append(usingLeft, l)
+		usingRight = append(usingRight, width+r)
+	}
+
+	match := func(row []any) (bool, error) {
+		switch {
+		case j.On != nil:
+			return ex.condition(j.On, &rowContext{rel: combined, row: row})
+		case len(j.Using) > 0:
+			for i := range usingLeft {
+				l, r := row[usingLeft[i]], row[usingRight[i]]
+				if l == nil || r == nil {
+					return false, nil
+				}
+				c, err := compareValues(l, r)
+				if err != nil || c != 0 {
+					return false, err
+				}
+			}
+		}
+		return true, nil
+	}
+
+	rightMatched := make([]bool, len(right.rows))
+	for _, lrow := range left.rows {
+		if err := ex.ctx.Err(); err != nil {
+			return nil, err
+		}
+		matched := false
+		for ri, rrow := range right.rows {
+			row := append(append(make([]any, 0, len(combined.columns)), lrow...), rrow...)
+			ok, err := match(row)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = true
+				rightMatched[ri] = true
+				combined.rows = append(combined.rows, row)
+			}
+		}
+		if !matched && (j.Kind == JoinLeft || j.Kind == JoinFull) {
+			row := append(append(make([]any, 0, len(combined.columns)), lrow...), make([]any, len(right.columns))...)
+			combined.rows = append(combined.rows, row)
+		}
+	}
+	if j.Kind == JoinRight || j.Kind == JoinFull {
+		for ri, rrow := range right.rows {
+			if !rightMatched[ri] {
+				row := append(make([]any, width, len(combined.columns)), rrow...)
+				combined.rows = append(combined.rows, row)
+			}
+		}
+	}
+
+	if len(j.Using) == 0 {
+		return combined, nil
+	}
+
+	// The merged columns take the value of either side, and the columns of the right side are dropped.
+	drop := map[int]bool{}
+	for _, r := range usingRight {
+		drop[r] = true
+	}
+	merged := &relation{}
+	for i, c := range combined.columns {
+		if !drop[i] {
+			merged.columns = append(merged.columns, c)
+		}
+	}
+	for _, row := range combined.rows {
+		for i, l := range usingLeft {
+			if row[l] == nil {
+				row[l] = row[usingRight[i]]
+			}
+		}
+		out := make([]any, 0, len(merged.columns))
+		for i, v := range row {
+			if !drop[i] {
+				out = append(out, v)
+			}
+		}
+		merged.rows = append(merged.rows, out)
+	}
+	return merged, nil
+}
+
+// resolveGroupBy replaces ordinals and aliases of the select list in the GROUP BY clause by the
+// expressions they refer to.
+func resolveGroupBy(stmt *SelectStatement, src *relation) ([]Expr, error) {
+	groupBy := make([]Expr, len(stmt.GroupBy))
+	for i, e := range stmt.GroupBy {
+		groupBy[i] = e
+		switch e := e.(type) {
+		case *Literal:
+			n, ok := e.Value.(int64)
+			if !ok {
+				continue
+			}
+			if n < 1 || int(n) > len(stmt.Columns) || stmt.Columns[n-1].Star {
+				return nil, execError("GROUP BY position %d is not in the select list", n)
+			}
+			groupBy[i] = stmt.Columns[n-1].Expr
+		case *ColumnRef:
+			if e.Table != "" {
+				continue
+			}
+			if _, err := src.resolve(e); err == nil {
+				continue
+			}
+			for _, item := range stmt.Columns {
+				if !item.Star && strings.EqualFold(item.Alias, e.Name) {
+					groupBy[i] = item.Expr
+					break
+				}
+			}
+		}
+	}
+	return groupBy, nil
+}
+
+// hasAggregate returns true if an aggregate function is used outside of a window function.
+func hasAggregate(stmt *SelectStatement) bool {
+	exprs := make([]Expr, 0, len(stmt.Columns)+len(stmt.OrderBy))
+	for _, item := range stmt.Columns {
+		if !item.Star {
+			exprs = append(exprs, item.Expr)
+		}
+	}
+	for _, o := range stmt.OrderBy {
+		exprs = append(exprs, o.Expr)
+	}
+	found := false
+	for _, e := range exprs {
+		visitExpr(e, func(e Expr) bool {
+			if f, ok := e.(*FuncCall); ok && f.Over == nil {
+				if _, ok := aggregates[f.Name]; ok {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// group groups the rows by the values of the expressions. Without expressions, all rows are in a
+// single group, even if there are none.
+func (ex *executor) group(src *relation, groupBy []Expr) ([]*rowContext, error) {
+	newGroup := func() *rowContext {
+		return &rowContext{rel: src, group: [][]any{}, groupKeys: map[string]any{}, groupCols: map[int]any{}}
+	}
+	if len(groupBy) == 0 {
+		ctx := newGroup()
+		ctx.group = append(ctx.group, src.rows...)
+		return []*rowContext{ctx}, nil
+	}
+
+	// Grouping by a column also allows referencing it with a different qualification.
+	cols := make([]int, len(groupBy))
+	for i, e := range groupBy {
+		cols[i] = -1
+		if ref, ok := e.(*ColumnRef); ok {
+			idx, err := src.resolve(ref)
+			if err != nil {
+				return nil, err
+			}
+			cols[i] = idx
+		}
+	}
+
+	var groups []*rowContext
+	index := map[string]*rowContext{}
+	for _, row := range src.rows {
+		vals := make([]any, len(groupBy))
+		for i, e := range groupBy {
+			v, err := ex.eval(e, &rowContext{rel: src, row: row})
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = v
+		}
+		k := rowKey(vals)
+		ctx, ok := index[k]
+		if !ok {
+			ctx = newGroup()
+			ctx.row = row
+			for i, e := range groupBy {
+				ctx.groupKeys[e.String()] = vals[i]
+				if cols[i] >= 0 {
+					ctx.groupCols[cols[i]] = vals[i]
+				}
+			}
+			index[k] = ctx
+			groups = append(groups, ctx)
+		}
+		ctx.group = append(ctx.group, row)
+	}
+	return groups, nil
+}
+
+// project evaluates the select list for each context.
+func (ex *executor) project(stmt *SelectStatement, src *relation, contexts []*rowContext) (*relation, error) {
+	type output struct {
+		expr Expr
+		col  int // index in src for star items, used when expr is nil
+	}
+	var outputs []output
+	out := &relation{}
+	for _, item := range stmt.Columns {
+		if item.Star {
+			n := 0
+			for i, c := range src.columns {
+				if item.Table != "" && !strings.EqualFold(item.Table, c.table) {
+					continue
+				}
+				outputs = append(outputs, output{expr: &ColumnRef{Table: c.table, Name: c.name}, col: i})
+				out.columns = append(out.columns, column{name: c.name})
+				n++
+			}
+			if item.Table != "" && n == 0 {
+				return nil, &TableNotFoundError{Table: item.Table}
+			}
+			continue
+		}
+		name := item.Alias
+		if name == "" {
+			if ref, ok := item.Expr.(*ColumnRef); ok {
+				name = ref.Name
+			} else {
+				name = item.Expr.String()
+			}
+		}
+		outputs = append(outputs, output{expr: item.Expr, col: -1})
+		out.columns = append(out.columns, column{name: name})
+	}
+
+	out.rows = make([][]any, 0, len(contexts))
+	for _, ctx := range contexts {
+		if err := ex.ctx.Err(); err != nil {
+			return nil, err
+		}
+		row := make([]any, len(outputs))
+		for i, o := range outputs {
+			var v any
+			var err error
+			if o.col >= 0 && ctx.group == nil {
+				v = ctx.row[o.col]
+			} else if v, err = ex.eval(o.expr, ctx); err != nil {
+				return nil, err
+			}
+			row[i] = v
+		}
+		out.rows = append(out.rows, row)
+	}
+	return out, nil
+}
+
+// orderBy sorts the output rows. Items can reference output columns by position or name, or be
+// expressions of the input. NULL values are sorted last, unless NULLS FIRST is used.
+func (ex *executor) orderBy(items []OrderItem, out *relation, contexts []*rowContext) error {
+	keys := make([][]any, len(out.rows))
+	for i, row := range out.rows {
+		keys[i] = make([]any, len(items))
+		for j, item := range items {
+			v, err := ex.orderValue(item.Expr, out, row, contexts[i])
+			if err != nil {
+				return err
+			}
+			keys[i][j] = v
+		}
+	}
+	idx := make([]int, len(out.rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	var sortErr error
+	sort.SliceStable(idx, func(a, b int) bool {
+		c, err := compareKeys(keys[idx[a]], keys[idx[b]], items)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+	rows := make([][]any, len(idx))
+	for i, j := range idx {
+		rows[i] = out.rows[j]
+	}
+	out.rows = rows
+	return nil
+}
+
+func (ex *executor) orderValue(e Expr, out *relation, row []any, ctx *rowContext) (any, error) {
+	switch e := e.(type) {
+	case *Literal:
+		if n, ok := e.Value.(int64); ok {
+			if n < 1 || int(n) > len(row) {
+				return nil, execError("ORDER BY position %d is not in the select list", n)
+			}
+			return row[n-1], nil
+		}
+	case *ColumnRef:
+		if e.Table == "" {
+			if idx, err := out.resolve(e); err == nil {
+				return row[idx], nil
+			}
+		}
+	}
+	return ex.eval(e, ctx)
+}
+
+// compareKeys compares two rows of sort keys.
+func compareKeys(a, b []any, items []OrderItem) (int, error) {
+	for i, item := range items {
+		av, bv := a[i], b[i]
+		if av == nil && bv == nil {
+			continue
+		}
+		nullsFirst := item.NullsFirst != nil && *item.NullsFirst
+		if av == nil || bv == nil {
+			if (av == nil) == nullsFirst {
+				return -1, nil
+			}
+			return 1, nil
+		}
+		c, err := compareValues(av, bv)
+		if err != nil {
+			return 0, err
+		}
+		if item.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+	return 0, nil
+}
+
+func (ex *executor) limit(stmt *SelectStatement, out *relation) (*relation, error) {
+	count := func(e Expr, clause string) (int, error) {
+		v, err := ex.eval(e, &rowContext{})
+		if err != nil {
+			return 0, err
+		}
+		n, ok := v.(int64)
+		if !ok || n < 0 {
+			return 0, execError("%s must be a non-negative integer", clause)
+		}
+		return int(n), nil
+	}
+	if stmt.Offset != nil {
+		n, err := count(stmt.Offset, "OFFSET")
+		if err != nil {
+			return nil, err
+		}
+		out.rows = out.rows[min(n, len(out.rows)):]
+	}
+	if stmt.Limit != nil {
+		n, err := count(stmt.Limit, "LIMIT")
+		if err != nil {
+			return nil, err
+		}
+		out.rows = out.rows[:min(n, len(out.rows))]
+	}
+	return out, nil
+}
@@ -0,0 +1,183 @@
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// framesToRelation converts the frames of a table to a relation. The columns are the union of the
+// fields of the frames, by name. Field labels are added as columns holding the label values, so
+// that several series of a refID become a single table in long format.
+func framesToRelation(frames []*data.Frame) *relation {
+	rel := &relation{}
+	index := map[string]int{}
+	addColumn := func(name string) int {
+		if i, ok := index[name]; ok {
+			return i
+		}
+		index[name] = len(rel.columns)
+		rel.columns = append(rel.columns, column{name: name})
+		return index[name]
+	}
+
+	for _, frame := range frames {
+		fieldCols := make([]int, len(frame.Fields))
+		names := map[string]bool{}
+		for i, field := range frame.Fields {
+			fieldCols[i] = addColumn(field.Name)
+			names[field.Name] = true
+		}
+		// Labels with the name of a field of the frame are ignored.
+		var keys []string
+		labelCols := map[string]int{}
+		for _, field := range frame.Fields {
+			for k := range field.Labels {
+				if _, ok := labelCols[k]; !ok && !names[k] {
+					labelCols[k] = -1
+					keys = append(keys, k)
+				}
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			labelCols[k] = addColumn(k)
+		}
+
+		rows, _ := frame.RowLen()
+		for r := 0; r < rows; r++ {
+			row := make([]any, len(rel.columns))
+			for i, field := range frame.Fields {
+				row[fieldCols[i]] = fieldValue(field, r)
+				for k, v := range field.Labels {
+					if !names[k] {
+						row[labelCols[k]] = v
+					}
+				}
+			}
+			rel.rows = append(rel.rows, row)
+		}
+	}
+
+	// Rows of earlier frames are padded with NULL for the columns of later frames.
+	for i, row := range rel.rows {
+		if len(row) < len(rel.columns) {
+			rel.rows[i] = append(row, make([]any, len(rel.columns)-len(row))...)
+		}
+	}
+	return rel
+}
+
+// fieldValue returns the value at the index of the field as one of the types of values.
+func fieldValue(field *data.Field, idx int) any {
+	v, ok := field.ConcreteAt(idx)
+	if !ok {
+		return nil
+	}
+	switch v := v.(type) {
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		return v
+	case bool:
+		return v
+	case time.Time:
+		return v
+	case json.RawMessage:
+		return string(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// relationToFrame converts a relation to a frame. The type of each field is the type of the
+// non-null values of the column. Columns of mixed numbers are float64, and other mixed columns are
+// converted to strings. Fields are nullable only when the column contains NULL.
+func relationToFrame(name string, rel *relation) *data.Frame {
+	frame := data.NewFrame(name)
+	for c, col := range rel.columns {
+		var hasNull, hasInt, hasFloat, hasString, hasBool, hasTime bool
+		for _, row := range rel.rows {
+			switch row[c].(type) {
+			case nil:
+				hasNull = true
+			case int64:
+				hasInt = true
+			case float64:
+				hasFloat = true
+			case string:
+				hasString = true
+			case bool:
+				hasBool = true
+			case time.Time:
+				hasTime = true
+			}
+		}
+
+		var typ data.FieldType
+		var convert func(v any) any
+		switch {
+		case hasString && !hasInt && !hasFloat && !hasBool && !hasTime:
+			typ = data.FieldTypeString
+		case hasBool && !hasString && !hasInt && !hasFloat && !hasTime:
+			typ = data.FieldTypeBool
+		case hasTime && !hasString && !hasInt && !hasFloat && !hasBool:
+			typ = data.FieldTypeTime
+		case hasInt && !hasFloat && !hasString && !hasBool && !hasTime:
+			typ = data.FieldTypeInt64
+		case (hasInt || hasFloat) && !hasString && !hasBool && !hasTime:
+			typ = data.FieldTypeFloat64
+			convert = func(v any) any {
+				f, _ := toFloat(v)
+				return f
+			}
+		case !hasInt && !hasFloat && !hasString && !hasBool && !hasTime:
+			typ = data.FieldTypeFloat64
+		default:
+			typ = data.FieldTypeString
+			convert = func(v any) any { return toString(v) }
+		}
+		if hasNull {
+			typ = typ.NullableType()
+		}
+
+		field := data.NewFieldFromFieldType(typ, len(rel.rows))
+		field.Name = col.name
+		for r, row := range rel.rows {
+			v := row[c]
+			if v == nil {
+				continue
+			}
+			if convert != nil {
+				v = convert(v)
+			}
+			if hasNull {
+				field.SetConcrete(r, v)
+			} else {
+				field.Set(r, v)
+			}
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame
+}
@@ -0,0 +1,164 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIdent
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+func (t token) String() string {
+	switch t.typ {
+	case tokEOF:
+		return "end of statement"
+	case tokString:
+		return fmt.Sprintf("'%s'", t.val)
+	default:
+		return fmt.Sprintf("%q", t.val)
+	}
+}
+
+// is returns true if the token is the keyword or operator, ignoring case.
+func (t token) is(val string) bool {
+	return (t.typ == tokIdent || t.typ == tokOp) && strings.EqualFold(t.val, val)
+}
+
+// operators are matched longest first.
+var operators = []string{"<>", "!=", "<=", ">=", "||", "::", "=", "<", ">", "+", "-", "*", "/", "%", ",", ".", "(", ")", ";", "[", "]"}
+
+// lex splits the statement into tokens. Comments are skipped.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	for pos < len(input) {
+		r, width := utf8.DecodeRuneInString(input[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += width
+		case strings.HasPrefix(input[pos:], "--"):
+			end := strings.IndexByte(input[pos:], '\n')
+			if end < 0 {
+				pos = len(input)
+			} else {
+				pos += end + 1
+			}
+		case strings.HasPrefix(input[pos:], "/*"):
+			end := strings.Index(input[pos+2:], "*/")
+			if end < 0 {
+				return nil, &ParseError{Pos: pos, Msg: "unterminated comment"}
+			}
+			pos += end + 4
+		case r == '\'':
+			val, next, err := lexQuoted(input, pos, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokString, val: val, pos: pos})
+			pos = next
+		case r == '"' || r == '`':
+			val, next, err := lexQuoted(input, pos, byte(r))
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokQuotedIdent, val: val, pos: pos})
+			pos = next
+		case isDigit(r) || (r == '.' && pos+1 < len(input) && isDigit(rune(input[pos+1]))):
+			next := lexNumber(input, pos)
+			tokens = append(tokens, token{typ: tokNumber, val: input[pos:next], pos: pos})
+			pos = next
+		case r == '_' || unicode.IsLetter(r):
+			next := pos
+			for next < len(input) {
+				r, w := utf8.DecodeRuneInString(input[next:])
+				if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				next += w
+			}
+			tokens = append(tokens, token{typ: tokIdent, val: input[pos:next], pos: pos})
+			pos = next
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(input[pos:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{typ: tokOp, val: op, pos: pos})
+			pos += len(op)
+		}
+	}
+	tokens = append(tokens, token{typ: tokEOF, pos: len(input)})
+	return tokens, nil
+}
+
+// lexQuoted reads a string or identifier that starts at pos with the quote character.
+// The quote character is escaped by doubling it.
+func lexQuoted(input string, pos int, quote byte) (string, int, error) {
+	var sb strings.Builder
+	i := pos + 1
+	for i < len(input) {
+		if input[i] == quote {
+			if i+1 < len(input) && input[i+1] == quote {
+				sb.WriteByte(quote)
+				i += 2
+				continue
+			}
+			return sb.String(), i + 1, nil
+		}
+		sb.WriteByte(input[i])
+		i++
+	}
+	return "", 0, &ParseError{Pos: pos, Msg: "unterminated quoted string"}
+}
+
+func lexNumber(input string, pos int) int {
+	i := pos
+	for i < len(input) && isDigit(rune(input[i])) {
+		i++
+	}
+	if i < len(input) && input[i] == '.' {
+		i++
+		for i < len(input) && isDigit(rune(input[i])) {
+			i++
+		}
+	}
+	if i < len(input) && (input[i] == 'e' || input[i] == 'E') {
+		j := i + 1
+		if j < len(input) && (input[j] == '+' || input[j] == '-') {
+			j++
+		}
+		if j < len(input) && isDigit(rune(input[j])) {
+			i = j
+			for i < len(input) && isDigit(rune(input[i])) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -1,90 +1,1019 @@
 package sql
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
-	"github.com/jeremywohl/flatten"
-	"github.com/scottlepp/go-duck/duck"
-)
-
-const (
-	TABLE_NAME    = "table_name"
-	ERROR         = ".error"
-	ERROR_MESSAGE = ".error_message"
 )
 
 var logger = log.New("sql_expr")
 
-// TablesList returns a list of tables for the sql statement
+// reserved are the keywords that can not be used as an alias or an unquoted column name.
+var reserved = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true, "CASE": true,
+	"CROSS": true, "DESC": true, "DISTINCT": true, "ELSE": true, "END": true, "EXCEPT": true,
+	"FALSE": true, "FROM": true, "FULL": true, "GROUP": true, "HAVING": true, "ILIKE": true,
+	"IN": true, "INNER": true, "INTERSECT": true, "IS": true, "JOIN": true, "LEFT": true,
+	"LIKE": true, "LIMIT": true, "NOT": true, "NULL": true, "OFFSET": true, "ON": true, "OR": true,
+	"ORDER": true, "OUTER": true, "OVER": true, "RIGHT": true, "SELECT": true, "THEN": true,
+	"TRUE": true, "UNION": true, "USING": true, "WHEN": true, "WHERE": true, "WINDOW": true,
+	"WITH": true,
+}
+
+// Parse parses a single SELECT statement.
+func Parse(rawSQL string) (*SelectStatement, error) {
+	tokens, err := lex(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	stmt, err := p.statement()
+	if err != nil {
+		return nil, err
+	}
+	p.accept(";")
+	if p.peek().typ != tokEOF {
+		return nil, p.unexpected()
+	}
+	return stmt, nil
+}
+
+// TablesList returns the sorted list of tables that the sql statement reads from. Common table
+// expressions defined in the statement are not included.
 func TablesList(rawSQL string) ([]string, error) {
-	duckDB := duck.NewInMemoryDB()
-	rawSQL = strings.Replace(rawSQL, "'", "''", -1)
-	cmd := fmt.Sprintf("SELECT json_serialize_sql('%s')", rawSQL)
-	ret, err := duckDB.RunCommands([]string{cmd})
+	stmt, err := Parse(rawSQL)
 	if err != nil {
-		logger.Error("error serializing sql", "error", err.Error(), "sql", rawSQL, "cmd", cmd)
-		return nil, fmt.Errorf("error serializing sql: %s", err.Error())
+		logger.Error("error parsing sql", "error", err.Error(), "sql", rawSQL)
+		return nil, err
+	}
+	seen := map[string]bool{}
+	tables := []string{}
+	collectTables(stmt, map[string]bool{}, func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			tables = append(tables, name)
+		}
+	})
+	sort.Strings(tables)
+
+	logger.Debug("tables found in sql", "tables", tables)
+
+	return tables, nil
+}
+
+// collectTables calls add for each table referenced by the statement and its subqueries
+// that is not the name of a common table expression in scope.
+func collectTables(stmt *SelectStatement, ctes map[string]bool, add func(string)) {
+	if len(stmt.With) > 0 {
+		scoped := make(map[string]bool, len(ctes)+len(stmt.With))
+		for k := range ctes {
+			scoped[k] = true
+		}
+		for _, cte := range stmt.With {
+			collectTables(cte.Select, scoped, add)
+			scoped[strings.ToLower(cte.Name)] = true
+		}
+		ctes = scoped
+	}
+
+	var fromTables func(t TableExpr)
+	fromTables = func(t TableExpr) {
+		switch t := t.(type) {
+		case *TableName:
+			if !ctes[strings.ToLower(t.Name)] {
+				add(t.Name)
+			}
+		case *SubqueryTable:
+			collectTables(t.Select, ctes, add)
+		case *JoinTable:
+			fromTables(t.Left)
+			fromTables(t.Right)
+			walkExpr(t.On, func(s *SelectStatement) { collectTables(s, ctes, add) })
+		}
+	}
+	if stmt.From != nil {
+		fromTables(stmt.From)
+	}
+
+	subqueries := func(s *SelectStatement) { collectTables(s, ctes, add) }
+	for _, c := range stmt.Columns {
+		walkExpr(c.Expr, subqueries)
+	}
+	walkExpr(stmt.Where, subqueries)
+	walkExpr(stmt.Having, subqueries)
+	for _, e := range stmt.GroupBy {
+		walkExpr(e, subqueries)
+	}
+	for _, o := range stmt.OrderBy {
+		walkExpr(o.Expr, subqueries)
+	}
+}
+
+// walkExpr calls fn for each subquery in the expression.
+func walkExpr(e Expr, fn func(*SelectStatement)) {
+	visitExpr(e, func(e Expr) bool {
+		switch e := e.(type) {
+		case *InExpr:
+			if e.Subquery != nil {
+				fn(e.Subquery)
+			}
+		case *SubqueryExpr:
+			fn(e.Select)
+		}
+		return true
+	})
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekN(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the keyword or operator.
+func (p *parser) accept(val string) bool {
+	if p.peek().is(val) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// acceptSeq consumes the next tokens if they are the keywords.
+func (p *parser) acceptSeq(vals ...string) bool {
+	for i, v := range vals {
+		if !p.peekN(i).is(v) {
+			return false
+		}
+	}
+	p.pos += len(vals)
+	return true
+}
+
+func (p *parser) expect(val string) error {
+	if !p.accept(val) {
+		return p.errorf("expected %s, got %s", val, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &ParseError{Pos: p.peek().pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) unexpected() error {
+	return p.errorf("unexpected %s", p.peek())
+}
+
+// isReserved returns true if the token is a reserved keyword.
+func isReserved(t token) bool {
+	return t.typ == tokIdent && reserved[strings.ToUpper(t.val)]
+}
+
+// identifier consumes a quoted or unquoted identifier that is not a reserved keyword.
+func (p *parser) identifier(context string) (string, error) {
+	t := p.peek()
+	if t.typ == tokQuotedIdent || (t.typ == tokIdent && !isReserved(t)) {
+		p.pos++
+		return t.val, nil
+	}
+	return "", p.errorf("expected %s, got %s", context, t)
+}
+
+func (p *parser) statement() (*SelectStatement, error) {
+	var ctes []CommonTableExpr
+	if p.accept("WITH") {
+		if p.accept("RECURSIVE") {
+			return nil, unsupported("WITH RECURSIVE")
+		}
+		for {
+			name, err := p.identifier("common table expression name")
+			if err != nil {
+				return nil, err
+			}
+			if p.peek().is("(") {
+				return nil, unsupported("column list of common table expression %s", name)
+			}
+			if err := p.expect("AS"); err != nil {
+				return nil, err
+			}
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			sel, err := p.statement()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			ctes = append(ctes, CommonTableExpr{Name: name, Select: sel})
+			if !p.accept(",") {
+				break
+			}
+		}
 	}
 
-	ast := []map[string]any{}
-	err = json.Unmarshal([]byte(ret), &ast)
+	stmt, err := p.selectStatement()
 	if err != nil {
-		logger.Error("error converting json sql to ast", "error", err.Error(), "ret", ret)
-		return nil, fmt.Errorf("error converting json to ast: %s", err.Error())
+		return nil, err
+	}
+	stmt.With = ctes
+	for _, op := range []string{"UNION", "INTERSECT", "EXCEPT"} {
+		if p.peek().is(op) {
+			return nil, unsupported(op)
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) selectStatement() (*SelectStatement, error) {
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &SelectStatement{}
+	if p.accept("DISTINCT") {
+		if p.peek().is("ON") {
+			return nil, unsupported("DISTINCT ON")
+		}
+		stmt.Distinct = true
+	} else {
+		p.accept("ALL")
 	}
 
-	return tablesFromAST(ast)
+	var err error
+	for {
+		item, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Columns = append(stmt.Columns, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if p.accept("FROM") {
+		if stmt.From, err = p.from(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("WHERE") {
+		if stmt.Where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptSeq("GROUP", "BY") {
+		if stmt.GroupBy, err = p.exprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("HAVING") {
+		if stmt.Having, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().is("WINDOW") {
+		return nil, unsupported("WINDOW clause")
+	}
+	if p.acceptSeq("ORDER", "BY") {
+		if stmt.OrderBy, err = p.orderList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("LIMIT") {
+		if stmt.Limit, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("OFFSET") {
+		if stmt.Offset, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
 }
 
-// tablesFromAST returns a list of tables from the ast
-func tablesFromAST(ast []map[string]any) ([]string, error) {
-	flat, err := flatten.Flatten(ast[0], "", flatten.DotStyle)
+func (p *parser) selectItem() (SelectItem, error) {
+	if p.accept("*") {
+		return SelectItem{Star: true}, nil
+	}
+	if t := p.peek(); (t.typ == tokIdent || t.typ == tokQuotedIdent) && p.peekN(1).is(".") && p.peekN(2).is("*") {
+		p.pos += 3
+		return SelectItem{Star: true, Table: t.val}, nil
+	}
+	e, err := p.expr()
 	if err != nil {
-		logger.Error("error flattening ast", "error", err.Error(), "ast", ast)
-		return nil, fmt.Errorf("error flattening ast: %s", err.Error())
+		return SelectItem{}, err
+	}
+	item := SelectItem{Expr: e}
+	if p.accept("AS") {
+		if p.peek().typ == tokString {
+			item.Alias = p.next().val
+			return item, nil
+		}
+		item.Alias, err = p.identifier("alias")
+		return item, err
+	}
+	if t := p.peek(); t.typ == tokQuotedIdent || (t.typ == tokIdent && !isReserved(t)) {
+		item.Alias = p.next().val
 	}
+	return item, nil
+}
 
-	tables := []string{}
-	for k, v := range flat {
-		if strings.HasSuffix(k, ERROR) {
-			v, ok := v.(bool)
-			if ok && v {
-				logger.Error("error in sql", "error", k)
-				return nil, astError(k, flat)
+func (p *parser) from() (TableExpr, error) {
+	left, err := p.tablePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.accept(",") {
+			right, err := p.tablePrimary()
+			if err != nil {
+				return nil, err
 			}
+			left = &JoinTable{Kind: JoinCross, Left: left, Right: right}
+			continue
 		}
-		if strings.Contains(k, TABLE_NAME) {
-			table, ok := v.(string)
-			if ok && !existsInList(table, tables) {
-				tables = append(tables, v.(string))
+		kind, ok := p.joinKind()
+		if !ok {
+			return left, nil
+		}
+		right, err := p.tablePrimary()
+		if err != nil {
+			return nil, err
+		}
+		join := &JoinTable{Kind: kind, Left: left, Right: right}
+		switch {
+		case kind == JoinCross:
+		case p.accept("ON"):
+			if join.On, err = p.expr(); err != nil {
+				return nil, err
+			}
+		case p.accept("USING"):
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			for {
+				col, err := p.identifier("column name")
+				if err != nil {
+					return nil, err
+				}
+				join.Using = append(join.Using, col)
+				if !p.accept(",") {
+					break
+				}
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
 			}
+		default:
+			return nil, p.errorf("expected ON or USING after join, got %s", p.peek())
 		}
+		left = join
 	}
-	sort.Strings(tables)
+}
 
-	logger.Debug("tables found in sql", "tables", tables)
+func (p *parser) joinKind() (JoinKind, bool) {
+	switch {
+	case p.accept("JOIN"), p.acceptSeq("INNER", "JOIN"):
+		return JoinInner, true
+	case p.acceptSeq("LEFT", "JOIN"), p.acceptSeq("LEFT", "OUTER", "JOIN"):
+		return JoinLeft, true
+	case p.acceptSeq("RIGHT", "JOIN"), p.acceptSeq("RIGHT", "OUTER", "JOIN"):
+		return JoinRight, true
+	case p.acceptSeq("FULL", "JOIN"), p.acceptSeq("FULL", "OUTER", "JOIN"):
+		return JoinFull, true
+	case p.acceptSeq("CROSS", "JOIN"):
+		return JoinCross, true
+	}
+	return "", false
+}
 
-	return tables, nil
+func (p *parser) tablePrimary() (TableExpr, error) {
+	if p.peek().is("(") {
+		if p.peekN(1).is("SELECT") || p.peekN(1).is("WITH") {
+			p.next()
+			sel, err := p.statement()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			alias, err := p.tableAlias()
+			if err != nil {
+				return nil, err
+			}
+			return &SubqueryTable{Select: sel, Alias: alias}, nil
+		}
+		p.next()
+		t, err := p.from()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	name, err := p.identifier("table name")
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().is(".") {
+		return nil, unsupported("qualified table name %s.%s", name, p.peekN(1).val)
+	}
+	if p.peek().is("(") {
+		return nil, unsupported("table function %s", name)
+	}
+	alias, err := p.tableAlias()
+	if err != nil {
+		return nil, err
+	}
+	return &TableName{Name: name, Alias: alias}, nil
 }
 
-func astError(k string, flat map[string]any) error {
-	key := strings.Replace(k, ERROR, "", 1)
-	message, ok := flat[key+ERROR_MESSAGE]
-	if !ok {
-		message = "unknown error in sql"
+func (p *parser) tableAlias() (string, error) {
+	if p.accept("AS") {
+		return p.identifier("alias")
+	}
+	if t := p.peek(); t.typ == tokQuotedIdent || (t.typ == tokIdent && !isReserved(t)) {
+		p.pos++
+		return t.val, nil
 	}
-	return fmt.Errorf("error in sql: %s", message)
+	return "", nil
 }
 
-func existsInList(table string, list []string) bool {
-	for _, t := range list {
-		if t == table {
-			return true
+func (p *parser) exprList() ([]Expr, error) {
+	var exprs []Expr
+	for {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+		if !p.accept(",") {
+			return exprs, nil
 		}
 	}
-	return false
+}
+
+func (p *parser) orderList() ([]OrderItem, error) {
+	var items []OrderItem
+	for {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		item := OrderItem{Expr: e}
+		if p.accept("DESC") {
+			item.Desc = true
+		} else {
+			p.accept("ASC")
+		}
+		if p.acceptSeq("NULLS", "FIRST") {
+			item.NullsFirst = &[]bool{true}[0]
+		} else if p.acceptSeq("NULLS", "LAST") {
+			item.NullsFirst = &[]bool{false}[0]
+		}
+		items = append(items, item)
+		if !p.accept(",") {
+			return items, nil
+		}
+	}
+}
+
+// expr parses an expression. From lowest to highest, the precedence of the operators is:
+// OR, AND, NOT, comparisons (including IS, IN, BETWEEN and LIKE), ||, + and -, *, / and %,
+// unary minus and ::.
+func (p *parser) expr() (Expr, error) {
+	return p.or()
+}
+
+func (p *parser) or() (Expr, error) {
+	l, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l = &BinaryExpr{Op: "OR", L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	l, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		r, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		l = &BinaryExpr{Op: "AND", L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) not() (Expr, error) {
+	if p.accept("NOT") {
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: "NOT", X: x}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	l, err := p.concat()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.is("=") || t.is("<>") || t.is("!=") || t.is("<") || t.is("<=") || t.is(">") || t.is(">="):
+			p.next()
+			op := t.val
+			if op == "!=" {
+				op = "<>"
+			}
+			r, err := p.concat()
+			if err != nil {
+				return nil, err
+			}
+			l = &BinaryExpr{Op: op, L: l, R: r}
+		case t.is("IS"):
+			p.next()
+			not := p.accept("NOT")
+			if p.accept("NULL") {
+				l = &IsNullExpr{X: l, Not: not}
+				continue
+			}
+			if p.acceptSeq("DISTINCT", "FROM") {
+				return nil, unsupported("IS DISTINCT FROM")
+			}
+			return nil, p.errorf("expected NULL after IS, got %s", p.peek())
+		default:
+			not := false
+			if t.is("NOT") && (p.peekN(1).is("IN") || p.peekN(1).is("BETWEEN") || p.peekN(1).is("LIKE") || p.peekN(1).is("ILIKE")) {
+				p.next()
+				not = true
+			}
+			switch {
+			case p.accept("IN"):
+				l, err = p.in(l, not)
+			case p.accept("BETWEEN"):
+				l, err = p.between(l, not)
+			case p.peek().is("LIKE") || p.peek().is("ILIKE"):
+				ci := p.next().is("ILIKE")
+				var pattern Expr
+				pattern, err = p.concat()
+				l = &LikeExpr{X: l, Pattern: pattern, Not: not, CaseInsensitive: ci}
+			default:
+				return l, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (p *parser) in(x Expr, not bool) (Expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	in := &InExpr{X: x, Not: not}
+	if p.peek().is("SELECT") || p.peek().is("WITH") {
+		sel, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		in.Subquery = sel
+	} else {
+		list, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		in.List = list
+	}
+	return in, p.expect(")")
+}
+
+func (p *parser) between(x Expr, not bool) (Expr, error) {
+	lo, err := p.concat()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("AND"); err != nil {
+		return nil, err
+	}
+	hi, err := p.concat()
+	if err != nil {
+		return nil, err
+	}
+	return &BetweenExpr{X: x, Lo: lo, Hi: hi, Not: not}, nil
+}
+
+func (p *parser) concat() (Expr, error) {
+	l, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		r, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		l = &BinaryExpr{Op: "||", L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) additive() (Expr, error) {
+	l, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("+") || p.peek().is("-") {
+		op := p.next().val
+		r, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		l = &BinaryExpr{Op: op, L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) multiplicative() (Expr, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("*") || p.peek().is("/") || p.peek().is("%") {
+		op := p.next().val
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = &BinaryExpr{Op: op, L: l, R: r}
+	}
+	return l, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.peek().is("-") || p.peek().is("+") {
+		op := p.next().val
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			return x, nil
+		}
+		// Fold negative number literals so that they can be used as frame offsets and in names.
+		if lit, ok := x.(*Literal); ok {
+			switch v := lit.Value.(type) {
+			case int64:
+				return &Literal{Value: -v}, nil
+			case float64:
+				return &Literal{Value: -v}, nil
+			}
+		}
+		return &UnaryExpr{Op: "-", X: x}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (Expr, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("::"):
+			typ, err := p.typeName()
+			if err != nil {
+				return nil, err
+			}
+			x = &CastExpr{X: x, Type: typ}
+		case p.peek().is("["):
+			return nil, unsupported("array subscripts")
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) typeName() (string, error) {
+	t := p.peek()
+	if t.typ != tokIdent {
+		return "", p.errorf("expected type name, got %s", t)
+	}
+	p.next()
+	typ := strings.ToUpper(t.val)
+	if typ == "DOUBLE" {
+		p.accept("PRECISION")
+	}
+	// Precision and length, such as DECIMAL(10, 2) or VARCHAR(255), do not change the result.
+	if p.accept("(") {
+		for !p.accept(")") {
+			if p.peek().typ == tokEOF {
+				return "", p.unexpected()
+			}
+			p.next()
+		}
+	}
+	if p.peek().is("[") {
+		return "", unsupported("array type %s[]", typ)
+	}
+	return typ, nil
+}
+
+func (p *parser) primary() (Expr, error) {
+	t := p.peek()
+	switch t.typ {
+	case tokNumber:
+		p.next()
+		if i, err := strconv.ParseInt(t.val, 10, 64); err == nil {
+			return &Literal{Value: i}, nil
+		}
+		f, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("invalid number %s", t.val)}
+		}
+		return &Literal{Value: f}, nil
+	case tokString:
+		p.next()
+		return &Literal{Value: t.val}, nil
+	case tokQuotedIdent:
+		p.next()
+		return p.columnRef(t.val)
+	case tokEOF:
+		return nil, p.errorf("unexpected end of statement")
+	case tokOp:
+		switch {
+		case t.is("("):
+			p.next()
+			if p.peek().is("SELECT") || p.peek().is("WITH") {
+				sel, err := p.statement()
+				if err != nil {
+					return nil, err
+				}
+				return &SubqueryExpr{Select: sel}, p.expect(")")
+			}
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if p.peek().is(",") {
+				return nil, unsupported("row values")
+			}
+			return e, p.expect(")")
+		case t.is("["):
+			return nil, unsupported("array literals")
+		}
+		return nil, p.unexpected()
+	}
+
+	switch strings.ToUpper(t.val) {
+	case "NULL":
+		p.next()
+		return &Literal{Value: nil}, nil
+	case "TRUE":
+		p.next()
+		return &Literal{Value: true}, nil
+	case "FALSE":
+		p.next()
+		return &Literal{Value: false}, nil
+	case "CASE":
+		p.next()
+		return p.caseExpr()
+	case "CAST":
+		if p.peekN(1).is("(") {
+			p.pos += 2
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("AS"); err != nil {
+				return nil, err
+			}
+			typ, err := p.typeName()
+			if err != nil {
+				return nil, err
+			}
+			return &CastExpr{X: x, Type: typ}, p.expect(")")
+		}
+	case "EXISTS":
+		if p.peekN(1).is("(") {
+			return nil, unsupported("EXISTS")
+		}
+	case "INTERVAL":
+		if p.peekN(1).typ == tokString {
+			return nil, unsupported("INTERVAL literals")
+		}
+	case "TIMESTAMP", "DATE":
+		// Typed literals such as TIMESTAMP '2024-01-01 00:00:00'
+		if p.peekN(1).typ == tokString {
+			p.next()
+			return &CastExpr{X: &Literal{Value: p.next().val}, Type: "TIMESTAMP"}, nil
+		}
+	}
+
+	if isReserved(t) {
+		return nil, p.unexpected()
+	}
+	p.next()
+	if p.peek().is("(") {
+		return p.funcCall(strings.ToLower(t.val))
+	}
+	return p.columnRef(t.val)
+}
+
+func (p *parser) columnRef(name string) (Expr, error) {
+	if !p.peek().is(".") {
+		return &ColumnRef{Name: name}, nil
+	}
+	p.next()
+	col, err := p.identifier("column name")
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().is(".") {
+		return nil, unsupported("qualified column name %s.%s.%s", name, col, p.peekN(1).val)
+	}
+	return &ColumnRef{Table: name, Name: col}, nil
+}
+
+func (p *parser) caseExpr() (Expr, error) {
+	c := &CaseExpr{}
+	var err error
+	if !p.peek().is("WHEN") {
+		if c.Operand, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.accept("WHEN") {
+		var w WhenClause
+		if w.Cond, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("THEN"); err != nil {
+			return nil, err
+		}
+		if w.Result, err = p.expr(); err != nil {
+			return nil, err
+		}
+		c.Whens = append(c.Whens, w)
+	}
+	if len(c.Whens) == 0 {
+		return nil, p.errorf("expected WHEN, got %s", p.peek())
+	}
+	if p.accept("ELSE") {
+		if c.Else, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	return c, p.expect("END")
+}
+
+func (p *parser) funcCall(name string) (Expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	f := &FuncCall{Name: name}
+	switch {
+	case p.accept("*"):
+		f.Star = true
+	case p.peek().is(")"):
+	default:
+		if p.accept("DISTINCT") {
+			f.Distinct = true
+		} else {
+			p.accept("ALL")
+		}
+		args, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		f.Args = args
+	}
+	if p.peek().is("ORDER") {
+		return nil, unsupported("ORDER BY in function arguments")
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if p.peek().is("FILTER") && p.peekN(1).is("(") {
+		return nil, unsupported("FILTER clause")
+	}
+	if p.accept("OVER") {
+		spec, err := p.windowSpec()
+		if err != nil {
+			return nil, err
+		}
+		f.Over = spec
+	}
+	return f, nil
+}
+
+func (p *parser) windowSpec() (*WindowSpec, error) {
+	if !p.peek().is("(") {
+		return nil, unsupported("named windows")
+	}
+	p.next()
+	spec := &WindowSpec{}
+	var err error
+	if p.acceptSeq("PARTITION", "BY") {
+		if spec.PartitionBy, err = p.exprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptSeq("ORDER", "BY") {
+		if spec.OrderBy, err = p.orderList(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case p.accept("ROWS"):
+		frame := &WindowFrame{End: FrameBound{}}
+		if p.accept("BETWEEN") {
+			if frame.Start, err = p.frameBound(); err != nil {
+				return nil, err
+			}
+			if err := p.expect("AND"); err != nil {
+				return nil, err
+			}
+			if frame.End, err = p.frameBound(); err != nil {
+				return nil, err
+			}
+		} else if frame.Start, err = p.frameBound(); err != nil {
+			return nil, err
+		}
+		spec.Frame = frame
+	case p.peek().is("RANGE"), p.peek().is("GROUPS"):
+		return nil, unsupported("%s window frames", strings.ToUpper(p.peek().val))
+	}
+	return spec, p.expect(")")
+}
+
+func (p *parser) frameBound() (FrameBound, error) {
+	switch {
+	case p.acceptSeq("UNBOUNDED", "PRECEDING"):
+		return FrameBound{Unbounded: true, Offset: -1}, nil
+	case p.acceptSeq("UNBOUNDED", "FOLLOWING"):
+		return FrameBound{Unbounded: true, Offset: 1}, nil
+	case p.acceptSeq("CURRENT", "ROW"):
+		return FrameBound{}, nil
+	}
+	t := p.peek()
+	if t.typ != tokNumber {
+		return FrameBound{}, p.errorf("expected window frame bound, got %s", t)
+	}
+	p.next()
+	n, err := strconv.Atoi(t.val)
+	if err != nil || n < 0 {
+		return FrameBound{}, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("invalid window frame offset %s", t.val)}
+	}
+	switch {
+	case p.accept("PRECEDING"):
+		return FrameBound{Offset: -n}, nil
+	case p.accept("FOLLOWING"):
+		return FrameBound{Offset: n}, nil
+	}
+	return FrameBound{}, p.errorf("expected PRECEDING or FOLLOWING, got %s", p.peek())
 }
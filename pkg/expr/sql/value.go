@@ -0,0 +1,310 @@
+package sql
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values are nil for NULL, or one of int64, float64, string, bool and time.Time.
+
+// timeLayouts are the layouts used to parse strings that are compared with or cast to timestamps.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func isNumber(v any) bool {
+	_, ok := toFloat(v)
+	return ok
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return "INTEGER"
+	case float64:
+		return "DOUBLE"
+	case string:
+		return "VARCHAR"
+	case bool:
+		return "BOOLEAN"
+	case time.Time:
+		return "TIMESTAMP"
+	}
+	return "UNKNOWN"
+}
+
+// compareValues compares two non-null values, returning -1, 0 or 1. Strings are converted to
+// timestamps when compared with timestamps.
+func compareValues(a, b any) (int, error) {
+	if ai, ok := a.(int64); ok {
+		if bi, ok := b.(int64); ok {
+			return compareOrdered(ai, bi), nil
+		}
+	}
+	if af, ok := toFloat(a); ok {
+		if bf, ok := toFloat(b); ok {
+			return compareOrdered(af, bf), nil
+		}
+	}
+	switch av := a.(type) {
+	case string:
+		switch bv := b.(type) {
+		case string:
+			return strings.Compare(av, bv), nil
+		case time.Time:
+			if at, ok := parseTime(av); ok {
+				return at.Compare(bv), nil
+			}
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return compareOrdered(boolToInt(av), boolToInt(bv)), nil
+		}
+	case time.Time:
+		switch bv := b.(type) {
+		case time.Time:
+			return av.Compare(bv), nil
+		case string:
+			if bt, ok := parseTime(bv); ok {
+				return av.Compare(bt), nil
+			}
+		}
+	}
+	return 0, execError("cannot compare %s with %s", typeName(a), typeName(b))
+}
+
+func compareOrdered[T int64 | float64 | int](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// valueKey returns a string that is equal for equal values, used to group and deduplicate rows.
+func valueKey(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case int64:
+		return "n" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case float64:
+		return "n" + strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return "s" + v
+	case bool:
+		return "b" + strconv.FormatBool(v)
+	case time.Time:
+		return "t" + strconv.FormatInt(v.UnixNano(), 10)
+	}
+	return "?"
+}
+
+func rowKey(vals []any) string {
+	var sb strings.Builder
+	for _, v := range vals {
+		k := valueKey(v)
+		sb.WriteString(strconv.Itoa(len(k)))
+		sb.WriteByte(':')
+		sb.WriteString(k)
+	}
+	return sb.String()
+}
+
+// arithmetic applies an arithmetic operator. Integer operations return integers, except for
+// division that always returns a double. Division by zero returns NULL.
+func arithmetic(op string, a, b any) (any, error) {
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	if ai, ok := a.(int64); ok {
+		if bi, ok := b.(int64); ok {
+			switch op {
+			case "+":
+				return ai + bi, nil
+			case "-":
+				return ai - bi, nil
+			case "*":
+				return ai * bi, nil
+			case "%":
+				if bi == 0 {
+					return nil, nil
+				}
+				return ai % bi, nil
+			}
+		}
+	}
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if !aok || !bok {
+		if at, ok := a.(time.Time); ok && op == "-" {
+			if bt, ok := b.(time.Time); ok {
+				return at.Sub(bt).Seconds(), nil
+			}
+		}
+		return nil, execError("cannot apply operator %s to %s and %s", op, typeName(a), typeName(b))
+	}
+	switch op {
+	case "+":
+		return af + bf, nil
+	case "-":
+		return af - bf, nil
+	case "*":
+		return af * bf, nil
+	case "/":
+		if bf == 0 {
+			return nil, nil
+		}
+		return af / bf, nil
+	case "%":
+		if bf == 0 {
+			return nil, nil
+		}
+		return math.Mod(af, bf), nil
+	}
+	return nil, execError("unknown operator %s", op)
+}
+
+// toBool converts the result of a condition. NULL is returned as false, and numbers are true when not zero.
+func toBool(v any) (bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	case float64:
+		return v != 0, nil
+	}
+	return false, execError("expected a boolean condition, got %s", typeName(v))
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format("2006-01-02 15:04:05.999999999")
+	}
+	return ""
+}
+
+// castValue converts a value to the type, that is one of the type names accepted by CAST.
+func castValue(v any, typ string) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch typ {
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "HUGEINT", "INT2", "INT4", "INT8", "LONG":
+		switch v := v.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			return int64(math.Round(v)), nil
+		case bool:
+			return int64(boolToInt(v)), nil
+		case string:
+			s := strings.TrimSpace(v)
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return int64(math.Round(f)), nil
+			}
+		case time.Time:
+			return v.Unix(), nil
+		}
+	case "DOUBLE", "FLOAT", "REAL", "DECIMAL", "NUMERIC", "FLOAT4", "FLOAT8":
+		switch v := v.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case bool:
+			return float64(boolToInt(v)), nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, nil
+			}
+		case time.Time:
+			return float64(v.UnixNano()) / float64(time.Second), nil
+		}
+	case "VARCHAR", "TEXT", "STRING", "CHAR":
+		return toString(v), nil
+	case "BOOLEAN", "BOOL":
+		switch v := v.(type) {
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		default:
+			if b, err := toBool(v); err == nil {
+				return b, nil
+			}
+		}
+	case "TIMESTAMP", "DATETIME", "TIMESTAMPTZ", "DATE":
+		switch v := v.(type) {
+		case time.Time:
+			if typ == "DATE" {
+				return v.UTC().Truncate(24 * time.Hour), nil
+			}
+			return v, nil
+		case string:
+			if t, ok := parseTime(v); ok {
+				if typ == "DATE" {
+					return t.UTC().Truncate(24 * time.Hour), nil
+				}
+				return t, nil
+			}
+		}
+	default:
+		return nil, unsupported("type %s", typ)
+	}
+	return nil, execError("cannot cast %s %q to %s", typeName(v), toString(v), typ)
+}
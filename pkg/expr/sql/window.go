@@ -0,0 +1,265 @@
+package sql
+
+import (
+	"sort"
+)
+
+// windowCalls returns the window function calls of the select list and the ORDER BY clause.
+func windowCalls(stmt *SelectStatement) []*FuncCall {
+	var calls []*FuncCall
+	collect := func(e Expr) {
+		visitExpr(e, func(e Expr) bool {
+			if f, ok := e.(*FuncCall); ok && f.Over != nil {
+				calls = append(calls, f)
+				return false
+			}
+			return true
+		})
+	}
+	for _, item := range stmt.Columns {
+		if !item.Star {
+			collect(item.Expr)
+		}
+	}
+	for _, o := range stmt.OrderBy {
+		collect(o.Expr)
+	}
+	return calls
+}
+
+// windows computes the window functions of the statement and stores their results in the contexts.
+func (ex *executor) windows(stmt *SelectStatement, contexts []*rowContext) error {
+	for _, f := range windowCalls(stmt) {
+		if err := ex.window(f, contexts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ex *executor) window(f *FuncCall, contexts []*rowContext) error {
+	_, isAggregate := aggregates[f.Name]
+	_, isWindow := windowOnly[f.Name]
+	if !isAggregate && !isWindow {
+		return execError("%s is not a window function", f.Name)
+	}
+
+	// Partition the rows, keeping the partitions in order of their first row.
+	var partitions [][]int
+	index := map[string]int{}
+	for i, ctx := range contexts {
+		vals := make([]any, len(f.Over.PartitionBy))
+		for j, e := range f.Over.PartitionBy {
+			v, err := ex.eval(e, ctx)
+			if err != nil {
+				return err
+			}
+			vals[j] = v
+		}
+		k := rowKey(vals)
+		p, ok := index[k]
+		if !ok {
+			p = len(partitions)
+			index[k] = p
+			partitions = append(partitions, nil)
+		}
+		partitions[p] = append(partitions[p], i)
+	}
+
+	keys := make([][]any, len(contexts))
+	for i, ctx := range contexts {
+		keys[i] = make([]any, len(f.Over.OrderBy))
+		for j, o := range f.Over.OrderBy {
+			v, err := ex.eval(o.Expr, ctx)
+			if err != nil {
+				return err
+			}
+			keys[i][j] = v
+		}
+	}
+
+	for _, rows := range partitions {
+		if err := ex.ctx.Err(); err != nil {
+			return err
+		}
+		var sortErr error
+		sort.SliceStable(rows, func(a, b int) bool {
+			c, err := compareKeys(keys[rows[a]], keys[rows[b]], f.Over.OrderBy)
+			if err != nil && sortErr == nil {
+				sortErr = err
+			}
+			return c < 0
+		})
+		if sortErr != nil {
+			return sortErr
+		}
+		results, err := ex.windowPartition(f, contexts, rows, keys)
+		if err != nil {
+			return err
+		}
+		for pos, i := range rows {
+			if contexts[i].windows == nil {
+				contexts[i].windows = map[*FuncCall]any{}
+			}
+			contexts[i].windows[f] = results[pos]
+		}
+	}
+	return nil
+}
+
+// windowPartition computes the window function for the sorted rows of a partition.
+func (ex *executor) windowPartition(f *FuncCall, contexts []*rowContext, rows []int, keys [][]any) ([]any, error) {
+	results := make([]any, len(rows))
+	peer := func(a, b int) bool {
+		c, _ := compareKeys(keys[rows[a]], keys[rows[b]], f.Over.OrderBy)
+		return c == 0
+	}
+
+	switch f.Name {
+	case "row_number":
+		for pos := range rows {
+			results[pos] = int64(pos + 1)
+		}
+		return results, nil
+	case "rank", "dense_rank":
+		rank, dense := int64(0), int64(0)
+		for pos := range rows {
+			if pos == 0 || !peer(pos-1, pos) {
+				rank = int64(pos + 1)
+				dense++
+			}
+			if f.Name == "rank" {
+				results[pos] = rank
+			} else {
+				results[pos] = dense
+			}
+		}
+		return results, nil
+	}
+
+	// The other functions use the values of their first argument.
+	var args []any
+	if !f.Star {
+		if len(f.Args) == 0 {
+			return nil, execError("window function %s requires an argument", f.Name)
+		}
+		args = make([]any, len(rows))
+		for pos, i := range rows {
+			v, err := ex.eval(f.Args[0], contexts[i])
+			if err != nil {
+				return nil, err
+			}
+			args[pos] = v
+		}
+	} else if f.Name != "count" {
+		return nil, execError("%s(*) is not supported", f.Name)
+	}
+
+	switch f.Name {
+	case "lag", "lead":
+		if len(f.Args) > 3 {
+			return nil, execError("wrong number of arguments to function %s", f.Name)
+		}
+		for pos, i := range rows {
+			offset := int64(1)
+			if len(f.Args) > 1 {
+				v, err := ex.eval(f.Args[1], contexts[i])
+				if err != nil {
+					return nil, err
+				}
+				n, ok := v.(int64)
+				if !ok {
+					return nil, execError("the offset of %s must be an integer", f.Name)
+				}
+				offset = n
+			}
+			if f.Name == "lag" {
+				offset = -offset
+			}
+			if target := int64(pos) + offset; target >= 0 && target < int64(len(rows)) {
+				results[pos] = args[target]
+			} else if len(f.Args) > 2 {
+				v, err := ex.eval(f.Args[2], contexts[i])
+				if err != nil {
+					return nil, err
+				}
+				results[pos] = v
+			}
+		}
+		return results, nil
+	}
+
+	for pos := range rows {
+		start, end := windowFrame(f.Over, pos, len(rows), peer)
+		var frame []any
+		if start <= end {
+			if f.Star {
+				frame = make([]any, end-start+1)
+				for i := range frame {
+					frame[i] = true
+				}
+			} else {
+				frame = args[start : end+1]
+			}
+		}
+		switch f.Name {
+		case "first_value":
+			if len(frame) > 0 {
+				results[pos] = frame[0]
+			}
+		case "last_value":
+			if len(frame) > 0 {
+				results[pos] = frame[len(frame)-1]
+			}
+		default:
+			if f.Distinct {
+				frame = distinctValues(frame)
+			}
+			v, err := aggregateValues(f, frame)
+			if err != nil {
+				return nil, err
+			}
+			results[pos] = v
+		}
+	}
+	return results, nil
+}
+
+// windowFrame returns the first and last positions of the frame of the row at pos. Without a frame
+// clause, the frame is the whole partition, or when the window is ordered, the rows up to the last
+// peer of the current row.
+func windowFrame(w *WindowSpec, pos, n int, peer func(a, b int) bool) (int, int) {
+	if w.Frame != nil {
+		bound := func(b FrameBound) int {
+			if b.Unbounded {
+				if b.Offset < 0 {
+					return 0
+				}
+				return n - 1
+			}
+			return pos + b.Offset
+		}
+		return max(bound(w.Frame.Start), 0), min(bound(w.Frame.End), n-1)
+	}
+	if len(w.OrderBy) == 0 {
+		return 0, n - 1
+	}
+	end := pos
+	for end+1 < n && peer(end+1, pos) {
+		end++
+	}
+	return 0, end
+}
+
+func distinctValues(vals []any) []any {
+	seen := map[string]bool{}
+	var out []any
+	for _, v := range vals {
+		k := valueKey(v)
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -1,12 +1,20 @@
 package expr
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
 func TestNewCommand(t *testing.T) {
-	t.Skip()
 	cmd, err := NewSQLCommand("a", "select a from foo, bar")
 	if err != nil && strings.Contains(err.Error(), "feature is not enabled") {
 		return
@@ -25,3 +33,58 @@ func TestNewCommand(t *testing.T) {
 		return
 	}
 }
+
+func TestSQLCommandExecute(t *testing.T) {
+	now := time.Now()
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			mathexp.TableData{Frame: data.NewFrame("",
+				data.NewField("host", nil, []string{"a", "b", "a"}),
+				data.NewField("value", nil, []float64{1, 2, 3}),
+			)},
+		}},
+	}
+	tracer := tracing.InitializeTracerForTest()
+
+	t.Run("returns a table", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT host, sum(value) AS total FROM A GROUP BY host ORDER BY host")
+		require.NoError(t, err)
+		require.Equal(t, []string{"A"}, cmd.NeedsVars())
+
+		res, err := cmd.Execute(context.Background(), now, vars, tracer)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Values, 1)
+		table, ok := res.Values[0].(mathexp.TableData)
+		require.True(t, ok)
+		require.Equal(t, "B", table.Frame.RefID)
+		require.Equal(t, 2, table.Frame.Rows())
+		require.Equal(t, 4.0, table.Frame.Fields[1].At(0))
+	})
+
+	t.Run("returns no data when there are no rows", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT * FROM A WHERE value > 10")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), now, vars, tracer)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.NoData{}, res.Values[0])
+	})
+
+	t.Run("returns the error of the query", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT missing FROM A")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), now, vars, tracer)
+		require.NoError(t, err)
+		var notFound *sql.ColumnNotFoundError
+		require.ErrorAs(t, res.Error, &notFound)
+	})
+
+	t.Run("rejects unsupported syntax", func(t *testing.T) {
+		_, err := NewSQLCommand("B", "SELECT * FROM A UNION SELECT * FROM A")
+		var unsupported *sql.UnsupportedError
+		require.ErrorAs(t, err, &unsupported)
+	})
+}
@@ -98,6 +98,13 @@ func UnmarshalThresholdCommand(rn *rawNode, features featuremgmt.FeatureToggles)
 	}
 	referenceVar := cmdConfig.Expression
 
+	if len(cmdConfig.Levels) > 0 {
+		if len(cmdConfig.Conditions) > 0 {
+			return nil, fmt.Errorf("threshold expression requires either conditions or levels, not both")
+		}
+		return NewThresholdLevelsCommand(rn.RefID, referenceVar, cmdConfig.Levels)
+	}
+
 	// we only support one condition for now, we might want to turn this in to "OR" expressions later
 	if len(cmdConfig.Conditions) != 1 {
 		return nil, fmt.Errorf("threshold expression requires exactly one condition")
@@ -147,23 +154,27 @@ func (tc *ThresholdCommand) Execute(_ context.Context, _ time.Time, vars mathexp
 		return util.Pointer(float64(0))
 	}
 
-	refVarResult := vars[tc.ReferenceVar]
+	return applyThreshold(tc.RefID, vars[tc.ReferenceVar], eval)
+}
+
+// applyThreshold maps each value of the results with eval.
+func applyThreshold(refID string, refVarResult mathexp.Results, eval func(*float64) *float64) (mathexp.Results, error) {
 	newRes := mathexp.Results{Values: make(mathexp.Values, 0, len(refVarResult.Values))}
 	for _, val := range refVarResult.Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			s := mathexp.NewSeries(tc.RefID, v.GetLabels(), v.Len())
+			s := mathexp.NewSeries(refID, v.GetLabels(), v.Len())
 			for i := 0; i < v.Len(); i++ {
 				t, value := v.GetPoint(i)
 				s.SetPoint(i, t, eval(value))
 			}
 			newRes.Values = append(newRes.Values, s)
 		case mathexp.Number:
-			copyV := mathexp.NewNumber(tc.RefID, v.GetLabels())
+			copyV := mathexp.NewNumber(refID, v.GetLabels())
 			copyV.SetValue(eval(v.GetFloat64Value()))
 			newRes.Values = append(newRes.Values, copyV)
 		case mathexp.Scalar:
-			copyV := mathexp.NewScalar(tc.RefID, eval(v.GetFloat64Value()))
+			copyV := mathexp.NewScalar(refID, eval(v.GetFloat64Value()))
 			newRes.Values = append(newRes.Values, copyV)
 		case mathexp.NoData:
 			newRes.Values = append(newRes.Values, mathexp.NewNoData())
@@ -192,6 +203,7 @@ func IsSupportedThresholdFunc(name string) bool {
 type ThresholdCommandConfig struct {
 	Expression string                   `json:"expression"`
 	Conditions []ThresholdConditionJSON `json:"conditions"`
+	Levels     []ThresholdLevelJSON     `json:"levels,omitempty"`
 }
 
 type ThresholdConditionJSON struct {
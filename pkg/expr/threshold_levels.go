@@ -0,0 +1,129 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ThresholdLevelsCommand is a special case of ThresholdCommand that evaluates an ordered list of severity levels,
+// for example "warning" and "critical", instead of a single condition.
+// The result of the execution of the command is, for each metric, the position (starting at 1) of the last level
+// in the list whose threshold is crossed, or 0 if no threshold is crossed.
+type ThresholdLevelsCommand struct {
+	RefID        string
+	ReferenceVar string
+	Levels       []ThresholdLevel
+}
+
+// ThresholdLevel is a severity level of a ThresholdLevelsCommand.
+type ThresholdLevel struct {
+	Severity  string
+	Threshold ThresholdCommand
+}
+
+// ThresholdLevelJSON is a severity level of a threshold expression.
+type ThresholdLevelJSON struct {
+	// The name of the severity, for example "warning" or "critical"
+	Severity string `json:"severity" jsonschema:"minLength=1"`
+
+	// The condition that the value must meet to have this severity
+	Evaluator ConditionEvalJSON `json:"evaluator"`
+}
+
+func NewThresholdLevelsCommand(refID, referenceVar string, levels []ThresholdLevelJSON) (*ThresholdLevelsCommand, error) {
+	if len(levels) == 0 {
+		return nil, errors.New("threshold levels require at least one level")
+	}
+	seen := make(map[string]struct{}, len(levels))
+	cmd := &ThresholdLevelsCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Levels:       make([]ThresholdLevel, 0, len(levels)),
+	}
+	for i, level := range levels {
+		if level.Severity == "" {
+			return nil, fmt.Errorf("threshold level %d has no severity", i+1)
+		}
+		if _, ok := seen[level.Severity]; ok {
+			return nil, fmt.Errorf("duplicate threshold level severity '%s'", level.Severity)
+		}
+		seen[level.Severity] = struct{}{}
+		threshold, err := NewThresholdCommand(refID, referenceVar, level.Evaluator.Type, level.Evaluator.Params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition of threshold level '%s': %w", level.Severity, err)
+		}
+		cmd.Levels = append(cmd.Levels, ThresholdLevel{Severity: level.Severity, Threshold: *threshold})
+	}
+	return cmd, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tl *ThresholdLevelsCommand) NeedsVars() []string {
+	return []string{tl.ReferenceVar}
+}
+
+func (tl *ThresholdLevelsCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars, _ tracing.Tracer) (mathexp.Results, error) {
+	eval := func(maybeValue *float64) *float64 {
+		if maybeValue == nil {
+			return nil
+		}
+		severity := 0
+		for i, level := range tl.Levels {
+			if level.Threshold.predicate.Eval(*maybeValue) {
+				severity = i + 1
+			}
+		}
+		return util.Pointer(float64(severity))
+	}
+	return applyThreshold(tl.RefID, vars[tl.ReferenceVar], eval)
+}
+
+func (tl *ThresholdLevelsCommand) Type() string {
+	return "threshold_levels"
+}
+
+// GetThresholdSeverities returns the names of the severity levels, in order, if the raw model describes a threshold
+// command with severity levels:
+// - field 'type' has value "threshold",
+// - field 'levels' is a non-empty array of objects with a 'severity' field.
+// It returns nil if the model describes any other command.
+func GetThresholdSeverities(query map[string]any) []string {
+	t, err := GetExpressionCommandType(query)
+	if err != nil || t != TypeThreshold {
+		return nil
+	}
+	arr, ok := query["levels"].([]any)
+	if !ok || len(arr) == 0 {
+		return nil
+	}
+	severities := make([]string, 0, len(arr))
+	for _, l := range arr {
+		level, ok := l.(map[string]any)
+		if !ok {
+			return nil
+		}
+		severity, ok := level["severity"].(string)
+		if !ok {
+			return nil
+		}
+		severities = append(severities, severity)
+	}
+	return severities
+}
+
+// SeverityOfLevel returns the name of the severity for the result of a threshold command with the given
+// severity levels, or an empty string if the result is not the position of a level.
+func SeverityOfLevel(severities []string, value float64) string {
+	idx := int(value)
+	if float64(idx) != value || idx < 1 || idx > len(severities) {
+		return ""
+	}
+	return severities[idx-1]
+}
@@ -0,0 +1,185 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalThresholdLevelsCommand(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expectedError string
+	}{
+		{
+			description: "levels",
+			query: `{
+				"expression": "A",
+				"type": "threshold",
+				"levels": [
+					{"severity": "warning", "evaluator": {"type": "gt", "params": [80]}},
+					{"severity": "critical", "evaluator": {"type": "gt", "params": [90]}}
+				]
+			}`,
+		},
+		{
+			description: "levels and conditions",
+			query: `{
+				"expression": "A",
+				"type": "threshold",
+				"conditions": [{"evaluator": {"type": "gt", "params": [80]}}],
+				"levels": [{"severity": "warning", "evaluator": {"type": "gt", "params": [80]}}]
+			}`,
+			expectedError: "either conditions or levels, not both",
+		},
+		{
+			description: "level without severity",
+			query: `{
+				"expression": "A",
+				"type": "threshold",
+				"levels": [{"evaluator": {"type": "gt", "params": [80]}}]
+			}`,
+			expectedError: "threshold level 1 has no severity",
+		},
+		{
+			description: "duplicate severity",
+			query: `{
+				"expression": "A",
+				"type": "threshold",
+				"levels": [
+					{"severity": "warning", "evaluator": {"type": "gt", "params": [80]}},
+					{"severity": "warning", "evaluator": {"type": "gt", "params": [90]}}
+				]
+			}`,
+			expectedError: "duplicate threshold level severity 'warning'",
+		},
+		{
+			description: "invalid evaluator",
+			query: `{
+				"expression": "A",
+				"type": "threshold",
+				"levels": [{"severity": "warning", "evaluator": {"type": "within_range", "params": [80]}}]
+			}`,
+			expectedError: "invalid condition of threshold level 'warning'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &qmap))
+
+			cmd, err := UnmarshalThresholdCommand(&rawNode{
+				RefID:    "B",
+				Query:    qmap,
+				QueryRaw: []byte(tc.query),
+			}, featuremgmt.WithFeatures())
+
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, &ThresholdLevelsCommand{}, cmd)
+			levels := cmd.(*ThresholdLevelsCommand)
+			require.Equal(t, []string{"A"}, levels.NeedsVars())
+			require.Len(t, levels.Levels, 2)
+			require.Equal(t, "warning", levels.Levels[0].Severity)
+			require.Equal(t, greaterThanPredicate{80}, levels.Levels[0].Threshold.predicate)
+			require.Equal(t, "critical", levels.Levels[1].Severity)
+			require.Equal(t, []string{"warning", "critical"}, GetThresholdSeverities(qmap))
+		})
+	}
+}
+
+func TestThresholdLevelsExecute(t *testing.T) {
+	cmd, err := NewThresholdLevelsCommand("", "A", []ThresholdLevelJSON{
+		{Severity: "warning", Evaluator: ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{80}}},
+		{Severity: "critical", Evaluator: ConditionEvalJSON{Type: ThresholdIsAbove, Params: []float64{90}}},
+	})
+	require.NoError(t, err)
+
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			newNumber(data.Labels{"host": "a"}, util.Pointer(50.0)),
+			newNumber(data.Labels{"host": "b"}, util.Pointer(85.0)),
+			newNumber(data.Labels{"host": "c"}, util.Pointer(95.0)),
+			newNumber(data.Labels{"host": "d"}, nil),
+			newSeries(50, 85, 95),
+		}},
+	}
+	results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	require.Equal(t, mathexp.Values{
+		newNumber(data.Labels{"host": "a"}, util.Pointer(0.0)),
+		newNumber(data.Labels{"host": "b"}, util.Pointer(1.0)),
+		newNumber(data.Labels{"host": "c"}, util.Pointer(2.0)),
+		newNumber(data.Labels{"host": "d"}, nil),
+		newSeries(0, 1, 2),
+	}, results.Values)
+}
+
+func TestGetThresholdSeverities(t *testing.T) {
+	cases := []struct {
+		description string
+		query       map[string]any
+		expected    []string
+	}{
+		{
+			description: "threshold with levels",
+			query: map[string]any{
+				"type": "threshold",
+				"levels": []any{
+					map[string]any{"severity": "warning"},
+					map[string]any{"severity": "critical"},
+				},
+			},
+			expected: []string{"warning", "critical"},
+		},
+		{
+			description: "threshold with conditions",
+			query: map[string]any{
+				"type":       "threshold",
+				"conditions": []any{map[string]any{}},
+			},
+		},
+		{
+			description: "not a threshold",
+			query: map[string]any{
+				"type":   "math",
+				"levels": []any{map[string]any{"severity": "warning"}},
+			},
+		},
+		{
+			description: "invalid levels",
+			query: map[string]any{
+				"type":   "threshold",
+				"levels": []any{"warning"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, GetThresholdSeverities(tc.query))
+		})
+	}
+}
+
+func TestSeverityOfLevel(t *testing.T) {
+	severities := []string{"warning", "critical"}
+	require.Equal(t, "", SeverityOfLevel(severities, 0))
+	require.Equal(t, "warning", SeverityOfLevel(severities, 1))
+	require.Equal(t, "critical", SeverityOfLevel(severities, 2))
+	require.Equal(t, "", SeverityOfLevel(severities, 3))
+	require.Equal(t, "", SeverityOfLevel(severities, 1.5))
+}
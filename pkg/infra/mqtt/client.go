@@ -0,0 +1,162 @@
+// Package mqtt publishes messages to an MQTT broker with the Eclipse Paho client.
+// Subscriptions are not supported.
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+// QoS is the quality of service level of a published message.
+type QoS byte
+
+const (
+	AtMostOnce  QoS = 0
+	AtLeastOnce QoS = 1
+	ExactlyOnce QoS = 2
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	// protocolVersion is MQTT 3.1.1. It is set explicitly so that refused connections are not retried with MQTT 3.1.
+	protocolVersion = 4
+)
+
+// Options configure the connection to the broker.
+type Options struct {
+	// BrokerURL is the address of the broker, for example tcp://localhost:1883 or ssl://localhost:8883.
+	BrokerURL string
+	ClientID  string
+	Username  string
+	Password  string
+	// TLSConfig is used by the ssl, tls and mqtts schemes. If nil, the default configuration is used.
+	TLSConfig *tls.Config
+	// Timeout is the timeout of the connection and of every acknowledgement of the broker. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// Client is a connection to a broker. It is safe for concurrent use.
+type Client struct {
+	client  paho.Client
+	timeout time.Duration
+}
+
+// ValidateBrokerURL returns an error if the URL is not an address of a broker that the client can connect to.
+func ValidateBrokerURL(brokerURL string) error {
+	_, err := parseBrokerURL(brokerURL)
+	return err
+}
+
+// ValidateTopic returns an error if the topic is not a valid topic name to publish to.
+func ValidateTopic(topic string) error {
+	if topic == "" {
+		return errors.New("topic must not be empty")
+	}
+	if len(topic) > 65535 {
+		return errors.New("topic must not be longer than 65535 bytes")
+	}
+	if strings.ContainsAny(topic, "+#\x00") {
+		return errors.New("topic must not contain wildcards or null characters")
+	}
+	return nil
+}
+
+// Connect opens a connection to the broker and waits for the broker to accept it.
+func Connect(ctx context.Context, opts Options) (*Client, error) {
+	broker, err := parseBrokerURL(opts.BrokerURL)
+	if err != nil {
+		return nil, err
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	options := paho.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(opts.ClientID).
+		SetUsername(opts.Username).
+		SetPassword(opts.Password).
+		SetProtocolVersion(protocolVersion).
+		SetConnectTimeout(timeout).
+		SetWriteTimeout(timeout).
+		SetDialer(&net.Dialer{Timeout: timeout}).
+		// Callers decide when to connect again.
+		SetAutoReconnect(false)
+	if opts.TLSConfig != nil {
+		options.SetTLSConfig(opts.TLSConfig)
+	}
+
+	c := &Client{client: paho.NewClient(options), timeout: timeout}
+	if err := c.wait(ctx, c.client.Connect()); err != nil {
+		c.client.Disconnect(0)
+		return nil, fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	return c, nil
+}
+
+// Publish publishes the payload to the topic. If the QoS is at least once or exactly once, it waits until the
+// broker acknowledges the message.
+func (c *Client) Publish(ctx context.Context, topic string, qos QoS, retain bool, payload []byte) error {
+	if err := ValidateTopic(topic); err != nil {
+		return err
+	}
+	if qos > ExactlyOnce {
+		return fmt.Errorf("invalid QoS %d", qos)
+	}
+	if err := c.wait(ctx, c.client.Publish(topic, byte(qos), retain, payload)); err != nil {
+		return fmt.Errorf("failed to publish to MQTT broker: %w", err)
+	}
+	return nil
+}
+
+// Close disconnects from the broker.
+func (c *Client) Close() error {
+	c.client.Disconnect(uint(c.timeout.Milliseconds()))
+	return nil
+}
+
+// wait waits until the token completes, the timeout expires or the context is done.
+func (c *Client) wait(ctx context.Context, token paho.Token) error {
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-timer.C:
+		return errors.New("timeout waiting for the broker")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseBrokerURL returns the broker URL with the default port of the scheme if it has no port.
+func parseBrokerURL(brokerURL string) (string, error) {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid broker URL: %w", err)
+	}
+	if u.Hostname() == "" {
+		return "", errors.New("broker URL must contain a host")
+	}
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		port = "8883"
+	default:
+		return "", fmt.Errorf("unsupported broker URL scheme '%s', must be one of tcp, mqtt, ssl, tls or mqtts", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return u.Scheme + "://" + net.JoinHostPort(u.Hostname(), port), nil
+}
@@ -0,0 +1,92 @@
+package mqtt_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/mqtt"
+	"github.com/grafana/grafana/pkg/infra/mqtt/mqtttest"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	newBroker := func(t *testing.T, username, password string, tlsConfig *tls.Config) *mqtttest.Broker {
+		broker, err := mqtttest.NewBroker(username, password, tlsConfig)
+		require.NoError(t, err)
+		t.Cleanup(broker.Close)
+		return broker
+	}
+
+	t.Run("publishes messages with every QoS", func(t *testing.T) {
+		broker := newBroker(t, "", "", nil)
+		c, err := mqtt.Connect(ctx, mqtt.Options{BrokerURL: broker.URL(), ClientID: "test"})
+		require.NoError(t, err)
+
+		require.NoError(t, c.Publish(ctx, "a/b", mqtt.AtMostOnce, false, []byte("0")))
+		require.NoError(t, c.Publish(ctx, "a/b", mqtt.AtLeastOnce, true, []byte("1")))
+		require.NoError(t, c.Publish(ctx, "a/c", mqtt.ExactlyOnce, false, []byte("2")))
+		require.NoError(t, c.Close())
+
+		require.Equal(t, []string{"test"}, broker.ClientIDs())
+		require.Equal(t, []mqtttest.Message{
+			{Topic: "a/b", QoS: mqtt.AtMostOnce, Payload: []byte("0")},
+			{Topic: "a/b", QoS: mqtt.AtLeastOnce, Retain: true, Payload: []byte("1")},
+			{Topic: "a/c", QoS: mqtt.ExactlyOnce, Payload: []byte("2")},
+		}, broker.Messages())
+	})
+
+	t.Run("authenticates with username and password", func(t *testing.T) {
+		broker := newBroker(t, "user", "pass", nil)
+		_, err := mqtt.Connect(ctx, mqtt.Options{BrokerURL: broker.URL(), Username: "user", Password: "wrong"})
+		require.ErrorContains(t, err, "bad user name or password")
+
+		c, err := mqtt.Connect(ctx, mqtt.Options{BrokerURL: broker.URL(), Username: "user", Password: "pass"})
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("connects with TLS", func(t *testing.T) {
+		srv := httptest.NewTLSServer(nil)
+		srv.Close()
+		broker := newBroker(t, "", "", &tls.Config{Certificates: srv.TLS.Certificates})
+
+		_, err := mqtt.Connect(ctx, mqtt.Options{BrokerURL: broker.URL()})
+		require.Error(t, err)
+
+		pool := x509.NewCertPool()
+		pool.AddCert(srv.Certificate())
+		c, err := mqtt.Connect(ctx, mqtt.Options{BrokerURL: broker.URL(), TLSConfig: &tls.Config{RootCAs: pool}})
+		require.NoError(t, err)
+		require.NoError(t, c.Publish(ctx, "topic", mqtt.AtLeastOnce, false, []byte("test")))
+		require.NoError(t, c.Close())
+		require.Len(t, broker.Messages(), 1)
+	})
+
+	t.Run("large payloads", func(t *testing.T) {
+		broker := newBroker(t, "", "", nil)
+		c, err := mqtt.Connect(ctx, mqtt.Options{BrokerURL: broker.URL()})
+		require.NoError(t, err)
+		payload := make([]byte, 100000)
+		require.NoError(t, c.Publish(ctx, "topic", mqtt.AtLeastOnce, false, payload))
+		require.NoError(t, c.Close())
+		require.Equal(t, payload, broker.Messages()[0].Payload)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, mqtt.ValidateBrokerURL("tcp://localhost"))
+	require.NoError(t, mqtt.ValidateBrokerURL("mqtts://localhost:8884"))
+	require.ErrorContains(t, mqtt.ValidateBrokerURL("ws://localhost"), "unsupported broker URL scheme")
+	require.ErrorContains(t, mqtt.ValidateBrokerURL("tcp://"), "must contain a host")
+
+	require.NoError(t, mqtt.ValidateTopic("plant/line-1/alerts"))
+	require.Error(t, mqtt.ValidateTopic(""))
+	require.Error(t, mqtt.ValidateTopic("plant/+/alerts"))
+	require.Error(t, mqtt.ValidateTopic("plant/#"))
+}
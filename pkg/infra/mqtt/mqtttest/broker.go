@@ -0,0 +1,202 @@
+// Package mqtttest implements an in-process MQTT broker for tests of the mqtt client.
+package mqtttest
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/mqtt"
+)
+
+const (
+	packetConnect    byte = 1
+	packetConnAck    byte = 2
+	packetPublish    byte = 3
+	packetPubAck     byte = 4
+	packetPubRec     byte = 5
+	packetPubRel     byte = 6
+	packetPubComp    byte = 7
+	packetPingReq    byte = 12
+	packetPingResp   byte = 13
+	packetDisconnect byte = 14
+)
+
+// Message is a message that is published to the Broker.
+type Message struct {
+	Topic   string
+	QoS     mqtt.QoS
+	Retain  bool
+	Payload []byte
+}
+
+// Broker accepts the connections of the client and records the published messages.
+type Broker struct {
+	listener net.Listener
+	tls      bool
+	username string
+	password string
+
+	mtx      sync.Mutex
+	messages []Message
+	clients  []string
+}
+
+// NewBroker starts a broker that listens on a random local port. If username or password are not empty,
+// the broker refuses connections with other credentials. If tlsConfig is not nil, the broker accepts only TLS
+// connections. The broker must be closed when it is no longer used.
+func NewBroker(username, password string, tlsConfig *tls.Config) (*Broker, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+	b := &Broker{listener: l, tls: tlsConfig != nil, username: username, password: password}
+	go b.serve()
+	return b, nil
+}
+
+// Close stops the broker from accepting connections.
+func (b *Broker) Close() {
+	_ = b.listener.Close()
+}
+
+// URL returns the broker URL of the broker.
+func (b *Broker) URL() string {
+	if b.tls {
+		return "ssl://" + b.listener.Addr().String()
+	}
+	return "tcp://" + b.listener.Addr().String()
+}
+
+// Messages returns the messages that were published to the broker.
+func (b *Broker) Messages() []Message {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return append([]Message(nil), b.messages...)
+}
+
+// ClientIDs returns the client IDs of the accepted connections.
+func (b *Broker) ClientIDs() []string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return append([]string(nil), b.clients...)
+}
+
+func (b *Broker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *Broker) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	write := func(header byte, body ...byte) bool {
+		_, err := conn.Write(append([]byte{header, byte(len(body))}, body...))
+		return err == nil
+	}
+	for {
+		header, data, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case packetConnect:
+			clientID, username, password := parseConnect(data)
+			if (b.username != "" || b.password != "") && (username != b.username || password != b.password) {
+				write(packetConnAck<<4, 0, 4)
+				return
+			}
+			b.mtx.Lock()
+			b.clients = append(b.clients, clientID)
+			b.mtx.Unlock()
+			if !write(packetConnAck<<4, 0, 0) {
+				return
+			}
+		case packetPublish:
+			qos := mqtt.QoS(header >> 1 & 0x03)
+			topicLen := int(data[0])<<8 | int(data[1])
+			msg := Message{Topic: string(data[2 : 2+topicLen]), QoS: qos, Retain: header&0x01 == 1}
+			rest := data[2+topicLen:]
+			var id []byte
+			if qos > mqtt.AtMostOnce {
+				id, rest = rest[:2], rest[2:]
+			}
+			msg.Payload = rest
+			b.mtx.Lock()
+			b.messages = append(b.messages, msg)
+			b.mtx.Unlock()
+			switch qos {
+			case mqtt.AtLeastOnce:
+				write(packetPubAck<<4, id...)
+			case mqtt.ExactlyOnce:
+				write(packetPubRec<<4, id...)
+			}
+		case packetPubRel:
+			write(packetPubComp<<4, data...)
+		case packetPingReq:
+			write(packetPingResp << 4)
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+// readPacket returns the fixed header and the variable header and payload of the next packet.
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+	return header, data, nil
+}
+
+func parseConnect(data []byte) (clientID, username, password string) {
+	// Skip the protocol name, the protocol level, the flags and the keep alive.
+	nameLen := int(data[0])<<8 | int(data[1])
+	flags := data[2+nameLen+1]
+	rest := data[2+nameLen+4:]
+	next := func() string {
+		l := int(rest[0])<<8 | int(rest[1])
+		s := string(rest[2 : 2+l])
+		rest = rest[2+l:]
+		return s
+	}
+	clientID = next()
+	if flags&0x80 != 0 {
+		username = next()
+	}
+	if flags&0x40 != 0 {
+		password = next()
+	}
+	return clientID, username, password
+}
@@ -0,0 +1,121 @@
+package caching
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+)
+
+type queryKey struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	Interval      time.Duration   `json:"interval"`
+	From          int64           `json:"from"`
+	To            int64           `json:"to"`
+	JSON          json.RawMessage `json:"json"`
+}
+
+// queryCacheKey returns the key of the query request. The time ranges of the queries are rounded down to the
+// resolution, so that requests for relative time ranges like the last hour share the key until it has passed.
+func queryCacheKey(ctx context.Context, req *backend.QueryDataRequest, resolution time.Duration) (string, error) {
+	queries := make([]queryKey, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		var query bytes.Buffer
+		if len(q.JSON) > 0 {
+			if err := json.Compact(&query, q.JSON); err != nil {
+				return "", err
+			}
+		}
+		queries = append(queries, queryKey{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval,
+			From:          q.TimeRange.From.Truncate(resolution).UnixMilli(),
+			To:            q.TimeRange.To.Truncate(resolution).UnixMilli(),
+			JSON:          query.Bytes(),
+		})
+	}
+	return hashKey(requestTypeQuery, dataSourceKey(ctx, req.PluginContext), queries)
+}
+
+type resourceKey struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+}
+
+// resourceCacheKey returns the key of the resource request.
+func resourceCacheKey(ctx context.Context, req *backend.CallResourceRequest) (string, error) {
+	return hashKey(requestTypeResource, dataSourceKey(ctx, req.PluginContext), resourceKey{Path: req.Path, URL: req.URL})
+}
+
+type dataSource struct {
+	OrgID   int64  `json:"orgId"`
+	UID     string `json:"uid"`
+	Updated int64  `json:"updated"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+// dataSourceKey identifies the data source of the request. Responses are cached until the data source is updated.
+func dataSourceKey(ctx context.Context, pCtx backend.PluginContext) dataSource {
+	settings := pCtx.DataSourceInstanceSettings
+	return dataSource{
+		OrgID:   pCtx.OrgID,
+		UID:     settings.UID,
+		Updated: settings.Updated.UnixMilli(),
+		Scope:   scope(ctx, pCtx),
+	}
+}
+
+// scope returns the user or teams whose responses must be cached separately, because the data source forwards
+// the identity of the user or adds headers for their teams.
+func scope(ctx context.Context, pCtx backend.PluginContext) string {
+	var jsonData struct {
+		OAuthPassThru   bool            `json:"oauthPassThru"`
+		TeamHTTPHeaders json.RawMessage `json:"teamHttpHeaders"`
+	}
+	_ = json.Unmarshal(pCtx.DataSourceInstanceSettings.JSONData, &jsonData)
+
+	if jsonData.OAuthPassThru {
+		if pCtx.User != nil {
+			return "user:" + pCtx.User.Login
+		}
+		return "user:"
+	}
+	if len(jsonData.TeamHTTPHeaders) > 0 && string(jsonData.TeamHTTPHeaders) != "null" {
+		var teams []int64
+		if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SignedInUser != nil {
+			teams = slices.Clone(reqCtx.SignedInUser.GetTeams())
+		}
+		slices.Sort(teams)
+		ids := make([]string, 0, len(teams))
+		for _, team := range teams {
+			ids = append(ids, strconv.FormatInt(team, 10))
+		}
+		return "teams:" + strings.Join(ids, ",")
+	}
+	return ""
+}
+
+func hashKey(requestType string, ds dataSource, request any) (string, error) {
+	b, err := json.Marshal(struct {
+		DataSource dataSource `json:"datasource"`
+		Request    any        `json:"request"`
+	}{ds, request})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return requestType + ":" + hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,88 @@
+package caching
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryBackend is a least recently used cache that is limited by the size of its keys and values.
+type memoryBackend struct {
+	maxSize int
+	onEvict func()
+	now     func() time.Time
+
+	mtx   sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func newMemoryBackend(maxSize int, onEvict func()) *memoryBackend {
+	return &memoryBackend{
+		maxSize: maxSize,
+		onEvict: onEvict,
+		now:     time.Now,
+		items:   map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func (b *memoryBackend) Get(_ context.Context, key string) ([]byte, bool, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	elem, ok := b.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !b.now().Before(entry.expires) {
+		b.remove(elem)
+		return nil, false, nil
+	}
+	b.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+func (b *memoryBackend) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	size := len(key) + len(value)
+	if size > b.maxSize {
+		return nil
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if elem, ok := b.items[key]; ok {
+		b.remove(elem)
+	}
+	b.items[key] = b.order.PushFront(&memoryEntry{key: key, value: value, expires: b.now().Add(ttl)})
+	b.size += size
+	for b.size > b.maxSize {
+		b.remove(b.order.Back())
+		if b.onEvict != nil {
+			b.onEvict()
+		}
+	}
+	return nil
+}
+
+func (b *memoryBackend) Delete(_ context.Context, key string) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if elem, ok := b.items[key]; ok {
+		b.remove(elem)
+	}
+	return nil
+}
+
+func (b *memoryBackend) remove(elem *list.Element) {
+	entry := b.order.Remove(elem).(*memoryEntry)
+	delete(b.items, entry.key)
+	b.size -= len(entry.key) + len(entry.value)
+}
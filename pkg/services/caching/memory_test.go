@@ -0,0 +1,62 @@
+package caching
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("evicts the least recently used values when it is full", func(t *testing.T) {
+		evictions := 0
+		b := newMemoryBackend(30, func() { evictions++ })
+
+		require.NoError(t, b.Set(ctx, "a", []byte("123456789"), time.Minute))
+		require.NoError(t, b.Set(ctx, "b", []byte("123456789"), time.Minute))
+		require.NoError(t, b.Set(ctx, "c", []byte("123456789"), time.Minute))
+		_, ok, _ := b.Get(ctx, "a")
+		require.True(t, ok)
+
+		require.NoError(t, b.Set(ctx, "d", []byte("123456789"), time.Minute))
+		require.Equal(t, 1, evictions)
+		_, ok, _ = b.Get(ctx, "b")
+		require.False(t, ok)
+		for _, key := range []string{"a", "c", "d"} {
+			_, ok, _ = b.Get(ctx, key)
+			require.True(t, ok, key)
+		}
+		require.Equal(t, 30, b.size)
+	})
+
+	t.Run("ignores values larger than the cache", func(t *testing.T) {
+		b := newMemoryBackend(10, nil)
+		require.NoError(t, b.Set(ctx, "a", []byte("1234567890"), time.Minute))
+		_, ok, _ := b.Get(ctx, "a")
+		require.False(t, ok)
+		require.Zero(t, b.size)
+	})
+
+	t.Run("expires values", func(t *testing.T) {
+		now := time.Now()
+		b := newMemoryBackend(100, nil)
+		b.now = func() time.Time { return now }
+
+		require.NoError(t, b.Set(ctx, "a", []byte("1"), time.Minute))
+		require.NoError(t, b.Set(ctx, "a", []byte("2"), 2*time.Minute))
+		require.Equal(t, 2, b.size)
+
+		now = now.Add(time.Minute)
+		value, ok, _ := b.Get(ctx, "a")
+		require.True(t, ok)
+		require.Equal(t, []byte("2"), value)
+
+		now = now.Add(time.Minute)
+		_, ok, _ = b.Get(ctx, "a")
+		require.False(t, ok)
+		require.Zero(t, b.size)
+	})
+}
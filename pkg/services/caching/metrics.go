@@ -0,0 +1,50 @@
+package caching
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namespace = "grafana"
+	subsystem = "caching"
+
+	requestTypeQuery    = "query"
+	requestTypeResource = "resource"
+)
+
+type metrics struct {
+	hits      *prometheus.CounterVec
+	misses    *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	evictions prometheus.Counter
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		hits: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "hits_total",
+			Help:      "The total number of requests that were answered from the cache.",
+		}, []string{"type"}),
+		misses: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "misses_total",
+			Help:      "The total number of requests that were not found in the cache.",
+		}, []string{"type"}),
+		errors: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "errors_total",
+			Help:      "The total number of failed cache operations.",
+		}, []string{"type"}),
+		evictions: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evictions_total",
+			Help:      "The total number of responses evicted from the in-memory cache because it was full.",
+		}),
+	}
+}
@@ -0,0 +1,50 @@
+package caching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisBackend stores responses in Redis, which takes care of their expiration and eviction.
+type redisBackend struct {
+	client *redis.Client
+	prefix string
+}
+
+func newRedisBackend(url, prefix string) (*redisBackend, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	return &redisBackend{client: redis.NewClient(opts), prefix: prefix}, nil
+}
+
+func (b *redisBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := b.client.Get(ctx, b.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (b *redisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.client.Set(ctx, b.key(key), value, ttl).Err()
+}
+
+func (b *redisBackend) Delete(ctx context.Context, key string) error {
+	return b.client.Del(ctx, b.key(key)).Err()
+}
+
+func (b *redisBackend) key(key string) string {
+	if b.prefix == "" {
+		return key
+	}
+	return b.prefix + ":" + key
+}
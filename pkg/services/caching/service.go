@@ -2,8 +2,18 @@ package caching
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
@@ -35,8 +45,11 @@ type CachedResourceDataResponse struct {
 	UpdateCacheFn CacheResourceResponseFn
 }
 
-func ProvideCachingService() *OSSCachingService {
-	return &OSSCachingService{}
+func ProvideCachingService(cfg *setting.Cfg, reg prometheus.Registerer) (*OSSCachingService, error) {
+	if !cfg.Caching.Enabled {
+		return &OSSCachingService{}, nil
+	}
+	return NewOSSCachingService(cfg.Caching, reg)
 }
 
 type CachingService interface {
@@ -49,16 +62,197 @@ type CachingService interface {
 	HandleResourceRequest(context.Context, *backend.CallResourceRequest) (bool, CachedResourceDataResponse)
 }
 
-// Implementation of interface - does nothing
+// cacheBackend stores the encoded responses.
+type cacheBackend interface {
+	// Get returns the value of the key, and false if it is not cached.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// OSSCachingService caches query and resource responses of data sources in memory or Redis.
+// The zero value does not cache anything.
 type OSSCachingService struct {
+	cfg     setting.CachingSettings
+	backend cacheBackend
+	metrics *metrics
+	log     log.Logger
+}
+
+func NewOSSCachingService(cfg setting.CachingSettings, reg prometheus.Registerer) (*OSSCachingService, error) {
+	s := &OSSCachingService{
+		cfg:     cfg,
+		metrics: newMetrics(reg),
+		log:     log.New("caching"),
+	}
+	switch cfg.Backend {
+	case setting.CachingBackendMemory:
+		s.backend = newMemoryBackend(cfg.MemoryMaxSize, s.metrics.evictions.Inc)
+	case setting.CachingBackendRedis:
+		backend, err := newRedisBackend(cfg.RedisURL, cfg.RedisPrefix)
+		if err != nil {
+			return nil, err
+		}
+		s.backend = backend
+	default:
+		return nil, fmt.Errorf("unsupported caching backend '%s'", cfg.Backend)
+	}
+	return s, nil
 }
 
+// HandleQueryRequest looks up the response of the query request. Responses are only cached if none of their
+// queries failed.
 func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
-	return false, CachedQueryDataResponse{}
+	dsCfg, ok := s.settings(ctx, req.PluginContext)
+	if !ok {
+		return false, CachedQueryDataResponse{}
+	}
+	key, err := queryCacheKey(ctx, req, dsCfg.TTL)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to build the cache key of the query", "error", err)
+		setCacheStatus(ctx, StatusBypass)
+		return false, CachedQueryDataResponse{}
+	}
+	value, ok, err := s.backend.Get(ctx, key)
+	if err != nil {
+		s.fail(ctx, requestTypeQuery, err)
+		return false, CachedQueryDataResponse{}
+	}
+	if ok {
+		var resp backend.QueryDataResponse
+		err := json.Unmarshal(value, &resp)
+		if err == nil {
+			s.hit(ctx, requestTypeQuery)
+			return true, CachedQueryDataResponse{Response: &resp}
+		}
+		s.log.FromContext(ctx).Warn("Failed to decode the cached query response", "error", err)
+	}
+	s.miss(ctx, requestTypeQuery)
+
+	return false, CachedQueryDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.QueryDataResponse) {
+			if resp == nil {
+				return
+			}
+			for _, r := range resp.Responses {
+				if r.Error != nil || r.Status >= http.StatusBadRequest {
+					return
+				}
+			}
+			value, err := json.Marshal(resp)
+			if err != nil {
+				s.log.FromContext(ctx).Warn("Failed to encode the query response", "error", err)
+				return
+			}
+			s.set(ctx, requestTypeQuery, key, value, dsCfg.TTL)
+		},
+	}
 }
 
+// HandleResourceRequest looks up the response of the resource request. Only successful responses of GET requests
+// are cached. Streamed responses are not cached.
 func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
-	return false, CachedResourceDataResponse{}
+	if req.Method != http.MethodGet {
+		return false, CachedResourceDataResponse{}
+	}
+	dsCfg, ok := s.settings(ctx, req.PluginContext)
+	if !ok {
+		return false, CachedResourceDataResponse{}
+	}
+	key, err := resourceCacheKey(ctx, req)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to build the cache key of the resource request", "error", err)
+		setCacheStatus(ctx, StatusBypass)
+		return false, CachedResourceDataResponse{}
+	}
+	value, ok, err := s.backend.Get(ctx, key)
+	if err != nil {
+		s.fail(ctx, requestTypeResource, err)
+		return false, CachedResourceDataResponse{}
+	}
+	if ok {
+		var resp backend.CallResourceResponse
+		err := json.Unmarshal(value, &resp)
+		if err == nil {
+			s.hit(ctx, requestTypeResource)
+			return true, CachedResourceDataResponse{Response: &resp}
+		}
+		s.log.FromContext(ctx).Warn("Failed to decode the cached resource response", "error", err)
+	}
+	s.miss(ctx, requestTypeResource)
+
+	var calls atomic.Int32
+	return false, CachedResourceDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.CallResourceResponse) {
+			if calls.Add(1) > 1 {
+				// The response is streamed, so the first part that might have been cached is incomplete.
+				if err := s.backend.Delete(ctx, key); err != nil {
+					s.metrics.errors.WithLabelValues(requestTypeResource).Inc()
+				}
+				return
+			}
+			if resp == nil || resp.Status < http.StatusOK || resp.Status >= http.StatusMultipleChoices {
+				return
+			}
+			value, err := json.Marshal(resp)
+			if err != nil {
+				s.log.FromContext(ctx).Warn("Failed to encode the resource response", "error", err)
+				return
+			}
+			s.set(ctx, requestTypeResource, key, value, dsCfg.ResourcesTTL)
+		},
+	}
+}
+
+// settings returns the caching settings of the data source of the request, and false if the request must not be
+// cached. The cache status is set accordingly.
+func (s *OSSCachingService) settings(ctx context.Context, pCtx backend.PluginContext) (setting.CachingDataSourceSettings, bool) {
+	if s.backend == nil || pCtx.DataSourceInstanceSettings == nil {
+		return setting.CachingDataSourceSettings{}, false
+	}
+	dsCfg := s.cfg.ForDataSource(pCtx.DataSourceInstanceSettings.UID)
+	if !dsCfg.Enabled {
+		setCacheStatus(ctx, StatusDisabled)
+		return dsCfg, false
+	}
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SkipQueryCache {
+		setCacheStatus(ctx, StatusBypass)
+		return dsCfg, false
+	}
+	return dsCfg, true
+}
+
+func (s *OSSCachingService) hit(ctx context.Context, requestType string) {
+	s.metrics.hits.WithLabelValues(requestType).Inc()
+	setCacheStatus(ctx, StatusHit)
+}
+
+func (s *OSSCachingService) miss(ctx context.Context, requestType string) {
+	s.metrics.misses.WithLabelValues(requestType).Inc()
+	setCacheStatus(ctx, StatusMiss)
+}
+
+func (s *OSSCachingService) fail(ctx context.Context, requestType string, err error) {
+	s.log.FromContext(ctx).Warn("Failed to read from the cache", "error", err)
+	s.metrics.errors.WithLabelValues(requestType).Inc()
+	setCacheStatus(ctx, StatusError)
+}
+
+func (s *OSSCachingService) set(ctx context.Context, requestType, key string, value []byte, ttl time.Duration) {
+	if len(value) > s.cfg.MaxValueSize {
+		return
+	}
+	if err := s.backend.Set(ctx, key, value, ttl); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to write to the cache", "error", err)
+		s.metrics.errors.WithLabelValues(requestType).Inc()
+	}
+}
+
+// setCacheStatus sets the X-Cache header of the response to the request in the context.
+func setCacheStatus(ctx context.Context, status string) {
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Resp != nil {
+		reqCtx.Resp.Header().Set(XCacheHeader, status)
+	}
 }
 
 var _ CachingService = &OSSCachingService{}
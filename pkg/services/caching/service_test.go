@@ -0,0 +1,351 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestOSSCachingService_HandleQueryRequest(t *testing.T) {
+	response := &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("cpu", data.NewField("value", nil, []float64{1, 2, 3}))}},
+	}}
+
+	t.Run("caches the response until it expires", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		ctx, resp := newTestContext(&user.SignedInUser{})
+
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, resp.Header().Get(XCacheHeader))
+		require.NotNil(t, cr.UpdateCacheFn)
+		cr.UpdateCacheFn(ctx, response)
+
+		ctx, resp = newTestContext(&user.SignedInUser{})
+		hit, cr = s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, resp.Header().Get(XCacheHeader))
+		require.Equal(t, []float64{1, 2, 3}, fieldValues(t, cr.Response.Responses["A"].Frames[0].Fields[0]))
+
+		require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.hits.WithLabelValues(requestTypeQuery)))
+		require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.misses.WithLabelValues(requestTypeQuery)))
+
+		s.backend.(*memoryBackend).now = func() time.Time { return time.Now().Add(time.Hour) }
+		hit, _ = s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		require.False(t, hit)
+	})
+
+	t.Run("shares responses of requests in the same rounded time range", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		ctx, _ := newTestContext(&user.SignedInUser{})
+		now := time.Now().Truncate(time.Minute)
+
+		req := newQueryRequest("up")
+		req.Queries[0].TimeRange = backend.TimeRange{From: now.Add(-time.Hour), To: now}
+		_, cr := s.HandleQueryRequest(ctx, req)
+		cr.UpdateCacheFn(ctx, response)
+
+		req = newQueryRequest("up")
+		req.Queries[0].JSON = json.RawMessage(`{ "expr":  "up" }`)
+		req.Queries[0].TimeRange = backend.TimeRange{From: now.Add(-time.Hour + 30*time.Second), To: now.Add(30 * time.Second)}
+		hit, _ := s.HandleQueryRequest(ctx, req)
+		require.True(t, hit)
+
+		req.Queries[0].TimeRange = backend.TimeRange{From: now.Add(-time.Hour + time.Minute), To: now.Add(time.Minute)}
+		hit, _ = s.HandleQueryRequest(ctx, req)
+		require.False(t, hit)
+
+		hit, _ = s.HandleQueryRequest(ctx, newQueryRequest("down"))
+		require.False(t, hit)
+
+		req = newQueryRequest("up")
+		req.PluginContext.DataSourceInstanceSettings.Updated = now
+		hit, _ = s.HandleQueryRequest(ctx, req)
+		require.False(t, hit, "updating the data source should invalidate the cache")
+	})
+
+	t.Run("does not cache failed queries", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		ctx, _ := newTestContext(&user.SignedInUser{})
+
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": response.Responses["A"],
+			"B": backend.ErrDataResponse(backend.StatusBadRequest, "bad query"),
+		}})
+
+		hit, _ := s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		require.False(t, hit)
+	})
+
+	t.Run("does not cache responses that are too large", func(t *testing.T) {
+		cfg := testSettings()
+		cfg.MaxValueSize = 10
+		s := newTestService(t, cfg)
+		ctx, _ := newTestContext(&user.SignedInUser{})
+
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		cr.UpdateCacheFn(ctx, response)
+
+		hit, _ := s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		require.False(t, hit)
+	})
+
+	t.Run("applies the settings of the data source", func(t *testing.T) {
+		cfg := testSettings()
+		cfg.DataSources = map[string]setting.CachingDataSourceSettings{"disabled": {Enabled: false}}
+		s := newTestService(t, cfg)
+		ctx, resp := newTestContext(&user.SignedInUser{})
+
+		req := newQueryRequest("up")
+		req.PluginContext.DataSourceInstanceSettings.UID = "disabled"
+		hit, cr := s.HandleQueryRequest(ctx, req)
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusDisabled, resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("bypasses the cache if the request asks to skip it", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		ctx, resp := newTestContext(&user.SignedInUser{})
+		contexthandler.FromContext(ctx).SkipQueryCache = true
+
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusBypass, resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("caches responses per user if the data source forwards the identity", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		ctx, _ := newTestContext(&user.SignedInUser{})
+
+		req := func(login string) *backend.QueryDataRequest {
+			req := newQueryRequest("up")
+			req.PluginContext.DataSourceInstanceSettings.JSONData = json.RawMessage(`{"oauthPassThru": true}`)
+			req.PluginContext.User = &backend.User{Login: login}
+			return req
+		}
+		_, cr := s.HandleQueryRequest(ctx, req("alice"))
+		cr.UpdateCacheFn(ctx, response)
+
+		hit, _ := s.HandleQueryRequest(ctx, req("alice"))
+		require.True(t, hit)
+		hit, _ = s.HandleQueryRequest(ctx, req("bob"))
+		require.False(t, hit)
+	})
+
+	t.Run("caches responses per teams if the data source has team headers", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		req := newQueryRequest("up")
+		req.PluginContext.DataSourceInstanceSettings.JSONData = json.RawMessage(`{"teamHttpHeaders": {"1": [{"header": "X-Team", "value": "1"}]}}`)
+
+		ctx, _ := newTestContext(&user.SignedInUser{Teams: []int64{2, 1}})
+		_, cr := s.HandleQueryRequest(ctx, req)
+		cr.UpdateCacheFn(ctx, response)
+
+		ctx, _ = newTestContext(&user.SignedInUser{Teams: []int64{1, 2}})
+		hit, _ := s.HandleQueryRequest(ctx, req)
+		require.True(t, hit)
+
+		ctx, _ = newTestContext(&user.SignedInUser{Teams: []int64{1}})
+		hit, _ = s.HandleQueryRequest(ctx, req)
+		require.False(t, hit)
+	})
+
+	t.Run("reports errors of the cache", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		s.backend = &failingBackend{}
+		ctx, resp := newTestContext(&user.SignedInUser{})
+
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusError, resp.Header().Get(XCacheHeader))
+		require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.errors.WithLabelValues(requestTypeQuery)))
+	})
+
+	t.Run("the zero value does not cache anything", func(t *testing.T) {
+		s := &OSSCachingService{}
+		ctx, resp := newTestContext(&user.SignedInUser{})
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest("up"))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Empty(t, resp.Header().Get(XCacheHeader))
+	})
+}
+
+func TestOSSCachingService_HandleResourceRequest(t *testing.T) {
+	response := &backend.CallResourceResponse{Status: http.StatusOK, Headers: map[string][]string{"Content-Type": {"application/json"}}, Body: []byte(`["up"]`)}
+
+	t.Run("caches successful responses of GET requests", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		ctx, resp := newTestContext(&user.SignedInUser{})
+
+		hit, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "api/v1/labels"))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, resp.Header().Get(XCacheHeader))
+		cr.UpdateCacheFn(ctx, response)
+
+		ctx, resp = newTestContext(&user.SignedInUser{})
+		hit, cr = s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "api/v1/labels"))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, resp.Header().Get(XCacheHeader))
+		require.Equal(t, response, cr.Response)
+
+		hit, _ = s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "api/v1/series"))
+		require.False(t, hit)
+	})
+
+	t.Run("does not cache other requests", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		ctx, resp := newTestContext(&user.SignedInUser{})
+
+		hit, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodPost, "api/v1/labels"))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Empty(t, resp.Header().Get(XCacheHeader))
+
+		_, cr = s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "api/v1/labels"))
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusInternalServerError})
+		hit, _ = s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "api/v1/labels"))
+		require.False(t, hit)
+	})
+
+	t.Run("does not cache streamed responses", func(t *testing.T) {
+		s := newTestService(t, testSettings())
+		ctx, _ := newTestContext(&user.SignedInUser{})
+
+		_, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "stream"))
+		cr.UpdateCacheFn(ctx, response)
+		cr.UpdateCacheFn(ctx, response)
+
+		hit, _ := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "stream"))
+		require.False(t, hit)
+	})
+}
+
+func TestOSSCachingService_Redis(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	cfg := testSettings()
+	cfg.Backend = setting.CachingBackendRedis
+	cfg.RedisURL = "redis://" + mr.Addr()
+	s := newTestService(t, cfg)
+	ctx, _ := newTestContext(&user.SignedInUser{})
+
+	_, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "api/v1/labels"))
+	cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte("[]")})
+
+	keys := mr.Keys()
+	require.Len(t, keys, 1)
+	require.Regexp(t, "^grafana-caching:resource:", keys[0])
+	require.Equal(t, 5*time.Minute, mr.TTL(keys[0]))
+
+	hit, _ := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "api/v1/labels"))
+	require.True(t, hit)
+
+	mr.FastForward(5 * time.Minute)
+	hit, _ = s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet, "api/v1/labels"))
+	require.False(t, hit)
+}
+
+func testSettings() setting.CachingSettings {
+	return setting.CachingSettings{
+		Enabled:       true,
+		Backend:       setting.CachingBackendMemory,
+		TTL:           time.Minute,
+		ResourcesTTL:  5 * time.Minute,
+		MaxTTL:        10 * time.Minute,
+		MaxValueSize:  1024 * 1024,
+		MemoryMaxSize: 10 * 1024 * 1024,
+		RedisPrefix:   "grafana-caching",
+	}
+}
+
+func newTestService(t *testing.T, cfg setting.CachingSettings) *OSSCachingService {
+	s, err := NewOSSCachingService(cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	return s
+}
+
+func newTestContext(u *user.SignedInUser) (context.Context, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	reqCtx := &contextmodel.ReqContext{
+		Context:      &web.Context{Resp: web.NewResponseWriter(http.MethodPost, recorder)},
+		SignedInUser: u,
+	}
+	return ctxkey.Set(context.Background(), reqCtx), recorder
+}
+
+func newQueryRequest(expr string) *backend.QueryDataRequest {
+	now := time.Now()
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID:                      1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "prometheus", JSONData: json.RawMessage(`{}`)},
+		},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			TimeRange: backend.TimeRange{From: now.Add(-time.Hour), To: now},
+			JSON:      json.RawMessage(`{"expr": "` + expr + `"}`),
+		}},
+	}
+}
+
+func newResourceRequest(method, path string) *backend.CallResourceRequest {
+	return &backend.CallResourceRequest{
+		PluginContext: backend.PluginContext{
+			OrgID:                      1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "prometheus", JSONData: json.RawMessage(`{}`)},
+		},
+		Method: method,
+		Path:   path,
+		URL:    path,
+	}
+}
+
+func fieldValues(t *testing.T, field *data.Field) []float64 {
+	t.Helper()
+	values := make([]float64, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		v, ok := field.ConcreteAt(i)
+		require.True(t, ok)
+		values = append(values, v.(float64))
+	}
+	return values
+}
+
+type failingBackend struct{}
+
+func (b *failingBackend) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+func (b *failingBackend) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("unavailable")
+}
+
+func (b *failingBackend) Delete(context.Context, string) error {
+	return errors.New("unavailable")
+}
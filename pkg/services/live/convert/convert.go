@@ -5,35 +5,75 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
 	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
 )
 
+// Supported input formats.
+const (
+	InputFormatInflux = "influx"
+	// InputFormatOTLP is the protobuf encoding of OTLP/HTTP metrics.
+	InputFormatOTLP = "otlp"
+	// InputFormatOTLPJSON is the JSON encoding of OTLP/HTTP metrics.
+	InputFormatOTLPJSON = "otlp_json"
+	// InputFormatPrometheus is the Prometheus text exposition format.
+	InputFormatPrometheus = "prometheus"
+)
+
+type frameFormatConverters struct {
+	wide         telemetry.Converter
+	labelsColumn telemetry.Converter
+}
+
 type Converter struct {
-	telegrafConverterWide         *telegraf.Converter
-	telegrafConverterLabelsColumn *telegraf.Converter
+	converters map[string]frameFormatConverters
 }
 
 func NewConverter() *Converter {
 	return &Converter{
-		telegrafConverterWide: telegraf.NewConverter(
-			telegraf.WithFloat64Numbers(true),
-		),
-		telegrafConverterLabelsColumn: telegraf.NewConverter(
-			telegraf.WithUseLabelsColumn(true),
-			telegraf.WithFloat64Numbers(true),
-		),
+		converters: map[string]frameFormatConverters{
+			InputFormatInflux: {
+				wide: telegraf.NewConverter(
+					telegraf.WithFloat64Numbers(true),
+				),
+				labelsColumn: telegraf.NewConverter(
+					telegraf.WithUseLabelsColumn(true),
+					telegraf.WithFloat64Numbers(true),
+				),
+			},
+			InputFormatOTLP: {
+				wide:         otlp.NewConverter(),
+				labelsColumn: otlp.NewConverter(otlp.WithUseLabelsColumn(true)),
+			},
+			InputFormatOTLPJSON: {
+				wide:         otlp.NewConverter(otlp.WithJSON(true)),
+				labelsColumn: otlp.NewConverter(otlp.WithJSON(true), otlp.WithUseLabelsColumn(true)),
+			},
+			InputFormatPrometheus: {
+				wide:         prometheus.NewConverter(),
+				labelsColumn: prometheus.NewConverter(prometheus.WithUseLabelsColumn(true)),
+			},
+		},
 	}
 }
 
-var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+var (
+	ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+	ErrUnsupportedInputFormat = errors.New("unsupported input format")
+)
 
-func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
+func (c *Converter) Convert(data []byte, inputFormat string, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	converters, ok := c.converters[inputFormat]
+	if !ok {
+		return nil, ErrUnsupportedInputFormat
+	}
 	var converter telemetry.Converter
 	switch frameFormat {
 	case "wide":
-		converter = c.telegrafConverterWide
+		converter = converters.wide
 	case "labels_column":
-		converter = c.telegrafConverterLabelsColumn
+		converter = converters.labelsColumn
 	default:
 		return nil, ErrUnsupportedFrameFormat
 	}
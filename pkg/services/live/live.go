@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -148,17 +149,21 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	frameHistory := managedstream.FrameHistory{
+		Size:   g.Cfg.LiveFrameHistorySize,
+		MaxAge: g.Cfg.LiveFrameHistoryMaxAge,
+	}
 	if redisClient != nil {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient),
+			managedstream.NewRedisFrameCache(redisClient, frameHistory),
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
+			managedstream.NewMemoryFrameCache(frameHistory),
 		)
 	}
 
@@ -463,6 +468,12 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.Pipeline != nil {
+		eGroup.Go(func() error {
+			return g.Pipeline.Run(eCtx)
+		})
+	}
+
 	return eGroup.Wait()
 }
 
@@ -1113,6 +1124,21 @@ func (s *DryRunRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]pipe
 	return s.ChannelRules, nil
 }
 
+// newRuleBuilder returns a builder of pipeline rules from the storage. Every
+// builder of the pipeline is created here, so rules are built with the same
+// outputs.
+func (g *GrafanaLive) newRuleBuilder(storage pipeline.Storage) *pipeline.StorageRuleBuilder {
+	return &pipeline.StorageRuleBuilder{
+		Node:                 g.node,
+		ManagedStream:        g.ManagedStreamRunner,
+		FrameStorage:         pipeline.NewFrameStorage(),
+		Storage:              storage,
+		ChannelHandlerGetter: g,
+		SecretsService:       g.SecretsService,
+		FileOutputPath:       filepath.Join(g.Cfg.DataPath, "live", "outputs"),
+	}
+}
+
 // HandlePipelineConvertTestHTTP ...
 func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext) response.Response {
 	body, err := io.ReadAll(c.Req.Body)
@@ -1127,14 +1153,7 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 	storage := &DryRunRuleStorage{
 		ChannelRules: req.ChannelRules,
 	}
-	builder := &pipeline.StorageRuleBuilder{
-		Node:                 g.node,
-		ManagedStream:        g.ManagedStreamRunner,
-		FrameStorage:         pipeline.NewFrameStorage(),
-		Storage:              storage,
-		ChannelHandlerGetter: g,
-	}
-	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
+	channelRuleGetter := pipeline.NewCacheSegmentedTree(g.newRuleBuilder(storage))
 	pipe, err := pipeline.New(channelRuleGetter)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Error creating pipeline", err)
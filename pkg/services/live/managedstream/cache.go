@@ -3,6 +3,7 @@ package managedstream
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -13,6 +14,67 @@ type FrameCache interface {
 	GetActiveChannels(orgID int64) (map[string]json.RawMessage, error)
 	// GetFrame returns full JSON frame for a channel in org.
 	GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error)
+	// GetHistory returns the frames kept for a channel in org concatenated into
+	// a single full JSON frame.
+	GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error)
 	// Update updates frame cache and returns true if schema changed.
 	Update(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error)
 }
+
+// FrameHistory configures the frames kept per channel.
+type FrameHistory struct {
+	// Size is the number of frames kept. 1 keeps only the last frame.
+	Size int
+	// MaxAge is the age of the oldest frame returned. Zero value means no limit.
+	MaxAge time.Duration
+}
+
+func (h FrameHistory) enabled() bool {
+	return h.Size > 1
+}
+
+// minTime returns the time of the oldest frame that is returned at now.
+func (h FrameHistory) minTime(now time.Time) time.Time {
+	if h.MaxAge <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-h.MaxAge)
+}
+
+// concatFrames appends the rows of the JSON frames to the first one. Frames
+// are expected to have the same schema, if a frame has different fields the
+// frames before it are dropped.
+func concatFrames(frames []json.RawMessage) (json.RawMessage, error) {
+	var result *data.Frame
+	for _, frameJSON := range frames {
+		var frame data.Frame
+		if err := json.Unmarshal(frameJSON, &frame); err != nil {
+			return nil, err
+		}
+		if result == nil || !sameFieldTypes(result, &frame) {
+			result = &frame
+			continue
+		}
+		for i, field := range frame.Fields {
+			for row := 0; row < field.Len(); row++ {
+				result.Fields[i].Append(field.At(row))
+			}
+		}
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return data.FrameToJSON(result, data.IncludeAll)
+}
+
+func sameFieldTypes(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -12,16 +13,27 @@ import (
 
 // MemoryFrameCache ...
 type MemoryFrameCache struct {
-	mu     sync.RWMutex
-	frames map[int64]map[string]data.FrameJSONCache
-	log    log.Logger
+	mu      sync.RWMutex
+	frames  map[int64]map[string]data.FrameJSONCache
+	history map[int64]map[string][]historyEntry
+	config  FrameHistory
+	now     func() time.Time
+	log     log.Logger
+}
+
+type historyEntry struct {
+	time  time.Time
+	frame json.RawMessage
 }
 
 // NewMemoryFrameCache ...
-func NewMemoryFrameCache() *MemoryFrameCache {
+func NewMemoryFrameCache(history FrameHistory) *MemoryFrameCache {
 	return &MemoryFrameCache{
-		frames: map[int64]map[string]data.FrameJSONCache{},
-		log:    log.New("live.memoryframecache"),
+		frames:  map[int64]map[string]data.FrameJSONCache{},
+		history: map[int64]map[string][]historyEntry{},
+		config:  history,
+		now:     time.Now,
+		log:     log.New("live.memoryframecache"),
 	}
 }
 
@@ -52,6 +64,36 @@ func (c *MemoryFrameCache) GetFrame(ctx context.Context, orgID int64, channel st
 	return raw, ok, nil
 }
 
+func (c *MemoryFrameCache) GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	if !c.config.enabled() {
+		return c.GetFrame(ctx, orgID, channel)
+	}
+	c.mu.RLock()
+	minTime := c.config.minTime(c.now())
+	frames := make([]json.RawMessage, 0, len(c.history[orgID][channel]))
+	for _, entry := range c.history[orgID][channel] {
+		if !entry.time.Before(minTime) {
+			frames = append(frames, entry.frame)
+		}
+	}
+	c.mu.RUnlock()
+	if len(frames) == 0 {
+		// Every frame is older than the max age, the last frame is still the current value.
+		return c.GetFrame(ctx, orgID, channel)
+	}
+	raw, err := concatFrames(frames)
+	if err != nil {
+		return nil, false, err
+	}
+	c.log.Debug("Cache get history",
+		"orgId", orgID,
+		"channel", channel,
+		"frames", len(frames),
+		"length", len(raw),
+	)
+	return raw, true, nil
+}
+
 func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -61,6 +103,9 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+	if c.config.enabled() {
+		c.updateHistory(orgID, channel, jsonFrame, schemaUpdated)
+	}
 	c.log.Debug("Cache update",
 		"orgId", orgID,
 		"channel", channel,
@@ -68,3 +113,34 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	)
 	return schemaUpdated, nil
 }
+
+// updateHistory appends the frame to the history of the channel. Frames with
+// a different schema can't be concatenated, so the history is reset if the
+// schema changed. Entries are sorted by time, old entries are removed from the
+// front in place so that the slice is reused.
+func (c *MemoryFrameCache) updateHistory(orgID int64, channel string, jsonFrame data.FrameJSONCache, schemaUpdated bool) {
+	if _, ok := c.history[orgID]; !ok {
+		c.history[orgID] = map[string][]historyEntry{}
+	}
+	now := c.now()
+	entries := c.history[orgID][channel]
+	expired := len(entries)
+	if !schemaUpdated {
+		minTime := c.config.minTime(now)
+		expired = 0
+		for expired < len(entries) && entries[expired].time.Before(minTime) {
+			expired++
+		}
+	}
+	// Keep room for the new frame.
+	if kept := len(entries) - expired; kept >= c.config.Size {
+		expired += kept - c.config.Size + 1
+	}
+	if expired > 0 {
+		n := copy(entries, entries[expired:])
+		// Release the frames of the removed entries.
+		clear(entries[n:])
+		entries = entries[:n]
+	}
+	c.history[orgID][channel] = append(entries, historyEntry{time: now, frame: jsonFrame.Bytes(data.IncludeAll)})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -63,7 +64,90 @@ func testFrameCache(t *testing.T, c FrameCache) {
 }
 
 func TestMemoryFrameCache(t *testing.T) {
-	c := NewMemoryFrameCache()
+	c := NewMemoryFrameCache(FrameHistory{Size: 1})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func testFrameCacheHistory(t *testing.T, c FrameCache, setNow func(time.Time)) {
+	ctx := context.Background()
+	now := time.Now()
+	push := func(values ...float64) {
+		t.Helper()
+		setNow(now)
+		frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("cpu", data.NewField("value", nil, values)))
+		require.NoError(t, err)
+		_, err = c.Update(ctx, 1, "test", frameJsonCache)
+		require.NoError(t, err)
+	}
+	history := func() []float64 {
+		t.Helper()
+		frameJSON, ok, err := c.GetHistory(ctx, 1, "test")
+		require.NoError(t, err)
+		if !ok {
+			return nil
+		}
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(frameJSON, &f))
+		values := make([]float64, 0, f.Rows())
+		for i := 0; i < f.Rows(); i++ {
+			values = append(values, f.Fields[0].At(i).(float64))
+		}
+		return values
+	}
+
+	require.Nil(t, history())
+
+	// Only the last frames are kept.
+	push(1)
+	push(2, 3)
+	push(4)
+	push(5)
+	require.Equal(t, []float64{2, 3, 4, 5}, history())
+
+	// Frames with another schema can't be concatenated.
+	setNow(now)
+	frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("cpu", data.NewField("other", nil, []string{"a"})))
+	require.NoError(t, err)
+	_, err = c.Update(ctx, 1, "test", frameJsonCache)
+	require.NoError(t, err)
+	push(6)
+	require.Equal(t, []float64{6}, history())
+
+	// Old frames are not returned.
+	now = now.Add(30 * time.Second)
+	push(7)
+	now = now.Add(45 * time.Second)
+	setNow(now)
+	require.Equal(t, []float64{7}, history())
+
+	// The last frame is returned when every frame is older than the max age.
+	now = now.Add(time.Minute)
+	setNow(now)
+	require.Equal(t, []float64{7}, history())
+
+	// The last frame is still available.
+	frameJSON, ok, err := c.GetFrame(ctx, 1, "test")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Contains(t, string(frameJSON), "value")
+}
+
+func TestMemoryFrameCacheHistory(t *testing.T) {
+	c := NewMemoryFrameCache(FrameHistory{Size: 3, MaxAge: time.Minute})
+	testFrameCacheHistory(t, c, func(now time.Time) { c.now = func() time.Time { return now } })
+
+	// The history is trimmed in place.
+	frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("cpu", data.NewField("value", nil, []float64{1})))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = c.Update(context.Background(), 1, "test", frameJsonCache)
+		require.NoError(t, err)
+	}
+	entries := c.history[1]["test"]
+	require.Len(t, entries, 3)
+	_, err = c.Update(context.Background(), 1, "test", frameJsonCache)
+	require.NoError(t, err)
+	require.Len(t, c.history[1]["test"], 3)
+	require.Same(t, &entries[0], &c.history[1]["test"][0])
+}
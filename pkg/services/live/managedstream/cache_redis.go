@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"hash/fnv"
+	"strconv"
 	"sync"
 	"time"
 
@@ -18,13 +20,17 @@ type RedisFrameCache struct {
 	mu          sync.RWMutex
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
+	history     FrameHistory
+	now         func() time.Time
 }
 
 // NewRedisFrameCache ...
-func NewRedisFrameCache(redisClient *redis.Client) *RedisFrameCache {
+func NewRedisFrameCache(redisClient *redis.Client, history FrameHistory) *RedisFrameCache {
 	return &RedisFrameCache{
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		history:     history,
+		now:         time.Now,
 	}
 }
 
@@ -55,6 +61,51 @@ func (c *RedisFrameCache) GetFrame(ctx context.Context, orgID int64, channel str
 	return json.RawMessage(result["frame"]), true, nil
 }
 
+// redisHistoryEntry is a frame in the history list of a channel. The schema
+// hash allows to skip frames that were pushed before the schema changed.
+type redisHistoryEntry struct {
+	Time   int64           `json:"time"`
+	Schema string          `json:"schema"`
+	Frame  json.RawMessage `json:"frame"`
+}
+
+func (c *RedisFrameCache) GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	if !c.history.enabled() {
+		return c.GetFrame(ctx, orgID, channel)
+	}
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	result, err := c.redisClient.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	minTime := c.history.minTime(c.now()).UnixMilli()
+	var frames []json.RawMessage
+	var schema string
+	// Walk from the latest frame back to the first one with another schema.
+	for i := len(result) - 1; i >= 0; i-- {
+		var entry redisHistoryEntry
+		if err := json.Unmarshal([]byte(result[i]), &entry); err != nil {
+			return nil, false, err
+		}
+		if i == len(result)-1 {
+			schema = entry.Schema
+		}
+		if entry.Schema != schema || entry.Time < minTime {
+			break
+		}
+		frames = append([]json.RawMessage{entry.Frame}, frames...)
+	}
+	if len(frames) == 0 {
+		// Every frame is older than the max age, the last frame is still the current value.
+		return c.GetFrame(ctx, orgID, channel)
+	}
+	raw, err := concatFrames(frames)
+	if err != nil {
+		return nil, false, err
+	}
+	return raw, true, nil
+}
+
 const (
 	frameCacheTTL = 7 * 24 * time.Hour
 )
@@ -80,6 +131,24 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 		"frame":  string(jsonFrame.Bytes(data.IncludeAll)),
 	})
 	pipe.Expire(ctx, key, frameCacheTTL)
+	if c.history.enabled() {
+		entry, err := json.Marshal(redisHistoryEntry{
+			Time:   c.now().UnixMilli(),
+			Schema: schemaHash(stringSchema),
+			Frame:  jsonFrame.Bytes(data.IncludeAll),
+		})
+		if err != nil {
+			return false, err
+		}
+		historyKey := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+		historyTTL := frameCacheTTL
+		if c.history.MaxAge > 0 {
+			historyTTL = c.history.MaxAge
+		}
+		pipe.RPush(ctx, historyKey, entry)
+		pipe.LTrim(ctx, historyKey, int64(-c.history.Size), -1)
+		pipe.Expire(ctx, historyKey, historyTTL)
+	}
 
 	replies, err := pipe.Exec(ctx)
 	if err != nil {
@@ -110,3 +179,13 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 func getCacheKey(channelID string) string {
 	return "gf_live.managed_stream." + channelID
 }
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream_history." + channelID
+}
+
+func schemaHash(schema string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(schema))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
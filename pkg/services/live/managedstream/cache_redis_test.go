@@ -1,9 +1,12 @@
 package managedstream
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
 )
@@ -30,7 +33,27 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 		Addr: addr,
 		DB:   db,
 	})
-	c := NewRedisFrameCache(redisClient)
+	c := NewRedisFrameCache(redisClient, FrameHistory{Size: 1})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestRedisFrameCacheHistory(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	c := NewRedisFrameCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), FrameHistory{Size: 3, MaxAge: time.Minute})
+	testFrameCacheHistory(t, c, func(now time.Time) { c.now = func() time.Time { return now } })
+
+	// Another replica serves the same history.
+	replica := NewRedisFrameCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), FrameHistory{Size: 3, MaxAge: time.Minute})
+	replica.now = c.now
+	expected, ok, err := c.GetHistory(context.Background(), 1, "test")
+	require.NoError(t, err)
+	require.True(t, ok)
+	actual, ok, err := replica.GetHistory(context.Background(), 1, "test")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.JSONEq(t, string(expected), string(actual))
+}
@@ -40,6 +40,8 @@ type ConverterConfig struct {
 	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
 	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
 	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	OTLPConverterConfig       *OTLPConverterConfig       `json:"otlp,omitempty"`
+	PrometheusConverterConfig *PrometheusConverterConfig `json:"prometheus,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -50,11 +52,62 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+// AggregateFunction is a function to aggregate numeric field values in a window.
+type AggregateFunction string
+
+// Known AggregateFunction types.
+const (
+	AggregateFunctionMin  AggregateFunction = "min"
+	AggregateFunctionMax  AggregateFunction = "max"
+	AggregateFunctionAvg  AggregateFunction = "avg"
+	AggregateFunctionLast AggregateFunction = "last"
+)
+
+type AggregateFrameProcessorConfig struct {
+	// WindowMilliseconds is the size of tumbling windows.
+	WindowMilliseconds int64 `json:"windowMilliseconds"`
+	// Functions applied to numeric fields, last by default.
+	Functions []AggregateFunction `json:"functions,omitempty"`
+	// FieldFunctions overrides Functions for specific fields.
+	FieldFunctions map[string][]AggregateFunction `json:"fieldFunctions,omitempty"`
+}
+
+// RateMode defines how RateFrameProcessor treats decreasing values.
+type RateMode string
+
+// Known RateMode types.
+const (
+	// RateModeRate treats fields as counters, a decrease is a counter reset.
+	RateModeRate RateMode = "rate"
+	// RateModeDerivative keeps negative changes.
+	RateModeDerivative RateMode = "derivative"
+)
+
+type RateFrameProcessorConfig struct {
+	// FieldNames to compute per-second rate for, all numeric fields if empty.
+	FieldNames []string `json:"fieldNames,omitempty"`
+	Mode       RateMode `json:"mode,omitempty"`
+}
+
+type ConvertFieldConfig struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName,omitempty"`
+	// Type to convert field values to, the field type is kept if not set.
+	Type data.FieldType `json:"type,omitempty"`
+}
+
+type ConvertFieldsFrameProcessorConfig struct {
+	Fields []ConvertFieldConfig `json:"fields"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                         string                             `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig    *DropFieldsFrameProcessorConfig    `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig    *KeepFieldsFrameProcessorConfig    `json:"keepFields,omitempty"`
+	MultipleProcessorConfig      *MultipleFrameProcessorConfig      `json:"multiple,omitempty"`
+	AggregateProcessorConfig     *AggregateFrameProcessorConfig     `json:"aggregate,omitempty"`
+	RateProcessorConfig          *RateFrameProcessorConfig          `json:"rate,omitempty"`
+	ConvertFieldsProcessorConfig *ConvertFieldsFrameProcessorConfig `json:"convertFields,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -79,6 +132,56 @@ type LokiOutputConfig struct {
 	UID string `json:"uid"`
 }
 
+// FrameOutputBatchConfig configures queueing and batching of outputs to
+// external systems.
+type FrameOutputBatchConfig struct {
+	// MaxSize is the max number of frames in a batch, 100 by default.
+	MaxSize int `json:"maxSize,omitempty"`
+	// FlushMilliseconds is the max time frames wait in a batch, 1000 by default.
+	FlushMilliseconds int64 `json:"flushMilliseconds,omitempty"`
+	// QueueSize is the max number of queued frames, 10000 by default. Frames
+	// are rejected when the queue is full.
+	QueueSize int `json:"queueSize,omitempty"`
+	// MaxRetries of a failed batch before it's dropped, 3 by default.
+	MaxRetries int `json:"maxRetries,omitempty"`
+}
+
+type KafkaOutputConfig struct {
+	// UID of a write config with comma separated list of brokers as endpoint.
+	UID   string                  `json:"uid"`
+	Topic string                  `json:"topic"`
+	Batch *FrameOutputBatchConfig `json:"batch,omitempty"`
+}
+
+type MQTTOutputConfig struct {
+	// UID of a write config with broker URL as endpoint.
+	UID    string                  `json:"uid"`
+	Topic  string                  `json:"topic"`
+	QoS    byte                    `json:"qos,omitempty"`
+	Retain bool                    `json:"retain,omitempty"`
+	Batch  *FrameOutputBatchConfig `json:"batch,omitempty"`
+}
+
+// FileOutputFormat is a format of files written by FileFrameOutput.
+type FileOutputFormat string
+
+// Known FileOutputFormat types.
+const (
+	FileOutputFormatNDJSON  FileOutputFormat = "ndjson"
+	FileOutputFormatParquet FileOutputFormat = "parquet"
+)
+
+type FileOutputConfig struct {
+	// Path is a directory relative to the Live outputs directory of the org.
+	Path   string           `json:"path"`
+	Format FileOutputFormat `json:"format,omitempty"`
+	// RotateMilliseconds is the max time a file is written to, 1 hour by default.
+	RotateMilliseconds int64 `json:"rotateMilliseconds,omitempty"`
+	// MaxFileBytes rotates a file when it grows larger, not limited by default.
+	MaxFileBytes int64                   `json:"maxFileBytes,omitempty"`
+	Batch        *FrameOutputBatchConfig `json:"batch,omitempty"`
+}
+
 type MultipleSubscriberConfig struct {
 	Subscribers []SubscriberConfig `json:"subscribers"`
 }
@@ -109,6 +212,9 @@ type FrameOutputterConfig struct {
 	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
 	LokiOutputConfig        *LokiOutputConfig          `json:"loki,omitempty"`
 	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	KafkaOutputConfig       *KafkaOutputConfig         `json:"kafka,omitempty"`
+	MQTTOutputConfig        *MQTTOutputConfig          `json:"mqtt,omitempty"`
+	FileOutputConfig        *FileOutputConfig          `json:"file,omitempty"`
 }
 
 type MultipleFrameConditionCheckerConfig struct {
@@ -152,4 +258,16 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+// OTLPConverterConfig ...
+type OTLPConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+	// Encoding of the OTLP/HTTP request, protobuf (default) or json.
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// PrometheusConverterConfig ...
+type PrometheusConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+}
+
 type ManagedStreamOutputConfig struct{}
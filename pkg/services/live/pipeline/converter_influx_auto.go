@@ -26,7 +26,13 @@ func (c *AutoInfluxConverter) Type() string {
 }
 
 func (c *AutoInfluxConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
-	frameWrappers, err := c.converter.Convert(body, c.config.FrameFormat)
+	return convertMetrics(c.converter, vars, body, convert.InputFormatInflux, c.config.FrameFormat)
+}
+
+// convertMetrics converts metrics in the input format to a ChannelFrame per
+// metric name.
+func convertMetrics(converter *convert.Converter, vars Vars, body []byte, inputFormat string, frameFormat string) ([]*ChannelFrame, error) {
+	frameWrappers, err := converter.Convert(body, inputFormat, frameFormat)
 	if err != nil {
 		return nil, err
 	}
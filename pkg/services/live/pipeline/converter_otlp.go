@@ -0,0 +1,34 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+)
+
+// OTLPConverter decodes OTLP/HTTP metrics and transforms them to several
+// ChannelFrame objects where Channel is constructed from original channel +
+// / + <metric_name>. Resource attributes become labels.
+type OTLPConverter struct {
+	config    OTLPConverterConfig
+	converter *convert.Converter
+}
+
+// NewOTLPConverter creates new OTLPConverter.
+func NewOTLPConverter(config OTLPConverterConfig) *OTLPConverter {
+	return &OTLPConverter{config: config, converter: convert.NewConverter()}
+}
+
+const ConverterTypeOTLP = "otlp"
+
+func (c *OTLPConverter) Type() string {
+	return ConverterTypeOTLP
+}
+
+func (c *OTLPConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	inputFormat := convert.InputFormatOTLP
+	if c.config.Encoding == "json" {
+		inputFormat = convert.InputFormatOTLPJSON
+	}
+	return convertMetrics(c.converter, vars, body, inputFormat, c.config.FrameFormat)
+}
@@ -0,0 +1,30 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+)
+
+// PrometheusConverter decodes the Prometheus text exposition format and
+// transforms it to several ChannelFrame objects where Channel is constructed
+// from original channel + / + <metric_name>.
+type PrometheusConverter struct {
+	config    PrometheusConverterConfig
+	converter *convert.Converter
+}
+
+// NewPrometheusConverter creates new PrometheusConverter.
+func NewPrometheusConverter(config PrometheusConverterConfig) *PrometheusConverter {
+	return &PrometheusConverter{config: config, converter: convert.NewConverter()}
+}
+
+const ConverterTypePrometheus = "prometheus"
+
+func (c *PrometheusConverter) Type() string {
+	return ConverterTypePrometheus
+}
+
+func (c *PrometheusConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	return convertMetrics(c.converter, vars, body, convert.InputFormatPrometheus, c.config.FrameFormat)
+}
@@ -0,0 +1,283 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	outputFramesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_pipeline",
+		Name:      "output_frames_total",
+		Help:      "Number of frames processed by batching frame outputs by status: sent, failed or dropped.",
+	}, []string{"output", "status"})
+	outputRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_pipeline",
+		Name:      "output_retries_total",
+		Help:      "Number of retried batch writes of batching frame outputs.",
+	}, []string{"output"})
+	outputQueueLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "live_pipeline",
+		Name:      "output_queue_length",
+		Help:      "Number of frames waiting in queues of batching frame outputs.",
+	}, []string{"output"})
+)
+
+const (
+	defaultOutputBatchSize          = 100
+	defaultOutputFlushInterval      = time.Second
+	defaultOutputQueueSize          = 10000
+	defaultOutputMaxRetries         = 3
+	outputWriteTimeout              = 10 * time.Second
+	outputRetryBackoff              = 500 * time.Millisecond
+	outputSinkIdleTimeout           = time.Minute
+	outputSinkCloseTimeout          = 30 * time.Second
+	outputSinkCleanupCheckFrequency = 10 * time.Second
+)
+
+var (
+	errOutputQueueFull = errors.New("output queue is full")
+	errOutputClosed    = errors.New("output is closed")
+)
+
+type outputMessage struct {
+	vars  Vars
+	frame *data.Frame
+}
+
+// frameOutputWriter writes batches of frames to an external system. Methods
+// are called from a single goroutine. Writers which wrote a part of a batch
+// return outputWriteError, so only the rest of the batch is retried.
+type frameOutputWriter interface {
+	WriteBatch(ctx context.Context, batch []outputMessage) error
+	Close() error
+}
+
+// outputWriteError is an error of a batch write with the messages of the batch
+// which were not written.
+type outputWriteError struct {
+	unwritten []outputMessage
+	err       error
+}
+
+func (e *outputWriteError) Error() string {
+	return e.err.Error()
+}
+
+func (e *outputWriteError) Unwrap() error {
+	return e.err
+}
+
+// frameOutputSink queues frames and writes them in batches with a writer from
+// a background goroutine. Failed batches are retried, frames are rejected when
+// the queue is full so publishers see backpressure.
+type frameOutputSink struct {
+	outputType   string
+	config       FrameOutputBatchConfig
+	writer       frameOutputWriter
+	retryBackoff time.Duration
+
+	queue     chan outputMessage
+	startOnce sync.Once
+	closeOnce sync.Once
+	done      chan struct{}
+	stopped   chan struct{}
+	lastUsed  time.Time
+}
+
+func newFrameOutputSink(outputType string, config *FrameOutputBatchConfig, writer frameOutputWriter) *frameOutputSink {
+	c := FrameOutputBatchConfig{}
+	if config != nil {
+		c = *config
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = defaultOutputBatchSize
+	}
+	if c.FlushMilliseconds <= 0 {
+		c.FlushMilliseconds = defaultOutputFlushInterval.Milliseconds()
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultOutputQueueSize
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = defaultOutputMaxRetries
+	}
+	return &frameOutputSink{
+		outputType:   outputType,
+		config:       c,
+		writer:       writer,
+		retryBackoff: outputRetryBackoff,
+		queue:        make(chan outputMessage, c.QueueSize),
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+}
+
+func (s *frameOutputSink) enqueue(vars Vars, frame *data.Frame) error {
+	select {
+	case <-s.done:
+		return errOutputClosed
+	default:
+	}
+	// Writers are started lazily, rules are also built for dry runs.
+	s.startOnce.Do(func() { go s.run() })
+	select {
+	case s.queue <- outputMessage{vars: vars, frame: frame}:
+		outputQueueLength.WithLabelValues(s.outputType).Inc()
+		return nil
+	default:
+		outputFramesTotal.WithLabelValues(s.outputType, "dropped").Inc()
+		return errOutputQueueFull
+	}
+}
+
+func (s *frameOutputSink) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(time.Duration(s.config.FlushMilliseconds) * time.Millisecond)
+	defer ticker.Stop()
+
+	var batch []outputMessage
+	add := func(msg outputMessage) {
+		outputQueueLength.WithLabelValues(s.outputType).Dec()
+		batch = append(batch, msg)
+		if len(batch) >= s.config.MaxSize {
+			s.write(batch)
+			batch = nil
+		}
+	}
+	for {
+		select {
+		case msg := <-s.queue:
+			add(msg)
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.write(batch)
+				batch = nil
+			}
+		case <-s.done:
+			for len(s.queue) > 0 {
+				add(<-s.queue)
+			}
+			if len(batch) > 0 {
+				s.write(batch)
+			}
+			if err := s.writer.Close(); err != nil {
+				logger.Error("Error closing frame output", "output", s.outputType, "error", err)
+			}
+			return
+		}
+	}
+}
+
+func (s *frameOutputSink) write(batch []outputMessage) {
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), outputWriteTimeout)
+		err := s.writer.WriteBatch(ctx, batch)
+		cancel()
+		if err == nil {
+			outputFramesTotal.WithLabelValues(s.outputType, "sent").Add(float64(len(batch)))
+			return
+		}
+		var writeErr *outputWriteError
+		if errors.As(err, &writeErr) {
+			outputFramesTotal.WithLabelValues(s.outputType, "sent").Add(float64(len(batch) - len(writeErr.unwritten)))
+			batch = writeErr.unwritten
+		}
+		if attempt >= s.config.MaxRetries {
+			logger.Error("Error writing frames, dropping batch", "output", s.outputType, "numFrames", len(batch), "error", err)
+			outputFramesTotal.WithLabelValues(s.outputType, "failed").Add(float64(len(batch)))
+			return
+		}
+		logger.Warn("Error writing frames, retrying", "output", s.outputType, "attempt", attempt+1, "error", err)
+		outputRetriesTotal.WithLabelValues(s.outputType).Inc()
+		time.Sleep(s.retryBackoff << attempt)
+	}
+}
+
+// close flushes queued frames and closes the writer.
+func (s *frameOutputSink) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.startOnce.Do(func() { go s.run() })
+	})
+	select {
+	case <-s.stopped:
+	case <-time.After(outputSinkCloseTimeout):
+		logger.Warn("Timeout closing frame output", "output", s.outputType)
+	}
+}
+
+// frameOutputSinks keeps sinks between rule builds. Rules are rebuilt
+// periodically while sinks hold connections, open files and queued frames.
+// Sinks which are not used by the rules of the last successful build of their
+// org belong to removed or changed rules and are closed once they were not
+// requested for a while. Rules of failed builds are discarded, so their sinks
+// are not kept either.
+type frameOutputSinks struct {
+	mu        sync.Mutex
+	sinks     map[string]*frameOutputSink
+	used      map[int64]map[string]struct{}
+	lastCheck time.Time
+}
+
+func (s *frameOutputSinks) get(key string, create func() (*frameOutputSink, error)) (*frameOutputSink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sinks == nil {
+		s.sinks = map[string]*frameOutputSink{}
+	}
+	now := time.Now()
+	if now.Sub(s.lastCheck) > outputSinkCleanupCheckFrequency {
+		s.lastCheck = now
+		s.closeUnused(now, key)
+	}
+	sink, ok := s.sinks[key]
+	if !ok {
+		var err error
+		sink, err = create()
+		if err != nil {
+			return nil, err
+		}
+		s.sinks[key] = sink
+	}
+	sink.lastUsed = now
+	return sink, nil
+}
+
+// setUsed sets the sinks used by the rules of a successful build of the org.
+func (s *frameOutputSinks) setUsed(orgID int64, keys map[string]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used == nil {
+		s.used = map[int64]map[string]struct{}{}
+	}
+	s.used[orgID] = keys
+}
+
+func (s *frameOutputSinks) closeUnused(now time.Time, requested string) {
+	for k, sink := range s.sinks {
+		if k == requested || now.Sub(sink.lastUsed) <= outputSinkIdleTimeout || s.isUsed(k) {
+			continue
+		}
+		delete(s.sinks, k)
+		go sink.close()
+	}
+}
+
+func (s *frameOutputSinks) isUsed(key string) bool {
+	for _, keys := range s.used {
+		if _, ok := keys[key]; ok {
+			return true
+		}
+	}
+	return false
+}
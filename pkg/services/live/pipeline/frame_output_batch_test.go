@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+type testOutputWriter struct {
+	mu       sync.Mutex
+	failures int
+	// partial is the number of messages written by failed writes.
+	partial int
+	block   chan struct{}
+	batches [][]outputMessage
+	closed  bool
+}
+
+func (w *testOutputWriter) WriteBatch(_ context.Context, batch []outputMessage) error {
+	if w.block != nil {
+		<-w.block
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		if w.partial > 0 {
+			w.batches = append(w.batches, batch[:w.partial])
+			return &outputWriteError{unwritten: batch[w.partial:], err: errors.New("boom")}
+		}
+		return errors.New("boom")
+	}
+	w.batches = append(w.batches, batch)
+	return nil
+}
+
+func (w *testOutputWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func (w *testOutputWriter) batchSizes() []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var sizes []int
+	for _, b := range w.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func TestFrameOutputSink_Batching(t *testing.T) {
+	writer := &testOutputWriter{failures: 2}
+	sink := newFrameOutputSink("test", &FrameOutputBatchConfig{MaxSize: 3, FlushMilliseconds: 50}, writer)
+	sink.retryBackoff = time.Millisecond
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, sink.enqueue(Vars{OrgID: 1, Channel: "stream/test/xxx"}, data.NewFrame("test")))
+	}
+	// Full batch is written after retries, the rest after flush interval.
+	require.Eventually(t, func() bool {
+		sizes := writer.batchSizes()
+		return len(sizes) == 2 && sizes[0] == 3 && sizes[1] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, sink.enqueue(Vars{OrgID: 1, Channel: "stream/test/xxx"}, data.NewFrame("test")))
+	sink.close()
+	require.Equal(t, []int{3, 1, 1}, writer.batchSizes())
+	require.True(t, writer.closed)
+	require.ErrorIs(t, sink.enqueue(Vars{}, data.NewFrame("test")), errOutputClosed)
+}
+
+func TestFrameOutputSink_PartialRetry(t *testing.T) {
+	writer := &testOutputWriter{failures: 1, partial: 1}
+	sink := newFrameOutputSink("test", &FrameOutputBatchConfig{MaxSize: 3}, writer)
+	sink.retryBackoff = time.Millisecond
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sink.enqueue(Vars{}, data.NewFrame("test")))
+	}
+	sink.close()
+	// Only frames which were not written are retried.
+	require.Equal(t, []int{1, 2}, writer.batchSizes())
+}
+
+func TestFrameOutputSink_QueueFull(t *testing.T) {
+	writer := &testOutputWriter{block: make(chan struct{})}
+	sink := newFrameOutputSink("test", &FrameOutputBatchConfig{MaxSize: 1, QueueSize: 1}, writer)
+
+	// First frame is taken by the blocked writer, second one waits in the queue.
+	require.NoError(t, sink.enqueue(Vars{}, data.NewFrame("test")))
+	require.Eventually(t, func() bool { return len(sink.queue) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, sink.enqueue(Vars{}, data.NewFrame("test")))
+	require.ErrorIs(t, sink.enqueue(Vars{}, data.NewFrame("test")), errOutputQueueFull)
+
+	close(writer.block)
+	sink.close()
+	require.Equal(t, []int{1, 1}, writer.batchSizes())
+}
+
+func TestFrameOutputSinks(t *testing.T) {
+	var sinks frameOutputSinks
+	created := 0
+	create := func() (*frameOutputSink, error) {
+		created++
+		return newFrameOutputSink("test", nil, &testOutputWriter{}), nil
+	}
+	first, err := sinks.get("a", create)
+	require.NoError(t, err)
+	second, err := sinks.get("a", create)
+	require.NoError(t, err)
+	require.Same(t, first, second)
+	require.Equal(t, 1, created)
+
+	_, err = sinks.get("b", func() (*frameOutputSink, error) { return nil, errors.New("boom") })
+	require.Error(t, err)
+}
+
+func TestFrameOutputSinks_CloseUnused(t *testing.T) {
+	var sinks frameOutputSinks
+	create := func() (*frameOutputSink, error) {
+		return newFrameOutputSink("test", nil, &testOutputWriter{}), nil
+	}
+	used, err := sinks.get("used", create)
+	require.NoError(t, err)
+	unused, err := sinks.get("unused", create)
+	require.NoError(t, err)
+	sinks.setUsed(1, map[string]struct{}{"used": {}})
+
+	// Rules of the last successful build still hold the used sink after a failed build.
+	now := time.Now().Add(2 * outputSinkIdleTimeout)
+	sinks.mu.Lock()
+	sinks.closeUnused(now, "")
+	sinks.mu.Unlock()
+	require.Eventually(t, func() bool {
+		select {
+		case <-unused.stopped:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	require.NoError(t, used.enqueue(Vars{}, data.NewFrame("test")))
+	require.ErrorIs(t, unused.enqueue(Vars{}, data.NewFrame("test")), errOutputClosed)
+
+	same, err := sinks.get("used", create)
+	require.NoError(t, err)
+	require.Same(t, used, same)
+	used.close()
+}
@@ -0,0 +1,265 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/parquet"
+	"github.com/apache/arrow/go/v15/parquet/compress"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const defaultFileRotateInterval = time.Hour
+
+// FileFrameOutput archives frames to rotated files in a directory. NDJSON files
+// contain a line per frame with org, channel and frame JSON. Parquet files
+// contain frame rows without field labels, a new file is started when frame
+// schema changes. Files are written with .tmp suffix which is removed on
+// rotation.
+type FileFrameOutput struct {
+	sink *frameOutputSink
+}
+
+func NewFileFrameOutput(sink *frameOutputSink) *FileFrameOutput {
+	return &FileFrameOutput{sink: sink}
+}
+
+const FrameOutputTypeFile = "file"
+
+func (out *FileFrameOutput) Type() string {
+	return FrameOutputTypeFile
+}
+
+func (out *FileFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	return nil, out.sink.enqueue(vars, frame)
+}
+
+type fileOutputRecord struct {
+	OrgID   int64       `json:"orgId"`
+	Channel string      `json:"channel"`
+	Frame   *data.Frame `json:"frame"`
+}
+
+// countingWriter counts bytes written to a file, it's also not an io.Closer so
+// parquet writer does not close the file.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+type fileOutputWriter struct {
+	dir      string
+	format   FileOutputFormat
+	rotate   time.Duration
+	maxBytes int64
+	now      func() time.Time
+
+	file    *os.File
+	path    string
+	opened  time.Time
+	counter *countingWriter
+	buf     *bufio.Writer
+	pw      *pqarrow.FileWriter
+	schema  *arrow.Schema
+}
+
+func newFileOutputWriter(dir string, config FileOutputConfig) (*fileOutputWriter, error) {
+	format := config.Format
+	switch format {
+	case "":
+		format = FileOutputFormatNDJSON
+	case FileOutputFormatNDJSON, FileOutputFormatParquet:
+	default:
+		return nil, fmt.Errorf("unknown file format: %s", format)
+	}
+	rotate := time.Duration(config.RotateMilliseconds) * time.Millisecond
+	if rotate <= 0 {
+		rotate = defaultFileRotateInterval
+	}
+	return &fileOutputWriter{
+		dir:      dir,
+		format:   format,
+		rotate:   rotate,
+		maxBytes: config.MaxFileBytes,
+		now:      time.Now,
+	}, nil
+}
+
+func (w *fileOutputWriter) WriteBatch(_ context.Context, batch []outputMessage) error {
+	for i, msg := range batch {
+		var err error
+		if w.format == FileOutputFormatParquet {
+			err = w.writeParquet(msg.frame)
+		} else {
+			err = w.writeNDJSON(msg)
+		}
+		if err != nil {
+			if err := w.flush(); err != nil {
+				return err
+			}
+			return &outputWriteError{unwritten: batch[i:], err: err}
+		}
+	}
+	return w.flush()
+}
+
+// flush writes buffered lines to the file. A file which failed to be written
+// may end with a partial line, it keeps the .tmp suffix and the next write
+// opens a new file.
+func (w *fileOutputWriter) flush() error {
+	if w.buf != nil {
+		if err := w.buf.Flush(); err != nil {
+			_ = w.file.Close()
+			w.file, w.counter, w.buf = nil, nil, nil
+			return err
+		}
+	}
+	if w.pw != nil {
+		// Row group per batch keeps buffered rows bounded.
+		if rows, err := w.pw.RowGroupNumRows(); err == nil && rows > 0 {
+			w.pw.NewBufferedRowGroup()
+		}
+	}
+	return nil
+}
+
+func (w *fileOutputWriter) needsRotation() bool {
+	if w.file == nil {
+		return false
+	}
+	if w.now().Sub(w.opened) >= w.rotate {
+		return true
+	}
+	return w.maxBytes > 0 && w.size() >= w.maxBytes
+}
+
+func (w *fileOutputWriter) size() int64 {
+	if w.buf != nil {
+		return w.counter.n + int64(w.buf.Buffered())
+	}
+	return w.counter.n
+}
+
+func (w *fileOutputWriter) open() error {
+	if err := os.MkdirAll(w.dir, 0750); err != nil {
+		return err
+	}
+	w.opened = w.now()
+	name := w.opened.UTC().Format("20060102T150405.000000000Z") + "." + string(w.format)
+	w.path = filepath.Join(w.dir, name)
+	// nolint:gosec
+	file, err := os.OpenFile(w.path+".tmp", os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0640)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.counter = &countingWriter{w: file}
+	return nil
+}
+
+func (w *fileOutputWriter) writeNDJSON(msg outputMessage) error {
+	line, err := json.Marshal(fileOutputRecord{OrgID: msg.vars.OrgID, Channel: msg.vars.Channel, Frame: msg.frame})
+	if err != nil {
+		return fmt.Errorf("error marshaling frame: %w", err)
+	}
+	if w.needsRotation() {
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+		w.buf = bufio.NewWriter(w.counter)
+	}
+	if _, err := w.buf.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *fileOutputWriter) writeParquet(frame *data.Frame) error {
+	record, err := frameToParquetRecord(frame)
+	if err != nil {
+		return err
+	}
+	defer record.Release()
+	if w.needsRotation() || (w.schema != nil && !w.schema.Equal(record.Schema())) {
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+		props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+		w.pw, err = pqarrow.NewFileWriter(record.Schema(), w.counter, props, pqarrow.DefaultWriterProps())
+		if err != nil {
+			return err
+		}
+		w.schema = record.Schema()
+	}
+	return w.pw.WriteBuffered(record)
+}
+
+// frameToParquetRecord converts a frame to a record with a schema without
+// frame and field metadata, so frames with different labels or meta can be
+// written to the same file.
+func frameToParquetRecord(frame *data.Frame) (arrow.Record, error) {
+	table, err := data.FrameToArrowTable(frame)
+	if err != nil {
+		return nil, fmt.Errorf("error converting frame to arrow: %w", err)
+	}
+	defer table.Release()
+
+	fields := make([]arrow.Field, 0, table.NumCols())
+	columns := make([]arrow.Array, 0, table.NumCols())
+	for i := 0; i < int(table.NumCols()); i++ {
+		col := table.Column(i)
+		fields = append(fields, arrow.Field{Name: col.Name(), Type: col.DataType(), Nullable: col.Field().Nullable})
+		chunks := col.Data().Chunks()
+		if len(chunks) != 1 {
+			return nil, fmt.Errorf("unexpected number of chunks: %d", len(chunks))
+		}
+		columns = append(columns, chunks[0])
+	}
+	return array.NewRecord(arrow.NewSchema(fields, nil), columns, table.NumRows()), nil
+}
+
+func (w *fileOutputWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	var err error
+	if w.pw != nil {
+		err = w.pw.Close()
+	}
+	if w.buf != nil && err == nil {
+		err = w.buf.Flush()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(w.path+".tmp", w.path)
+	}
+	w.file, w.counter, w.buf, w.pw, w.schema = nil, nil, nil, nil, nil
+	return err
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/parquet/file"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func testFileOutputWriter(t *testing.T, config FileOutputConfig) (*fileOutputWriter, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writer, err := newFileOutputWriter(dir, config)
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	writer.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return writer, dir
+}
+
+func TestFileOutputWriter_NDJSON(t *testing.T) {
+	writer, dir := testFileOutputWriter(t, FileOutputConfig{MaxFileBytes: 100})
+
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1, 2}))
+	msg := outputMessage{vars: Vars{OrgID: 1, Channel: "stream/test/xxx"}, frame: frame}
+	require.NoError(t, writer.WriteBatch(context.Background(), []outputMessage{msg, msg}))
+	require.NoError(t, writer.WriteBatch(context.Background(), []outputMessage{msg}))
+	require.NoError(t, writer.Close())
+
+	// Every line is larger than max file size, so each one goes to its own file.
+	files := listFiles(t, dir)
+	require.Len(t, files, 3)
+	for _, name := range files {
+		require.Equal(t, ".ndjson", filepath.Ext(name))
+		f, err := os.Open(name)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(f)
+		require.True(t, scanner.Scan())
+		var record struct {
+			OrgID   int64      `json:"orgId"`
+			Channel string     `json:"channel"`
+			Frame   data.Frame `json:"frame"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		require.Equal(t, int64(1), record.OrgID)
+		require.Equal(t, "stream/test/xxx", record.Channel)
+		require.Equal(t, 2, record.Frame.Rows())
+		require.False(t, scanner.Scan())
+		require.NoError(t, f.Close())
+	}
+}
+
+func TestFileOutputWriter_Parquet(t *testing.T) {
+	writer, dir := testFileOutputWriter(t, FileOutputConfig{Format: FileOutputFormatParquet})
+
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	frame := func(labels data.Labels) *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{start, start.Add(time.Second)}),
+			data.NewField("value", labels, []float64{1, 2}),
+		)
+	}
+	changed := data.NewFrame("test", data.NewField("time", nil, []time.Time{start}), data.NewField("value", nil, []string{"a"}))
+	require.NoError(t, writer.WriteBatch(context.Background(), []outputMessage{
+		{frame: frame(data.Labels{"host": "a"})},
+		{frame: frame(data.Labels{"host": "b"})},
+	}))
+	require.NoError(t, writer.WriteBatch(context.Background(), []outputMessage{{frame: frame(nil)}, {frame: changed}}))
+	require.NoError(t, writer.Close())
+
+	// Labels do not change schema, field type does.
+	files := listFiles(t, dir)
+	require.Len(t, files, 2)
+	var rows []int64
+	for _, name := range files {
+		require.Equal(t, ".parquet", filepath.Ext(name))
+		reader, err := file.OpenParquetFile(name, false)
+		require.NoError(t, err)
+		rows = append(rows, reader.NumRows())
+		require.NoError(t, reader.Close())
+	}
+	require.Equal(t, []int64{6, 1}, rows)
+}
+
+func TestStorageRuleBuilder_FileOutputPath(t *testing.T) {
+	builder := &StorageRuleBuilder{FileOutputPath: t.TempDir()}
+	out, err := builder.extractFrameOutputter(1, &FrameOutputterConfig{
+		Type:             FrameOutputTypeFile,
+		FileOutputConfig: &FileOutputConfig{Path: "archive"},
+	}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, FrameOutputTypeFile, out.Type())
+
+	_, err = builder.extractFrameOutputter(1, &FrameOutputterConfig{
+		Type:             FrameOutputTypeFile,
+		FileOutputConfig: &FileOutputConfig{Path: "../2/archive"},
+	}, nil, nil)
+	require.ErrorContains(t, err, "invalid file output path")
+
+	_, err = (&StorageRuleBuilder{}).extractFrameOutputter(1, &FrameOutputterConfig{
+		Type:             FrameOutputTypeFile,
+		FileOutputConfig: &FileOutputConfig{},
+	}, nil, nil)
+	require.ErrorContains(t, err, "not configured")
+}
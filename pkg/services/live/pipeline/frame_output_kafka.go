@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// KafkaFrameOutput sends frames as JSON messages to a Kafka topic. Messages are
+// keyed by channel so frames of a channel keep their order in a partition.
+type KafkaFrameOutput struct {
+	sink *frameOutputSink
+}
+
+func NewKafkaFrameOutput(sink *frameOutputSink) *KafkaFrameOutput {
+	return &KafkaFrameOutput{sink: sink}
+}
+
+const FrameOutputTypeKafka = "kafka"
+
+func (out *KafkaFrameOutput) Type() string {
+	return FrameOutputTypeKafka
+}
+
+func (out *KafkaFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	return nil, out.sink.enqueue(vars, frame)
+}
+
+type kafkaMessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+type kafkaOutputWriter struct {
+	writer kafkaMessageWriter
+}
+
+func newKafkaOutputWriter(brokers string, topic string, basicAuth *BasicAuth, batchSize int) *kafkaOutputWriter {
+	var addrs []string
+	for _, addr := range strings.Split(brokers, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	transport := &kafka.Transport{}
+	if basicAuth != nil {
+		transport.SASL = plain.Mechanism{Username: basicAuth.User, Password: basicAuth.Password}
+	}
+	return &kafkaOutputWriter{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(addrs...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireOne,
+			// Batching and retries are done by frameOutputSink.
+			MaxAttempts:  1,
+			BatchSize:    batchSize,
+			BatchTimeout: 10 * time.Millisecond,
+			Transport:    transport,
+		},
+	}
+}
+
+func (w *kafkaOutputWriter) WriteBatch(ctx context.Context, batch []outputMessage) error {
+	msgs := make([]kafka.Message, 0, len(batch))
+	for _, msg := range batch {
+		value, err := json.Marshal(msg.frame)
+		if err != nil {
+			return fmt.Errorf("error marshaling frame: %w", err)
+		}
+		msgs = append(msgs, kafka.Message{
+			Key:   []byte(msg.vars.Channel),
+			Value: value,
+			Headers: []kafka.Header{
+				{Key: "orgId", Value: []byte(strconv.FormatInt(msg.vars.OrgID, 10))},
+				{Key: "channel", Value: []byte(msg.vars.Channel)},
+			},
+		})
+	}
+	err := w.writer.WriteMessages(ctx, msgs...)
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		// Messages of other partitions may have been written.
+		var unwritten []outputMessage
+		for i, writeErr := range writeErrs {
+			if writeErr != nil {
+				unwritten = append(unwritten, batch[i])
+			}
+		}
+		return &outputWriteError{unwritten: unwritten, err: err}
+	}
+	return err
+}
+
+func (w *kafkaOutputWriter) Close() error {
+	return w.writer.Close()
+}
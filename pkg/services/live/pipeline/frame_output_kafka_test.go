@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+type testKafkaWriter struct {
+	messages []kafka.Message
+	errs     kafka.WriteErrors
+}
+
+func (w *testKafkaWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.errs != nil {
+		for i, msg := range msgs {
+			if w.errs[i] == nil {
+				w.messages = append(w.messages, msg)
+			}
+		}
+		return w.errs
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *testKafkaWriter) Close() error {
+	return nil
+}
+
+func TestKafkaOutputWriter(t *testing.T) {
+	messageWriter := &testKafkaWriter{}
+	writer := &kafkaOutputWriter{writer: messageWriter}
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+
+	err := writer.WriteBatch(context.Background(), []outputMessage{
+		{vars: Vars{OrgID: 2, Channel: "stream/test/xxx"}, frame: frame},
+	})
+	require.NoError(t, err)
+	require.Len(t, messageWriter.messages, 1)
+
+	msg := messageWriter.messages[0]
+	require.Equal(t, "stream/test/xxx", string(msg.Key))
+	require.Equal(t, []kafka.Header{
+		{Key: "orgId", Value: []byte("2")},
+		{Key: "channel", Value: []byte("stream/test/xxx")},
+	}, msg.Headers)
+	var decoded data.Frame
+	require.NoError(t, json.Unmarshal(msg.Value, &decoded))
+	require.Equal(t, 1.0, decoded.Fields[0].At(0))
+}
+
+func TestKafkaOutputWriter_PartialWrite(t *testing.T) {
+	messageWriter := &testKafkaWriter{errs: kafka.WriteErrors{nil, errors.New("boom"), nil}}
+	writer := &kafkaOutputWriter{writer: messageWriter}
+	batch := []outputMessage{
+		{vars: Vars{Channel: "stream/test/a"}, frame: data.NewFrame("a")},
+		{vars: Vars{Channel: "stream/test/b"}, frame: data.NewFrame("b")},
+		{vars: Vars{Channel: "stream/test/c"}, frame: data.NewFrame("c")},
+	}
+
+	err := writer.WriteBatch(context.Background(), batch)
+	var writeErr *outputWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, batch[1:2], writeErr.unwritten)
+	require.Len(t, messageWriter.messages, 2)
+}
+
+func TestNewKafkaOutputWriter(t *testing.T) {
+	writer := newKafkaOutputWriter("broker-1:9092, broker-2:9092", "live", &BasicAuth{User: "user", Password: "pass"}, 10)
+	kafkaWriter := writer.writer.(*kafka.Writer)
+	require.Equal(t, "broker-1:9092,broker-2:9092", kafkaWriter.Addr.String())
+	require.Equal(t, "live", kafkaWriter.Topic)
+	require.NotNil(t, kafkaWriter.Transport.(*kafka.Transport).SASL)
+}
@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/mqtt"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// MQTTFrameOutput publishes frames as JSON messages to an MQTT topic. Frames
+// which failed to be published are published again, a frame may be duplicated
+// if the broker received it but the acknowledgement was lost.
+type MQTTFrameOutput struct {
+	sink *frameOutputSink
+}
+
+func NewMQTTFrameOutput(sink *frameOutputSink) *MQTTFrameOutput {
+	return &MQTTFrameOutput{sink: sink}
+}
+
+const FrameOutputTypeMQTT = "mqtt"
+
+func (out *MQTTFrameOutput) Type() string {
+	return FrameOutputTypeMQTT
+}
+
+func (out *MQTTFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	return nil, out.sink.enqueue(vars, frame)
+}
+
+type mqttOutputWriter struct {
+	options mqtt.Options
+	topic   string
+	qos     mqtt.QoS
+	retain  bool
+	client  *mqtt.Client
+}
+
+func newMQTTOutputWriter(brokerURL string, basicAuth *BasicAuth, config MQTTOutputConfig) (*mqttOutputWriter, error) {
+	if err := mqtt.ValidateBrokerURL(brokerURL); err != nil {
+		return nil, err
+	}
+	if err := mqtt.ValidateTopic(config.Topic); err != nil {
+		return nil, err
+	}
+	qos := mqtt.QoS(config.QoS)
+	if qos > mqtt.ExactlyOnce {
+		return nil, fmt.Errorf("invalid qos: %d", config.QoS)
+	}
+	options := mqtt.Options{
+		BrokerURL: brokerURL,
+		ClientID:  "grafana-live-" + util.GenerateShortUID(),
+	}
+	if basicAuth != nil {
+		options.Username = basicAuth.User
+		options.Password = basicAuth.Password
+	}
+	return &mqttOutputWriter{options: options, topic: config.Topic, qos: qos, retain: config.Retain}, nil
+}
+
+func (w *mqttOutputWriter) WriteBatch(ctx context.Context, batch []outputMessage) error {
+	if w.client == nil {
+		client, err := mqtt.Connect(ctx, w.options)
+		if err != nil {
+			return fmt.Errorf("error connecting to broker: %w", err)
+		}
+		w.client = client
+	}
+	for i, msg := range batch {
+		payload, err := json.Marshal(msg.frame)
+		if err != nil {
+			return &outputWriteError{unwritten: batch[i:], err: fmt.Errorf("error marshaling frame: %w", err)}
+		}
+		if err := w.client.Publish(ctx, w.topic, w.qos, w.retain, payload); err != nil {
+			// Reconnect on the next attempt.
+			_ = w.client.Close()
+			w.client = nil
+			return &outputWriteError{unwritten: batch[i:], err: fmt.Errorf("error publishing frame: %w", err)}
+		}
+	}
+	return nil
+}
+
+func (w *mqttOutputWriter) Close() error {
+	if w.client == nil {
+		return nil
+	}
+	return w.client.Close()
+}
@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/mqtt"
+	"github.com/grafana/grafana/pkg/infra/mqtt/mqtttest"
+)
+
+func TestMQTTOutputWriter(t *testing.T) {
+	broker, err := mqtttest.NewBroker("user", "pass", nil)
+	require.NoError(t, err)
+	t.Cleanup(broker.Close)
+	writer, err := newMQTTOutputWriter(broker.URL(), &BasicAuth{User: "user", Password: "pass"}, MQTTOutputConfig{
+		Topic:  "grafana/live",
+		QoS:    1,
+		Retain: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = writer.Close() })
+
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+	err = writer.WriteBatch(context.Background(), []outputMessage{{vars: Vars{OrgID: 1}, frame: frame}, {vars: Vars{OrgID: 1}, frame: frame}})
+	require.NoError(t, err)
+
+	messages := broker.Messages()
+	require.Len(t, messages, 2)
+	require.Equal(t, "grafana/live", messages[0].Topic)
+	require.Equal(t, mqtt.AtLeastOnce, messages[0].QoS)
+	require.True(t, messages[0].Retain)
+	var decoded data.Frame
+	require.NoError(t, json.Unmarshal(messages[0].Payload, &decoded))
+	require.Equal(t, "test", decoded.Name)
+
+	_, err = newMQTTOutputWriter(broker.URL(), nil, MQTTOutputConfig{Topic: "grafana/#"})
+	require.Error(t, err)
+	_, err = newMQTTOutputWriter(broker.URL(), nil, MQTTOutputConfig{Topic: "grafana/live", QoS: 3})
+	require.Error(t, err)
+}
@@ -0,0 +1,280 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// AggregateFrameProcessor aggregates frame rows over tumbling time windows to
+// reduce the rate of high frequency streams. Rows are held back until a row of
+// the next window arrives, then one row per completed window is emitted with the
+// window start time. A window of a channel which gets no frames for the window
+// size is emitted on flush and the channel state is removed. Numeric fields are
+// aggregated with configured functions, other fields keep their last value.
+// State is kept per channel and reset when frame schema changes.
+type AggregateFrameProcessor struct {
+	config AggregateFrameProcessorConfig
+	window time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*aggregateState
+}
+
+func NewAggregateFrameProcessor(config AggregateFrameProcessorConfig) (*AggregateFrameProcessor, error) {
+	if config.WindowMilliseconds <= 0 {
+		return nil, errors.New("aggregate window must be positive")
+	}
+	if len(config.Functions) == 0 {
+		config.Functions = []AggregateFunction{AggregateFunctionLast}
+	}
+	functions := append([]AggregateFunction{}, config.Functions...)
+	for _, fieldFunctions := range config.FieldFunctions {
+		functions = append(functions, fieldFunctions...)
+	}
+	for _, fn := range functions {
+		switch fn {
+		case AggregateFunctionMin, AggregateFunctionMax, AggregateFunctionAvg, AggregateFunctionLast:
+		default:
+			return nil, fmt.Errorf("unknown aggregate function: %s", fn)
+		}
+	}
+	return &AggregateFrameProcessor{
+		config: config,
+		window: time.Duration(config.WindowMilliseconds) * time.Millisecond,
+		now:    time.Now,
+		states: map[string]*aggregateState{},
+	}, nil
+}
+
+const FrameProcessorTypeAggregate = "aggregate"
+
+func (p *AggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeAggregate
+}
+
+type aggregateField struct {
+	count     int
+	min       float64
+	max       float64
+	sum       float64
+	last      float64
+	lastValue any
+}
+
+type aggregateState struct {
+	schema string
+	start  time.Time
+	rows   int
+	fields []aggregateField
+
+	// vars, layout and timeIndex describe the output frame of a flushed window.
+	vars      Vars
+	layout    *data.Frame
+	timeIndex int
+	// updated is when the state got the last frame.
+	updated time.Time
+}
+
+func (s *aggregateState) reset(start time.Time) {
+	s.start = start
+	s.rows = 0
+	for i := range s.fields {
+		s.fields[i] = aggregateField{}
+	}
+}
+
+func (s *aggregateState) add(frame *data.Frame, row int, timeIndex int) error {
+	s.rows++
+	for i, f := range frame.Fields {
+		if i == timeIndex {
+			continue
+		}
+		agg := &s.fields[i]
+		if !f.Type().Numeric() {
+			agg.lastValue = f.At(row)
+			continue
+		}
+		v, err := f.NullableFloatAt(row)
+		if err != nil {
+			return err
+		}
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		if agg.count == 0 || *v < agg.min {
+			agg.min = *v
+		}
+		if agg.count == 0 || *v > agg.max {
+			agg.max = *v
+		}
+		agg.count++
+		agg.sum += *v
+		agg.last = *v
+	}
+	return nil
+}
+
+func (agg aggregateField) value(fn AggregateFunction) *float64 {
+	if agg.count == 0 {
+		return nil
+	}
+	var v float64
+	switch fn {
+	case AggregateFunctionMin:
+		v = agg.min
+	case AggregateFunctionMax:
+		v = agg.max
+	case AggregateFunctionAvg:
+		v = agg.sum / float64(agg.count)
+	default:
+		v = agg.last
+	}
+	return &v
+}
+
+func frameSchema(frame *data.Frame) string {
+	var b strings.Builder
+	for _, f := range frame.Fields {
+		b.WriteString(f.Name)
+		b.WriteString(f.Labels.String())
+		b.WriteString(f.Type().ItemTypeString())
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+func (p *AggregateFrameProcessor) functions(fieldName string) []AggregateFunction {
+	if functions, ok := p.config.FieldFunctions[fieldName]; ok && len(functions) > 0 {
+		return functions
+	}
+	return p.config.Functions
+}
+
+func (p *AggregateFrameProcessor) newOutputFrame(frame *data.Frame, timeIndex int) *data.Frame {
+	var fields []*data.Field
+	for i, f := range frame.Fields {
+		if i != timeIndex && f.Type().Numeric() {
+			functions := p.functions(f.Name)
+			for _, fn := range functions {
+				field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+				field.Name = f.Name
+				if len(functions) > 1 {
+					field.Name = f.Name + "_" + string(fn)
+				}
+				field.Labels = f.Labels
+				field.Config = f.Config
+				fields = append(fields, field)
+			}
+			continue
+		}
+		fieldType := f.Type()
+		if i == timeIndex {
+			fieldType = data.FieldTypeTime
+		}
+		field := data.NewFieldFromFieldType(fieldType, 0)
+		field.Name = f.Name
+		field.Labels = f.Labels
+		field.Config = f.Config
+		fields = append(fields, field)
+	}
+	return data.NewFrame(frame.Name, fields...)
+}
+
+func (p *AggregateFrameProcessor) appendWindow(out *data.Frame, frame *data.Frame, timeIndex int, state *aggregateState) {
+	j := 0
+	for i, f := range frame.Fields {
+		switch {
+		case i == timeIndex:
+			out.Fields[j].Append(state.start)
+			j++
+		case f.Type().Numeric():
+			for _, fn := range p.functions(f.Name) {
+				out.Fields[j].Append(state.fields[i].value(fn))
+				j++
+			}
+		default:
+			out.Fields[j].Append(state.fields[i].lastValue)
+			j++
+		}
+	}
+}
+
+func (p *AggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIndex := timeFieldIndex(frame)
+	if timeIndex < 0 {
+		return nil, errors.New("aggregate processor requires a time field")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := channelStateKey(vars)
+	schema := frameSchema(frame)
+	state, ok := p.states[key]
+	if !ok || state.schema != schema {
+		state = &aggregateState{
+			schema:    schema,
+			fields:    make([]aggregateField, len(frame.Fields)),
+			vars:      vars,
+			layout:    frame.EmptyCopy(),
+			timeIndex: timeIndex,
+		}
+		p.states[key] = state
+	}
+	state.updated = p.now()
+
+	var out *data.Frame
+	for row := 0; row < frame.Rows(); row++ {
+		t, ok := rowTime(frame.Fields[timeIndex], row)
+		if !ok {
+			continue
+		}
+		start := t.Truncate(p.window)
+		if state.rows == 0 {
+			state.start = start
+		} else if start.After(state.start) {
+			if out == nil {
+				out = p.newOutputFrame(frame, timeIndex)
+			}
+			p.appendWindow(out, frame, timeIndex, state)
+			state.reset(start)
+		}
+		// Late rows are added to the current window.
+		if err := state.add(frame, row, timeIndex); err != nil {
+			return nil, err
+		}
+	}
+	if out == nil {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// FlushFrames emits windows of channels which got no frames for the window
+// size and removes their state.
+func (p *AggregateFrameProcessor) FlushFrames(_ context.Context, now time.Time) ([]FlushedFrame, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var result []FlushedFrame
+	for key, state := range p.states {
+		if now.Sub(state.updated) < p.window {
+			continue
+		}
+		if state.rows > 0 {
+			out := p.newOutputFrame(state.layout, state.timeIndex)
+			p.appendWindow(out, state.layout, state.timeIndex, state)
+			result = append(result, FlushedFrame{Vars: state.vars, Frame: out})
+		}
+		delete(p.states, key)
+	}
+	return result, len(p.states) > 0
+}
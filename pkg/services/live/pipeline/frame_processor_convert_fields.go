@@ -0,0 +1,123 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ConvertFieldsFrameProcessor can rename fields and convert their values to
+// another type. Converted fields are nullable, values which can't be converted
+// become nulls.
+type ConvertFieldsFrameProcessor struct {
+	config ConvertFieldsFrameProcessorConfig
+}
+
+func NewConvertFieldsFrameProcessor(config ConvertFieldsFrameProcessorConfig) (*ConvertFieldsFrameProcessor, error) {
+	for _, f := range config.Fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("field name required")
+		}
+		if f.Type == data.FieldTypeUnknown {
+			continue
+		}
+		switch f.Type.NonNullableType() {
+		case data.FieldTypeFloat64, data.FieldTypeString, data.FieldTypeBool, data.FieldTypeTime:
+		default:
+			return nil, fmt.Errorf("unsupported field type conversion: %s", f.Type.ItemTypeString())
+		}
+	}
+	return &ConvertFieldsFrameProcessor{config: config}, nil
+}
+
+const FrameProcessorTypeConvertFields = "convertFields"
+
+func (p *ConvertFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeConvertFields
+}
+
+func (p *ConvertFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, c := range p.config.Fields {
+		for i, field := range frame.Fields {
+			if field.Name != c.Name {
+				continue
+			}
+			if c.Type != data.FieldTypeUnknown && c.Type.NullableType() != field.Type().NullableType() {
+				field = convertField(field, c.Type.NonNullableType())
+				frame.Fields[i] = field
+			}
+			if c.NewName != "" {
+				field.Name = c.NewName
+			}
+		}
+	}
+	return frame, nil
+}
+
+func convertField(f *data.Field, fieldType data.FieldType) *data.Field {
+	converted := data.NewFieldFromFieldType(fieldType.NullableType(), f.Len())
+	converted.Name = f.Name
+	converted.Labels = f.Labels
+	converted.Config = f.Config
+	for row := 0; row < f.Len(); row++ {
+		if v, ok := convertValue(f, row, fieldType); ok {
+			converted.SetConcrete(row, v)
+		}
+	}
+	return converted
+}
+
+func convertValue(f *data.Field, row int, fieldType data.FieldType) (any, bool) {
+	v, ok := f.ConcreteAt(row)
+	if !ok {
+		return nil, false
+	}
+	switch fieldType {
+	case data.FieldTypeFloat64:
+		// FloatAt supports numbers, strings, booleans and time.
+		fv, err := f.FloatAt(row)
+		if err != nil || math.IsNaN(fv) {
+			return nil, false
+		}
+		return fv, true
+	case data.FieldTypeString:
+		switch v := v.(type) {
+		case string:
+			return v, true
+		case time.Time:
+			return v.Format(time.RFC3339Nano), true
+		default:
+			return fmt.Sprint(v), true
+		}
+	case data.FieldTypeBool:
+		switch v := v.(type) {
+		case bool:
+			return v, true
+		case string:
+			b, err := strconv.ParseBool(v)
+			return b, err == nil
+		}
+		if f.Type().Numeric() {
+			fv, err := f.FloatAt(row)
+			return fv != 0, err == nil
+		}
+	case data.FieldTypeTime:
+		switch v := v.(type) {
+		case time.Time:
+			return v, true
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			return t, err == nil
+		}
+		if f.Type().Numeric() {
+			// Numbers are treated as Unix milliseconds.
+			fv, err := f.FloatAt(row)
+			return time.UnixMilli(int64(fv)).UTC(), err == nil
+		}
+	}
+	return nil, false
+}
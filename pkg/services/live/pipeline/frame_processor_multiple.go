@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -19,13 +20,44 @@ func (p *MultipleFrameProcessor) Type() string {
 }
 
 func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
-	for _, p := range p.Processors {
+	return p.processFrom(ctx, 0, vars, frame)
+}
+
+// FlushFrames flushes processors which implement FrameFlusher, flushed frames
+// are passed to the processors after them.
+func (p *MultipleFrameProcessor) FlushFrames(ctx context.Context, now time.Time) ([]FlushedFrame, bool) {
+	var result []FlushedFrame
+	pending := false
+	for i, proc := range p.Processors {
+		flusher, ok := proc.(FrameFlusher)
+		if !ok {
+			continue
+		}
+		frames, procPending := flusher.FlushFrames(ctx, now)
+		pending = pending || procPending
+		for _, f := range frames {
+			frame, err := p.processFrom(ctx, i+1, f.Vars, f.Frame)
+			if err != nil || frame == nil {
+				continue
+			}
+			result = append(result, FlushedFrame{Vars: f.Vars, Frame: frame})
+		}
+	}
+	return result, pending
+}
+
+func (p *MultipleFrameProcessor) processFrom(ctx context.Context, first int, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, p := range p.Processors[first:] {
 		var err error
 		frame, err = p.ProcessFrame(ctx, vars, frame)
 		if err != nil {
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			// Processor decided to skip frame, e.g. aggregation window is not complete yet.
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
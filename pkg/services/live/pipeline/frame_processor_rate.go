@@ -0,0 +1,128 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// RateFrameProcessor replaces numeric field values with their per-second rate
+// of change. Previous values are kept per channel and series so rate is also
+// calculated between frames, values of a channel which gets no frames for
+// rateStateTTL are removed on flush. In RateModeRate a decrease of value is
+// considered a counter reset. First value of a series results in null.
+type RateFrameProcessor struct {
+	config RateFrameProcessorConfig
+	now    func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*rateState
+}
+
+const rateStateTTL = 10 * time.Minute
+
+type rateState struct {
+	series map[string]ratePoint
+	// updated is when the state got the last frame.
+	updated time.Time
+}
+
+type ratePoint struct {
+	time  time.Time
+	value float64
+}
+
+func NewRateFrameProcessor(config RateFrameProcessorConfig) (*RateFrameProcessor, error) {
+	switch config.Mode {
+	case "":
+		config.Mode = RateModeRate
+	case RateModeRate, RateModeDerivative:
+	default:
+		return nil, fmt.Errorf("unknown rate mode: %s", config.Mode)
+	}
+	return &RateFrameProcessor{config: config, now: time.Now, states: map[string]*rateState{}}, nil
+}
+
+const FrameProcessorTypeRate = "rate"
+
+func (p *RateFrameProcessor) Type() string {
+	return FrameProcessorTypeRate
+}
+
+func (p *RateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIndex := timeFieldIndex(frame)
+	if timeIndex < 0 {
+		return nil, errors.New("rate processor requires a time field")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := channelStateKey(vars)
+	state, ok := p.states[key]
+	if !ok {
+		state = &rateState{series: map[string]ratePoint{}}
+		p.states[key] = state
+	}
+	state.updated = p.now()
+	series := state.series
+
+	for i, f := range frame.Fields {
+		if i == timeIndex || !f.Type().Numeric() {
+			continue
+		}
+		if len(p.config.FieldNames) > 0 && !stringInSlice(f.Name, p.config.FieldNames) {
+			continue
+		}
+		seriesKey := f.Name + f.Labels.String()
+		rate := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, f.Len())
+		rate.Name = f.Name
+		rate.Labels = f.Labels
+		for row := 0; row < f.Len(); row++ {
+			t, ok := rowTime(frame.Fields[timeIndex], row)
+			if !ok {
+				continue
+			}
+			v, err := f.NullableFloatAt(row)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				continue
+			}
+			prev, ok := series[seriesKey]
+			if ok && !t.After(prev.time) {
+				// Out of order or duplicate point.
+				continue
+			}
+			if ok {
+				delta := *v - prev.value
+				if delta < 0 && p.config.Mode == RateModeRate {
+					delta = *v
+				}
+				r := delta / t.Sub(prev.time).Seconds()
+				rate.Set(row, &r)
+			}
+			series[seriesKey] = ratePoint{time: t, value: *v}
+		}
+		frame.Fields[i] = rate
+	}
+	return frame, nil
+}
+
+// FlushFrames removes values of channels which got no frames for rateStateTTL.
+func (p *RateFrameProcessor) FlushFrames(_ context.Context, now time.Time) ([]FlushedFrame, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, state := range p.states {
+		if now.Sub(state.updated) >= rateStateTTL {
+			delete(p.states, key)
+		}
+	}
+	return nil, len(p.states) > 0
+}
@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// channelStateKey is a key of channel state in stateful frame processors. A rule
+// may match many channels so processors can't keep a single state.
+func channelStateKey(vars Vars) string {
+	return strconv.FormatInt(vars.OrgID, 10) + "/" + vars.Channel
+}
+
+// timeFieldIndex returns index of the first time field in a frame or -1.
+func timeFieldIndex(frame *data.Frame) int {
+	for i, f := range frame.Fields {
+		if f.Type().Time() {
+			return i
+		}
+	}
+	return -1
+}
+
+func rowTime(field *data.Field, row int) (time.Time, bool) {
+	v, ok := field.ConcreteAt(row)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, ok := v.(time.Time)
+	return t, ok
+}
+
+// frameProcessors keeps frame processors between rule builds. Rules are rebuilt
+// periodically while aggregate and rate processors keep the state of channels,
+// so a processor is reused by the next build if the rule has the same processor
+// configuration at the same position. Processors which are not used by the
+// rules of the last successful build of their org are dropped.
+type frameProcessors struct {
+	mu         sync.Mutex
+	processors map[int64]map[string]FrameProcessor
+}
+
+func (p *frameProcessors) get(orgID int64, key string) (FrameProcessor, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	proc, ok := p.processors[orgID][key]
+	return proc, ok
+}
+
+// setUsed sets the processors used by the rules of a successful build of the org.
+func (p *frameProcessors) setUsed(orgID int64, processors map[string]FrameProcessor) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.processors == nil {
+		p.processors = map[int64]map[string]FrameProcessor{}
+	}
+	p.processors[orgID] = processors
+}
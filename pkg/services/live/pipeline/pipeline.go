@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -98,6 +100,22 @@ type FrameProcessor interface {
 	ProcessFrame(ctx context.Context, vars Vars, frame *data.Frame) (*data.Frame, error)
 }
 
+// FlushedFrame is a frame of a channel returned by FrameFlusher.
+type FlushedFrame struct {
+	Vars  Vars
+	Frame *data.Frame
+}
+
+// FrameFlusher is implemented by a FrameProcessor which keeps state between
+// frames. Pipeline calls FlushFrames periodically, returned frames are passed
+// to the rest of the rule as if ProcessFrame returned them, so frames held back
+// by the processor are output without waiting for the next frame of a channel.
+// FlushFrames also evicts idle state and returns false if no state is left,
+// then Pipeline does not call it until the processor gets a frame again.
+type FrameFlusher interface {
+	FlushFrames(ctx context.Context, now time.Time) ([]FlushedFrame, bool)
+}
+
 // FrameOutputter outputs data.Frame to a custom destination. Or simply
 // do nothing if some conditions not met.
 type FrameOutputter interface {
@@ -182,12 +200,27 @@ type ChannelRuleGetter interface {
 type Pipeline struct {
 	ruleGetter ChannelRuleGetter
 	tracer     trace.Tracer
+
+	flushMu  sync.Mutex
+	flushers map[FrameFlusher]flushTarget
+	flushSeq uint64
 }
 
+// flushTarget is the rule and the index of a FrameFlusher in rule processors.
+// seq changes every time the flusher gets a frame.
+type flushTarget struct {
+	rule  *LiveChannelRule
+	index int
+	seq   uint64
+}
+
+const frameFlushInterval = time.Second
+
 // New creates new Pipeline.
 func New(ruleGetter ChannelRuleGetter) (*Pipeline, error) {
 	p := &Pipeline{
 		ruleGetter: ruleGetter,
+		flushers:   map[FrameFlusher]flushTarget{},
 	}
 
 	if os.Getenv("GF_LIVE_PIPELINE_TRACE") != "" {
@@ -214,6 +247,58 @@ func (p *Pipeline) Get(orgID int64, channel string) (*LiveChannelRule, bool, err
 	return p.ruleGetter.Get(orgID, channel)
 }
 
+// Run flushes frame processors which implement FrameFlusher until ctx is done.
+func (p *Pipeline) Run(ctx context.Context) error {
+	ticker := time.NewTicker(frameFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			p.flushFrames(ctx, now)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *Pipeline) addFlusher(flusher FrameFlusher, rule *LiveChannelRule, index int) {
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+	p.flushSeq++
+	p.flushers[flusher] = flushTarget{rule: rule, index: index, seq: p.flushSeq}
+}
+
+func (p *Pipeline) flushFrames(ctx context.Context, now time.Time) {
+	p.flushMu.Lock()
+	targets := make(map[FrameFlusher]flushTarget, len(p.flushers))
+	for flusher, target := range p.flushers {
+		targets[flusher] = target
+	}
+	p.flushMu.Unlock()
+
+	for flusher, target := range targets {
+		frames, pending := flusher.FlushFrames(ctx, now)
+		if !pending {
+			p.flushMu.Lock()
+			// Flusher may have got a frame after FlushFrames returned.
+			if p.flushers[flusher].seq == target.seq {
+				delete(p.flushers, flusher)
+			}
+			p.flushMu.Unlock()
+		}
+		for _, f := range frames {
+			channelFrames, err := p.applyRule(ctx, target.rule, target.index+1, f.Vars, f.Frame)
+			if err == nil && len(channelFrames) > 0 {
+				visitedChannels := map[string]struct{}{f.Vars.Channel: {}}
+				err = p.processChannelFrames(ctx, f.Vars.OrgID, f.Vars.Channel, channelFrames, visitedChannels)
+			}
+			if err != nil {
+				logger.Error("Error processing flushed frame", "error", err, "channel", f.Vars.Channel)
+			}
+		}
+	}
+}
+
 func (p *Pipeline) ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error) {
 	var span trace.Span
 	if p.tracer != nil {
@@ -405,16 +490,25 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 		Path:      ch.Path,
 	}
 
-	if len(rule.FrameProcessors) > 0 {
-		for _, proc := range rule.FrameProcessors {
-			frame, err = p.execProcessor(ctx, proc, vars, frame)
-			if err != nil {
-				logger.Error("Error processing frame", "error", err)
-				return nil, err
-			}
-			if frame == nil {
-				return nil, nil
-			}
+	return p.applyRule(ctx, rule, 0, vars, frame)
+}
+
+// applyRule applies rule frame processors starting from the one with the
+// index first, then rule frame outputters.
+func (p *Pipeline) applyRule(ctx context.Context, rule *LiveChannelRule, first int, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	for i := first; i < len(rule.FrameProcessors); i++ {
+		proc := rule.FrameProcessors[i]
+		if flusher, ok := proc.(FrameFlusher); ok {
+			p.addFlusher(flusher, rule, i)
+		}
+		var err error
+		frame, err = p.execProcessor(ctx, proc, vars, frame)
+		if err != nil {
+			logger.Error("Error processing frame", "error", err)
+			return nil, err
+		}
+		if frame == nil {
+			return nil, nil
 		}
 	}
 
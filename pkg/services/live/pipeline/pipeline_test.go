@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -142,3 +143,314 @@ func TestPipeline_Recursion(t *testing.T) {
 	_, err = p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
 	require.ErrorIs(t, err, errChannelRecursion)
 }
+
+func TestPipeline_Aggregate(t *testing.T) {
+	proc, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Functions:          []AggregateFunction{AggregateFunctionMin, AggregateFunctionMax, AggregateFunctionAvg},
+		FieldFunctions:     map[string][]AggregateFunction{"humidity": {AggregateFunctionLast}},
+	})
+	require.NoError(t, err)
+	converter := &testConverter{}
+	outputter := &testOutputter{}
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{
+			"stream/test/xxx": {
+				Converter:       converter,
+				FrameProcessors: []FrameProcessor{NewMultipleFrameProcessor(proc)},
+				FrameOutputters: []FrameOutputter{outputter},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	push := func(offsets []time.Duration, temperatures []float64, humidity []*float64, status []string) *data.Frame {
+		times := make([]time.Time, 0, len(offsets))
+		for _, o := range offsets {
+			times = append(times, start.Add(o))
+		}
+		converter.frame = data.NewFrame("test",
+			data.NewField("time", nil, times),
+			data.NewField("temperature", nil, temperatures),
+			data.NewField("humidity", nil, humidity),
+			data.NewField("status", nil, status),
+		)
+		outputter.frame = nil
+		_, err := p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
+		require.NoError(t, err)
+		return outputter.frame
+	}
+	float := func(v float64) *float64 { return &v }
+
+	// Window is not complete yet, nothing is output.
+	require.Nil(t, push([]time.Duration{0, 300 * time.Millisecond}, []float64{10, 20}, []*float64{float(50), nil}, []string{"ok", "warn"}))
+
+	frame := push(
+		[]time.Duration{600 * time.Millisecond, 1200 * time.Millisecond, 2500 * time.Millisecond},
+		[]float64{30, 5, 7},
+		[]*float64{nil, float(40), float(45)},
+		[]string{"ok", "ok", "ok"},
+	)
+	require.NotNil(t, frame)
+	var names []string
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"time", "temperature_min", "temperature_max", "temperature_avg", "humidity", "status"}, names)
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, start, frame.Fields[0].At(0))
+	require.Equal(t, 10.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, 30.0, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, 20.0, *frame.Fields[3].At(0).(*float64))
+	require.Equal(t, 50.0, *frame.Fields[4].At(0).(*float64))
+	require.Equal(t, "ok", frame.Fields[5].At(0))
+	require.Equal(t, start.Add(time.Second), frame.Fields[0].At(1))
+	require.Equal(t, 5.0, *frame.Fields[3].At(1).(*float64))
+	require.Equal(t, 40.0, *frame.Fields[4].At(1).(*float64))
+
+	// State is kept per channel, the same frame in another org starts from
+	// the first window.
+	outputter.frame = nil
+	_, err = p.ProcessInput(context.Background(), 2, "stream/test/xxx", []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, 2, outputter.frame.Rows())
+	require.Equal(t, 30.0, *outputter.frame.Fields[3].At(0).(*float64))
+
+	// Current window of the first org is still open.
+	require.Nil(t, push([]time.Duration{2900 * time.Millisecond}, []float64{9}, []*float64{nil}, []string{"ok"}))
+}
+
+func TestPipeline_Rate(t *testing.T) {
+	for _, tc := range []struct {
+		mode     RateMode
+		expected []*float64
+	}{
+		{mode: RateModeRate, expected: []*float64{nil, float64Ptr(5), float64Ptr(4), float64Ptr(1)}},
+		{mode: RateModeDerivative, expected: []*float64{nil, float64Ptr(5), float64Ptr(-6), float64Ptr(1)}},
+	} {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			proc, err := NewRateFrameProcessor(RateFrameProcessorConfig{FieldNames: []string{"requests"}, Mode: tc.mode})
+			require.NoError(t, err)
+			converter := &testConverter{}
+			outputter := &testOutputter{}
+			p, err := New(&testRuleGetter{
+				rules: map[string]*LiveChannelRule{
+					"stream/test/xxx": {
+						Converter:       converter,
+						FrameProcessors: []FrameProcessor{proc},
+						FrameOutputters: []FrameOutputter{outputter},
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+			var result []*float64
+			for i, v := range []int64{10, 20, 8, 10} {
+				converter.frame = data.NewFrame("test",
+					data.NewField("time", nil, []time.Time{start.Add(time.Duration(i*2) * time.Second)}),
+					data.NewField("requests", nil, []int64{v}),
+					data.NewField("other", nil, []int64{v}),
+				)
+				_, err := p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
+				require.NoError(t, err)
+				require.Equal(t, v, outputter.frame.Fields[2].At(0))
+				result = append(result, outputter.frame.Fields[1].At(0).(*float64))
+			}
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestPipeline_AggregateFlush(t *testing.T) {
+	proc, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{WindowMilliseconds: 1000})
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	proc.now = func() time.Time { return now }
+	outputter := &testOutputter{}
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{
+			"stream/test/xxx": {
+				Converter: &testConverter{"", data.NewFrame("test",
+					data.NewField("time", nil, []time.Time{now, now.Add(500 * time.Millisecond)}),
+					data.NewField("value", nil, []float64{1, 2}),
+				)},
+				FrameProcessors: []FrameProcessor{NewMultipleFrameProcessor(proc)},
+				FrameOutputters: []FrameOutputter{outputter},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
+	require.NoError(t, err)
+	require.Nil(t, outputter.frame)
+
+	// Window is kept while the channel gets frames.
+	p.flushFrames(context.Background(), now.Add(500*time.Millisecond))
+	require.Nil(t, outputter.frame)
+	require.Len(t, p.flushers, 1)
+
+	p.flushFrames(context.Background(), now.Add(time.Second))
+	require.NotNil(t, outputter.frame)
+	require.Equal(t, 1, outputter.frame.Rows())
+	require.Equal(t, now, outputter.frame.Fields[0].At(0))
+	require.Equal(t, 2.0, *outputter.frame.Fields[1].At(0).(*float64))
+	require.Empty(t, proc.states)
+	require.Empty(t, p.flushers)
+}
+
+func TestPipeline_RateEvict(t *testing.T) {
+	proc, err := NewRateFrameProcessor(RateFrameProcessorConfig{})
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	proc.now = func() time.Time { return now }
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{
+			"stream/test/xxx": {
+				Converter: &testConverter{"", data.NewFrame("test",
+					data.NewField("time", nil, []time.Time{now}),
+					data.NewField("value", nil, []float64{1}),
+				)},
+				FrameProcessors: []FrameProcessor{proc},
+				FrameOutputters: []FrameOutputter{&testOutputter{}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
+	require.NoError(t, err)
+	p.flushFrames(context.Background(), now.Add(rateStateTTL-time.Second))
+	require.Len(t, proc.states, 1)
+
+	p.flushFrames(context.Background(), now.Add(rateStateTTL))
+	require.Empty(t, proc.states)
+	require.Empty(t, p.flushers)
+}
+
+type testRuleStorage struct {
+	Storage
+	rules []ChannelRule
+}
+
+func (t *testRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]ChannelRule, error) {
+	return t.rules, nil
+}
+
+func (t *testRuleStorage) ListWriteConfigs(_ context.Context, _ int64) ([]WriteConfig, error) {
+	return nil, nil
+}
+
+func TestPipeline_AggregateRebuild(t *testing.T) {
+	storage := &testRuleStorage{rules: []ChannelRule{{
+		Pattern: "stream/test/xxx",
+		Settings: ChannelRuleSettings{
+			FrameProcessors: []*FrameProcessorConfig{{
+				Type:                     FrameProcessorTypeAggregate,
+				AggregateProcessorConfig: &AggregateFrameProcessorConfig{WindowMilliseconds: 1000},
+			}},
+		},
+	}}}
+	builder := &StorageRuleBuilder{Storage: storage}
+	converter := &testConverter{}
+	outputter := &testOutputter{}
+	getter := &testRuleGetter{}
+	build := func() {
+		rules, err := builder.BuildRules(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		rules[0].Converter = converter
+		rules[0].FrameOutputters = []FrameOutputter{outputter}
+		getter.mu.Lock()
+		getter.rules = map[string]*LiveChannelRule{"stream/test/xxx": rules[0]}
+		getter.mu.Unlock()
+	}
+	p, err := New(getter)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	push := func(offset time.Duration, value float64) *data.Frame {
+		converter.frame = data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{start.Add(offset)}),
+			data.NewField("value", nil, []float64{value}),
+		)
+		outputter.frame = nil
+		_, err := p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
+		require.NoError(t, err)
+		return outputter.frame
+	}
+
+	build()
+	require.Nil(t, push(0, 1))
+	require.Nil(t, push(500*time.Millisecond, 2))
+
+	// Rules are rebuilt while the window is open, the window is kept.
+	build()
+	frame := push(time.Second, 3)
+	require.NotNil(t, frame)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start, frame.Fields[0].At(0))
+	require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+	require.Len(t, p.flushers, 1)
+
+	// A changed processor starts without state.
+	storage.rules[0].Settings.FrameProcessors[0].AggregateProcessorConfig = &AggregateFrameProcessorConfig{WindowMilliseconds: 2000}
+	build()
+	require.Nil(t, push(2*time.Second, 4))
+	require.Nil(t, push(3*time.Second, 5))
+	frame = push(4*time.Second, 6)
+	require.NotNil(t, frame)
+	require.Equal(t, start.Add(2*time.Second), frame.Fields[0].At(0))
+	require.Equal(t, 5.0, *frame.Fields[1].At(0).(*float64))
+}
+
+func TestPipeline_ConvertFields(t *testing.T) {
+	proc, err := NewConvertFieldsFrameProcessor(ConvertFieldsFrameProcessorConfig{
+		Fields: []ConvertFieldConfig{
+			{Name: "value", NewName: "temperature", Type: data.FieldTypeFloat64},
+			{Name: "ts", Type: data.FieldTypeTime},
+			{Name: "on", Type: data.FieldTypeBool},
+			{Name: "code", NewName: "status"},
+		},
+	})
+	require.NoError(t, err)
+	outputter := &testOutputter{}
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{
+			"stream/test/xxx": {
+				Converter: &testConverter{"", data.NewFrame("test",
+					data.NewField("value", nil, []string{"21.5", "n/a"}),
+					data.NewField("ts", nil, []int64{1717236000000, 1717236001000}),
+					data.NewField("on", nil, []string{"true", "0"}),
+					data.NewField("code", nil, []int64{200, 500}),
+				)},
+				FrameProcessors: []FrameProcessor{proc},
+				FrameOutputters: []FrameOutputter{outputter},
+			},
+		},
+	})
+	require.NoError(t, err)
+	_, err = p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
+	require.NoError(t, err)
+
+	frame := outputter.frame
+	require.Equal(t, "temperature", frame.Fields[0].Name)
+	require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[0].Type())
+	require.Equal(t, 21.5, *frame.Fields[0].At(0).(*float64))
+	require.Nil(t, frame.Fields[0].At(1))
+	require.Equal(t, time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), *frame.Fields[1].At(0).(*time.Time))
+	require.Equal(t, false, *frame.Fields[2].At(1).(*bool))
+	require.Equal(t, "status", frame.Fields[3].Name)
+	require.Equal(t, int64(500), frame.Fields[3].At(1))
+
+	_, err = NewConvertFieldsFrameProcessor(ConvertFieldsFrameProcessorConfig{
+		Fields: []ConvertFieldConfig{{Name: "value", Type: data.FieldTypeInt8}},
+	})
+	require.Error(t, err)
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
@@ -1,5 +1,9 @@
 package pipeline
 
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
 type EntityInfo struct {
 	Type        string `json:"type"`
 	Description string `json:"description"`
@@ -48,6 +52,28 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeKafka,
+		Description: "output frame as JSON to a Kafka topic",
+		Example: KafkaOutputConfig{
+			Topic: "grafana-live",
+		},
+	},
+	{
+		Type:        FrameOutputTypeMQTT,
+		Description: "publish frame as JSON to an MQTT topic",
+		Example: MQTTOutputConfig{
+			Topic: "grafana/live",
+		},
+	},
+	{
+		Type:        FrameOutputTypeFile,
+		Description: "archive frames to rotated NDJSON or Parquet files",
+		Example: FileOutputConfig{
+			Path:   "archive",
+			Format: FileOutputFormatNDJSON,
+		},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -66,6 +92,21 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypeOTLP,
+		Description: "accept OTLP/HTTP metrics in protobuf or JSON encoding",
+		Example: OTLPConverterConfig{
+			FrameFormat: "labels_column",
+			Encoding:    "protobuf",
+		},
+	},
+	{
+		Type:        ConverterTypePrometheus,
+		Description: "accept Prometheus text exposition format",
+		Example: PrometheusConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -79,6 +120,28 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeAggregate,
+		Description: "aggregate field values over tumbling time windows",
+		Example: AggregateFrameProcessorConfig{
+			WindowMilliseconds: 1000,
+			Functions:          []AggregateFunction{AggregateFunctionAvg},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeRate,
+		Description: "compute per-second rate or derivative of numeric fields",
+		Example: RateFrameProcessorConfig{
+			Mode: RateModeRate,
+		},
+	},
+	{
+		Type:        FrameProcessorTypeConvertFields,
+		Description: "rename fields and convert their types",
+		Example: ConvertFieldsFrameProcessorConfig{
+			Fields: []ConvertFieldConfig{{Name: "value", NewName: "temperature", Type: data.FieldTypeNullableFloat64}},
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
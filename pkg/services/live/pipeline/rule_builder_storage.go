@@ -2,7 +2,13 @@ package pipeline
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strconv"
 
 	"github.com/centrifugal/centrifuge"
 
@@ -17,6 +23,12 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	// FileOutputPath is a directory for file outputs, file outputs are
+	// disabled if empty.
+	FileOutputPath string
+
+	sinks      frameOutputSinks
+	processors frameProcessors
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -69,6 +81,16 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypeOTLP:
+		if config.OTLPConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewOTLPConverter(*config.OTLPConverterConfig), nil
+	case ConverterTypePrometheus:
+		if config.PrometheusConverterConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewPrometheusConverter(*config.PrometheusConverterConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
@@ -104,6 +126,21 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeAggregate:
+		if config.AggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAggregateFrameProcessor(*config.AggregateProcessorConfig)
+	case FrameProcessorTypeRate:
+		if config.RateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRateFrameProcessor(*config.RateProcessorConfig)
+	case FrameProcessorTypeConvertFields:
+		if config.ConvertFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewConvertFieldsFrameProcessor(*config.ConvertFieldsProcessorConfig)
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -164,7 +201,7 @@ func (f *StorageRuleBuilder) constructBasicAuth(writeConfig WriteConfig) (*Basic
 	}, nil
 }
 
-func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig, writeConfigs []WriteConfig) (FrameOutputter, error) {
+func (f *StorageRuleBuilder) extractFrameOutputter(orgID int64, config *FrameOutputterConfig, writeConfigs []WriteConfig, sinkKeys map[string]struct{}) (FrameOutputter, error) {
 	if config == nil {
 		return nil, nil
 	}
@@ -182,7 +219,7 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 		var outputters []FrameOutputter
 		for _, outConf := range config.MultipleOutputterConfig.Outputters {
 			out := outConf
-			outputter, err := f.extractFrameOutputter(&out, writeConfigs)
+			outputter, err := f.extractFrameOutputter(orgID, &out, writeConfigs, sinkKeys)
 			if err != nil {
 				return nil, err
 			}
@@ -201,7 +238,7 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 		if err != nil {
 			return nil, err
 		}
-		outputter, err := f.extractFrameOutputter(config.ConditionalOutputConfig.Outputter, writeConfigs)
+		outputter, err := f.extractFrameOutputter(orgID, config.ConditionalOutputConfig.Outputter, writeConfigs, sinkKeys)
 		if err != nil {
 			return nil, err
 		}
@@ -249,11 +286,128 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeKafka:
+		if config.KafkaOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		kafkaConfig := *config.KafkaOutputConfig
+		if kafkaConfig.Topic == "" {
+			return nil, errors.New("kafka topic required")
+		}
+		writeConfig, ok := f.getWriteConfig(kafkaConfig.UID, writeConfigs)
+		if !ok {
+			return nil, fmt.Errorf("unknown write config uid: %s", kafkaConfig.UID)
+		}
+		basicAuth, err := f.constructBasicAuth(writeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error getting password: %w", err)
+		}
+		sink, err := f.getSink(config.Type, orgID, []any{kafkaConfig, writeConfig.Settings.Endpoint, basicAuth}, sinkKeys, func() (*frameOutputSink, error) {
+			writer := newKafkaOutputWriter(writeConfig.Settings.Endpoint, kafkaConfig.Topic, basicAuth, batchSize(kafkaConfig.Batch))
+			return newFrameOutputSink(config.Type, kafkaConfig.Batch, writer), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewKafkaFrameOutput(sink), nil
+	case FrameOutputTypeMQTT:
+		if config.MQTTOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		mqttConfig := *config.MQTTOutputConfig
+		writeConfig, ok := f.getWriteConfig(mqttConfig.UID, writeConfigs)
+		if !ok {
+			return nil, fmt.Errorf("unknown write config uid: %s", mqttConfig.UID)
+		}
+		basicAuth, err := f.constructBasicAuth(writeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error getting password: %w", err)
+		}
+		sink, err := f.getSink(config.Type, orgID, []any{mqttConfig, writeConfig.Settings.Endpoint, basicAuth}, sinkKeys, func() (*frameOutputSink, error) {
+			writer, err := newMQTTOutputWriter(writeConfig.Settings.Endpoint, basicAuth, mqttConfig)
+			if err != nil {
+				return nil, err
+			}
+			return newFrameOutputSink(config.Type, mqttConfig.Batch, writer), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewMQTTFrameOutput(sink), nil
+	case FrameOutputTypeFile:
+		if config.FileOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		fileConfig := *config.FileOutputConfig
+		if f.FileOutputPath == "" {
+			return nil, errors.New("file outputs are not configured")
+		}
+		// Rules are managed by org admins, keep files inside the org directory.
+		if fileConfig.Path != "" && !filepath.IsLocal(fileConfig.Path) {
+			return nil, fmt.Errorf("invalid file output path: %s", fileConfig.Path)
+		}
+		dir := filepath.Join(f.FileOutputPath, strconv.FormatInt(orgID, 10), fileConfig.Path)
+		sink, err := f.getSink(config.Type, orgID, []any{fileConfig}, sinkKeys, func() (*frameOutputSink, error) {
+			writer, err := newFileOutputWriter(dir, fileConfig)
+			if err != nil {
+				return nil, err
+			}
+			return newFrameOutputSink(config.Type, fileConfig.Batch, writer), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewFileFrameOutput(sink), nil
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
 }
 
+func batchSize(config *FrameOutputBatchConfig) int {
+	if config == nil || config.MaxSize <= 0 {
+		return defaultOutputBatchSize
+	}
+	return config.MaxSize
+}
+
+// getSink returns a sink shared by outputs with the same settings across rule
+// builds. The key of the sink is added to sinkKeys of the build.
+func (f *StorageRuleBuilder) getSink(outputType string, orgID int64, settings []any, sinkKeys map[string]struct{}, create func() (*frameOutputSink, error)) (*frameOutputSink, error) {
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	// Settings may contain credentials.
+	hash := sha256.Sum256(settingsJSON)
+	key := outputType + "/" + strconv.FormatInt(orgID, 10) + "/" + hex.EncodeToString(hash[:])
+	if sinkKeys != nil {
+		sinkKeys[key] = struct{}{}
+	}
+	return f.sinks.get(key, create)
+}
+
+// getFrameProcessor returns the processor of the previous build of the org if
+// the rule has the same processor configuration at the same position, so that
+// the state of the processor is kept. The processor is added to processors of
+// the build.
+func (f *StorageRuleBuilder) getFrameProcessor(orgID int64, pattern string, index int, config *FrameProcessorConfig, processors map[string]FrameProcessor) (FrameProcessor, error) {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(configJSON)
+	key := pattern + "/" + strconv.Itoa(index) + "/" + hex.EncodeToString(hash[:])
+	proc, ok := f.processors.get(orgID, key)
+	if !ok {
+		proc, err = f.extractFrameProcessor(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	processors[key] = proc
+	return proc, nil
+}
+
 func (f *StorageRuleBuilder) extractDataOutputter(config *DataOutputterConfig, writeConfigs []WriteConfig) (DataOutputter, error) {
 	if config == nil {
 		return nil, nil
@@ -311,6 +465,8 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 	}
 
 	rules := make([]*LiveChannelRule, 0, len(channelRules))
+	sinkKeys := map[string]struct{}{}
+	processorsByKey := map[string]FrameProcessor{}
 
 	for _, ruleConfig := range channelRules {
 		rule := &LiveChannelRule{
@@ -334,8 +490,8 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		}
 
 		var processors []FrameProcessor
-		for _, procConfig := range ruleConfig.Settings.FrameProcessors {
-			proc, err := f.extractFrameProcessor(procConfig)
+		for i, procConfig := range ruleConfig.Settings.FrameProcessors {
+			proc, err := f.getFrameProcessor(orgID, ruleConfig.Pattern, i, procConfig, processorsByKey)
 			if err != nil {
 				return nil, fmt.Errorf("error building processor for %s: %w", rule.Pattern, err)
 			}
@@ -355,7 +511,7 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 
 		var outputters []FrameOutputter
 		for _, outConfig := range ruleConfig.Settings.FrameOutputters {
-			out, err := f.extractFrameOutputter(outConfig, writeConfigs)
+			out, err := f.extractFrameOutputter(orgID, outConfig, writeConfigs, sinkKeys)
 			if err != nil {
 				return nil, fmt.Errorf("error building frame outputter for %s: %w", rule.Pattern, err)
 			}
@@ -376,5 +532,7 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		rules = append(rules, rule)
 	}
 
+	f.sinks.setUsed(orgID, sinkKeys)
+	f.processors.setUsed(orgID, processorsByKey)
 	return rules, nil
 }
@@ -1,12 +1,14 @@
 package pushurl
 
 import (
+	"mime"
 	"net/url"
 	"strings"
 )
 
 const (
 	frameFormatParam = "gf_live_frame_format"
+	inputFormatParam = "gf_live_input_format"
 )
 
 // FrameFormatFromValues extracts frame format tip from url values.
@@ -17,3 +19,17 @@ func FrameFormatFromValues(values url.Values) string {
 	}
 	return frameFormat
 }
+
+// InputFormatFromValues extracts input format from url values. Without it the
+// format is derived from the content type, so OTLP/HTTP exporters sending
+// protobuf can push without extra parameters. Defaults to Influx line protocol.
+func InputFormatFromValues(values url.Values, contentType string) string {
+	inputFormat := strings.ToLower(values.Get(inputFormatParam))
+	if inputFormat != "" {
+		return inputFormat
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "application/x-protobuf" {
+		return "otlp"
+	}
+	return "influx"
+}
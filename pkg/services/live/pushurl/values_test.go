@@ -13,3 +13,12 @@ func TestFrameFormatFromValues(t *testing.T) {
 	values.Set(frameFormatParam, "wide")
 	require.Equal(t, "wide", FrameFormatFromValues(values))
 }
+
+func TestInputFormatFromValues(t *testing.T) {
+	values := url.Values{}
+	require.Equal(t, "influx", InputFormatFromValues(values, ""))
+	require.Equal(t, "influx", InputFormatFromValues(values, "text/plain"))
+	require.Equal(t, "otlp", InputFormatFromValues(values, "application/x-protobuf"))
+	values.Set(inputFormatParam, "Prometheus")
+	require.Equal(t, "prometheus", InputFormatFromValues(values, "application/x-protobuf"))
+}
@@ -0,0 +1,170 @@
+package otlp
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts OTLP metrics export requests to Grafana frames. Resource
+// and data point attributes become labels, data point attributes take
+// precedence.
+type Converter struct {
+	useJSON         bool
+	useLabelsColumn bool
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithJSON makes the converter expect the JSON encoding of OTLP/HTTP instead of
+// protobuf.
+func WithJSON(enabled bool) ConverterOption {
+	return func(c *Converter) {
+		c.useJSON = enabled
+	}
+}
+
+// WithUseLabelsColumn ...
+func WithUseLabelsColumn(enabled bool) ConverterOption {
+	return func(c *Converter) {
+		c.useLabelsColumn = enabled
+	}
+}
+
+// NewConverter creates new Converter from OTLP to Grafana Data Frames.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	req := pmetricotlp.NewExportRequest()
+	var err error
+	if c.useJSON {
+		err = req.UnmarshalJSON(body)
+	} else {
+		err = req.UnmarshalProto(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+	return telemetry.SamplesToFrames(toSamples(req.Metrics()), c.useLabelsColumn), nil
+}
+
+func toSamples(md pmetric.Metrics) []telemetry.Sample {
+	var samples []telemetry.Sample
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		resourceLabels := attributesToLabels(rm.Resource().Attributes(), nil)
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			metrics := rm.ScopeMetrics().At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				samples = appendMetricSamples(samples, metrics.At(k), resourceLabels)
+			}
+		}
+	}
+	return samples
+}
+
+func appendMetricSamples(samples []telemetry.Sample, m pmetric.Metric, resourceLabels data.Labels) []telemetry.Sample {
+	sample := func(attrs pcommon.Map, ts pcommon.Timestamp, values map[string]float64) telemetry.Sample {
+		return telemetry.Sample{
+			Name:   m.Name(),
+			Labels: attributesToLabels(attrs, resourceLabels),
+			Time:   ts.AsTime(),
+			Values: values,
+		}
+	}
+
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		return appendNumberSamples(samples, m.Gauge().DataPoints(), sample)
+	case pmetric.MetricTypeSum:
+		return appendNumberSamples(samples, m.Sum().DataPoints(), sample)
+	case pmetric.MetricTypeHistogram:
+		points := m.Histogram().DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			p := points.At(i)
+			values := map[string]float64{"count": float64(p.Count())}
+			if p.HasSum() {
+				values["sum"] = p.Sum()
+			}
+			// Buckets are cumulative like Prometheus histograms.
+			var cumulative uint64
+			for b := 0; b < p.BucketCounts().Len(); b++ {
+				cumulative += p.BucketCounts().At(b)
+				bound := "+Inf"
+				if b < p.ExplicitBounds().Len() {
+					bound = strconv.FormatFloat(p.ExplicitBounds().At(b), 'g', -1, 64)
+				}
+				values["le_"+bound] = float64(cumulative)
+			}
+			samples = append(samples, sample(p.Attributes(), p.Timestamp(), values))
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		points := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			p := points.At(i)
+			values := map[string]float64{"count": float64(p.Count())}
+			if p.HasSum() {
+				values["sum"] = p.Sum()
+			}
+			samples = append(samples, sample(p.Attributes(), p.Timestamp(), values))
+		}
+	case pmetric.MetricTypeSummary:
+		points := m.Summary().DataPoints()
+		for i := 0; i < points.Len(); i++ {
+			p := points.At(i)
+			values := map[string]float64{"count": float64(p.Count()), "sum": p.Sum()}
+			for q := 0; q < p.QuantileValues().Len(); q++ {
+				qv := p.QuantileValues().At(q)
+				values["quantile_"+strconv.FormatFloat(qv.Quantile(), 'g', -1, 64)] = qv.Value()
+			}
+			samples = append(samples, sample(p.Attributes(), p.Timestamp(), values))
+		}
+	}
+	return samples
+}
+
+func appendNumberSamples(samples []telemetry.Sample, points pmetric.NumberDataPointSlice, sample func(pcommon.Map, pcommon.Timestamp, map[string]float64) telemetry.Sample) []telemetry.Sample {
+	for i := 0; i < points.Len(); i++ {
+		p := points.At(i)
+		var v float64
+		switch p.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			v = float64(p.IntValue())
+		case pmetric.NumberDataPointValueTypeDouble:
+			v = p.DoubleValue()
+		default:
+			continue
+		}
+		samples = append(samples, sample(p.Attributes(), p.Timestamp(), map[string]float64{"value": v}))
+	}
+	return samples
+}
+
+// attributesToLabels returns the attributes as labels on top of base.
+func attributesToLabels(attrs pcommon.Map, base data.Labels) data.Labels {
+	labels := make(data.Labels, len(base)+attrs.Len())
+	for k, v := range base {
+		labels[k] = v
+	}
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		labels[k] = v.AsString()
+		return true
+	})
+	return labels
+}
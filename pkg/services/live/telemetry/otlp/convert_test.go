@@ -0,0 +1,106 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+)
+
+var testTime = time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func testMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "press")
+	rm.Resource().Attributes().PutStr("host", "edge-1")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("temperature")
+	points := gauge.SetEmptyGauge().DataPoints()
+	for _, zone := range []string{"a", "b"} {
+		p := points.AppendEmpty()
+		p.Attributes().PutStr("zone", zone)
+		p.Attributes().PutStr("host", "override")
+		p.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+		p.SetDoubleValue(21.5)
+	}
+
+	sum := metrics.AppendEmpty()
+	sum.SetName("parts")
+	p := sum.SetEmptySum().DataPoints().AppendEmpty()
+	p.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	p.SetIntValue(42)
+
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("cycle_seconds")
+	hp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	hp.SetCount(6)
+	hp.SetSum(9)
+	hp.ExplicitBounds().FromRaw([]float64{1, 2})
+	hp.BucketCounts().FromRaw([]uint64{1, 2, 3})
+	return md
+}
+
+func TestConverter_Convert(t *testing.T) {
+	req := pmetricotlp.NewExportRequestFromMetrics(testMetrics())
+	protoBody, err := req.MarshalProto()
+	require.NoError(t, err)
+	jsonBody, err := req.MarshalJSON()
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		converter *Converter
+		body      []byte
+	}{
+		"protobuf": {converter: NewConverter(WithUseLabelsColumn(true)), body: protoBody},
+		"json":     {converter: NewConverter(WithJSON(true), WithUseLabelsColumn(true)), body: jsonBody},
+	} {
+		t.Run(name, func(t *testing.T) {
+			frameWrappers, err := tc.converter.Convert(tc.body)
+			require.NoError(t, err)
+			require.Len(t, frameWrappers, 3)
+
+			temperature := frameWrappers[0].Frame()
+			require.Equal(t, "temperature", frameWrappers[0].Key())
+			require.Equal(t, 2, temperature.Rows())
+			require.Equal(t, "host=override, service.name=press, zone=a", temperature.Fields[0].At(0))
+			require.Equal(t, testTime, temperature.Fields[1].At(0))
+			require.Equal(t, "value", temperature.Fields[2].Name)
+			require.Equal(t, 21.5, *temperature.Fields[2].At(1).(*float64))
+
+			parts := frameWrappers[1].Frame()
+			require.Equal(t, "host=edge-1, service.name=press", parts.Fields[0].At(0))
+			require.Equal(t, 42.0, *parts.Fields[2].At(0).(*float64))
+
+			histogram := frameWrappers[2].Frame()
+			values := map[string]float64{}
+			for _, f := range histogram.Fields[2:] {
+				values[f.Name] = *f.At(0).(*float64)
+			}
+			require.Equal(t, map[string]float64{"count": 6, "sum": 9, "le_1": 1, "le_2": 3, "le_+Inf": 6}, values)
+		})
+	}
+
+	t.Run("wide", func(t *testing.T) {
+		frameWrappers, err := NewConverter().Convert(protoBody)
+		require.NoError(t, err)
+		require.Len(t, frameWrappers, 3)
+
+		temperature := frameWrappers[0].Frame()
+		require.Len(t, temperature.Fields, 3)
+		require.Equal(t, 1, temperature.Rows())
+		require.Equal(t, data.Labels{"host": "override", "service.name": "press", "zone": "b"}, temperature.Fields[2].Labels)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, err := NewConverter(WithJSON(true)).Convert([]byte("{"))
+		require.ErrorContains(t, err, "error parsing metrics")
+	})
+}
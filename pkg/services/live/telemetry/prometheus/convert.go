@@ -0,0 +1,107 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts metrics in the Prometheus text exposition format to
+// Grafana frames. Samples without timestamp get the time of the conversion.
+type Converter struct {
+	useLabelsColumn bool
+	now             func() time.Time
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithUseLabelsColumn ...
+func WithUseLabelsColumn(enabled bool) ConverterOption {
+	return func(c *Converter) {
+		c.useLabelsColumn = enabled
+	}
+}
+
+// NewConverter creates new Converter from Prometheus text format to Grafana Data Frames.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+	// Families are returned in a map, sort them for stable frame order.
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := c.now()
+	var samples []telemetry.Sample
+	for _, name := range names {
+		family := families[name]
+		for _, m := range family.GetMetric() {
+			values := metricValues(family.GetType(), m)
+			if values == nil {
+				continue
+			}
+			ts := now
+			if m.TimestampMs != nil {
+				ts = time.UnixMilli(m.GetTimestampMs())
+			}
+			labels := make(data.Labels, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			samples = append(samples, telemetry.Sample{Name: name, Labels: labels, Time: ts, Values: values})
+		}
+	}
+	return telemetry.SamplesToFrames(samples, c.useLabelsColumn), nil
+}
+
+func metricValues(t dto.MetricType, m *dto.Metric) map[string]float64 {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return map[string]float64{"value": m.GetCounter().GetValue()}
+	case dto.MetricType_GAUGE:
+		return map[string]float64{"value": m.GetGauge().GetValue()}
+	case dto.MetricType_UNTYPED:
+		return map[string]float64{"value": m.GetUntyped().GetValue()}
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		values := map[string]float64{"count": float64(s.GetSampleCount()), "sum": s.GetSampleSum()}
+		for _, q := range s.GetQuantile() {
+			values["quantile_"+strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64)] = q.GetValue()
+		}
+		return values
+	case dto.MetricType_HISTOGRAM:
+		h := m.GetHistogram()
+		values := map[string]float64{"count": float64(h.GetSampleCount()), "sum": h.GetSampleSum()}
+		for _, b := range h.GetBucket() {
+			values["le_"+strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)] = float64(b.GetCumulativeCount())
+		}
+		values["le_+Inf"] = float64(h.GetSampleCount())
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+const testBody = `# TYPE http_requests_total counter
+http_requests_total{method="get",code="200"} 1027
+http_requests_total{method="post",code="200"} 3 1717236000000
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds{quantile="0.99"} 0.2
+rpc_duration_seconds_sum 17.5
+rpc_duration_seconds_count 250
+`
+
+func testConverter(opts ...ConverterOption) *Converter {
+	c := NewConverter(opts...)
+	c.now = func() time.Time { return testTime.Add(time.Minute) }
+	return c
+}
+
+func TestConverter_Convert(t *testing.T) {
+	t.Run("labels column", func(t *testing.T) {
+		frameWrappers, err := testConverter(WithUseLabelsColumn(true)).Convert([]byte(testBody))
+		require.NoError(t, err)
+		require.Len(t, frameWrappers, 2)
+
+		requests := frameWrappers[0].Frame()
+		require.Equal(t, "http_requests_total", frameWrappers[0].Key())
+		require.Equal(t, 2, requests.Rows())
+		require.Equal(t, "code=200, method=get", requests.Fields[0].At(0))
+		require.Equal(t, testTime.Add(time.Minute), requests.Fields[1].At(0))
+		require.Equal(t, 1027.0, *requests.Fields[2].At(0).(*float64))
+		require.True(t, testTime.Equal(requests.Fields[1].At(1).(time.Time)))
+
+		summary := frameWrappers[1].Frame()
+		values := map[string]float64{}
+		for _, f := range summary.Fields[2:] {
+			values[f.Name] = *f.At(0).(*float64)
+		}
+		require.Equal(t, map[string]float64{"count": 250, "sum": 17.5, "quantile_0.5": 0.05, "quantile_0.99": 0.2}, values)
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		frameWrappers, err := testConverter().Convert([]byte(testBody))
+		require.NoError(t, err)
+		// Requests are split by timestamp.
+		require.Len(t, frameWrappers, 3)
+		requests := frameWrappers[0].Frame()
+		require.Len(t, requests.Fields, 2)
+		require.Equal(t, data.Labels{"code": "200", "method": "get"}, requests.Fields[1].Labels)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, err := NewConverter().Convert([]byte("metric{ 1"))
+		require.ErrorContains(t, err, "error parsing metrics")
+	})
+}
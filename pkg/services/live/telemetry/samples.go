@@ -0,0 +1,112 @@
+package telemetry
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Sample is a set of values of a metric series at a point in time.
+type Sample struct {
+	// Name of the metric, used as the frame key.
+	Name   string
+	Labels data.Labels
+	Time   time.Time
+	// Values maps field names to values, e.g. value, or count and sum.
+	Values map[string]float64
+}
+
+type sampleFrame struct {
+	key    string
+	fields []*data.Field
+}
+
+func (f *sampleFrame) Key() string {
+	return f.key
+}
+
+func (f *sampleFrame) Frame() *data.Frame {
+	return data.NewFrame(f.key, f.fields...)
+}
+
+// SamplesToFrames converts samples to frames in the same layouts as the Telegraf
+// converter. With labels column there is one frame per metric with a row per
+// series, otherwise one frame per metric and time with a field per series.
+// Frames are returned in the order metrics appear in samples.
+func SamplesToFrames(samples []Sample, useLabelsColumn bool) []FrameWrapper {
+	var order []string
+	frames := map[string]*sampleFrame{}
+	rows := map[string]int{}
+	columns := map[string]map[string]*data.Field{}
+
+	for _, s := range samples {
+		key := s.Name
+		if !useLabelsColumn {
+			key = s.Name + "_" + s.Time.String()
+		}
+		frame, ok := frames[key]
+		if !ok {
+			frame = &sampleFrame{key: s.Name}
+			if useLabelsColumn {
+				frame.fields = []*data.Field{
+					data.NewField("labels", nil, []string{}),
+					data.NewField("time", nil, []time.Time{}),
+				}
+				columns[key] = map[string]*data.Field{}
+			} else {
+				frame.fields = []*data.Field{data.NewField("time", nil, []time.Time{s.Time})}
+			}
+			frames[key] = frame
+			order = append(order, key)
+		}
+
+		names := make([]string, 0, len(s.Values))
+		for name := range s.Values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if !useLabelsColumn {
+			for _, name := range names {
+				v := s.Values[name]
+				field := data.NewField(name, s.Labels, []*float64{&v})
+				frame.fields = append(frame.fields, field)
+			}
+			continue
+		}
+
+		frame.fields[0].Append(s.Labels.String())
+		frame.fields[1].Append(s.Time)
+		rows[key]++
+		for _, name := range names {
+			v := s.Values[name]
+			field, ok := columns[key][name]
+			if !ok {
+				field = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+				field.Name = name
+				columns[key][name] = field
+				frame.fields = append(frame.fields, field)
+			}
+			// Columns that appeared later or are missing in some samples are filled with nulls.
+			for field.Len() < rows[key]-1 {
+				field.Append(nil)
+			}
+			field.Append(&v)
+		}
+	}
+
+	result := make([]FrameWrapper, 0, len(order))
+	for _, key := range order {
+		frame := frames[key]
+		if useLabelsColumn {
+			for _, field := range frame.fields[2:] {
+				for field.Len() < rows[key] {
+					field.Append(nil)
+				}
+			}
+		}
+		result = append(result, frame)
+	}
+	return result
+}
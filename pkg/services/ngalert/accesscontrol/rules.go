@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/template"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
@@ -53,7 +54,8 @@ func (r *RuleService) getRulesReadEvaluator(rules ...*models.AlertRule) accessco
 	return accesscontrol.EvalAll(evals...)
 }
 
-// getRulesQueryEvaluator constructs accesscontrol.Evaluator that checks all permissions to query data sources used by the provided rules
+// getRulesQueryEvaluator constructs accesscontrol.Evaluator that checks all permissions to query data sources used by the provided rules,
+// including the data sources of the query function in their templates
 func (r *RuleService) getRulesQueryEvaluator(rules ...*models.AlertRule) accesscontrol.Evaluator {
 	added := make(map[string]struct{}, 2)
 	evals := make([]accesscontrol.Evaluator, 0, 2)
@@ -69,6 +71,14 @@ func (r *RuleService) getRulesQueryEvaluator(rules ...*models.AlertRule) accessc
 			evals = append(evals, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(query.DatasourceUID)))
 			added[query.DatasourceUID] = struct{}{}
 		}
+		// The query function in templates runs queries on behalf of the rule.
+		for _, uid := range template.QueryDatasourceUIDs(rule.Labels, rule.Annotations) {
+			if _, ok := added[uid]; ok {
+				continue
+			}
+			evals = append(evals, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(uid)))
+			added[uid] = struct{}{}
+		}
 	}
 	if len(evals) == 1 {
 		return evals[0]
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"math/rand"
+	"slices"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -397,6 +398,31 @@ func TestCheckDatasourcePermissionsForRule(t *testing.T) {
 		require.Len(t, ac.EvaluateRecordings, 1)
 	})
 
+	t.Run("should check data sources of queries in templates", func(t *testing.T) {
+		ruleWithTemplateQuery := models.CopyRule(rule)
+		ruleWithTemplateQuery.Annotations = map[string]string{
+			"summary": "{{ range query `{\"datasource\": \"template-ds\", \"expr\": \"up\"}` }}{{ .Value }}{{ end }}",
+		}
+		ac := &recordingAccessControlFake{
+			Callback: func(user identity.Requester, evaluator accesscontrol.Evaluator) (bool, error) {
+				return evaluator.Evaluate(user.GetPermissions()), nil
+			},
+		}
+		svc := RuleService{
+			genericService{ac: ac},
+		}
+
+		err := svc.AuthorizeDatasourceAccessForRule(context.Background(), createUserWithPermissions(map[string][]string{
+			datasources.ActionQuery: scopes,
+		}), ruleWithTemplateQuery)
+		require.Error(t, err)
+
+		err = svc.AuthorizeDatasourceAccessForRule(context.Background(), createUserWithPermissions(map[string][]string{
+			datasources.ActionQuery: append(slices.Clone(scopes), datasources.ScopeProvider.GetResourceScopeUID("template-ds")),
+		}), ruleWithTemplateQuery)
+		require.NoError(t, err)
+	})
+
 	t.Run("should return on first negative evaluation", func(t *testing.T) {
 		ac := &recordingAccessControlFake{
 			Callback: func(user identity.Requester, evaluator accesscontrol.Evaluator) (bool, error) {
@@ -10,15 +10,21 @@ import (
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	authz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -33,6 +39,9 @@ type AlertmanagerSrv struct {
 	mam        *notifier.MultiOrgAlertmanager
 	crypto     notifier.Crypto
 	silenceSvc SilenceService
+	ruleStore  RuleStore
+	authz      RuleAccessControlService
+	cfg        *setting.UnifiedAlertingSettings
 }
 
 type UnknownReceiverError struct {
@@ -279,6 +288,109 @@ func (srv AlertmanagerSrv) RoutePostTestTemplates(c *contextmodel.ReqContext, bo
 	return response.JSON(http.StatusOK, newTestTemplateResult(res))
 }
 
+// RoutePostRoutingSimulation returns the routes of the notification policy tree that an alert matches, with their
+// timing, time intervals, and the silences that match the alert. If a rule UID is given, the alert has the labels of
+// an alert instance of the rule. Nothing is sent.
+func (srv AlertmanagerSrv) RoutePostRoutingSimulation(c *contextmodel.ReqContext, body apimodels.RoutingSimulationBody) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	if _, errResp := srv.AlertmanagerFor(orgID); errResp != nil {
+		return errResp
+	}
+
+	at := time.Now()
+	if body.Time != nil {
+		at = time.Time(*body.Time)
+	}
+
+	lbls := make(model.LabelSet, len(body.Labels))
+	for k, v := range body.Labels {
+		lbls[k] = v
+	}
+	if body.RuleUID != "" {
+		rule, err := srv.ruleStore.GetAlertRuleByUID(ctx, &ngmodels.GetAlertRuleByUIDQuery{UID: body.RuleUID, OrgID: orgID})
+		if err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				return ErrResp(http.StatusNotFound, err, "")
+			}
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+		}
+		if err := srv.authz.AuthorizeAccessInFolder(ctx, c.SignedInUser, rule); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to the rule", err)
+		}
+		namespace, err := srv.ruleStore.GetNamespaceByUID(ctx, rule.NamespaceUID, orgID, c.SignedInUser)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to get the folder of the rule", err)
+		}
+		instance := make(data.Labels, len(body.Labels))
+		for k, v := range body.Labels {
+			instance[string(k)] = string(v)
+		}
+		includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(ngmodels.FolderTitleLabel)
+		lbls = make(model.LabelSet)
+		for k, v := range state.GetRuleInstanceLabels(ctx, srv.log, rule, namespace.Fullpath, includeFolder, instance, at) {
+			lbls[model.LabelName(k)] = model.LabelValue(v)
+		}
+	}
+	if len(lbls) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("labels or rule UID must be specified"), "")
+	}
+
+	// Only the silences that the user can read are matched, users without access to silences get none.
+	silences, err := srv.silenceSvc.ListSilences(ctx, c.SignedInUser, nil)
+	if err != nil {
+		if !errors.Is(err, authz.ErrAuthorizationBase) {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to list silences", err)
+		}
+		silences = nil
+	}
+
+	result, err := srv.mam.SimulateRouting(ctx, orgID, notifier.RoutingSimulationQuery{Labels: lbls, Time: at, Silences: silences})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to simulate routing", err)
+	}
+	return response.JSON(http.StatusOK, newRoutingSimulationResult(result))
+}
+
+func newRoutingSimulationResult(r *notifier.RoutingSimulationResult) apimodels.RoutingSimulationResult {
+	result := apimodels.RoutingSimulationResult{
+		Labels:   r.Labels,
+		Time:     r.Time,
+		Routes:   make([]apimodels.SimulatedRoute, 0, len(r.Routes)),
+		Silences: make([]apimodels.GettableSilence, 0, len(r.Silences)),
+	}
+	for _, route := range r.Routes {
+		groupBy := route.GroupBy
+		if route.GroupByAll {
+			groupBy = []string{"..."}
+		}
+		simulated := apimodels.SimulatedRoute{
+			Path:           make([]apimodels.SimulatedRouteNode, 0, len(route.Path)),
+			Receiver:       route.Receiver,
+			GroupBy:        groupBy,
+			GroupLabels:    route.GroupLabels,
+			GroupWait:      model.Duration(route.GroupWait),
+			GroupInterval:  model.Duration(route.GroupInterval),
+			RepeatInterval: model.Duration(route.RepeatInterval),
+			Muted:          route.Muted,
+		}
+		for _, node := range route.Path {
+			simulated.Path = append(simulated.Path, apimodels.SimulatedRouteNode(node))
+		}
+		for _, ti := range route.MuteTimeIntervals {
+			simulated.MuteTimeIntervals = append(simulated.MuteTimeIntervals, apimodels.SimulatedTimeInterval(ti))
+		}
+		for _, ti := range route.ActiveTimeIntervals {
+			simulated.ActiveTimeIntervals = append(simulated.ActiveTimeIntervals, apimodels.SimulatedTimeInterval(ti))
+		}
+		result.Routes = append(result.Routes, simulated)
+	}
+	for _, s := range r.Silences {
+		result.Silences = append(result.Silences, apimodels.GettableSilence(*s))
+	}
+	return result
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
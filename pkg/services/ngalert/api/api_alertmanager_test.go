@@ -12,8 +12,10 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	alertingModels "github.com/grafana/alerting/models"
 	alertingNotify "github.com/grafana/alerting/notify"
 
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
@@ -21,9 +23,12 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -623,6 +628,131 @@ func TestRoutePostTestTemplates(t *testing.T) {
 	})
 }
 
+func TestRoutePostRoutingSimulation(t *testing.T) {
+	t.Run("returns the routes that the labels match", func(t *testing.T) {
+		sut := createSut(t)
+		rc := createRequestCtxInOrg(1)
+
+		response := sut.RoutePostRoutingSimulation(rc, apimodels.RoutingSimulationBody{Labels: model.LabelSet{"alertname": "test"}})
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result apimodels.RoutingSimulationResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, model.LabelSet{"alertname": "test"}, result.Labels)
+		require.Len(t, result.Routes, 1)
+		require.Equal(t, "grafana-default-email", result.Routes[0].Receiver)
+		require.Len(t, result.Routes[0].Path, 1)
+		require.Empty(t, result.Routes[0].GroupBy)
+		require.Empty(t, result.Silences)
+	})
+
+	t.Run("adds the labels of the rule", func(t *testing.T) {
+		sut := createSut(t)
+		sut.authz = &fakeRuleAccessControlService{}
+		ruleStore := sut.ruleStore.(*ngfakes.RuleStore)
+		f := &folder.Folder{UID: "folder-uid", Title: "Folder", Fullpath: "Parent/Folder"}
+		ruleStore.Folders[1] = append(ruleStore.Folders[1], f)
+		rule := ngmodels.RuleGen.With(
+			ngmodels.RuleMuts.WithOrgID(1),
+			ngmodels.RuleMuts.WithNamespaceUID(f.UID),
+			ngmodels.RuleMuts.WithLabels(map[string]string{"team": "a"}),
+		).GenerateRef()
+		ruleStore.PutRule(context.Background(), rule)
+		rc := createRequestCtxInOrg(1)
+
+		response := sut.RoutePostRoutingSimulation(rc, apimodels.RoutingSimulationBody{RuleUID: rule.UID, Labels: model.LabelSet{"instance": "a"}})
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result apimodels.RoutingSimulationResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, model.LabelValue("a"), result.Labels["team"])
+		require.Equal(t, model.LabelValue("a"), result.Labels["instance"])
+		require.Equal(t, model.LabelValue(rule.Title), result.Labels[model.AlertNameLabel])
+		require.Equal(t, model.LabelValue(f.Fullpath), result.Labels[ngmodels.FolderTitleLabel])
+	})
+
+	t.Run("returns 403 if user cannot access the rule", func(t *testing.T) {
+		sut := createSut(t)
+		rule := ngmodels.RuleGen.With(ngmodels.RuleMuts.WithOrgID(1)).GenerateRef()
+		sut.ruleStore.(*ngfakes.RuleStore).PutRule(context.Background(), rule)
+		rc := createRequestCtxInOrg(1)
+
+		response := sut.RoutePostRoutingSimulation(rc, apimodels.RoutingSimulationBody{RuleUID: rule.UID})
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("returns 404 if the rule does not exist", func(t *testing.T) {
+		sut := createSut(t)
+		sut.ruleStore.(*ngfakes.RuleStore).PutRule(context.Background(), ngmodels.RuleGen.With(ngmodels.RuleMuts.WithOrgID(1)).GenerateRef())
+		rc := createRequestCtxInOrg(1)
+
+		response := sut.RoutePostRoutingSimulation(rc, apimodels.RoutingSimulationBody{RuleUID: "unknown"})
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("returns 400 without labels", func(t *testing.T) {
+		sut := createSut(t)
+		rc := createRequestCtxInOrg(1)
+
+		response := sut.RoutePostRoutingSimulation(rc, apimodels.RoutingSimulationBody{})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("returns only the silences that the user can read", func(t *testing.T) {
+		sut := createSut(t)
+		ruleStore := sut.ruleStore.(*ngfakes.RuleStore)
+		rule := ngmodels.RuleGen.With(ngmodels.RuleMuts.WithOrgID(1), ngmodels.RuleMuts.WithNamespaceUID("folder-uid")).GenerateRef()
+		ruleStore.PutRule(context.Background(), rule)
+
+		onlyMatchers := func(s *ngmodels.Silence) {
+			s.Silence.Matchers = nil
+		}
+		silenceGen := ngmodels.SilenceGen(ngmodels.SilenceMuts.WithEmptyId(), onlyMatchers, ngmodels.SilenceMuts.WithMatcher("alertname", "test", labels.MatchEqual))
+		general, err := sut.mam.CreateSilence(context.Background(), 1, silenceGen())
+		require.NoError(t, err)
+		ruleSilence, err := sut.mam.CreateSilence(context.Background(), 1, ngmodels.CopySilenceWith(silenceGen(), ngmodels.SilenceMuts.WithRuleUID(rule.UID)))
+		require.NoError(t, err)
+
+		silenceIDs := func(permissions map[string][]string) []string {
+			rc := createRequestCtxInOrg(1)
+			rc.SignedInUser.Permissions = map[int64]map[string][]string{1: permissions}
+			body := apimodels.RoutingSimulationBody{Labels: model.LabelSet{"alertname": "test", alertingModels.RuleUIDLabel: model.LabelValue(rule.UID)}}
+			response := sut.RoutePostRoutingSimulation(rc, body)
+			require.Equal(t, http.StatusOK, response.Status())
+
+			var result apimodels.RoutingSimulationResult
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			ids := make([]string, 0, len(result.Silences))
+			for _, s := range result.Silences {
+				ids = append(ids, *s.ID)
+			}
+			return ids
+		}
+
+		require.Empty(t, silenceIDs(nil))
+		require.ElementsMatch(t, []string{general, ruleSilence}, silenceIDs(map[string][]string{ac.ActionAlertingInstanceRead: {}}))
+		require.Equal(t, []string{general}, silenceIDs(map[string][]string{
+			ac.ActionAlertingSilencesRead: {dashboards.ScopeFoldersProvider.GetResourceScopeUID("other-folder-uid")},
+		}))
+	})
+}
+
+func TestNewRoutingSimulationResult(t *testing.T) {
+	t.Run("groups by all labels", func(t *testing.T) {
+		result := newRoutingSimulationResult(&notifier.RoutingSimulationResult{
+			Routes: []notifier.SimulatedRoute{{Receiver: "a", GroupByAll: true}},
+		})
+		require.Equal(t, []string{"..."}, result.Routes[0].GroupBy)
+	})
+
+	t.Run("groups by the labels of the route", func(t *testing.T) {
+		result := newRoutingSimulationResult(&notifier.RoutingSimulationResult{
+			Routes: []notifier.SimulatedRoute{{Receiver: "a", GroupBy: []string{"alertname", "team"}}},
+		})
+		require.Equal(t, []string{"alertname", "team"}, result.Routes[0].GroupBy)
+	})
+}
+
 func createSut(t *testing.T) AlertmanagerSrv {
 	t.Helper()
 
@@ -642,6 +772,9 @@ func createSut(t *testing.T) AlertmanagerSrv {
 		ac:         ac,
 		log:        log,
 		silenceSvc: notifier.NewSilenceService(accesscontrol.NewSilenceService(ac, ruleStore), ruleStore, log, mam, ruleStore, ruleAuthzService),
+		ruleStore:  ruleStore,
+		authz:      ruleAuthzService,
+		cfg:        &setting.UnifiedAlertingSettings{},
 	}
 }
 
@@ -18,6 +18,7 @@ import (
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	alerting_provisioning "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -31,6 +32,7 @@ type ProvisioningSrv struct {
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
 	folderSvc           folder.Service
+	xactManager         provisioning.TransactionManager
 
 	// XXX: Used to flag recording rules, remove when FT is removed
 	featureManager featuremgmt.FeatureToggles
@@ -596,7 +598,7 @@ func exportHcl(download bool, body definitions.AlertingFileExport) response.Resp
 			})
 		}
 		for idx, cp := range body.ContactPoints {
-			upd, err := ContactPointFromContactPointExport(cp)
+			upd, err := alerting_provisioning.ContactPointFromContactPointExport(cp)
 			if err != nil {
 				return fmt.Errorf("failed to convert contact points to HCL:%w", err)
 			}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	alerting_provisioning "github.com/grafana/grafana/pkg/services/provisioning/alerting"
+)
+
+// hclImportFilename is the name of the document of the request in the errors that refer to its lines.
+const hclImportFilename = "import.tf"
+
+// errHclDryRun rolls back the transaction of a dry run of the import.
+var errHclDryRun = errors.New("dry run")
+
+// hclImportError is the error of the resources of the document that cannot be applied.
+type hclImportError struct {
+	message string
+	err     error
+}
+
+func (e *hclImportError) Error() string {
+	return fmt.Sprintf("%s: %s", e.message, e.err)
+}
+
+func (e *hclImportError) Unwrap() error {
+	return e.err
+}
+
+// RoutePostHclImport decodes the Terraform HCL document of the request and applies its mute timings, contact points,
+// notification policy tree and rule groups, in this order, to the organization of the user in a single transaction.
+// Existing resources are replaced. It returns the decoded resources. If dryRun is set, the resources are validated
+// by applying them in the same way, and the transaction is rolled back.
+func (srv *ProvisioningSrv) RoutePostHclImport(c *contextmodel.ReqContext) response.Response {
+	if c.Req.Body == nil {
+		return ErrResp(http.StatusBadRequest, errors.New("request has no body"), "")
+	}
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read request body")
+	}
+	orgID := c.SignedInUser.GetOrgID()
+	export, err := alerting_provisioning.AlertingFileExportFromHCL(orgID, body, hclImportFilename)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to decode HCL")
+	}
+	if err := checkHclImportOrg(orgID, export); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if len(export.Policies) > 1 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("document has %d notification policy trees but at most one is allowed", len(export.Policies)), "")
+	}
+	for _, g := range export.Groups {
+		if g.FolderUID == "" {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("rule group %q has no folder_uid", g.Name), "")
+		}
+		for _, r := range g.Rules {
+			if r.Record != nil && !srv.featureManager.IsEnabledGlobally(featuremgmt.FlagGrafanaManagedRecordingRules) {
+				return ErrResp(
+					http.StatusBadRequest,
+					fmt.Errorf("%w: recording rules cannot be created on this instance", alerting_models.ErrAlertRuleFailedValidation),
+					"",
+				)
+			}
+		}
+	}
+
+	dryRun := c.QueryBoolWithDefault("dryRun", false)
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		if err := srv.importHcl(ctx, c.SignedInUser, export, provenance); err != nil {
+			return err
+		}
+		if dryRun {
+			return errHclDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errHclDryRun) {
+		var importErr *hclImportError
+		if errors.As(err, &importErr) {
+			return importErrResp(importErr.err, importErr.message)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to import HCL", err)
+	}
+	return response.JSON(http.StatusOK, export)
+}
+
+// checkHclImportOrg returns an error if a resource of the document belongs to another organization than orgID.
+func checkHclImportOrg(orgID int64, export definitions.AlertingFileExport) error {
+	check := func(resourceOrgID int64, resource string) error {
+		if resourceOrgID != orgID {
+			return fmt.Errorf("%s belongs to organization %d but the document is imported to organization %d", resource, resourceOrgID, orgID)
+		}
+		return nil
+	}
+	var errs []error
+	for _, g := range export.Groups {
+		errs = append(errs, check(g.OrgID, fmt.Sprintf("rule group %q", g.Name)))
+	}
+	for _, cp := range export.ContactPoints {
+		errs = append(errs, check(cp.OrgID, fmt.Sprintf("contact point %q", cp.Name)))
+	}
+	for _, p := range export.Policies {
+		errs = append(errs, check(p.OrgID, "notification policy tree"))
+	}
+	for _, mt := range export.MuteTimings {
+		errs = append(errs, check(mt.OrgID, fmt.Sprintf("mute timing %q", mt.Name)))
+	}
+	return errors.Join(errs...)
+}
+
+// importHcl applies the resources of the document. The error is a *hclImportError if a resource cannot be applied.
+func (srv *ProvisioningSrv) importHcl(ctx context.Context, user identity.Requester, export definitions.AlertingFileExport, provenance alerting_models.Provenance) error {
+	orgID := user.GetOrgID()
+	if err := srv.importMuteTimings(ctx, orgID, export.MuteTimings, provenance); err != nil {
+		return &hclImportError{message: "failed to import mute timings", err: err}
+	}
+	if err := srv.importContactPoints(ctx, user, export.ContactPoints, provenance); err != nil {
+		return &hclImportError{message: "failed to import contact points", err: err}
+	}
+	for _, p := range export.Policies {
+		route, err := RouteFromRouteExport(p.RouteExport)
+		if err != nil {
+			return &hclImportError{message: "failed to import notification policies", err: fmt.Errorf("%w: %s", provisioning.ErrValidation, err)}
+		}
+		if err := srv.policies.UpdatePolicyTree(ctx, orgID, route, provenance); err != nil {
+			return &hclImportError{message: "failed to import notification policies", err: err}
+		}
+	}
+	for _, g := range export.Groups {
+		if err := srv.importRuleGroup(ctx, user, g, provenance); err != nil {
+			return &hclImportError{message: fmt.Sprintf("failed to import rule group %q", g.Name), err: err}
+		}
+	}
+	return nil
+}
+
+func importErrResp(err error, message string) response.Response {
+	if errors.Is(err, provisioning.ErrValidation) ||
+		errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) ||
+		errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
+		return ErrResp(http.StatusBadRequest, err, message)
+	}
+	if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, message)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+}
+
+func (srv *ProvisioningSrv) importMuteTimings(ctx context.Context, orgID int64, timings []definitions.MuteTimeIntervalExport, provenance alerting_models.Provenance) error {
+	if len(timings) == 0 {
+		return nil
+	}
+	existing, err := srv.muteTimings.GetMuteTimings(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	versions := make(map[string]string, len(existing))
+	for _, mt := range existing {
+		versions[mt.Name] = mt.Version
+	}
+	for _, mt := range timings {
+		interval := definitions.MuteTimeInterval{
+			MuteTimeInterval: mt.MuteTimeInterval,
+			Provenance:       definitions.Provenance(provenance),
+		}
+		if version, ok := versions[mt.Name]; ok {
+			interval.Version = version
+			_, err = srv.muteTimings.UpdateMuteTiming(ctx, interval, orgID)
+		} else {
+			_, err = srv.muteTimings.CreateMuteTiming(ctx, interval, orgID)
+		}
+		if err != nil {
+			return fmt.Errorf("mute timing %q: %w", mt.Name, err)
+		}
+	}
+	return nil
+}
+
+// importContactPoints creates or replaces the integrations of the contact points. The integrations of the document
+// have no UID, so they replace the existing integrations of the same type of the contact point in order, and the
+// remaining existing integrations are deleted.
+func (srv *ProvisioningSrv) importContactPoints(ctx context.Context, user identity.Requester, contactPoints []definitions.ContactPointExport, provenance alerting_models.Provenance) error {
+	orgID := user.GetOrgID()
+	for _, cp := range contactPoints {
+		existing, err := srv.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{OrgID: orgID, Name: cp.Name}, user)
+		if err != nil {
+			return err
+		}
+		byType := make(map[string][]string)
+		for _, e := range existing {
+			byType[e.Type] = append(byType[e.Type], e.UID)
+		}
+
+		for _, r := range cp.Receivers {
+			settings, err := simplejson.NewJson(r.Settings)
+			if err != nil {
+				return fmt.Errorf("contact point %q: %w", cp.Name, err)
+			}
+			embedded := definitions.EmbeddedContactPoint{
+				Name:                  cp.Name,
+				Type:                  r.Type,
+				Settings:              settings,
+				DisableResolveMessage: r.DisableResolveMessage,
+			}
+			if uids := byType[r.Type]; len(uids) > 0 {
+				embedded.UID, byType[r.Type] = uids[0], uids[1:]
+				err = srv.contactPointService.UpdateContactPoint(ctx, orgID, embedded, provenance)
+			} else {
+				_, err = srv.contactPointService.CreateContactPoint(ctx, orgID, embedded, provenance)
+			}
+			if err != nil {
+				return fmt.Errorf("contact point %q: %w", cp.Name, err)
+			}
+		}
+
+		for _, uids := range byType {
+			for _, uid := range uids {
+				if err := srv.contactPointService.DeleteContactPoint(ctx, orgID, uid); err != nil {
+					return fmt.Errorf("contact point %q: %w", cp.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// importRuleGroup replaces the rule group. The rules of the document that have no UID update the existing rules of
+// the group that have the same title.
+func (srv *ProvisioningSrv) importRuleGroup(ctx context.Context, user identity.Requester, g definitions.AlertRuleGroupExport, provenance alerting_models.Provenance) error {
+	g.OrgID = user.GetOrgID()
+	existing, err := srv.alertRules.GetRuleGroup(ctx, user, g.FolderUID, g.Name)
+	if err != nil && !errors.Is(err, alerting_models.ErrAlertRuleGroupNotFound) {
+		return err
+	}
+	uids := make(map[string]string, len(existing.Rules))
+	for _, r := range existing.Rules {
+		uids[r.Title] = r.UID
+	}
+
+	group := definitions.AlertRuleGroup{
+		Title:     g.Name,
+		FolderUID: g.FolderUID,
+		Interval:  g.IntervalSeconds,
+		Rules:     make([]definitions.ProvisionedAlertRule, 0, len(g.Rules)),
+	}
+	for _, r := range g.Rules {
+		rule, err := ProvisionedAlertRuleFromAlertRuleExport(g, r)
+		if err != nil {
+			return fmt.Errorf("%w: %s", provisioning.ErrValidation, err)
+		}
+		if rule.UID == "" {
+			rule.UID = uids[rule.Title]
+		}
+		group.Rules = append(group.Rules, rule)
+	}
+	groupModel, err := AlertRuleGroupFromApiAlertRuleGroup(group)
+	if err != nil {
+		return err
+	}
+	return srv.alertRules.ReplaceRuleGroup(ctx, user, groupModel, provenance)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -1723,6 +1724,161 @@ func TestProvisioningApiContactPointExport(t *testing.T) {
 	})
 }
 
+func TestProvisioningApiHclImport(t *testing.T) {
+	ruleGroupDoc := `
+resource "grafana_rule_group" "group" {
+  name             = "imported-group"
+  folder_uid       = "folder-uid"
+  interval_seconds = 60
+
+  rule {
+    name           = "imported-rule"
+    condition      = "A"
+    for            = "1m"
+    no_data_state  = "OK"
+    exec_err_state = "OK"
+
+    data {
+      ref_id = "A"
+      model  = jsonencode(jsondecode(%q))
+
+      relative_time_range {
+        from = 60
+        to   = 0
+      }
+    }
+  }
+}
+`
+	requestWith := func(doc string) contextmodel.ReqContext {
+		rc := createTestRequestCtx()
+		rc.Req.Body = io.NopCloser(strings.NewReader(doc))
+		return rc
+	}
+
+	t.Run("dry run returns the decoded resources without applying them", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := requestWith(fmt.Sprintf(ruleGroupDoc, testModel))
+		rc.Req.Form.Set("dryRun", "true")
+
+		response := sut.RoutePostHclImport(&rc)
+		require.Equal(t, 200, response.Status())
+		var export definitions.AlertingFileExport
+		require.NoError(t, json.Unmarshal(response.Body(), &export))
+		require.Len(t, export.Groups, 1)
+		require.Equal(t, "imported-rule", export.Groups[0].Rules[0].Title)
+
+		_, err := sut.alertRules.GetRuleGroup(context.Background(), rc.SignedInUser, "folder-uid", "imported-group")
+		require.ErrorIs(t, err, models.ErrAlertRuleGroupNotFound)
+	})
+
+	t.Run("creates and updates rule groups", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := requestWith(fmt.Sprintf(ruleGroupDoc, testModel))
+
+		response := sut.RoutePostHclImport(&rc)
+		require.Equal(t, 200, response.Status(), string(response.Body()))
+		group, err := sut.alertRules.GetRuleGroup(context.Background(), rc.SignedInUser, "folder-uid", "imported-group")
+		require.NoError(t, err)
+		require.Len(t, group.Rules, 1)
+		require.Equal(t, time.Minute, group.Rules[0].For)
+		uid := group.Rules[0].UID
+
+		updated := strings.Replace(fmt.Sprintf(ruleGroupDoc, testModel), `for            = "1m"`, `for            = "5m"`, 1)
+		rc = requestWith(updated)
+		response = sut.RoutePostHclImport(&rc)
+		require.Equal(t, 200, response.Status(), string(response.Body()))
+		group, err = sut.alertRules.GetRuleGroup(context.Background(), rc.SignedInUser, "folder-uid", "imported-group")
+		require.NoError(t, err)
+		require.Len(t, group.Rules, 1)
+		require.Equalf(t, uid, group.Rules[0].UID, "the rule with the same title should be updated")
+		require.Equal(t, 5*time.Minute, group.Rules[0].For)
+	})
+
+	t.Run("replaces the integrations of existing contact points", func(t *testing.T) {
+		env := createTestEnv(t, testConfig)
+		var saved models.SaveAlertmanagerConfigurationCmd
+		env.configs.(*provisioning.MockAMConfigStore).EXPECT().SaveSucceedsIntercept(&saved)
+		sut := createProvisioningSrvSutFromEnv(t, &env)
+		rc := requestWith(`
+resource "grafana_contact_point" "default" {
+  name = "grafana-default-email"
+
+  email {
+    addresses = ["imported@example.com"]
+  }
+}
+`)
+
+		response := sut.RoutePostHclImport(&rc)
+		require.Equal(t, 200, response.Status(), string(response.Body()))
+		cfg, err := notifier.Load([]byte(saved.AlertmanagerConfiguration))
+		require.NoError(t, err)
+		var integrations []*definitions.PostableGrafanaReceiver
+		for _, r := range cfg.AlertmanagerConfig.Receivers {
+			if r.Name == "grafana-default-email" {
+				integrations = r.GrafanaManagedReceivers
+			}
+		}
+		require.Len(t, integrations, 1)
+		require.Equal(t, "email-uid", integrations[0].UID)
+		require.Contains(t, string(integrations[0].Settings), "imported@example.com")
+	})
+
+	t.Run("invalid document returns 400 with the line of the error", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := requestWith("resource \"grafana_rule_group\" \"group\" {\n  name = \"group\"\n  interval_seconds = \"often\"\n}\n")
+
+		response := sut.RoutePostHclImport(&rc)
+		require.Equal(t, 400, response.Status())
+		require.Contains(t, string(response.Body()), "import.tf:3,")
+	})
+
+	t.Run("rule group without folder returns 400", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := requestWith(`resource "grafana_rule_group" "group" {
+  name = "group"
+}
+`)
+
+		response := sut.RoutePostHclImport(&rc)
+		require.Equal(t, 400, response.Status())
+		require.Contains(t, string(response.Body()), "has no folder_uid")
+	})
+
+	t.Run("dry run returns 400 if the resources fail validation", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := requestWith(`
+resource "grafana_contact_point" "new" {
+  name = "new"
+
+  email {
+    addresses = []
+  }
+}
+`)
+		rc.Req.Form.Set("dryRun", "true")
+
+		response := sut.RoutePostHclImport(&rc)
+		require.Equal(t, 400, response.Status(), string(response.Body()))
+		require.Contains(t, string(response.Body()), "failed to import contact points")
+	})
+
+	t.Run("resources of another organization return 400", func(t *testing.T) {
+		sut := createProvisioningSrvSut(t)
+		rc := requestWith(`
+resource "grafana_mute_timing" "mt" {
+  org_id = 2
+  name   = "mt"
+}
+`)
+
+		response := sut.RoutePostHclImport(&rc)
+		require.Equal(t, 400, response.Status())
+		require.Contains(t, string(response.Body()), `mute timing \"mt\" belongs to organization 2`)
+	})
+}
+
 // testEnvironment binds together common dependencies for testing alerting APIs.
 type testEnvironment struct {
 	secrets          secrets.Service
@@ -1870,6 +2026,7 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
 		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.folderService, env.quotas, env.xact, 60, 10, 100, env.log, &provisioning.NotificationSettingsValidatorProviderFake{}, env.rulesAuthz),
 		folderSvc:           env.folderService,
+		xactManager:         &env.store,
 		featureManager:      env.features,
 	}
 }
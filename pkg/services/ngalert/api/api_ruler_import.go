@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// RoutePostImportPrometheusRules converts Prometheus rule groups to Grafana-managed rules that query the data source ds
+// and saves them in the folder. Every imported group replaces the Grafana group with the same name, and rules keep
+// the UID of the rule with the same title in that group, so that a file can be imported again after it changes.
+// Nothing is saved if any group or rule cannot be converted, or if dryRun is set.
+func (srv RulerSrv) RoutePostImportPrometheusRules(c *contextmodel.ReqContext, ruleFile apimodels.PrometheusRuleFile, namespaceUID string, ds *datasources.DataSource, dryRun bool) response.Response {
+	namespace, err := srv.store.GetNamespaceByUID(c.Req.Context(), namespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:        ds.UID,
+		DatasourceType:       ds.Type,
+		BaseInterval:         srv.cfg.BaseInterval,
+		DefaultInterval:      srv.cfg.DefaultRuleEvaluationInterval,
+		RejectRecordingRules: !RuleLimitsFromConfig(srv.cfg, srv.featureManager).RecordingRulesAllowed,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create rule converter")
+	}
+
+	groups := make([]rulefmt.RuleGroup, 0, len(ruleFile.Groups))
+	for _, group := range ruleFile.Groups {
+		groups = append(groups, toRulefmtRuleGroup(group))
+	}
+	converted, convErrs := converter.PrometheusRulesToGrafana(c.SignedInUser.GetOrgID(), namespace.UID, groups)
+	if len(convErrs) > 0 {
+		body := apimodels.ImportPrometheusRulesResponse{
+			Message: "failed to convert Prometheus rules",
+			Errors:  make([]apimodels.PrometheusRuleConversionError, 0, len(convErrs)),
+		}
+		for _, e := range convErrs {
+			body.Errors = append(body.Errors, apimodels.PrometheusRuleConversionError{
+				Group:     e.Group,
+				RuleIndex: e.RuleIndex,
+				Rule:      e.Rule,
+				Error:     e.Err.Error(),
+			})
+		}
+		return response.JSON(http.StatusBadRequest, body)
+	}
+
+	if dryRun {
+		body := apimodels.ImportPrometheusRulesResponse{
+			Message: "rules converted successfully",
+			Groups:  make([]apimodels.ImportedRuleGroup, 0, len(converted)),
+		}
+		for _, group := range converted {
+			imported := apimodels.ImportedRuleGroup{
+				Name:  group.Title,
+				Rules: make([]apimodels.GettableExtendedRuleNode, 0, len(group.Rules)),
+			}
+			for _, rule := range group.Rules {
+				imported.Rules = append(imported.Rules, toGettableExtendedRuleNode(rule, nil))
+			}
+			body.Groups = append(body.Groups, imported)
+		}
+		return response.JSON(http.StatusOK, body)
+	}
+
+	body := apimodels.ImportPrometheusRulesResponse{
+		Message: "rules imported successfully",
+		Groups:  make([]apimodels.ImportedRuleGroup, 0, len(converted)),
+	}
+	var dbConfig *ngmodels.AlertConfiguration
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		for _, group := range converted {
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Title,
+			}
+			rules, err := srv.withExistingRuleUIDs(tranCtx, groupKey, group.Rules)
+			if err != nil {
+				return err
+			}
+			delta, cfg, err := srv.applyRuleGroupChanges(tranCtx, c, groupKey, rules, nil)
+			if err != nil {
+				return err
+			}
+			if cfg != nil {
+				dbConfig = cfg
+			}
+			body.Groups = append(body.Groups, toImportedRuleGroup(group.Title, delta))
+		}
+		return nil
+	})
+	if err != nil {
+		return ruleGroupUpdateErrorResponse(err)
+	}
+
+	srv.refreshAlertmanagerConfig(c, c.SignedInUser.GetOrgID(), dbConfig)
+
+	return response.JSON(http.StatusAccepted, body)
+}
+
+// withExistingRuleUIDs assigns to the rules the UIDs of the rules with the same titles in the group.
+func (srv RulerSrv) withExistingRuleUIDs(ctx context.Context, groupKey ngmodels.AlertRuleGroupKey, rules []ngmodels.AlertRule) ([]*ngmodels.AlertRuleWithOptionals, error) {
+	existing, err := srv.store.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
+		OrgID:         groupKey.OrgID,
+		NamespaceUIDs: []string{groupKey.NamespaceUID},
+		RuleGroups:    []string{groupKey.RuleGroup},
+	})
+	if err != nil {
+		return nil, err
+	}
+	uids := make(map[string]string, len(existing))
+	for _, rule := range existing {
+		uids[rule.Title] = rule.UID
+	}
+
+	result := make([]*ngmodels.AlertRuleWithOptionals, 0, len(rules))
+	for _, rule := range rules {
+		rule.UID = uids[rule.Title]
+		result = append(result, &ngmodels.AlertRuleWithOptionals{AlertRule: rule})
+	}
+	return result, nil
+}
+
+func toImportedRuleGroup(name string, delta *store.GroupDelta) apimodels.ImportedRuleGroup {
+	result := apimodels.ImportedRuleGroup{Name: name}
+	for _, r := range delta.New {
+		result.Created = append(result.Created, r.UID)
+	}
+	for _, r := range delta.Update {
+		result.Updated = append(result.Updated, r.Existing.UID)
+	}
+	for _, r := range delta.Delete {
+		result.Deleted = append(result.Deleted, r.UID)
+	}
+	return result
+}
+
+func toRulefmtRuleGroup(group apimodels.PrometheusRuleGroup) rulefmt.RuleGroup {
+	result := rulefmt.RuleGroup{
+		Name:     group.Name,
+		Interval: group.Interval,
+		Limit:    group.Limit,
+		Rules:    make([]rulefmt.RuleNode, 0, len(group.Rules)),
+	}
+	scalar := func(value string) yaml.Node {
+		if value == "" {
+			return yaml.Node{}
+		}
+		return yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	}
+	for _, rule := range group.Rules {
+		node := rulefmt.RuleNode{
+			Record:      scalar(rule.Record),
+			Alert:       scalar(rule.Alert),
+			Expr:        scalar(rule.Expr),
+			Labels:      rule.Labels,
+			Annotations: rule.Annotations,
+		}
+		if rule.For != nil {
+			node.For = *rule.For
+		}
+		if rule.KeepFiringFor != nil {
+			node.KeepFiringFor = *rule.KeepFiringFor
+		}
+		result.Rules = append(result.Rules, node)
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+func TestRoutePostImportPrometheusRules(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ds := &datasources.DataSource{UID: "prom-uid", Type: datasources.DS_PROMETHEUS}
+	forDuration := model.Duration(5 * time.Minute)
+	ruleFile := apimodels.PrometheusRuleFile{
+		Groups: []apimodels.PrometheusRuleGroup{
+			{
+				Name:     "node",
+				Interval: model.Duration(time.Minute),
+				Rules: []apimodels.ApiRuleNode{
+					{Alert: "HighLoad", Expr: "node_load1 > 4", For: &forDuration, Labels: map[string]string{"severity": "warning"}},
+					{Alert: "InstanceDown", Expr: "up == 0"},
+				},
+			},
+		},
+	}
+	permissions := map[int64]map[string][]string{
+		orgID: {
+			ac.ActionAlertingRuleRead:    {dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)},
+			ac.ActionAlertingRuleCreate:  {dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)},
+			ac.ActionAlertingRuleUpdate:  {dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)},
+			ac.ActionAlertingRuleDelete:  {dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)},
+			dashboards.ActionFoldersRead: {dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)},
+			datasources.ActionQuery:      {datasources.ScopeProvider.GetResourceScopeUID(ds.UID)},
+		},
+	}
+	setup := func(t *testing.T) (*fakes.RuleStore, *RulerSrv) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		svc := createService(ruleStore)
+		svc.conditionValidator = &recordingConditionValidator{}
+		svc.QuotaService = quotatest.New(false, nil)
+		return ruleStore, svc
+	}
+	insertedRules := func(ruleStore *fakes.RuleStore) []models.AlertRule {
+		var result []models.AlertRule
+		for _, cmd := range ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			rules, ok := cmd.([]models.AlertRule)
+			return rules, ok
+		}) {
+			result = append(result, cmd.([]models.AlertRule)...)
+		}
+		return result
+	}
+
+	t.Run("should convert and save the rules", func(t *testing.T) {
+		ruleStore, svc := setup(t)
+
+		response := svc.RoutePostImportPrometheusRules(createRequestContextWithPerms(orgID, permissions, nil), ruleFile, folder.UID, ds, false)
+
+		require.Equalf(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		var result apimodels.ImportPrometheusRulesResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Groups, 1)
+		require.Equal(t, "node", result.Groups[0].Name)
+		require.Len(t, result.Groups[0].Created, 2)
+
+		inserted := insertedRules(ruleStore)
+		require.Len(t, inserted, 2)
+		require.Equal(t, "HighLoad", inserted[0].Title)
+		require.Equal(t, folder.UID, inserted[0].NamespaceUID)
+		require.Equal(t, "node", inserted[0].RuleGroup)
+		require.EqualValues(t, 60, inserted[0].IntervalSeconds)
+		require.Equal(t, 5*time.Minute, inserted[0].For)
+		require.Equal(t, ds.UID, inserted[0].Data[0].DatasourceUID)
+	})
+
+	t.Run("should update rules with the same title in the group", func(t *testing.T) {
+		ruleStore, svc := setup(t)
+		existing := models.RuleGen.With(
+			models.RuleGen.WithOrgID(orgID),
+			models.RuleGen.WithNamespaceUID(folder.UID),
+			models.RuleGen.WithGroupName("node"),
+			models.RuleGen.WithTitle("HighLoad"),
+		).GenerateRef()
+		ruleStore.PutRule(context.Background(), existing)
+
+		response := svc.RoutePostImportPrometheusRules(createRequestContextWithPerms(orgID, permissions, nil), ruleFile, folder.UID, ds, false)
+
+		require.Equalf(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		var result apimodels.ImportPrometheusRulesResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, []string{existing.UID}, result.Groups[0].Updated)
+		require.Len(t, result.Groups[0].Created, 1)
+	})
+
+	t.Run("should not save anything on dry run", func(t *testing.T) {
+		ruleStore, svc := setup(t)
+
+		response := svc.RoutePostImportPrometheusRules(createRequestContextWithPerms(orgID, permissions, nil), ruleFile, folder.UID, ds, true)
+
+		require.Equalf(t, http.StatusOK, response.Status(), string(response.Body()))
+		var result apimodels.ImportPrometheusRulesResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Groups, 1)
+		require.Len(t, result.Groups[0].Rules, 2)
+		require.Equal(t, "HighLoad", result.Groups[0].Rules[0].GrafanaManagedAlert.Title)
+		require.Empty(t, insertedRules(ruleStore))
+	})
+
+	t.Run("should report conversion errors and not save anything", func(t *testing.T) {
+		ruleStore, svc := setup(t)
+		broken := apimodels.PrometheusRuleFile{
+			Groups: []apimodels.PrometheusRuleGroup{
+				ruleFile.Groups[0],
+				{
+					Name: "broken",
+					Rules: []apimodels.ApiRuleNode{
+						{Alert: "Valid", Expr: "up == 0"},
+						{Alert: "Invalid", Expr: "up =="},
+						{Record: "job:up:sum", Expr: "sum by (job) (up)"},
+					},
+				},
+			},
+		}
+
+		response := svc.RoutePostImportPrometheusRules(createRequestContextWithPerms(orgID, permissions, nil), broken, folder.UID, ds, false)
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+		var result apimodels.ImportPrometheusRulesResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Errors, 2)
+		require.Equal(t, "broken", result.Errors[0].Group)
+		require.Equal(t, 1, result.Errors[0].RuleIndex)
+		require.Equal(t, "Invalid", result.Errors[0].Rule)
+		// recording rules are disabled by default
+		require.Equal(t, 2, result.Errors[1].RuleIndex)
+		require.Contains(t, result.Errors[1].Error, "recording rules are not enabled")
+		require.Empty(t, insertedRules(ruleStore))
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"path"
 	"strconv"
@@ -315,6 +316,40 @@ func TestValidateRuleGroupFailures(t *testing.T) {
 	}
 }
 
+func TestValidateRuleGroup_RecordingRuleDependencies(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+	limits := allowRecording(makeLimits(cfg))
+
+	recordingRule := func(metric, promQL string) apimodels.PostableExtendedRuleNode {
+		r := validRule()
+		r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: metric, From: "A"}
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.NoDataState = ""
+		r.GrafanaManagedAlert.ExecErrState = ""
+		r.GrafanaManagedAlert.NotificationSettings = nil
+		r.GrafanaManagedAlert.Data[0].Model = json.RawMessage(fmt.Sprintf(`{"expr": %q}`, promQL))
+		r.ApiRuleNode.For = nil
+		return r
+	}
+
+	t.Run("should accept rules that depend on recording rules of the group", func(t *testing.T) {
+		alert := validRule()
+		alert.GrafanaManagedAlert.Data[0].Model = json.RawMessage(`{"expr": "job:up:sum == 0"}`)
+		g := validGroup(cfg, alert, recordingRule("job:up:sum", "sum by (job) (up)"))
+		_, err := ValidateRuleGroup(&g, orgId, folder.UID, *limits)
+		require.NoError(t, err)
+	})
+
+	t.Run("should fail if recording rules depend on each other in a cycle", func(t *testing.T) {
+		g := validGroup(cfg, recordingRule("metric_a", "metric_b + 1"), recordingRule("metric_b", "metric_a + 1"))
+		_, err := ValidateRuleGroup(&g, orgId, folder.UID, *limits)
+		require.ErrorIs(t, err, models.ErrRuleGroupDependencyCycle)
+		require.ErrorContains(t, err, g.Rules[0].GrafanaManagedAlert.Title)
+	})
+}
+
 func TestValidateRuleNode_NoUID(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
@@ -357,6 +392,18 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Nil(t, alert.Record)
 			},
 		},
+		{
+			name: "converts keep_firing_for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(5 * time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, alert.KeepFiringFor)
+			},
+		},
 		{
 			name: "coverts api without ApiRuleNode",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -631,6 +678,16 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "rejects negative keep_firing_for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(-time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+			expErr: "field `keep_firing_for` cannot be negative",
+		},
 		{
 			name: "rejects valid recording rules if toggle is disabled",
 			rule: func() *apimodels.PostableExtendedRuleNode {
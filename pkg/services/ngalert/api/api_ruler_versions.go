@@ -0,0 +1,329 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RouteGetRuleVersionsByUID returns all stored versions of the rule, newest first.
+func (srv RulerSrv) RouteGetRuleVersionsByUID(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	ctx := c.Req.Context()
+	rule, provenance, resp := srv.getAuthorizedRuleWithProvenance(c, ruleUID)
+	if resp != nil {
+		return resp
+	}
+
+	versions, err := srv.store.GetAlertRuleVersions(ctx, c.SignedInUser.GetOrgID(), ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule versions", err)
+	}
+
+	result := make(apimodels.GettableRuleVersions, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, toGettableRuleVersion(rule, v, provenance))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetRuleVersionByUID returns a single stored version of the rule.
+func (srv RulerSrv) RouteGetRuleVersionByUID(c *contextmodel.ReqContext, ruleUID string, version string) response.Response {
+	v, err := parseRuleVersion(version)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	rule, provenance, resp := srv.getAuthorizedRuleWithProvenance(c, ruleUID)
+	if resp != nil {
+		return resp
+	}
+
+	ruleVersion, err := srv.store.GetAlertRuleVersion(c.Req.Context(), c.SignedInUser.GetOrgID(), ruleUID, v)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule version", err)
+	}
+	return response.JSON(http.StatusOK, toGettableRuleVersion(rule, ruleVersion, provenance))
+}
+
+// RouteGetRuleVersionDiff returns the changes between two versions of the rule.
+// The version to compare with is taken from the query parameter "compareTo" and defaults to the parent version.
+func (srv RulerSrv) RouteGetRuleVersionDiff(c *contextmodel.ReqContext, ruleUID string, version string) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	v, err := parseRuleVersion(version)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	var compareTo int64
+	if s := c.Query("compareTo"); s != "" {
+		compareTo, err = parseRuleVersion(s)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid compareTo")
+		}
+	}
+	if _, _, resp := srv.getAuthorizedRuleWithProvenance(c, ruleUID); resp != nil {
+		return resp
+	}
+
+	to, err := srv.store.GetAlertRuleVersion(ctx, orgID, ruleUID, v)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule version", err)
+	}
+	if compareTo == 0 {
+		compareTo = to.ParentVersion
+	}
+	if compareTo == 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("version %d of rule %s has no parent version, specify the version to compare with", v, ruleUID), "")
+	}
+	from, err := srv.store.GetAlertRuleVersion(ctx, orgID, ruleUID, compareTo)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule version", err)
+	}
+
+	return response.JSON(http.StatusOK, apimodels.RuleVersionDiff{
+		RuleUID:     ruleUID,
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Changes:     diffRuleVersions(from, to),
+	})
+}
+
+// RoutePostRestoreRuleVersion saves the content of an old version of the rule as a new version.
+// The rule stays in its current folder and group, and keeps its position, interval and paused state.
+// The update goes through the same authorization, validation and provenance checks as a regular update of the group.
+func (srv RulerSrv) RoutePostRestoreRuleVersion(c *contextmodel.ReqContext, ruleUID string, version string) response.Response {
+	ctx := c.Req.Context()
+
+	v, err := parseRuleVersion(version)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	ruleVersion, err := srv.store.GetAlertRuleVersion(ctx, c.SignedInUser.GetOrgID(), ruleUID, v)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule version", err)
+	}
+
+	groupKey := rule.GetGroupKey()
+	group, err := srv.getAuthorizedRuleGroup(ctx, c, groupKey)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule group", err)
+	}
+
+	// The whole group is submitted because rules that are missing from the submission are deleted.
+	rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group))
+	for _, r := range group {
+		submitted := ngmodels.AlertRuleWithOptionals{AlertRule: *r}
+		if r.UID == ruleUID {
+			applyRuleVersion(&submitted.AlertRule, ruleVersion)
+		}
+		rules = append(rules, &submitted)
+	}
+
+	return srv.updateAlertRulesInGroup(c, groupKey, rules, map[string]int64{ruleUID: v})
+}
+
+// getAuthorizedRuleWithProvenance fetches the rule the user is authorized to read and its provenance.
+// Returns a non-nil response if the request cannot proceed.
+func (srv RulerSrv) getAuthorizedRuleWithProvenance(c *contextmodel.ReqContext, ruleUID string) (ngmodels.AlertRule, ngmodels.Provenance, response.Response) {
+	ctx := c.Req.Context()
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return rule, ngmodels.ProvenanceNone, response.Empty(http.StatusNotFound)
+		}
+		return rule, ngmodels.ProvenanceNone, response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	provenance, err := srv.provenanceStore.GetProvenance(ctx, &rule, c.SignedInUser.GetOrgID())
+	if err != nil {
+		return rule, ngmodels.ProvenanceNone, response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule provenance", err)
+	}
+	return rule, provenance, nil
+}
+
+func parseRuleVersion(s string) (int64, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid rule version %q: must be a positive integer", s)
+	}
+	return v, nil
+}
+
+// applyRuleVersion replaces the definition of the rule with the one stored in the version.
+func applyRuleVersion(rule *ngmodels.AlertRule, v *ngmodels.AlertRuleVersion) {
+	rule.Title = v.Title
+	rule.Condition = v.Condition
+	rule.Data = v.Data
+	rule.Record = v.Record
+	rule.NoDataState = v.NoDataState
+	rule.ExecErrState = v.ExecErrState
+	rule.For = v.For
+	rule.KeepFiringFor = v.KeepFiringFor
+	rule.Annotations = v.Annotations
+	rule.Labels = v.Labels
+	rule.NotificationSettings = v.NotificationSettings
+}
+
+func toGettableRuleVersion(current ngmodels.AlertRule, v *ngmodels.AlertRuleVersion, provenance ngmodels.Provenance) apimodels.GettableRuleVersion {
+	rule := current
+	applyRuleVersion(&rule, v)
+	rule.NamespaceUID = v.RuleNamespaceUID
+	rule.RuleGroup = v.RuleGroup
+	rule.RuleGroupIndex = v.RuleGroupIndex
+	rule.IntervalSeconds = v.IntervalSeconds
+	rule.IsPaused = v.IsPaused
+	rule.Version = v.Version
+	rule.Updated = v.Created
+
+	return apimodels.GettableRuleVersion{
+		Version:       v.Version,
+		ParentVersion: v.ParentVersion,
+		RestoredFrom:  v.RestoredFrom,
+		Created:       v.Created,
+		CreatedBy:     v.CreatedBy,
+		Rule:          toGettableExtendedRuleNode(rule, map[string]ngmodels.Provenance{rule.ResourceID(): provenance}),
+	}
+}
+
+// diffRuleVersions returns the changes needed to get from one version of a rule to the other.
+func diffRuleVersions(from, to *ngmodels.AlertRuleVersion) []apimodels.RuleVersionChange {
+	changes := make([]apimodels.RuleVersionChange, 0)
+	add := func(section apimodels.RuleVersionChangeSection, field string, fromVal, toVal any, fromSet, toSet bool) {
+		change := apimodels.RuleVersionChange{Section: section, Field: field}
+		switch {
+		case fromSet && toSet:
+			if reflect.DeepEqual(fromVal, toVal) {
+				return
+			}
+			change.Op, change.From, change.To = apimodels.RuleVersionChangeChanged, fromVal, toVal
+		case toSet:
+			change.Op, change.To = apimodels.RuleVersionChangeAdded, toVal
+		case fromSet:
+			change.Op, change.From = apimodels.RuleVersionChangeRemoved, fromVal
+		default:
+			return
+		}
+		changes = append(changes, change)
+	}
+
+	rule := func(field string, fromVal, toVal any) {
+		add(apimodels.RuleVersionChangeSectionRule, field, fromVal, toVal, true, true)
+	}
+	rule("title", from.Title, to.Title)
+	rule("condition", from.Condition, to.Condition)
+	rule("namespace_uid", from.RuleNamespaceUID, to.RuleNamespaceUID)
+	rule("rule_group", from.RuleGroup, to.RuleGroup)
+	rule("interval_seconds", from.IntervalSeconds, to.IntervalSeconds)
+	rule("no_data_state", string(from.NoDataState), string(to.NoDataState))
+	rule("exec_err_state", string(from.ExecErrState), string(to.ExecErrState))
+	rule("for", model.Duration(from.For).String(), model.Duration(to.For).String())
+	rule("keep_firing_for", model.Duration(from.KeepFiringFor).String(), model.Duration(to.KeepFiringFor).String())
+	rule("is_paused", from.IsPaused, to.IsPaused)
+	add(apimodels.RuleVersionChangeSectionRule, "record", ApiRecordFromModelRecord(from.Record), ApiRecordFromModelRecord(to.Record), from.Record != nil, to.Record != nil)
+
+	diffQueries(from.Data, to.Data, add)
+	diffStringMaps(apimodels.RuleVersionChangeSectionLabels, from.Labels, to.Labels, add)
+	diffStringMaps(apimodels.RuleVersionChangeSectionAnnotations, from.Annotations, to.Annotations, add)
+	diffNotificationSettings(from.NotificationSettings, to.NotificationSettings, add)
+	return changes
+}
+
+type addChangeFunc func(section apimodels.RuleVersionChangeSection, field string, fromVal, toVal any, fromSet, toSet bool)
+
+// diffQueries matches queries by RefID. Query models are compared as JSON documents so that formatting does not matter.
+func diffQueries(from, to []ngmodels.AlertQuery, add addChangeFunc) {
+	toByRefID := make(map[string]ngmodels.AlertQuery, len(to))
+	for _, q := range to {
+		toByRefID[q.RefID] = q
+	}
+	fromRefIDs := make(map[string]struct{}, len(from))
+	for _, f := range from {
+		fromRefIDs[f.RefID] = struct{}{}
+		t, ok := toByRefID[f.RefID]
+		if ok && queriesEqual(f, t) {
+			continue
+		}
+		add(apimodels.RuleVersionChangeSectionQueries, f.RefID, ApiAlertQueryFromAlertQuery(f), ApiAlertQueryFromAlertQuery(t), true, ok)
+	}
+	for _, t := range to {
+		if _, ok := fromRefIDs[t.RefID]; !ok {
+			add(apimodels.RuleVersionChangeSectionQueries, t.RefID, nil, ApiAlertQueryFromAlertQuery(t), false, true)
+		}
+	}
+}
+
+func queriesEqual(a, b ngmodels.AlertQuery) bool {
+	if a.QueryType != b.QueryType || a.DatasourceUID != b.DatasourceUID || a.RelativeTimeRange != b.RelativeTimeRange {
+		return false
+	}
+	var am, bm any
+	if json.Unmarshal(a.Model, &am) != nil || json.Unmarshal(b.Model, &bm) != nil {
+		return string(a.Model) == string(b.Model)
+	}
+	return reflect.DeepEqual(am, bm)
+}
+
+func diffStringMaps(section apimodels.RuleVersionChangeSection, from, to map[string]string, add addChangeFunc) {
+	keys := make([]string, 0, len(from)+len(to))
+	for k := range from {
+		keys = append(keys, k)
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f, fromOk := from[k]
+		t, toOk := to[k]
+		add(section, k, f, t, fromOk, toOk)
+	}
+}
+
+// diffNotificationSettings compares the simplified routing settings field by field.
+// If the settings were added or removed altogether, the change is reported once with an empty field name.
+func diffNotificationSettings(from, to []ngmodels.NotificationSettings, add addChangeFunc) {
+	f := AlertRuleNotificationSettingsFromNotificationSettings(from)
+	t := AlertRuleNotificationSettingsFromNotificationSettings(to)
+	if f == nil || t == nil {
+		add(apimodels.RuleVersionChangeSectionNotificationSettings, "", f, t, f != nil, t != nil)
+		return
+	}
+	ns := func(field string, fromVal, toVal any, fromSet, toSet bool) {
+		add(apimodels.RuleVersionChangeSectionNotificationSettings, field, fromVal, toVal, fromSet, toSet)
+	}
+	ns("receiver", f.Receiver, t.Receiver, true, true)
+	ns("group_by", f.GroupBy, t.GroupBy, len(f.GroupBy) > 0, len(t.GroupBy) > 0)
+	ns("group_wait", durationString(f.GroupWait), durationString(t.GroupWait), f.GroupWait != nil, t.GroupWait != nil)
+	ns("group_interval", durationString(f.GroupInterval), durationString(t.GroupInterval), f.GroupInterval != nil, t.GroupInterval != nil)
+	ns("repeat_interval", durationString(f.RepeatInterval), durationString(t.RepeatInterval), f.RepeatInterval != nil, t.RepeatInterval != nil)
+	ns("mute_time_intervals", f.MuteTimeIntervals, t.MuteTimeIntervals, len(f.MuteTimeIntervals) > 0, len(t.MuteTimeIntervals) > 0)
+}
+
+func durationString(d *model.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestRouteGetRuleVersionsByUID(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+	gen := models.RuleGen.With(models.RuleGen.WithGroupKey(groupKey))
+
+	rule := gen.With(gen.WithUniqueID()).GenerateRef()
+	ruleStore.PutRule(context.Background(), rule)
+	for i := int64(1); i <= 3; i++ {
+		ruleStore.PutRuleVersion(ruleVersionFromRule(rule, i))
+	}
+	req := createRequestContextWithPerms(orgID, createPermissionsForRules([]*models.AlertRule{rule}, orgID), nil)
+
+	t.Run("should return versions newest first", func(t *testing.T) {
+		response := createService(ruleStore).RouteGetRuleVersionsByUID(req, rule.UID)
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.GettableRuleVersions
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 3)
+		for i, v := range result {
+			require.EqualValues(t, 3-i, v.Version)
+			require.EqualValues(t, 2-i, v.ParentVersion)
+			require.Equal(t, "user:test", v.CreatedBy)
+			require.Equal(t, rule.UID, v.Rule.GrafanaManagedAlert.UID)
+			require.Equal(t, v.Version, v.Rule.GrafanaManagedAlert.Version)
+		}
+	})
+
+	t.Run("should return a single version", func(t *testing.T) {
+		response := createService(ruleStore).RouteGetRuleVersionByUID(req, rule.UID, "2")
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.GettableRuleVersion
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.EqualValues(t, 2, result.Version)
+		require.Equal(t, rule.Title, result.Rule.GrafanaManagedAlert.Title)
+	})
+
+	t.Run("should return 404 if version does not exist", func(t *testing.T) {
+		response := createService(ruleStore).RouteGetRuleVersionByUID(req, rule.UID, "10")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 400 if version is not a number", func(t *testing.T) {
+		response := createService(ruleStore).RouteGetRuleVersionByUID(req, rule.UID, "latest")
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should return 404 if rule does not exist", func(t *testing.T) {
+		response := createService(ruleStore).RouteGetRuleVersionsByUID(req, "foobar")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
+func TestRouteGetRuleVersionDiff(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+	gen := models.RuleGen.With(models.RuleGen.WithGroupKey(groupKey))
+
+	rule := gen.With(gen.WithUniqueID(), gen.WithTitle("v1"), gen.WithLabels(map[string]string{"team": "a"})).GenerateRef()
+	ruleStore.PutRule(context.Background(), rule)
+	v1 := ruleVersionFromRule(rule, 1)
+	v2 := ruleVersionFromRule(rule, 2)
+	v2.Title = "v2"
+	v3 := ruleVersionFromRule(rule, 3)
+	v3.Title = "v2"
+	v3.Labels = map[string]string{"team": "b"}
+	ruleStore.PutRuleVersion(v1, v2, v3)
+
+	t.Run("should compare with the parent version by default", func(t *testing.T) {
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules([]*models.AlertRule{rule}, orgID), nil)
+		response := createService(ruleStore).RouteGetRuleVersionDiff(req, rule.UID, "3")
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.RuleVersionDiff
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.EqualValues(t, 2, result.FromVersion)
+		require.EqualValues(t, 3, result.ToVersion)
+		require.Equal(t, []apimodels.RuleVersionChange{
+			{Section: apimodels.RuleVersionChangeSectionLabels, Field: "team", Op: apimodels.RuleVersionChangeChanged, From: "a", To: "b"},
+		}, result.Changes)
+	})
+
+	t.Run("should compare with the version from query", func(t *testing.T) {
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules([]*models.AlertRule{rule}, orgID), nil)
+		req.Req.Form.Set("compareTo", "1")
+		response := createService(ruleStore).RouteGetRuleVersionDiff(req, rule.UID, "3")
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.RuleVersionDiff
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.EqualValues(t, 1, result.FromVersion)
+		require.Len(t, result.Changes, 2)
+	})
+
+	t.Run("should return 400 if version has no parent", func(t *testing.T) {
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules([]*models.AlertRule{rule}, orgID), nil)
+		response := createService(ruleStore).RouteGetRuleVersionDiff(req, rule.UID, "1")
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
+
+func TestRoutePostRestoreRuleVersion(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+	gen := models.RuleGen.With(models.RuleGen.WithGroupKey(groupKey))
+
+	setup := func(t *testing.T) (*fakes.RuleStore, []*models.AlertRule) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		rules := gen.With(gen.WithUniqueGroupIndex(), gen.WithUniqueID()).GenerateManyRef(3)
+		ruleStore.PutRule(context.Background(), rules...)
+
+		old := ruleVersionFromRule(rules[0], 1)
+		old.Title = "old title"
+		old.Labels = map[string]string{"restored": "true"}
+		ruleStore.PutRuleVersion(old, ruleVersionFromRule(rules[0], 2))
+		return ruleStore, rules
+	}
+	permissions := func(rules []*models.AlertRule) map[int64]map[string][]string {
+		perms := createPermissionsForRules(rules, orgID)
+		perms[orgID][ac.ActionAlertingRuleUpdate] = []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)}
+		return perms
+	}
+
+	t.Run("should update the rule with the content of the version", func(t *testing.T) {
+		ruleStore, rules := setup(t)
+		svc := createService(ruleStore)
+		svc.conditionValidator = &recordingConditionValidator{}
+
+		response := svc.RoutePostRestoreRuleVersion(createRequestContextWithPerms(orgID, permissions(rules), nil), rules[0].UID, "1")
+
+		require.Equalf(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			u, ok := cmd.([]models.UpdateRule)
+			return u, ok
+		})
+		require.Len(t, updates, 1)
+		// all rules in the group are updated but only the restored one has a new definition
+		var restored *models.UpdateRule
+		for _, u := range updates[0].([]models.UpdateRule) {
+			if u.New.UID != rules[0].UID {
+				require.Zero(t, u.RestoredFrom)
+				continue
+			}
+			restored = &u
+		}
+		require.NotNil(t, restored)
+		require.EqualValues(t, 1, restored.RestoredFrom)
+		require.Equal(t, "old title", restored.New.Title)
+		require.Equal(t, map[string]string{"restored": "true"}, restored.New.Labels)
+		require.Equal(t, rules[0].RuleGroupIndex, restored.New.RuleGroupIndex)
+		require.Equal(t, rules[0].IsPaused, restored.New.IsPaused)
+	})
+
+	t.Run("should not restore provisioned rules", func(t *testing.T) {
+		ruleStore, rules := setup(t)
+		provenanceStore := fakes.NewFakeProvisioningStore()
+		require.NoError(t, provenanceStore.SetProvenance(context.Background(), rules[0], orgID, models.ProvenanceAPI))
+		svc := createServiceWithProvenanceStore(ruleStore, provenanceStore)
+		svc.conditionValidator = &recordingConditionValidator{}
+
+		response := svc.RoutePostRestoreRuleVersion(createRequestContextWithPerms(orgID, permissions(rules), nil), rules[0].UID, "1")
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should return 404 if version does not exist", func(t *testing.T) {
+		ruleStore, rules := setup(t)
+
+		response := createService(ruleStore).RoutePostRestoreRuleVersion(createRequestContextWithPerms(orgID, permissions(rules), nil), rules[0].UID, "5")
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
+func TestDiffRuleVersions(t *testing.T) {
+	minute := model.Duration(time.Minute)
+	from := &models.AlertRuleVersion{
+		Title:     "rule",
+		Condition: "B",
+		Data: []models.AlertQuery{
+			{RefID: "A", DatasourceUID: "ds", Model: json.RawMessage(`{"expr": "up", "refId": "A"}`)},
+			{RefID: "B", DatasourceUID: "__expr__", Model: json.RawMessage(`{"type":"threshold"}`)},
+		},
+		Labels:      map[string]string{"team": "a", "severity": "low"},
+		Annotations: map[string]string{"summary": "s"},
+		NotificationSettings: []models.NotificationSettings{
+			{Receiver: "email", GroupWait: &minute},
+		},
+	}
+
+	t.Run("should return no changes for identical versions", func(t *testing.T) {
+		to := *from
+		to.Data = []models.AlertQuery{
+			{RefID: "A", DatasourceUID: "ds", Model: json.RawMessage(`{"refId":"A","expr":"up"}`)},
+			from.Data[1],
+		}
+		require.Empty(t, diffRuleVersions(from, &to))
+	})
+
+	t.Run("should report changes by section", func(t *testing.T) {
+		to := *from
+		to.Condition = "C"
+		to.Data = []models.AlertQuery{
+			{RefID: "A", DatasourceUID: "ds", Model: json.RawMessage(`{"expr":"down","refId":"A"}`)},
+			{RefID: "C", DatasourceUID: "__expr__", Model: json.RawMessage(`{"type":"math"}`)},
+		}
+		to.Labels = map[string]string{"team": "b", "env": "prod"}
+		to.Annotations = nil
+		to.NotificationSettings = []models.NotificationSettings{{Receiver: "slack"}}
+
+		changes := diffRuleVersions(from, &to)
+
+		type key struct {
+			section apimodels.RuleVersionChangeSection
+			field   string
+			op      apimodels.RuleVersionChangeOp
+		}
+		var keys []key
+		for _, c := range changes {
+			keys = append(keys, key{c.Section, c.Field, c.Op})
+		}
+		require.Equal(t, []key{
+			{apimodels.RuleVersionChangeSectionRule, "condition", apimodels.RuleVersionChangeChanged},
+			{apimodels.RuleVersionChangeSectionQueries, "A", apimodels.RuleVersionChangeChanged},
+			{apimodels.RuleVersionChangeSectionQueries, "B", apimodels.RuleVersionChangeRemoved},
+			{apimodels.RuleVersionChangeSectionQueries, "C", apimodels.RuleVersionChangeAdded},
+			{apimodels.RuleVersionChangeSectionLabels, "env", apimodels.RuleVersionChangeAdded},
+			{apimodels.RuleVersionChangeSectionLabels, "severity", apimodels.RuleVersionChangeRemoved},
+			{apimodels.RuleVersionChangeSectionLabels, "team", apimodels.RuleVersionChangeChanged},
+			{apimodels.RuleVersionChangeSectionAnnotations, "summary", apimodels.RuleVersionChangeRemoved},
+			{apimodels.RuleVersionChangeSectionNotificationSettings, "receiver", apimodels.RuleVersionChangeChanged},
+			{apimodels.RuleVersionChangeSectionNotificationSettings, "group_wait", apimodels.RuleVersionChangeRemoved},
+		}, keys)
+	})
+
+	t.Run("should report notification settings added as a whole", func(t *testing.T) {
+		to := *from
+		to.NotificationSettings = nil
+
+		changes := diffRuleVersions(&to, from)
+
+		require.Len(t, changes, 1)
+		require.Equal(t, apimodels.RuleVersionChangeSectionNotificationSettings, changes[0].Section)
+		require.Equal(t, apimodels.RuleVersionChangeAdded, changes[0].Op)
+		require.Empty(t, changes[0].Field)
+	})
+}
+
+func ruleVersionFromRule(rule *models.AlertRule, version int64) *models.AlertRuleVersion {
+	return &models.AlertRuleVersion{
+		RuleOrgID:            rule.OrgID,
+		RuleUID:              rule.UID,
+		RuleNamespaceUID:     rule.NamespaceUID,
+		RuleGroup:            rule.RuleGroup,
+		RuleGroupIndex:       rule.RuleGroupIndex,
+		ParentVersion:        version - 1,
+		Version:              version,
+		Created:              rule.Updated,
+		CreatedBy:            "user:test",
+		Title:                rule.Title,
+		Condition:            rule.Condition,
+		Data:                 rule.Data,
+		IntervalSeconds:      rule.IntervalSeconds,
+		Record:               rule.Record,
+		NoDataState:          rule.NoDataState,
+		ExecErrState:         rule.ExecErrState,
+		For:                  rule.For,
+		KeepFiringFor:        rule.KeepFiringFor,
+		Annotations:          rule.Annotations,
+		Labels:               rule.Labels,
+		IsPaused:             rule.IsPaused,
+		NotificationSettings: rule.NotificationSettings,
+	}
+}
@@ -286,3 +286,85 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// BacktestRuleGroup evaluates the rules of the group over the range and simulates the notifications that the current
+// notification policy tree would send for their alerts.
+func (srv TestingApiSrv) BacktestRuleGroup(c *contextmodel.ReqContext, cmd apimodels.BacktestRuleGroupConfig) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backgtesting API is not enabled")
+	}
+
+	if cmd.From.After(cmd.To) {
+		return ErrResp(400, nil, "From cannot be greater than To")
+	}
+
+	folder, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.FolderUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(dashboards.ErrFolderAccessDenied)
+	}
+
+	validated, err := ValidateRuleGroup(&cmd.RuleGroup, c.SignedInUser.GetOrgID(), folder.UID, RuleLimitsFromConfig(srv.cfg, srv.featureManager))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	rules := make([]*ngmodels.AlertRule, 0, len(validated))
+	// UIDs of the rules in the request. The rules that have none get a UID for the evaluation only.
+	uids := make([]string, 0, len(validated))
+	for _, r := range validated {
+		rule := r.AlertRule
+		// The scheduler does not evaluate paused rules, and recording rules do not fire alerts.
+		if rule.IsPaused || rule.Type() == ngmodels.RuleTypeRecording {
+			continue
+		}
+		uids = append(uids, rule.UID)
+		if rule.UID == "" {
+			// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs
+			rule.UID = "backtesting-" + util.GenerateShortUID()
+		}
+		if err := srv.authz.AuthorizeDatasourceAccessForRule(c.Req.Context(), c.SignedInUser, &rule); err != nil {
+			return errorToResponse(err)
+		}
+		rules = append(rules, &rule)
+	}
+	if len(rules) == 0 {
+		return ErrResp(http.StatusBadRequest, nil, "Rule group has no alert rules to evaluate")
+	}
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel)
+	extraLabels := func(rule *ngmodels.AlertRule) data.Labels {
+		return state.GetRuleExtraLabels(srv.log, rule, folder.Fullpath, includeFolder)
+	}
+	result, err := srv.backtesting.TestGroup(c.Req.Context(), c.SignedInUser, rules, extraLabels, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	body := apimodels.BacktestRuleGroupResult{
+		Rules:         make([]apimodels.BacktestRuleResult, 0, len(rules)),
+		Notifications: make([]apimodels.BacktestNotification, 0, len(result.Notifications.Notifications)),
+		Receivers:     make([]apimodels.BacktestReceiverNotifications, 0, len(result.Notifications.Receivers)),
+	}
+	for i, rule := range rules {
+		body.Rules = append(body.Rules, apimodels.BacktestRuleResult{UID: uids[i], Title: rule.Title, States: result.Rules[i]})
+	}
+	for _, n := range result.Notifications.Notifications {
+		body.Notifications = append(body.Notifications, apimodels.BacktestNotification{
+			Time:        n.Time,
+			Receiver:    n.Receiver,
+			GroupLabels: n.GroupLabels,
+			Firing:      n.Firing,
+			Resolved:    n.Resolved,
+		})
+	}
+	for _, r := range result.Notifications.Receivers {
+		body.Receivers = append(body.Receivers, apimodels.BacktestReceiverNotifications{
+			Receiver:      r.Receiver,
+			Notifications: r.Notifications,
+			Muted:         r.Muted,
+		})
+	}
+	return response.JSON(http.StatusOK, body)
+}
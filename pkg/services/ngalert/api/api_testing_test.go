@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -23,9 +25,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	fakes2 "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
@@ -243,6 +247,105 @@ func TestRouteTestGrafanaRuleConfig(t *testing.T) {
 	})
 }
 
+func TestBacktestRuleGroup(t *testing.T) {
+	rc := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+	gen := models.RuleGen
+	query := gen.GenerateQuery()
+	permissions := acMock.New().WithPermissions([]ac.Permission{
+		{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(query.DatasourceUID)},
+	})
+	group := func(interval time.Duration) definitions.PostableRuleGroupConfig {
+		rule := validRule()
+		rule.GrafanaManagedAlert.Data = ApiAlertQueriesFromAlertQueries([]models.AlertQuery{query})
+		rule.GrafanaManagedAlert.Condition = query.RefID
+		rule.GrafanaManagedAlert.UID = ""
+		rule.GrafanaManagedAlert.IsPaused = nil
+		noFor := model.Duration(0)
+		rule.ApiRuleNode.For = &noFor
+		return definitions.PostableRuleGroupConfig{
+			Name:     "group",
+			Interval: model.Duration(interval),
+			Rules:    []definitions.PostableExtendedRuleNode{rule},
+		}
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should return NotFound if backtesting is disabled", func(t *testing.T) {
+		srv := createTestingApiSrv(t, nil, permissions, eval_mocks.NewEvaluatorFactory(&eval_mocks.ConditionEvaluatorMock{}), featuremgmt.WithFeatures(), fakes2.NewRuleStore(t))
+		interval := srv.cfg.BaseInterval
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestRuleGroupConfig{From: from, To: from.Add(10 * interval), FolderUID: "folder", RuleGroup: group(interval)})
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return Forbidden if user cannot access folder", func(t *testing.T) {
+		ruleStore := fakes2.NewRuleStore(t)
+		srv := createTestingApiSrv(t, nil, permissions, eval_mocks.NewEvaluatorFactory(&eval_mocks.ConditionEvaluatorMock{}), featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting), ruleStore)
+		interval := srv.cfg.BaseInterval
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestRuleGroupConfig{From: from, To: from.Add(10 * interval), FolderUID: uuid.NewString(), RuleGroup: group(interval)})
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("should return the states of the rules and the simulated notifications", func(t *testing.T) {
+		f := randFolder()
+		ruleStore := fakes2.NewRuleStore(t)
+		ruleStore.Folders[rc.OrgID] = []*folder.Folder{f}
+
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(eval.Results{{Instance: data.Labels{"instance": "1"}, State: eval.Alerting}}, nil)
+		evalFactory := eval_mocks.NewEvaluatorFactory(evaluator)
+		srv := createTestingApiSrv(t, nil, permissions, evalFactory, featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting), ruleStore)
+		simulator := &fakeNotificationSimulator{
+			result: &notifier.NotificationSimulationResult{
+				Notifications: []notifier.SimulatedNotification{{Time: from, Receiver: "team", GroupLabels: model.LabelSet{"alertname": "test"}}},
+				Receivers:     []notifier.SimulatedReceiverNotifications{{Receiver: "team", Notifications: 1, Muted: 2}},
+			},
+		}
+		srv.backtesting = backtesting.NewEngine(nil, evalFactory, srv.tracer, simulator)
+
+		interval := srv.cfg.BaseInterval
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestRuleGroupConfig{From: from, To: from.Add(10 * interval), FolderUID: f.UID, RuleGroup: group(interval)})
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result struct {
+			Rules []struct {
+				UID    string          `json:"uid"`
+				Title  string          `json:"title"`
+				States json.RawMessage `json:"states"`
+			} `json:"rules"`
+			Notifications []definitions.BacktestNotification          `json:"notifications"`
+			Receivers     []definitions.BacktestReceiverNotifications `json:"receivers"`
+		}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Rules, 1)
+		require.Empty(t, result.Rules[0].UID)
+		require.NotEmpty(t, result.Rules[0].States)
+		require.Equal(t, []definitions.BacktestReceiverNotifications{{Receiver: "team", Notifications: 1, Muted: 2}}, result.Receivers)
+		require.Len(t, result.Notifications, 1)
+		require.Equal(t, "team", result.Notifications[0].Receiver)
+
+		require.Len(t, simulator.alerts, 10)
+		require.Equal(t, model.LabelValue(f.Fullpath), simulator.alerts[0].Labels[models.FolderTitleLabel])
+		require.Equal(t, model.LabelValue("1"), simulator.alerts[0].Labels["instance"])
+	})
+}
+
+type fakeNotificationSimulator struct {
+	alerts []notifier.SimulatedAlert
+	result *notifier.NotificationSimulationResult
+}
+
+func (f *fakeNotificationSimulator) SimulateNotifications(_ context.Context, _ int64, alerts []notifier.SimulatedAlert, _ time.Time) (*notifier.NotificationSimulationResult, error) {
+	f.alerts = alerts
+	return f.result, nil
+}
+
 func TestRouteEvalQueries(t *testing.T) {
 	t.Run("when fine-grained access is enabled", func(t *testing.T) {
 		rc := &contextmodel.ReqContext{
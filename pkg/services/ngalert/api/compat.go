@@ -28,6 +28,7 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
 		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
 		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
 		Annotations:          a.Annotations,
 		Labels:               a.Labels,
 		IsPaused:             a.IsPaused,
@@ -46,6 +47,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		RuleGroup:            rule.RuleGroup,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 ApiAlertQueriesFromAlertQueries(rule.Data),
 		Updated:              rule.Updated,
@@ -91,20 +93,25 @@ func AlertQueriesFromApiAlertQueries(queries []definitions.AlertQuery) []models.
 func ApiAlertQueriesFromAlertQueries(queries []models.AlertQuery) []definitions.AlertQuery {
 	result := make([]definitions.AlertQuery, 0, len(queries))
 	for _, q := range queries {
-		result = append(result, definitions.AlertQuery{
-			RefID:     q.RefID,
-			QueryType: q.QueryType,
-			RelativeTimeRange: definitions.RelativeTimeRange{
-				From: definitions.Duration(q.RelativeTimeRange.From),
-				To:   definitions.Duration(q.RelativeTimeRange.To),
-			},
-			DatasourceUID: q.DatasourceUID,
-			Model:         q.Model,
-		})
+		result = append(result, ApiAlertQueryFromAlertQuery(q))
 	}
 	return result
 }
 
+// ApiAlertQueryFromAlertQuery converts models.AlertQuery to definitions.AlertQuery
+func ApiAlertQueryFromAlertQuery(q models.AlertQuery) definitions.AlertQuery {
+	return definitions.AlertQuery{
+		RefID:     q.RefID,
+		QueryType: q.QueryType,
+		RelativeTimeRange: definitions.RelativeTimeRange{
+			From: definitions.Duration(q.RelativeTimeRange.From),
+			To:   definitions.Duration(q.RelativeTimeRange.To),
+		},
+		DatasourceUID: q.DatasourceUID,
+		Model:         q.Model,
+	}
+}
+
 func AlertRuleGroupFromApiAlertRuleGroup(a definitions.AlertRuleGroup) (models.AlertRuleGroup, error) {
 	ruleGroup := models.AlertRuleGroup{
 		Title:     a.Title,
@@ -183,6 +190,7 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		UID:                  rule.UID,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 data,
 		DashboardUID:         rule.DashboardUID,
@@ -196,6 +204,9 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor > 0 {
+		result.KeepFiringForString = util.Pointer(model.Duration(rule.KeepFiringFor).String())
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
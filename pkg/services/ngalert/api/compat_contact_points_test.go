@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	alerting_provisioning "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 )
 
 // Test that conversion notify.APIReceiver -> definitions.ContactPoint -> notify.APIReceiver does not lose data
@@ -67,10 +68,10 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			result, err := ContactPointFromContactPointExport(getContactPointExport(t, recCfg))
+			result, err := alerting_provisioning.ContactPointFromContactPointExport(getContactPointExport(t, recCfg))
 			require.NoError(t, err)
 
-			back, err := ContactPointToContactPointExport(result)
+			back, err := alerting_provisioning.ContactPointToContactPointExport(result)
 			require.NoError(t, err)
 
 			actual, err := notify.BuildReceiverConfiguration(context.Background(), &back, func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
@@ -105,7 +106,7 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 				},
 			},
 		}
-		result, err := ContactPointFromContactPointExport(export)
+		result, err := alerting_provisioning.ContactPointFromContactPointExport(export)
 		require.NoError(t, err)
 		require.Len(t, result.Pushover, 1)
 		require.Equal(t, int64(1), *result.Pushover[0].AlertingPriority)
@@ -123,7 +124,7 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 				},
 			},
 		}
-		result, err := ContactPointFromContactPointExport(export)
+		result, err := alerting_provisioning.ContactPointFromContactPointExport(export)
 		require.NoError(t, err)
 		require.Len(t, result.Email, 1)
 		require.EqualValues(t, []string{
@@ -151,7 +152,7 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 				},
 			},
 		}
-		result, err := ContactPointFromContactPointExport(export)
+		result, err := alerting_provisioning.ContactPointFromContactPointExport(export)
 		require.NoError(t, err)
 		require.Len(t, result.Webhook, 3)
 		require.Equal(t, int64(112), *result.Webhook[0].MaxAlerts)
@@ -177,11 +178,36 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 				},
 			},
 		}
-		result, err := ContactPointFromContactPointExport(export)
+		result, err := alerting_provisioning.ContactPointFromContactPointExport(export)
 		require.NoError(t, err)
 		require.Len(t, result.OnCall, 3)
 		require.Equal(t, int64(112), *result.OnCall[0].MaxAlerts)
 		require.Nil(t, result.OnCall[1].MaxAlerts)
 		require.Nil(t, result.OnCall[2].MaxAlerts)
 	})
+
+	t.Run("integrations that are not part of the alerting module", func(t *testing.T) {
+		settings := map[string]string{
+			"mqtt":   `{"broker_url": "ssl://localhost", "topic": "alerts", "client_id": "edge", "qos": 1, "retain": true, "message_format": "text", "message": "msg", "username": "user", "password": "secret", "tls_client_key": "key"}`,
+			"nats":   `{"url": "nats://localhost", "subject": "alerts", "jetstream": true, "token": "secret"}`,
+			"syslog": `{"address": "localhost:514", "protocol": "tls", "facility": "daemon", "severity": "crit", "insecure_skip_verify": true}`,
+		}
+		export := definitions.ContactPointExport{Name: "test"}
+		for _, typ := range []string{"mqtt", "nats", "syslog"} {
+			export.Receivers = append(export.Receivers, definitions.ReceiverExport{Type: typ, Settings: definitions.RawMessage(settings[typ])})
+		}
+		result, err := alerting_provisioning.ContactPointFromContactPointExport(export)
+		require.NoError(t, err)
+		require.Len(t, result.Mqtt, 1)
+		require.Equal(t, int64(1), *result.Mqtt[0].QoS)
+		require.Len(t, result.Nats, 1)
+		require.Len(t, result.Syslog, 1)
+
+		back, err := alerting_provisioning.ContactPointToContactPointExport(result)
+		require.NoError(t, err)
+		require.Len(t, back.Integrations, 3)
+		for _, integration := range back.Integrations {
+			require.JSONEq(t, settings[integration.Type], string(integration.Settings))
+		}
+	})
 }
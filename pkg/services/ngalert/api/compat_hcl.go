@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// RouteFromRouteExport converts definitions.RouteExport to definitions.Route using JSON marshalling.
+func RouteFromRouteExport(route *definitions.RouteExport) (definitions.Route, error) {
+	var result definitions.Route
+	data, err := json.Marshal(route)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+// ProvisionedAlertRuleFromAlertRuleExport converts definitions.AlertRuleExport of a rule of the group to definitions.ProvisionedAlertRule.
+func ProvisionedAlertRuleFromAlertRuleExport(group definitions.AlertRuleGroupExport, rule definitions.AlertRuleExport) (definitions.ProvisionedAlertRule, error) {
+	data := make([]definitions.AlertQuery, 0, len(rule.Data))
+	for _, q := range rule.Data {
+		raw, err := json.Marshal(q.Model)
+		if err != nil {
+			return definitions.ProvisionedAlertRule{}, err
+		}
+		query := definitions.AlertQuery{
+			RefID: q.RefID,
+			RelativeTimeRange: definitions.RelativeTimeRange{
+				From: definitions.Duration(time.Duration(q.RelativeTimeRange.FromSeconds) * time.Second),
+				To:   definitions.Duration(time.Duration(q.RelativeTimeRange.ToSeconds) * time.Second),
+			},
+			DatasourceUID: q.DatasourceUID,
+			Model:         raw,
+		}
+		if q.QueryType != nil {
+			query.QueryType = *q.QueryType
+		}
+		data = append(data, query)
+	}
+
+	result := definitions.ProvisionedAlertRule{
+		UID:           rule.UID,
+		OrgID:         group.OrgID,
+		FolderUID:     group.FolderUID,
+		RuleGroup:     group.Name,
+		Title:         rule.Title,
+		Condition:     rule.Condition,
+		Data:          data,
+		NoDataState:   rule.NoDataState,
+		ExecErrState:  rule.ExecErrState,
+		For:           rule.For,
+		KeepFiringFor: rule.KeepFiringFor,
+		IsPaused:      rule.IsPaused,
+	}
+	if rule.Annotations != nil {
+		result.Annotations = *rule.Annotations
+	}
+	if rule.Labels != nil {
+		result.Labels = *rule.Labels
+	}
+	if ns := rule.NotificationSettings; ns != nil {
+		result.NotificationSettings = &definitions.AlertRuleNotificationSettings{
+			Receiver:          ns.Receiver,
+			GroupBy:           ns.GroupBy,
+			MuteTimeIntervals: ns.MuteTimeIntervals,
+		}
+		var err error
+		settings := result.NotificationSettings
+		if settings.GroupWait, err = parseOptionalDuration(ns.GroupWait); err != nil {
+			return definitions.ProvisionedAlertRule{}, fmt.Errorf("rule %q: invalid group_wait: %w", rule.Title, err)
+		}
+		if settings.GroupInterval, err = parseOptionalDuration(ns.GroupInterval); err != nil {
+			return definitions.ProvisionedAlertRule{}, fmt.Errorf("rule %q: invalid group_interval: %w", rule.Title, err)
+		}
+		if settings.RepeatInterval, err = parseOptionalDuration(ns.RepeatInterval); err != nil {
+			return definitions.ProvisionedAlertRule{}, fmt.Errorf("rule %q: invalid repeat_interval: %w", rule.Title, err)
+		}
+	}
+	if rule.Record != nil {
+		result.Record = &definitions.Record{
+			Metric: rule.Record.Metric,
+			From:   rule.Record.From,
+		}
+	}
+	return result, nil
+}
+
+func parseOptionalDuration(s *string) (*model.Duration, error) {
+	if s == nil {
+		return nil, nil
+	}
+	d, err := model.ParseDuration(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	alerting_provisioning "github.com/grafana/grafana/pkg/services/provisioning/alerting"
+)
+
+func TestAlertingFileExportFromHCL(t *testing.T) {
+	t.Run("decodes exported rule groups", func(t *testing.T) {
+		exported, err := testData.ReadFile(path.Join("test-data", "post-rulegroup-101-export.hcl"))
+		require.NoError(t, err)
+		expected, err := testData.ReadFile(path.Join("test-data", "post-rulegroup-101-export.json"))
+		require.NoError(t, err)
+		folder := `resource "grafana_folder" "foo_bar" {
+  uid   = "e4584834-1a87-4dff-8913-8a4748dfca79"
+  title = "foo bar"
+}
+`
+
+		result, err := alerting_provisioning.AlertingFileExportFromHCL(1, append([]byte(folder), exported...), "export.tf")
+		require.NoError(t, err)
+		actual, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), string(actual))
+	})
+
+	t.Run("decodes exported mute timings", func(t *testing.T) {
+		exported, err := testData.ReadFile(path.Join("test-data", "alertmanager_default_mutetimings-export.hcl"))
+		require.NoError(t, err)
+		expected, err := testData.ReadFile(path.Join("test-data", "alertmanager_default_mutetimings-export.json"))
+		require.NoError(t, err)
+
+		result, err := alerting_provisioning.AlertingFileExportFromHCL(1, exported, "export.tf")
+		require.NoError(t, err)
+		actual, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), string(actual))
+	})
+
+	t.Run("decodes contact points and notification policies", func(t *testing.T) {
+		doc := `
+resource "grafana_contact_point" "team" {
+  name = "team"
+
+  email {
+    addresses               = ["team@example.com"]
+    disable_resolve_message = true
+  }
+}
+
+resource "grafana_notification_policy" "policy" {
+  contact_point = "default"
+  group_by      = ["alertname"]
+
+  policy {
+    contact_point = grafana_contact_point.team.name
+
+    matcher {
+      label = "team"
+      match = "=~"
+      value = "a|b"
+    }
+
+    group_wait = "10s"
+  }
+}
+`
+		result, err := alerting_provisioning.AlertingFileExportFromHCL(2, []byte(doc), "main.tf")
+		require.NoError(t, err)
+
+		require.Len(t, result.ContactPoints, 1)
+		cp := result.ContactPoints[0]
+		require.Equal(t, int64(2), cp.OrgID)
+		require.Equal(t, "team", cp.Name)
+		require.Len(t, cp.Receivers, 1)
+		require.Equal(t, "email", cp.Receivers[0].Type)
+		require.True(t, cp.Receivers[0].DisableResolveMessage)
+		require.JSONEq(t, `{"addresses":"team@example.com"}`, string(cp.Receivers[0].Settings))
+
+		require.Len(t, result.Policies, 1)
+		route, err := RouteFromRouteExport(result.Policies[0].RouteExport)
+		require.NoError(t, err)
+		require.Equal(t, "default", route.Receiver)
+		require.Equal(t, []string{"alertname"}, route.GroupByStr)
+		require.Len(t, route.Routes, 1)
+		require.Equal(t, "team", route.Routes[0].Receiver)
+		require.Equal(t, `team=~"a|b"`, route.Routes[0].ObjectMatchers[0].String())
+		require.Equal(t, "10s", route.Routes[0].GroupWait.String())
+	})
+
+	t.Run("converts rules to provisioned rules", func(t *testing.T) {
+		doc := `
+resource "grafana_rule_group" "group" {
+  name             = "group"
+  folder_uid       = "folder"
+  interval_seconds = 60
+
+  rule {
+    name      = "rule"
+    condition = "A"
+    for       = "5m"
+    labels    = { team = "a" }
+
+    data {
+      ref_id         = "A"
+      datasource_uid = "prom"
+      model          = jsonencode({ expr = "up" })
+
+      relative_time_range {
+        from = 600
+        to   = 0
+      }
+    }
+
+    notification_settings {
+      contact_point = "team"
+      group_wait    = "1m"
+    }
+  }
+}
+`
+		result, err := alerting_provisioning.AlertingFileExportFromHCL(1, []byte(doc), "main.tf")
+		require.NoError(t, err)
+		require.Len(t, result.Groups, 1)
+		group := result.Groups[0]
+		require.Equal(t, "1m", group.Interval.String())
+
+		rule, err := ProvisionedAlertRuleFromAlertRuleExport(group, group.Rules[0])
+		require.NoError(t, err)
+		require.Equal(t, "folder", rule.FolderUID)
+		require.Equal(t, "group", rule.RuleGroup)
+		require.Equal(t, "5m", rule.For.String())
+		require.Equal(t, map[string]string{"team": "a"}, rule.Labels)
+		require.JSONEq(t, `{"expr":"up"}`, string(rule.Data[0].Model))
+		require.Equal(t, definitions.Duration(600_000_000_000), rule.Data[0].RelativeTimeRange.From)
+		require.Equal(t, "team", rule.NotificationSettings.Receiver)
+		require.Equal(t, "1m", rule.NotificationSettings.GroupWait.String())
+	})
+}
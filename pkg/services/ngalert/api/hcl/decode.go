@@ -0,0 +1,178 @@
+package hcl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+var documentSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{{Type: "resource", LabelNames: []string{"type", "name"}}},
+}
+
+var functions = map[string]function.Function{
+	"jsonencode": stdlib.JSONEncodeFunc,
+	"jsondecode": stdlib.JSONDecodeFunc,
+}
+
+// Decode parses the HCL document and decodes the body of every resource whose type is a key of bodies.
+// The function of the type returns a pointer to the struct that the body is decoded into.
+// Resources of other types, and any other blocks of the document, such as provider or variable blocks, are ignored.
+//
+// The body is decoded according to the hcl tags of the struct, the same as Encode uses. All attributes and blocks are
+// optional, and the attributes and blocks that the struct does not have are ignored. Expressions can use the functions
+// jsonencode and jsondecode, and refer to the attributes of other resources of the document whose values are literals,
+// e.g. grafana_contact_point.default.name.
+//
+// If the document cannot be decoded, the error is hcl.Diagnostics, which refer to the lines of the document.
+func Decode(data []byte, filename string, bodies map[string]func() any) ([]Resource, error) {
+	file, diags := hclsyntax.ParseConfig(data, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	content, _, diags := file.Body.PartialContent(documentSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: resourceVariables(content.Blocks),
+		Functions: functions,
+	}
+	result := make([]Resource, 0, len(content.Blocks))
+	for _, block := range content.Blocks {
+		newBody, ok := bodies[block.Labels[0]]
+		if !ok {
+			continue
+		}
+		body := newBody()
+		diags = append(diags, decodeBody(block.Body, ctx, reflect.ValueOf(body).Elem())...)
+		result = append(result, Resource{
+			Type:     block.Labels[0],
+			Name:     block.Labels[1],
+			Body:     body,
+			DefRange: block.DefRange,
+		})
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return result, nil
+}
+
+// resourceVariables returns the variables that refer to the resources of the document. The value of a resource is an
+// object of its attributes whose values do not depend on anything else.
+func resourceVariables(blocks hcl.Blocks) map[string]cty.Value {
+	resources := make(map[string]map[string]cty.Value)
+	for _, block := range blocks {
+		body, ok := block.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		attrs := make(map[string]cty.Value, len(body.Attributes))
+		for name, attr := range body.Attributes {
+			v, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !v.IsWhollyKnown() {
+				continue
+			}
+			attrs[name] = v
+		}
+		typ, name := block.Labels[0], block.Labels[1]
+		if resources[typ] == nil {
+			resources[typ] = make(map[string]cty.Value)
+		}
+		resources[typ][name] = cty.ObjectVal(attrs)
+	}
+	result := make(map[string]cty.Value, len(resources))
+	for typ, byName := range resources {
+		result[typ] = cty.ObjectVal(byName)
+	}
+	return result
+}
+
+type field struct {
+	name  string
+	index int
+	block bool
+}
+
+// fieldsOf returns the fields of the struct type that have an hcl tag of an attribute or a block.
+func fieldsOf(t reflect.Type) []field {
+	result := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("hcl")
+		if !ok {
+			continue
+		}
+		name, kind, _ := strings.Cut(tag, ",")
+		switch kind {
+		case "", "attr", "optional":
+			result = append(result, field{name: name, index: i})
+		case "block":
+			result = append(result, field{name: name, index: i, block: true})
+		}
+	}
+	return result
+}
+
+func decodeBody(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
+	fields := fieldsOf(v.Type())
+	schema := &hcl.BodySchema{}
+	for _, f := range fields {
+		if f.block {
+			schema.Blocks = append(schema.Blocks, hcl.BlockHeaderSchema{Type: f.name})
+		} else {
+			schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{Name: f.name})
+		}
+	}
+	content, _, diags := body.PartialContent(schema)
+
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if !f.block {
+			if attr, ok := content.Attributes[f.name]; ok {
+				diags = append(diags, gohcl.DecodeExpression(attr.Expr, ctx, fv.Addr().Interface())...)
+			}
+			continue
+		}
+		blocks := content.Blocks.OfType(f.name)
+		if len(blocks) == 0 {
+			continue
+		}
+		if fv.Kind() == reflect.Slice {
+			elems := reflect.MakeSlice(fv.Type(), len(blocks), len(blocks))
+			for i, block := range blocks {
+				diags = append(diags, decodeBlock(block.Body, ctx, elems.Index(i))...)
+			}
+			fv.Set(elems)
+			continue
+		}
+		for _, duplicate := range blocks[1:] {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate block",
+				Detail:   fmt.Sprintf("Only one %q block is allowed.", f.name),
+				Subject:  duplicate.DefRange.Ptr(),
+			})
+		}
+		diags = append(diags, decodeBlock(blocks[0].Body, ctx, fv)...)
+	}
+	return diags
+}
+
+func decodeBlock(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
+	if v.Kind() != reflect.Ptr {
+		return decodeBody(body, ctx, v)
+	}
+	elem := reflect.New(v.Type().Elem())
+	diags := decodeBody(body, ctx, elem.Elem())
+	v.Set(elem)
+	return diags
+}
@@ -3,6 +3,7 @@ package hcl
 import (
 	"fmt"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 )
@@ -11,6 +12,8 @@ type Resource struct {
 	Type string      `hcl:"type,label"`
 	Name string      `hcl:"name,label"`
 	Body interface{} `hcl:",block"`
+	// DefRange is the range of the header of the resource block in the decoded document.
+	DefRange hcl.Range
 }
 
 func Encode(resources ...Resource) (data []byte, err error) {
@@ -73,3 +73,94 @@ func TestEncode(t *testing.T) {
 }
 `, string(encoded))
 }
+
+func TestDecode(t *testing.T) {
+	type data struct {
+		Name      string            `hcl:"name"`
+		Number    float64           `hcl:"number"`
+		NumberRef *float64          `hcl:"numberRef"`
+		Labels    map[string]string `hcl:"labels"`
+		Ignored   string
+		Blocks    []data `hcl:"blocks,block"`
+		SubData   *data  `hcl:"sub,block"`
+	}
+	bodies := map[string]func() any{
+		"grafana_test": func() any { return &data{} },
+	}
+
+	t.Run("decodes the resources of known types", func(t *testing.T) {
+		resources, err := Decode([]byte(`
+provider "grafana" {
+  url = "http://localhost:3000"
+}
+
+resource "grafana_other" "other" {
+  name = "other"
+}
+
+resource "grafana_test" "test-01" {
+  name      = "test"
+  number    = 123
+  numberRef = 1333
+  labels    = jsondecode("{\"a\":\"b\"}")
+  unknown   = "ignored"
+
+  blocks {
+    name = grafana_other.other.name
+  }
+  blocks {
+    name   = "${grafana_test.test-01.name}-1"
+    number = 2
+  }
+
+  sub {
+    name = "sub-data"
+  }
+}
+`), "main.tf", bodies)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		require.Equal(t, "grafana_test", resources[0].Type)
+		require.Equal(t, "test-01", resources[0].Name)
+		require.Equal(t, 10, resources[0].DefRange.Start.Line)
+		require.Equal(t, &data{
+			Name:      "test",
+			Number:    123,
+			NumberRef: func(f float64) *float64 { return &f }(1333),
+			Labels:    map[string]string{"a": "b"},
+			Blocks: []data{
+				{Name: "other"},
+				{Name: "test-1", Number: 2},
+			},
+			SubData: &data{Name: "sub-data"},
+		}, resources[0].Body)
+	})
+
+	t.Run("decodes what Encode encodes", func(t *testing.T) {
+		expected := &data{
+			Name:    "test",
+			Blocks:  []data{{Name: "el-0", Number: 1}},
+			SubData: &data{Name: "sub", Number: 2},
+		}
+		encoded, err := Encode(Resource{Type: "grafana_test", Name: "test", Body: expected})
+		require.NoError(t, err)
+
+		resources, err := Decode(encoded, "main.tf", bodies)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		require.Equal(t, expected, resources[0].Body)
+	})
+
+	t.Run("fails if a single block is repeated", func(t *testing.T) {
+		_, err := Decode([]byte(`resource "grafana_test" "test" {
+  sub {
+    name = "a"
+  }
+  sub {
+    name = "b"
+  }
+}
+`), "main.tf", bodies)
+		require.ErrorContains(t, err, `main.tf:5,3-6: Duplicate block; Only one "sub" block is allowed.`)
+	})
+}
@@ -72,6 +72,10 @@ func (f *ProvisioningApiHandler) handleRouteGetMuteTimings(ctx *contextmodel.Req
 	return f.svc.RouteGetMuteTimings(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRoutePostHclImport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RoutePostHclImport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePostMuteTiming(ctx *contextmodel.ReqContext, mt apimodels.MuteTimeInterval) response.Response {
 	return f.svc.RoutePostMuteTiming(ctx, mt)
 }
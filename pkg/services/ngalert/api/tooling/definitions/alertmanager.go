@@ -179,6 +179,18 @@ import (
 //       403: PermissionDenied
 //       409: AlertManagerNotReady
 
+// swagger:route POST /alertmanager/grafana/config/api/v1/routing/simulate alertmanager RoutePostGrafanaRoutingSimulation
+//
+// Simulate how an alert is routed through the Grafana notification policy tree without sending any notification.
+//
+//     Responses:
+//
+//       200: RoutingSimulationResult
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+//       409: AlertManagerNotReady
+
 // swagger:route GET /alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -375,6 +387,68 @@ const (
 	ExecutionError  TemplateErrorKind = "execution_error"
 )
 
+// swagger:parameters RoutePostGrafanaRoutingSimulation
+type RoutingSimulationParams struct {
+	// in:body
+	Body RoutingSimulationBody
+}
+
+// swagger:model
+type RoutingSimulationBody struct {
+	// Labels of the alert. If RuleUID is set, these are the labels of a sample alert instance of the rule.
+	Labels model.LabelSet `json:"labels,omitempty"`
+	// UID of the alert rule that fires the alert. The labels of the rule, and the labels that Grafana adds to the alerts
+	// of the rule, are added to Labels.
+	RuleUID string `json:"rule_uid,omitempty"`
+	// Time at which the alert is routed. Defaults to the current time.
+	// Format: date-time
+	Time *strfmt.DateTime `json:"time,omitempty"`
+}
+
+// swagger:model
+type RoutingSimulationResult struct {
+	// Labels of the alert that is routed.
+	Labels model.LabelSet `json:"labels"`
+	// Time at which the alert is routed.
+	Time time.Time `json:"time"`
+	// Routes that the alert matches. The alert matches more than one route if some routes continue matching.
+	Routes []SimulatedRoute `json:"routes"`
+	// Silences that match the alert and are active at the time.
+	Silences []GettableSilence `json:"silences"`
+}
+
+type SimulatedRoute struct {
+	// Nodes of the notification policy tree from the root to the matched route.
+	Path []SimulatedRouteNode `json:"path"`
+	// Receiver is the name of the contact point that the alert is sent to.
+	Receiver string `json:"receiver"`
+	// Labels that the alerts are grouped by. It is ["..."] if the alerts are grouped by all labels.
+	GroupBy []string `json:"group_by"`
+	// Labels of the alert that identify the group it belongs to.
+	GroupLabels    model.LabelSet `json:"group_labels"`
+	GroupWait      model.Duration `json:"group_wait"`
+	GroupInterval  model.Duration `json:"group_interval"`
+	RepeatInterval model.Duration `json:"repeat_interval"`
+	// Time intervals during which notifications of the route are muted.
+	MuteTimeIntervals []SimulatedTimeInterval `json:"mute_time_intervals,omitempty"`
+	// Time intervals outside which notifications of the route are muted.
+	ActiveTimeIntervals []SimulatedTimeInterval `json:"active_time_intervals,omitempty"`
+	// Muted is true if the time intervals of the route mute notifications at the time.
+	Muted bool `json:"muted"`
+}
+
+type SimulatedRouteNode struct {
+	Receiver string   `json:"receiver,omitempty"`
+	Matchers []string `json:"matchers,omitempty"`
+	Continue bool     `json:"continue,omitempty"`
+}
+
+type SimulatedTimeInterval struct {
+	Name string `json:"name"`
+	// Active is true if the time is within the time interval.
+	Active bool `json:"active"`
+}
+
 // swagger:parameters RouteCreateSilence RouteCreateGrafanaSilence
 type CreateSilenceParams struct {
 	// in:body
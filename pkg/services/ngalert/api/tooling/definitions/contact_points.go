@@ -100,6 +100,43 @@ type OnCallIntegration struct {
 	Message                  *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
 }
 
+type MqttIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	BrokerURL string `json:"broker_url" yaml:"broker_url" hcl:"broker_url"`
+	Topic     string `json:"topic" yaml:"topic" hcl:"topic"`
+
+	ClientID             *string `json:"client_id,omitempty" yaml:"client_id,omitempty" hcl:"client_id"`
+	QoS                  *int64  `json:"qos,omitempty" yaml:"qos,omitempty" hcl:"qos"`
+	Retain               *bool   `json:"retain,omitempty" yaml:"retain,omitempty" hcl:"retain"`
+	MessageFormat        *string `json:"message_format,omitempty" yaml:"message_format,omitempty" hcl:"message_format"`
+	Message              *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+	Username             *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	Password             *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	InsecureSkipVerify   *bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty" hcl:"insecure_skip_verify"`
+	TLSCACertificate     *string `json:"tls_ca_certificate,omitempty" yaml:"tls_ca_certificate,omitempty" hcl:"tls_ca_certificate"`
+	TLSClientCertificate *string `json:"tls_client_certificate,omitempty" yaml:"tls_client_certificate,omitempty" hcl:"tls_client_certificate"`
+	TLSClientKey         *Secret `json:"tls_client_key,omitempty" yaml:"tls_client_key,omitempty" hcl:"tls_client_key"`
+}
+
+type NatsIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL     string `json:"url" yaml:"url" hcl:"url"`
+	Subject string `json:"subject" yaml:"subject" hcl:"subject"`
+
+	JetStream            *bool   `json:"jetstream,omitempty" yaml:"jetstream,omitempty" hcl:"jetstream"`
+	MessageFormat        *string `json:"message_format,omitempty" yaml:"message_format,omitempty" hcl:"message_format"`
+	Message              *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+	Username             *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	Password             *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	Token                *Secret `json:"token,omitempty" yaml:"token,omitempty" hcl:"token"`
+	InsecureSkipVerify   *bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty" hcl:"insecure_skip_verify"`
+	TLSCACertificate     *string `json:"tls_ca_certificate,omitempty" yaml:"tls_ca_certificate,omitempty" hcl:"tls_ca_certificate"`
+	TLSClientCertificate *string `json:"tls_client_certificate,omitempty" yaml:"tls_client_certificate,omitempty" hcl:"tls_client_certificate"`
+	TLSClientKey         *Secret `json:"tls_client_key,omitempty" yaml:"tls_client_key,omitempty" hcl:"tls_client_key"`
+}
+
 type OpsgenieIntegrationResponder struct {
 	ID       *string `json:"id,omitempty" yaml:"id,omitempty" hcl:"id"`
 	Name     *string `json:"name,omitempty" yaml:"name,omitempty" hcl:"name"`
@@ -207,6 +244,25 @@ type SlackIntegration struct {
 	MentionGroups  *string `json:"mentionGroups,omitempty" yaml:"mentionGroups,omitempty" hcl:"mention_groups"`
 }
 
+type SyslogIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	Address string `json:"address" yaml:"address" hcl:"address"`
+
+	Protocol             *string `json:"protocol,omitempty" yaml:"protocol,omitempty" hcl:"protocol"`
+	Facility             *string `json:"facility,omitempty" yaml:"facility,omitempty" hcl:"facility"`
+	Severity             *string `json:"severity,omitempty" yaml:"severity,omitempty" hcl:"severity"`
+	ResolvedSeverity     *string `json:"resolved_severity,omitempty" yaml:"resolved_severity,omitempty" hcl:"resolved_severity"`
+	Hostname             *string `json:"hostname,omitempty" yaml:"hostname,omitempty" hcl:"hostname"`
+	AppName              *string `json:"app_name,omitempty" yaml:"app_name,omitempty" hcl:"app_name"`
+	MsgID                *string `json:"msg_id,omitempty" yaml:"msg_id,omitempty" hcl:"msg_id"`
+	Message              *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+	InsecureSkipVerify   *bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty" hcl:"insecure_skip_verify"`
+	TLSCACertificate     *string `json:"tls_ca_certificate,omitempty" yaml:"tls_ca_certificate,omitempty" hcl:"tls_ca_certificate"`
+	TLSClientCertificate *string `json:"tls_client_certificate,omitempty" yaml:"tls_client_certificate,omitempty" hcl:"tls_client_certificate"`
+	TLSClientKey         *Secret `json:"tls_client_key,omitempty" yaml:"tls_client_key,omitempty" hcl:"tls_client_key"`
+}
+
 type TelegramIntegration struct {
 	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
 
@@ -299,6 +355,8 @@ type ContactPoint struct {
 	Googlechat   []GooglechatIntegration   `json:"googlechat" yaml:"googlechat" hcl:"googlechat,block"`
 	Kafka        []KafkaIntegration        `json:"kafka" yaml:"kafka" hcl:"kafka,block"`
 	Line         []LineIntegration         `json:"line" yaml:"line" hcl:"line,block"`
+	Mqtt         []MqttIntegration         `json:"mqtt" yaml:"mqtt" hcl:"mqtt,block"`
+	Nats         []NatsIntegration         `json:"nats" yaml:"nats" hcl:"nats,block"`
 	Opsgenie     []OpsgenieIntegration     `json:"opsgenie" yaml:"opsgenie" hcl:"opsgenie,block"`
 	Pagerduty    []PagerdutyIntegration    `json:"pagerduty" yaml:"pagerduty" hcl:"pagerduty,block"`
 	OnCall       []OnCallIntegration       `json:"oncall" yaml:"oncall" hcl:"oncall,block"`
@@ -306,6 +364,7 @@ type ContactPoint struct {
 	Sensugo      []SensugoIntegration      `json:"sensugo" yaml:"sensugo" hcl:"sensugo,block"`
 	Slack        []SlackIntegration        `json:"slack" yaml:"slack" hcl:"slack,block"`
 	Sns          []SnsIntegration          `json:"sns" yaml:"sns" hcl:"sns,block"`
+	Syslog       []SyslogIntegration       `json:"syslog" yaml:"syslog" hcl:"syslog,block"`
 	Teams        []TeamsIntegration        `json:"teams" yaml:"teams" hcl:"teams,block"`
 	Telegram     []TelegramIntegration     `json:"telegram" yaml:"telegram" hcl:"telegram,block"`
 	Threema      []ThreemaIntegration      `json:"threema" yaml:"threema" hcl:"threema,block"`
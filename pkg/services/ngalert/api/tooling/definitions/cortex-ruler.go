@@ -20,6 +20,57 @@ import (
 //       403: ForbiddenError
 //       404: description: Not found.
 
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions ruler RouteGetRuleVersionsByUID
+//
+// List all stored versions of a rule, newest first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersions
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version} ruler RouteGetRuleVersionByUID
+//
+// Get a specific version of a rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersion
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/diff ruler RouteGetRuleVersionDiff
+//
+// Get the changes between a version of a rule and another version, by default its parent version
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionDiff
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route POST /ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore ruler RoutePostRestoreRuleVersion
+//
+// Restores a version of a rule by saving its content as a new version
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: UpdateRuleGroupResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+//       409: description: Conflict.
+
 // swagger:route Get /ruler/grafana/api/v1/rules ruler RouteGetGrafanaRulesConfig
 //
 // List rule groups
@@ -73,6 +124,24 @@ import (
 //       403: ForbiddenError
 //
 
+// swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/import ruler RoutePostImportPrometheusRules
+//
+// Converts Prometheus rule groups to Grafana-managed rules and saves them in the folder
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ImportPrometheusRulesResponse
+//       202: ImportPrometheusRulesResponse
+//       400: ImportPrometheusRulesResponse
+//       403: ForbiddenError
+//       404: description: Not found.
+//       409: description: Conflict.
+
 // swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/export ruler RoutePostRulesGroupForExport
 //
 // Converts submitted rule group to provisioning format
@@ -224,6 +293,144 @@ type PathGetRuleByUIDParams struct {
 	RuleUID string
 }
 
+// swagger:parameters RouteGetRuleVersionsByUID
+type PathGetRuleVersionsByUIDParams struct {
+	// in: path
+	RuleUID string
+}
+
+// swagger:parameters RouteGetRuleVersionByUID RoutePostRestoreRuleVersion
+type PathRuleVersionParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+}
+
+// swagger:parameters RouteGetRuleVersionDiff
+type RuleVersionDiffParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+	// The version to compare with. Defaults to the parent of Version.
+	// in: query
+	// required: false
+	CompareTo int64 `json:"compareTo"`
+}
+
+// swagger:parameters RoutePostImportPrometheusRules
+type ImportPrometheusRulesParams struct {
+	// The UID of the rule folder
+	// in: path
+	Namespace string
+	// The UID of the Prometheus data source that the imported rules query.
+	// in: query
+	// required: true
+	DatasourceUID string `json:"datasourceUID"`
+	// Convert the rules and return them without saving.
+	// in: query
+	// required: false
+	DryRun bool `json:"dryRun"`
+	// in: body
+	Body PrometheusRuleFile
+}
+
+// GettableRuleVersion is a stored version of a Grafana managed rule.
+// swagger:model
+type GettableRuleVersion struct {
+	Version       int64 `json:"version"`
+	ParentVersion int64 `json:"parent_version"`
+	// The version that this version was restored from, if any.
+	RestoredFrom int64     `json:"restored_from,omitempty"`
+	Created      time.Time `json:"created"`
+	// Identifier of the user or service account that created the version, if known.
+	CreatedBy string                   `json:"created_by,omitempty"`
+	Rule      GettableExtendedRuleNode `json:"rule"`
+}
+
+// swagger:model
+type GettableRuleVersions []GettableRuleVersion
+
+// swagger:enum RuleVersionChangeSection
+type RuleVersionChangeSection string
+
+const (
+	RuleVersionChangeSectionRule                 RuleVersionChangeSection = "rule"
+	RuleVersionChangeSectionQueries              RuleVersionChangeSection = "queries"
+	RuleVersionChangeSectionLabels               RuleVersionChangeSection = "labels"
+	RuleVersionChangeSectionAnnotations          RuleVersionChangeSection = "annotations"
+	RuleVersionChangeSectionNotificationSettings RuleVersionChangeSection = "notification_settings"
+)
+
+// swagger:enum RuleVersionChangeOp
+type RuleVersionChangeOp string
+
+const (
+	RuleVersionChangeAdded   RuleVersionChangeOp = "added"
+	RuleVersionChangeRemoved RuleVersionChangeOp = "removed"
+	RuleVersionChangeChanged RuleVersionChangeOp = "changed"
+)
+
+// RuleVersionChange is a single difference between two versions of a rule.
+type RuleVersionChange struct {
+	Section RuleVersionChangeSection `json:"section"`
+	// The name of the field, label, annotation or the RefID of the query that changed.
+	Field string              `json:"field"`
+	Op    RuleVersionChangeOp `json:"op"`
+	From  any                 `json:"from,omitempty"`
+	To    any                 `json:"to,omitempty"`
+}
+
+// RuleVersionDiff describes how to get from one version of a rule to another.
+// swagger:model
+type RuleVersionDiff struct {
+	RuleUID     string              `json:"rule_uid"`
+	FromVersion int64               `json:"from_version"`
+	ToVersion   int64               `json:"to_version"`
+	Changes     []RuleVersionChange `json:"changes"`
+}
+
+// PrometheusRuleFile has the same structure as a Prometheus rule file.
+// swagger:model
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// swagger:model
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Limit    int            `yaml:"limit,omitempty" json:"limit,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
+
+// swagger:model
+type ImportPrometheusRulesResponse struct {
+	Message string              `json:"message"`
+	Groups  []ImportedRuleGroup `json:"groups,omitempty"`
+	// Errors lists the groups and rules that could not be converted. Nothing is saved if there are any.
+	Errors []PrometheusRuleConversionError `json:"errors,omitempty"`
+}
+
+// ImportedRuleGroup is the result of importing a Prometheus rule group.
+type ImportedRuleGroup struct {
+	Name string `json:"name"`
+	// The converted rules. Only set for dry runs.
+	Rules   []GettableExtendedRuleNode `json:"rules,omitempty"`
+	Created []string                   `json:"created,omitempty"`
+	Updated []string                   `json:"updated,omitempty"`
+	Deleted []string                   `json:"deleted,omitempty"`
+}
+
+type PrometheusRuleConversionError struct {
+	Group string `json:"group"`
+	// The position of the rule in the group, or -1 if the error is about the group itself.
+	RuleIndex int    `json:"rule_index"`
+	Rule      string `json:"rule,omitempty"`
+	Error     string `json:"error"`
+}
+
 // swagger:model
 type RuleGroupConfigResponse struct {
 	GettableRuleGroupConfig
@@ -34,3 +34,33 @@ type DecryptQueryParams struct {
 	// default: false
 	Decrypt bool `json:"decrypt"`
 }
+
+// swagger:route POST /v1/provisioning/import/hcl provisioning stable RoutePostHclImport
+//
+// Import rule groups, contact points, notification policies and mute timings from a Terraform HCL document.
+// The resources are applied in this order in a single transaction, and no resource is applied if one of them cannot be applied.
+//
+//     Consumes:
+//     - text/hcl
+//     - application/terraform+hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+
+// swagger:parameters RoutePostHclImport
+type HclImportParams struct {
+	// The Terraform HCL document. Resources of other types than grafana_rule_group, grafana_contact_point,
+	// grafana_notification_policy, grafana_mute_timing and grafana_folder are ignored.
+	// in:body
+	Body string
+
+	// Whether to only validate the document. The resources are validated the same way as they are applied, and none of them is saved.
+	// in: query
+	// required: false
+	// default: false
+	DryRun bool `json:"dryRun"`
+
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
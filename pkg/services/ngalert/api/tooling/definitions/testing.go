@@ -68,6 +68,24 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /v1/rule/backtest/group testing BacktestRuleGroup
+//
+// Test a rule group and simulate the notifications of its alerts
+//
+// The rules of the group are evaluated over the range, and their alerts are replayed through the current notification
+// policy tree of the organization, with grouping, group wait, group interval, repeat interval and time intervals
+// applied. Silences are not applied. Recording rules and paused rules are not evaluated.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestRuleGroupResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -234,3 +252,54 @@ type BacktestConfig struct {
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:parameters BacktestRuleGroup
+type BacktestRuleGroupRequest struct {
+	// in:body
+	Body BacktestRuleGroupConfig
+}
+
+// swagger:model
+type BacktestRuleGroupConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// UID of the folder of the rule group.
+	FolderUID string `json:"folder_uid"`
+	// The rule group in the same format as the ruler API accepts it. The group and its rules do not need to exist.
+	RuleGroup PostableRuleGroupConfig `json:"rule_group"`
+}
+
+// swagger:model
+type BacktestRuleGroupResult struct {
+	// Results of the rules of the group that are evaluated, in the order of the group.
+	Rules []BacktestRuleResult `json:"rules"`
+	// Notifications that would be sent, ordered by time.
+	Notifications []BacktestNotification `json:"notifications"`
+	// Number of notifications of every receiver that would be notified.
+	Receivers []BacktestReceiverNotifications `json:"receivers"`
+}
+
+type BacktestRuleResult struct {
+	UID   string `json:"uid,omitempty"`
+	Title string `json:"title"`
+	// States of the alert instances of the rule, in the same format as the result of testing a rule.
+	States *data.Frame `json:"states"`
+}
+
+type BacktestNotification struct {
+	Time     time.Time `json:"time"`
+	Receiver string    `json:"receiver"`
+	// Labels that identify the group of alerts of the notification.
+	GroupLabels model.LabelSet   `json:"group_labels"`
+	Firing      []model.LabelSet `json:"firing"`
+	// Resolved alerts are empty if all integrations of the receiver disable resolve messages.
+	Resolved []model.LabelSet `json:"resolved"`
+}
+
+type BacktestReceiverNotifications struct {
+	Receiver      string `json:"receiver"`
+	Notifications int    `json:"notifications"`
+	// Number of notifications that are not sent because the time intervals of the route mute them.
+	Muted int `json:"muted"`
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 )
@@ -39,14 +41,24 @@ type stateManager interface {
 	schedule.RuleStateProvider
 }
 
+// NotificationSimulator simulates the notifications that the notification policy tree of the organization would send
+// for the alerts.
+type NotificationSimulator interface {
+	SimulateNotifications(ctx context.Context, orgID int64, alerts []notifier.SimulatedAlert, until time.Time) (*notifier.NotificationSimulationResult, error)
+}
+
 type Engine struct {
 	evalFactory        eval.EvaluatorFactory
 	createStateManager func() stateManager
+	notifications      NotificationSimulator
+	appUrl             *url.URL
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer, notifications NotificationSimulator) *Engine {
 	return &Engine{
-		evalFactory: evalFactory,
+		evalFactory:   evalFactory,
+		notifications: notifications,
+		appUrl:        appUrl,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:       nil,
@@ -64,18 +76,93 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 }
 
 func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
-	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
-	logger := logger.FromContext(ctx)
+	if err := validateRange(rule, from, to); err != nil {
+		return nil, err
+	}
+	return e.testRule(ctx, user, e.createStateManager(), rule, from, to, nil, nil)
+}
+
+// GroupResult is the result of testing a rule group.
+type GroupResult struct {
+	// Rules contain the states of the alert instances of every rule, in the same format as Test returns, and in the
+	// order of the rules.
+	Rules []*data.Frame
+	// Notifications are the notifications that the current notification policy tree would send for the alerts of
+	// the rules.
+	Notifications *notifier.NotificationSimulationResult
+}
+
+// TestGroup evaluates the rules of a group over the range, and replays the evaluations through the state manager and
+// then through the current notification policy tree of the organization, in the same way as the scheduler sends the
+// alerts of the rules to the Alertmanager. The rules must belong to the same organization and have the same interval.
+// extraLabels returns the labels that the scheduler adds to the alerts of a rule.
+func (e *Engine) TestGroup(ctx context.Context, user identity.Requester, rules []*models.AlertRule, extraLabels func(*models.AlertRule) data.Labels, from, to time.Time) (*GroupResult, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: rule group has no rules", ErrInvalidInputData)
+	}
+	if e.notifications == nil {
+		return nil, errors.New("notification simulation is not available")
+	}
+	for _, rule := range rules {
+		if rule.OrgID != rules[0].OrgID || rule.IntervalSeconds != rules[0].IntervalSeconds {
+			return nil, fmt.Errorf("%w: rules of the group must have the same organization and interval", ErrInvalidInputData)
+		}
+		if err := validateRange(rule, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	// The state manager has the states of all rules of the group, the same as the one of the scheduler.
+	stateManager := e.createStateManager()
+	result := &GroupResult{Rules: make([]*data.Frame, 0, len(rules))}
+	var alerts []notifier.SimulatedAlert
+	for _, rule := range rules {
+		send := func(_ context.Context, states state.StateTransitions) {
+			for _, s := range states {
+				alert := state.StateToPostableAlert(s, e.appUrl)
+				lbls := make(model.LabelSet, len(alert.Labels))
+				for k, v := range alert.Labels {
+					lbls[model.LabelName(k)] = model.LabelValue(v)
+				}
+				alerts = append(alerts, notifier.SimulatedAlert{
+					// The scheduler sends the alerts right after the evaluation.
+					ReceivedAt: s.LastEvaluationTime,
+					Labels:     lbls,
+					StartsAt:   time.Time(alert.StartsAt),
+					EndsAt:     time.Time(alert.EndsAt),
+				})
+			}
+		}
+		frame, err := e.testRule(ctx, user, stateManager, rule, from, to, extraLabels(rule), send)
+		if err != nil {
+			return nil, fmt.Errorf("failed to test rule %s: %w", rule.UID, err)
+		}
+		result.Rules = append(result.Rules, frame)
+	}
 
+	notifications, err := e.notifications.SimulateNotifications(ctx, rules[0].OrgID, alerts, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate notifications: %w", err)
+	}
+	result.Notifications = notifications
+	return result, nil
+}
+
+func validateRange(rule *models.AlertRule, from, to time.Time) error {
 	if !from.Before(to) {
-		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+		return fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
 	}
 	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
-		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+		return fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
 	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
+	return nil
+}
 
-	stateManager := e.createStateManager()
+func (e *Engine) testRule(ctx context.Context, user identity.Requester, stateManager stateManager, rule *models.AlertRule, from, to time.Time, extraLabels data.Labels, send state.Sender) (*data.Frame, error) {
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	logger := logger.FromContext(ctx)
+
+	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
 
 	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition(), &schedule.AlertingResultsFromRuleState{
 		Manager: stateManager,
@@ -97,7 +184,7 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
 			return nil
 		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil, nil)
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, extraLabels, send)
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -9,14 +9,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -411,3 +414,78 @@ func (f *fakeBacktestingEvaluator) Eval(_ context.Context, from time.Time, inter
 	}
 	return nil
 }
+
+func TestEvaluatorTestGroup(t *testing.T) {
+	gen := models.RuleGen
+	gen = gen.With(gen.WithOrgID(1), gen.WithInterval(time.Minute), gen.WithFor(0), gen.WithKeepFiringFor(0), gen.WithNoNotificationSettings())
+	firing := gen.GenerateRef()
+	normal := gen.GenerateRef()
+
+	stateByRule := map[string]eval.State{
+		firing.UID: eval.Alerting,
+		normal.UID: eval.Normal,
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+		key, _ := models.RuleKeyFromContext(ctx)
+		return &fakeBacktestingEvaluator{
+			evalCallback: func(now time.Time) (eval.Results, error) {
+				return eval.Results{{Instance: data.Labels{"instance": "1"}, State: stateByRule[key.UID], EvaluatedAt: now}}, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	simulator := &fakeNotificationSimulator{}
+	engine := NewEngine(nil, nil, tracing.InitializeTracerForTest(), simulator)
+	extraLabels := func(rule *models.AlertRule) data.Labels {
+		return data.Labels{"rule": rule.UID}
+	}
+	from := time.Unix(0, 0)
+	to := from.Add(10 * time.Minute)
+
+	t.Run("should replay the alerts of all rules through the notification simulation", func(t *testing.T) {
+		result, err := engine.TestGroup(context.Background(), nil, []*models.AlertRule{firing, normal}, extraLabels, from, to)
+		require.NoError(t, err)
+		require.Len(t, result.Rules, 2)
+		require.Equal(t, 10, result.Rules[0].Rows())
+		require.Equal(t, 10, result.Rules[1].Rows())
+		require.Same(t, simulator.result, result.Notifications)
+
+		require.Equal(t, int64(1), simulator.orgID)
+		require.Equal(t, to, simulator.until)
+		require.NotEmpty(t, simulator.alerts)
+		for _, a := range simulator.alerts {
+			require.Equal(t, model.LabelValue(firing.UID), a.Labels["rule"], "only alerts of the firing rule should be sent")
+			require.Equal(t, model.LabelValue("1"), a.Labels["instance"])
+			require.Equal(t, from, a.StartsAt)
+			require.True(t, a.EndsAt.After(a.ReceivedAt))
+		}
+		require.Equal(t, from, simulator.alerts[0].ReceivedAt)
+	})
+
+	t.Run("should fail if rules have different intervals", func(t *testing.T) {
+		other := gen.With(gen.WithInterval(2 * time.Minute)).GenerateRef()
+		_, err := engine.TestGroup(context.Background(), nil, []*models.AlertRule{firing, other}, extraLabels, from, to)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("should fail if there are no rules", func(t *testing.T) {
+		_, err := engine.TestGroup(context.Background(), nil, nil, extraLabels, from, to)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
+type fakeNotificationSimulator struct {
+	orgID  int64
+	alerts []notifier.SimulatedAlert
+	until  time.Time
+	result *notifier.NotificationSimulationResult
+}
+
+func (f *fakeNotificationSimulator) SimulateNotifications(_ context.Context, orgID int64, alerts []notifier.SimulatedAlert, until time.Time) (*notifier.NotificationSimulationResult, error) {
+	f.orgID, f.alerts, f.until = orgID, alerts, until
+	f.result = &notifier.NotificationSimulationResult{}
+	return f.result, nil
+}
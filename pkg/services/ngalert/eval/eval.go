@@ -161,6 +161,9 @@ type ExecutionResults struct {
 	// NoData contains the DatasourceUID for RefIDs that returned no data.
 	NoData map[string]string
 
+	// Severities contains the names of the severity levels of the condition, if it is a threshold with severity levels.
+	Severities []string
+
 	Error error
 }
 
@@ -262,6 +265,10 @@ type Result struct {
 	// as EvalMatches (from "classic condition"), and in the future from operations
 	// like SSE "math".
 	EvaluationString string
+
+	// Severity is the name of the severity level of an Alerting result when the condition is a threshold with
+	// severity levels.
+	Severity string
 }
 
 func NewResultFromError(err error, evaluatedAt time.Time, duration time.Duration) Result {
@@ -450,11 +457,15 @@ func queryDataResponseToExecutionResults(c models.Condition, execResp *backend.Q
 	// datasourceUIDsForRefIDs is a short-lived lookup table of RefID to DatasourceUID
 	// for efficient lookups of the DatasourceUID when a RefID returns no data
 	datasourceUIDsForRefIDs := make(map[string]string)
+	var severities []string
 	for _, next := range c.Data {
 		datasourceUIDsForRefIDs[next.RefID] = next.DatasourceUID
+		if next.RefID == c.Condition && expr.NodeTypeFromDatasourceUID(next.DatasourceUID) == expr.TypeCMDNode {
+			severities, _ = next.GetThresholdSeverities() // the model was validated when the pipeline was built
+		}
 	}
 
-	result := ExecutionResults{Results: make(map[string]data.Frames)}
+	result := ExecutionResults{Results: make(map[string]data.Frames), Severities: severities}
 
 	result.Error = FindConditionError(execResp, c.Condition)
 
@@ -646,7 +657,8 @@ func datasourceUIDsToRefIDs(refIDsToDatasourceUIDs map[string]string) map[string
 //
 // If a value is set:
 //   - 0 results in Normal.
-//   - Nonzero (e.g 1.2, NaN) results in Alerting.
+//   - Nonzero (e.g 1.2, NaN) results in Alerting. If the condition is a threshold with severity levels,
+//     the value is the position of the severity level of the result.
 //   - nil results in noData.
 //   - unsupported Frame schemas results in Error.
 func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results {
@@ -741,6 +753,7 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 			r.State = Normal
 		default:
 			r.State = Alerting
+			r.Severity = expr.SeverityOfLevel(execResults.Severities, *val)
 		}
 
 		evalResults = append(evalResults, r)
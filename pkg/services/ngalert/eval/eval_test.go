@@ -2,6 +2,7 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -61,6 +62,34 @@ func TestEvaluateExecutionResult(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "threshold with severity levels sets the severity of Alerting results",
+			execResults: ExecutionResults{
+				Condition: []*data.Frame{
+					data.NewFrame("", data.NewField("", data.Labels{"host": "a"}, []*float64{util.Pointer(0.0)})),
+					data.NewFrame("", data.NewField("", data.Labels{"host": "b"}, []*float64{util.Pointer(1.0)})),
+					data.NewFrame("", data.NewField("", data.Labels{"host": "c"}, []*float64{util.Pointer(2.0)})),
+				},
+				Severities: []string{"warning", "critical"},
+			},
+			expectResultLength: 3,
+			expectResults: Results{
+				{
+					State:    Normal,
+					Instance: data.Labels{"host": "a"},
+				},
+				{
+					State:    Alerting,
+					Instance: data.Labels{"host": "b"},
+					Severity: "warning",
+				},
+				{
+					State:    Alerting,
+					Instance: data.Labels{"host": "c"},
+					Severity: "critical",
+				},
+			},
+		},
 		{
 			desc: "nil value single instance is single a NoData state result",
 			execResults: ExecutionResults{
@@ -303,6 +332,7 @@ func TestEvaluateExecutionResult(t *testing.T) {
 			for i, r := range res {
 				require.Equal(t, tc.expectResults[i].State, r.State)
 				require.Equal(t, tc.expectResults[i].Instance, r.Instance)
+				require.Equal(t, tc.expectResults[i].Severity, r.Severity)
 				if tc.expectResults[i].State == Error {
 					require.EqualError(t, tc.expectResults[i].Error, r.Error.Error())
 				}
@@ -311,6 +341,30 @@ func TestEvaluateExecutionResult(t *testing.T) {
 	}
 }
 
+func TestQueryDataResponseToExecutionResultsSeverities(t *testing.T) {
+	threshold := func(refID string, model string) models.AlertQuery {
+		return models.AlertQuery{
+			RefID:         refID,
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(model),
+		}
+	}
+	levels := `{"type": "threshold", "expression": "A", "levels": [{"severity": "warning", "evaluator": {"type": "gt", "params": [80]}}, {"severity": "critical", "evaluator": {"type": "gt", "params": [90]}}]}`
+	resp := &backend.QueryDataResponse{Responses: backend.Responses{}}
+
+	t.Run("condition with severity levels", func(t *testing.T) {
+		c := models.Condition{Condition: "B", Data: []models.AlertQuery{threshold("B", levels)}}
+		res := queryDataResponseToExecutionResults(c, resp)
+		require.Equal(t, []string{"warning", "critical"}, res.Severities)
+	})
+
+	t.Run("severity levels of another expression", func(t *testing.T) {
+		c := models.Condition{Condition: "C", Data: []models.AlertQuery{threshold("B", levels), threshold("C", `{"type": "math", "expression": "$B"}`)}}
+		res := queryDataResponseToExecutionResults(c, resp)
+		require.Nil(t, res.Severities)
+	})
+}
+
 func TestEvaluateExecutionResultsNoData(t *testing.T) {
 	t.Run("no data for Ref ID will produce NoData result", func(t *testing.T) {
 		results := ExecutionResults{
@@ -31,6 +31,9 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	ShardOwnedRules                     prometheus.Gauge
+	ShardMembers                        prometheus.Gauge
+	ShardRebalances                     prometheus.Counter
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -183,5 +186,29 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		ShardOwnedRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_owned_rules",
+				Help:      "The number of rules that are assigned to this instance when rule evaluation is sharded across the HA cluster.",
+			},
+		),
+		ShardMembers: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_members",
+				Help:      "The number of instances that rule evaluation is sharded across.",
+			},
+		),
+		ShardRebalances: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_rebalances_total",
+				Help:      "The total number of times rules were reassigned because the members of the HA cluster changed.",
+			},
+		),
 	}
 }
@@ -140,6 +140,18 @@ func (aq *AlertQuery) PatchHysteresisExpression(loadedMetrics map[data.Fingerpri
 	return expr.SetLoadedDimensionsToHysteresisCommand(aq.modelProps, loadedMetrics)
 }
 
+// GetThresholdSeverities returns the names of the severity levels, in order, if the model describes a threshold
+// command expression with severity levels. Returns error if the Model is not a valid JSON
+func (aq *AlertQuery) GetThresholdSeverities() ([]string, error) {
+	if aq.modelProps == nil {
+		err := aq.setModelProps()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return expr.GetThresholdSeverities(aq.modelProps), nil
+}
+
 // setMaxDatapoints sets the model maxDataPoints if it's missing or invalid
 func (aq *AlertQuery) setMaxDatapoints() error {
 	if aq.modelProps == nil {
@@ -128,6 +128,10 @@ const (
 	// FolderTitleLabel is the label that will contain the title of an alert's folder/namespace.
 	FolderTitleLabel = GrafanaReservedLabelPrefix + "folder"
 
+	// SeverityLabel is the label that will contain the severity of an alert instance when the condition of its rule is a
+	// threshold with severity levels.
+	SeverityLabel = GrafanaReservedLabelPrefix + "severity"
+
 	// StateReasonAnnotation is the name of the annotation that explains the difference between evaluation state and alert state (i.e. changing state when NoData or Error).
 	StateReasonAnnotation = GrafanaReservedLabelPrefix + "state_reason"
 
@@ -163,6 +167,7 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	StateReasonKeepFiring    = "KeepFiring"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -263,7 +268,9 @@ type AlertRule struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For                  time.Duration
+	For time.Duration
+	// KeepFiringFor is how long an alert keeps firing after its condition is no longer met.
+	KeepFiringFor        time.Duration `xorm:"keep_firing_for"`
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
@@ -548,6 +555,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -628,7 +639,9 @@ type AlertRuleVersion struct {
 	RestoredFrom     int64
 	Version          int64
 
-	Created         time.Time
+	Created time.Time
+	// CreatedBy is the identifier of the user or service account that created the version, if known.
+	CreatedBy       string `xorm:"created_by"`
 	Title           string
 	Condition       string
 	Data            []AlertQuery
@@ -638,7 +651,9 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For                  time.Duration
+	For time.Duration
+	// KeepFiringFor is how long an alert keeps firing after its condition is no longer met.
+	KeepFiringFor        time.Duration `xorm:"keep_firing_for"`
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
@@ -707,6 +722,8 @@ type ListNamespaceAlertRulesQuery struct {
 type UpdateRule struct {
 	Existing *AlertRule
 	New      AlertRule
+	// RestoredFrom is the version of the rule that the update restores, or 0 if the update is not a restore.
+	RestoredFrom int64
 }
 
 // Condition contains backend expressions and queries and the RefID
@@ -759,6 +776,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -15,6 +15,10 @@ type AlertInstance struct {
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
 	ResultFingerprint string
+	// Severity is the severity of the state, it is not one of the Labels.
+	Severity string
+	// KeepFiringSince is the time at which the condition of the firing state was first not met.
+	KeepFiringSince *time.Time
 }
 
 type AlertInstanceKey struct {
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// ErrRuleGroupDependencyCycle is returned when rules of a group query the metrics recorded by each other in a cycle.
+var ErrRuleGroupDependencyCycle = errors.New("rules of the group depend on each other in a cycle")
+
+// QueriedMetrics returns the names of the metrics that the queries of the rule select.
+// Only the `expr` of queries that are valid PromQL is inspected, other queries are ignored.
+func (alertRule *AlertRule) QueriedMetrics() map[string]struct{} {
+	result := make(map[string]struct{})
+	for _, q := range alertRule.Data {
+		if isExpr, _ := q.IsExpression(); isExpr {
+			continue
+		}
+		// The model is decoded here instead of using GetQuery because it caches the result in the query,
+		// and the rule can be shared with other goroutines.
+		var model struct {
+			Expr string `json:"expr"`
+		}
+		if err := json.Unmarshal(q.Model, &model); err != nil || model.Expr == "" {
+			continue
+		}
+		e, err := parser.ParseExpr(model.Expr)
+		if err != nil {
+			continue
+		}
+		parser.Inspect(e, func(node parser.Node, _ []parser.Node) error {
+			vs, ok := node.(*parser.VectorSelector)
+			if !ok {
+				return nil
+			}
+			if vs.Name != "" {
+				result[vs.Name] = struct{}{}
+			}
+			for _, m := range vs.LabelMatchers {
+				if m.Name == labels.MetricName && m.Type == labels.MatchEqual && m.Value != "" {
+					result[m.Value] = struct{}{}
+				}
+			}
+			return nil
+		})
+	}
+	return result
+}
+
+// GroupEvaluationOrder returns the rules of a group in the order they should be evaluated so that every rule is
+// evaluated after the recording rules of the group whose metrics it queries. Rules that do not depend on each other
+// keep their relative order. Returns nil if no rule of the group depends on another one, and an error that wraps
+// ErrRuleGroupDependencyCycle if the dependencies cannot be ordered.
+func GroupEvaluationOrder(rules []*AlertRule) ([]*AlertRule, error) {
+	recorders := make(map[string][]int)
+	for idx, rule := range rules {
+		if rule.Type() == RuleTypeRecording && rule.Record.Metric != "" {
+			recorders[rule.Record.Metric] = append(recorders[rule.Record.Metric], idx)
+		}
+	}
+	if len(recorders) == 0 {
+		return nil, nil
+	}
+
+	// dependents[i] are the indices of the rules that query the metric recorded by the rule i.
+	dependents := make([][]int, len(rules))
+	inDegree := make([]int, len(rules))
+	hasDependencies := false
+	for idx, rule := range rules {
+		for metric := range rule.QueriedMetrics() {
+			for _, recorder := range recorders[metric] {
+				// A recording rule can query the previous values of the metric it records.
+				if recorder == idx {
+					continue
+				}
+				dependents[recorder] = append(dependents[recorder], idx)
+				inDegree[idx]++
+				hasDependencies = true
+			}
+		}
+	}
+	if !hasDependencies {
+		return nil, nil
+	}
+
+	result := make([]*AlertRule, 0, len(rules))
+	ready := make([]int, 0, len(rules))
+	for idx := range rules {
+		if inDegree[idx] == 0 {
+			ready = append(ready, idx)
+		}
+	}
+	for len(ready) > 0 {
+		// Take the rule that comes first in the group to keep the order stable.
+		slices.Sort(ready)
+		idx := ready[0]
+		ready = ready[1:]
+		result = append(result, rules[idx])
+		for _, dependent := range dependents[idx] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(result) < len(rules) {
+		cycle := make([]string, 0, len(rules)-len(result))
+		for idx, rule := range rules {
+			if inDegree[idx] > 0 {
+				cycle = append(cycle, fmt.Sprintf("%q", rule.Title))
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrRuleGroupDependencyCycle, strings.Join(cycle, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+func promQuery(t *testing.T, refID, promQL string) AlertQuery {
+	t.Helper()
+	model, err := json.Marshal(map[string]any{"refId": refID, "expr": promQL})
+	require.NoError(t, err)
+	return AlertQuery{RefID: refID, DatasourceUID: "prometheus", Model: model}
+}
+
+func TestAlertRule_QueriedMetrics(t *testing.T) {
+	testCases := []struct {
+		name     string
+		queries  []string
+		expected []string
+	}{
+		{
+			name:     "selector",
+			queries:  []string{`job:up:sum > 0`},
+			expected: []string{"job:up:sum"},
+		},
+		{
+			name:     "name matcher and functions",
+			queries:  []string{`sum by (job) (rate({__name__="http_requests_total", code="500"}[5m])) / on(job) job:requests:rate5m`},
+			expected: []string{"http_requests_total", "job:requests:rate5m"},
+		},
+		{
+			name:     "several queries",
+			queries:  []string{`a`, `b offset 5m`},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "regex name matcher is ignored",
+			queries:  []string{`{__name__=~"job:.*"}`},
+			expected: []string{},
+		},
+		{
+			name:     "invalid query is ignored",
+			queries:  []string{`count_over_time({job="x"} |= "error" [5m])`},
+			expected: []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := RuleGen.GenerateRef()
+			rule.Data = nil
+			for _, q := range tc.queries {
+				rule.Data = append(rule.Data, promQuery(t, "A", q))
+			}
+			rule.Data = append(rule.Data, AlertQuery{RefID: "B", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"expr": "not_a_metric"}`)})
+
+			result := rule.QueriedMetrics()
+			actual := make([]string, 0, len(result))
+			for metric := range result {
+				actual = append(actual, metric)
+			}
+			require.ElementsMatch(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGroupEvaluationOrder(t *testing.T) {
+	recording := func(title, metric, promQL string) *AlertRule {
+		rule := RuleGen.With(RuleMuts.WithTitle(title), RuleMuts.WithAllRecordingRules(), RuleMuts.WithMetric(metric)).GenerateRef()
+		rule.Data = []AlertQuery{promQuery(t, rule.Record.From, promQL)}
+		return rule
+	}
+	alerting := func(title, promQL string) *AlertRule {
+		rule := RuleGen.With(RuleMuts.WithTitle(title)).GenerateRef()
+		rule.Record = nil
+		rule.Data = []AlertQuery{promQuery(t, "A", promQL)}
+		return rule
+	}
+	titles := func(rules []*AlertRule) []string {
+		result := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			result = append(result, rule.Title)
+		}
+		return result
+	}
+
+	t.Run("returns nil if rules do not depend on each other", func(t *testing.T) {
+		order, err := GroupEvaluationOrder([]*AlertRule{
+			alerting("alert", "up == 0"),
+			recording("record", "job:up:sum", "sum by (job) (up)"),
+		})
+		require.NoError(t, err)
+		require.Nil(t, order)
+	})
+
+	t.Run("evaluates consumers after the recording rules", func(t *testing.T) {
+		order, err := GroupEvaluationOrder([]*AlertRule{
+			alerting("alert", "job:errors:ratio > 0.1"),
+			alerting("independent", "up == 0"),
+			recording("ratio", "job:errors:ratio", "job:errors:sum / job:requests:sum"),
+			recording("errors", "job:errors:sum", `sum by (job) (requests{code="500"})`),
+			recording("requests", "job:requests:sum", "sum by (job) (requests)"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"independent", "errors", "requests", "ratio", "alert"}, titles(order))
+	})
+
+	t.Run("ignores a recording rule that queries its own metric", func(t *testing.T) {
+		order, err := GroupEvaluationOrder([]*AlertRule{
+			alerting("alert", "job:up:max > 0"),
+			recording("record", "job:up:max", "max(job:up:max or up)"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"record", "alert"}, titles(order))
+	})
+
+	t.Run("fails if rules depend on each other in a cycle", func(t *testing.T) {
+		_, err := GroupEvaluationOrder([]*AlertRule{
+			recording("a", "metric_a", "metric_b"),
+			recording("b", "metric_b", "metric_a"),
+			alerting("independent", "up == 0"),
+		})
+		require.ErrorIs(t, err, ErrRuleGroupDependencyCycle)
+		require.ErrorContains(t, err, `"a", "b"`)
+		require.NotContains(t, err.Error(), "independent")
+	})
+}
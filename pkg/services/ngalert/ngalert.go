@@ -12,6 +12,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/expr"
@@ -136,6 +137,7 @@ type AlertNG struct {
 	ImageService        image.ImageService
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	historian           Historian
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 	Api                 *api.API
@@ -369,10 +371,11 @@ func (ng *AlertNG) init() error {
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	ApplyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.SQLStore, ng.httpClientProvider, ng.Metrics.GetHistorianMetrics(), ng.Metrics.GetRemoteWriterMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
 	if err != nil {
 		return err
 	}
+	ng.historian = history
 	cfg := state.ManagerCfg{
 		Metrics:                        ng.Metrics.GetStateMetrics(),
 		ExternalURL:                    appUrl,
@@ -389,12 +392,29 @@ func (ng *AlertNG) init() error {
 		Log:                            log.New("ngalert.state.manager"),
 		ResolvedRetention:              ng.Cfg.UnifiedAlerting.ResolvedAlertRetention,
 	}
+	if ng.Cfg.UnifiedAlerting.TemplateQueryTimeout > 0 {
+		cfg.TemplateQuerier = state.NewTemplateQuerier(evalFactory, func(orgID int64, datasourceUIDs []string) identity.Requester {
+			return schedule.TemplateQueryUserFor(orgID, datasourceUIDs)
+		}, ng.Cfg.UnifiedAlerting.TemplateQueryTimeout, ng.Cfg.UnifiedAlerting.TemplateQueryMaxSeries)
+	}
 	logger := log.New("ngalert.state.manager.persist")
 	statePersister := state.NewSyncStatePersisiter(logger, cfg)
 	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
 		ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
 		statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
 	}
+	if ng.Cfg.UnifiedAlerting.HAShardRuleEvaluation {
+		membership := ng.MultiOrgAlertmanager.ClusterMembership()
+		switch {
+		case membership == nil:
+			ng.Log.Warn("Rule evaluation is not sharded because high availability is not configured")
+		case ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic):
+			// The periodic state persister replaces all saved states with the states of this instance.
+			ng.Log.Warn("Rule evaluation is not sharded because it is not supported when the state is saved periodically")
+		default:
+			schedCfg.ClusterMembership = membership
+		}
+	}
 	stateManager := state.NewManager(cfg, statePersister)
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
 
@@ -511,6 +531,11 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 			return ng.stateManager.Run(subCtx)
 		})
 	}
+	if r, ok := ng.historian.(historian.Runner); ok {
+		children.Go(func() error {
+			return r.Run(subCtx)
+		})
+	}
 	return children.Wait()
 }
 
@@ -534,7 +559,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, store db.DB, httpClientProvider httpclient.Provider, met *metrics.Historian, wm *metrics.RemoteWriter, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -549,7 +574,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l, tracer, ac)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, store, httpClientProvider, met, wm, l, tracer, ac)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -558,7 +583,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l, tracer, ac)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, store, httpClientProvider, met, wm, l, tracer, ac)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -589,6 +614,28 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		}
 		return backend, nil
 	}
+	if backend == historian.BackendTypePrometheus {
+		pcfg, err := historian.NewPrometheusConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote prometheus configuration: %w", err)
+		}
+		promBackendLogger := log.New("ngalert.state.historian", "backend", "prometheus")
+		w, err := writer.NewPrometheusWriter(setting.RecordingRuleSettings{
+			URL:               cfg.PrometheusWriteURL,
+			BasicAuthUsername: cfg.PrometheusBasicAuthUsername,
+			BasicAuthPassword: cfg.PrometheusBasicAuthPassword,
+			Timeout:           cfg.PrometheusTimeout,
+		}, httpClientProvider, tracer, promBackendLogger, wm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create prometheus remote writer: %w", err)
+		}
+		req := historian.NewRequester()
+		return historian.NewPrometheusBackend(promBackendLogger, pcfg, w, req, met, tracer, rs, ac), nil
+	}
+	if backend == historian.BackendTypeSQL {
+		sqlBackendLogger := log.New("ngalert.state.historian", "backend", "sql")
+		return historian.NewSQLBackend(sqlBackendLogger, store, rs, met, ac, cfg.SQLRetention), nil
+	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
@@ -0,0 +1,260 @@
+// Package channels contains the integrations of Grafana Managed Alerts that are not part of the module
+// github.com/grafana/alerting. They are built together with the integrations of the module.
+package channels
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	TypeMQTT   = "mqtt"
+	TypeNATS   = "nats"
+	TypeSyslog = "syslog"
+)
+
+const (
+	messageFormatJSON = "json"
+	messageFormatText = "text"
+)
+
+type notificationChannel interface {
+	notify.Notifier
+	notify.ResolvedSender
+}
+
+type factory func(settings json.RawMessage, decryptFn receivers.DecryptFunc, meta receivers.Metadata, tmpl *templates.Template, logger alertingLogging.Logger, orgID int64) (notificationChannel, error)
+
+var factories = map[string]factory{
+	TypeMQTT:   newMQTTNotifier,
+	TypeNATS:   newNATSNotifier,
+	TypeSyslog: newSyslogNotifier,
+}
+
+// IsSupported returns true if the integration of the given type is implemented in this package.
+func IsSupported(integrationType string) bool {
+	_, ok := factories[strings.ToLower(integrationType)]
+	return ok
+}
+
+// SplitReceiver returns a copy of the receiver without the integrations that are implemented in this package, and
+// these integrations.
+func SplitReceiver(receiver *alertingNotify.APIReceiver) (*alertingNotify.APIReceiver, []*alertingNotify.GrafanaIntegrationConfig) {
+	var supported, other []*alertingNotify.GrafanaIntegrationConfig
+	for _, integration := range receiver.Integrations {
+		if IsSupported(integration.Type) {
+			supported = append(supported, integration)
+		} else {
+			other = append(other, integration)
+		}
+	}
+	if len(supported) == 0 {
+		return receiver, nil
+	}
+	result := *receiver
+	result.Integrations = other
+	return &result, supported
+}
+
+// BuildIntegrations parses, decrypts and validates the configurations of the integrations and creates the
+// integrations. All integrations must be of a type that is implemented in this package.
+func BuildIntegrations(
+	ctx context.Context,
+	integrations []*alertingNotify.GrafanaIntegrationConfig,
+	tmpl *templates.Template,
+	decrypt alertingNotify.GetDecryptedValueFn,
+	logger alertingLogging.LoggerFactory,
+	orgID int64,
+) ([]*alertingNotify.Integration, error) {
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	indexes := make(map[string]int, len(factories))
+	for _, integration := range integrations {
+		meta := receivers.Metadata{
+			UID:                   integration.UID,
+			Name:                  integration.Name,
+			Type:                  integration.Type,
+			DisableResolveMessage: integration.DisableResolveMessage,
+		}
+		n, err := newNotifier(ctx, integration, decrypt, meta, tmpl, logger("ngalert.notifier."+meta.Type, "notifierUID", meta.UID), orgID)
+		if err != nil {
+			return nil, err
+		}
+		typ := strings.ToLower(integration.Type)
+		result = append(result, alertingNotify.NewIntegration(n, n, meta.Type, indexes[typ], meta.Name))
+		indexes[typ]++
+	}
+	return result, nil
+}
+
+// Validate parses, decrypts and validates the configuration of the integration. The integration must be of a type
+// that is implemented in this package.
+func Validate(ctx context.Context, integration *alertingNotify.GrafanaIntegrationConfig, decrypt alertingNotify.GetDecryptedValueFn) error {
+	meta := receivers.Metadata{UID: integration.UID, Name: integration.Name, Type: integration.Type}
+	_, err := newNotifier(ctx, integration, decrypt, meta, nil, nil, 0)
+	return err
+}
+
+func newNotifier(
+	ctx context.Context,
+	integration *alertingNotify.GrafanaIntegrationConfig,
+	decrypt alertingNotify.GetDecryptedValueFn,
+	meta receivers.Metadata,
+	tmpl *templates.Template,
+	logger alertingLogging.Logger,
+	orgID int64,
+) (notificationChannel, error) {
+	f, ok := factories[strings.ToLower(integration.Type)]
+	if !ok {
+		return nil, alertingNotify.IntegrationValidationError{
+			Integration: integration,
+			Err:         fmt.Errorf("notifier %s is not supported", integration.Type),
+		}
+	}
+	secureSettings, err := decodeSecrets(integration.SecureSettings)
+	if err != nil {
+		// An error means that the secure settings are not base-64 encoded.
+		secureSettings = make(map[string][]byte, len(integration.SecureSettings))
+		for k, v := range integration.SecureSettings {
+			secureSettings[k] = []byte(v)
+		}
+	}
+	decryptFn := func(key string, fallback string) string {
+		return decrypt(ctx, secureSettings, key, fallback)
+	}
+	n, err := f(integration.Settings, decryptFn, meta, tmpl, logger, orgID)
+	if err != nil {
+		return nil, alertingNotify.IntegrationValidationError{Integration: integration, Err: err}
+	}
+	return n, nil
+}
+
+func decodeSecrets(secrets map[string]string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(secrets))
+	for k, v := range secrets {
+		d, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secure settings key %s: %w", k, err)
+		}
+		result[k] = d
+	}
+	return result, nil
+}
+
+// TLSSettings are the settings of integrations that connect with TLS.
+type TLSSettings struct {
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+	CACertificate      string `json:"tls_ca_certificate,omitempty" yaml:"tls_ca_certificate,omitempty"`
+	ClientCertificate  string `json:"tls_client_certificate,omitempty" yaml:"tls_client_certificate,omitempty"`
+	ClientKey          string `json:"tls_client_key,omitempty" yaml:"tls_client_key,omitempty"`
+}
+
+func (s *TLSSettings) decrypt(decryptFn receivers.DecryptFunc) {
+	s.ClientKey = decryptFn("tls_client_key", s.ClientKey)
+}
+
+// tlsConfig returns the TLS configuration. It returns an error if the certificates cannot be parsed.
+func (s TLSSettings) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		// #nosec G402 -- the user explicitly disables the verification
+		InsecureSkipVerify: s.InsecureSkipVerify,
+	}
+	if s.CACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(s.CACertificate)) {
+			return nil, errors.New("failed to parse the CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	if s.ClientCertificate != "" || s.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(s.ClientCertificate), []byte(s.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the client certificate and key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// message is the JSON payload of the integrations that publish messages. It is the same payload as the payload of
+// the webhook integration.
+type message struct {
+	*templates.ExtendedData
+
+	// The protocol version.
+	Version  string `json:"version"`
+	GroupKey string `json:"groupKey"`
+	OrgID    int64  `json:"orgId"`
+	Title    string `json:"title"`
+	State    string `json:"state"`
+	Message  string `json:"message"`
+}
+
+// buildPayload templates the payload of the notification in the given format, and the extra templates.
+func buildPayload(ctx context.Context, tmpl *templates.Template, logger alertingLogging.Logger, orgID int64, format, msg string, as []*types.Alert, extra ...string) ([]byte, []string, error) {
+	var tmplErr error
+	tmplFn, data := templates.TmplText(ctx, tmpl, as, logger, &tmplErr)
+
+	result := make([]string, 0, len(extra))
+	for _, e := range extra {
+		result = append(result, tmplFn(e))
+	}
+	if format == messageFormatText {
+		payload := tmplFn(msg)
+		if tmplErr != nil {
+			return nil, nil, tmplErr
+		}
+		return []byte(payload), result, nil
+	}
+
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := &message{
+		Version:      "1",
+		ExtendedData: data,
+		GroupKey:     groupKey.String(),
+		OrgID:        orgID,
+		Title:        tmplFn(templates.DefaultMessageTitleEmbed),
+		Message:      tmplFn(msg),
+	}
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		m.State = string(receivers.AlertStateAlerting)
+	} else {
+		m.State = string(receivers.AlertStateOK)
+	}
+	if tmplErr != nil {
+		return nil, nil, tmplErr
+	}
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload, result, nil
+}
+
+func validateMessageFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return messageFormatJSON, nil
+	case messageFormatJSON, messageFormatText:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported message format '%s', must be %s or %s", format, messageFormatJSON, messageFormatText)
+	}
+}
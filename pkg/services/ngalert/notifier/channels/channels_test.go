@@ -0,0 +1,111 @@
+package channels
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitReceiver(t *testing.T) {
+	receiver := &alertingNotify.APIReceiver{
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+			Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+				{UID: "1", Type: "email"},
+				{UID: "2", Type: "MQTT"},
+				{UID: "3", Type: "syslog"},
+			},
+		},
+	}
+	rest, supported := SplitReceiver(receiver)
+	require.Len(t, rest.Integrations, 1)
+	require.Equal(t, "1", rest.Integrations[0].UID)
+	require.Len(t, supported, 2)
+	require.Len(t, receiver.Integrations, 3, "the receiver should not be modified")
+
+	again, supported := SplitReceiver(rest)
+	require.Same(t, rest, again)
+	require.Empty(t, supported)
+}
+
+func TestBuildIntegrations(t *testing.T) {
+	broker := newTestMQTTBroker(t, "user", "secret", nil)
+	integrations := []*alertingNotify.GrafanaIntegrationConfig{
+		{
+			UID:            "mqtt-uid",
+			Name:           "mqtt",
+			Type:           "mqtt",
+			Settings:       []byte(`{"broker_url": "` + broker.URL() + `", "topic": "alerts", "username": "user"}`),
+			SecureSettings: map[string]string{"password": base64.StdEncoding.EncodeToString([]byte("secret"))},
+		},
+		{
+			UID:                   "syslog-uid",
+			Name:                  "syslog",
+			Type:                  "syslog",
+			DisableResolveMessage: true,
+			Settings:              []byte(`{"address": "localhost:514"}`),
+		},
+	}
+
+	result, err := BuildIntegrations(context.Background(), integrations, testTemplate(t), alertingNotify.NoopDecrypt, newFakeLoggerFactory(), 1)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Equal(t, "mqtt", result[0].Name())
+	require.Equal(t, "syslog", result[1].Name())
+	require.False(t, result[1].SendResolved())
+
+	_, err = result[0].Notify(testContext(), testAlerts()...)
+	require.NoError(t, err, "the password should be decrypted")
+	// Messages with QoS 0 are not acknowledged.
+	require.Eventually(t, func() bool { return len(broker.Messages()) == 1 }, time.Second, 10*time.Millisecond)
+
+	t.Run("returns a validation error if the settings are invalid", func(t *testing.T) {
+		invalid := &alertingNotify.GrafanaIntegrationConfig{UID: "invalid", Type: "nats", Settings: []byte(`{"url": "nats://localhost"}`)}
+		_, err := BuildIntegrations(context.Background(), []*alertingNotify.GrafanaIntegrationConfig{invalid}, testTemplate(t), alertingNotify.NoopDecrypt, newFakeLoggerFactory(), 1)
+		var validationErr alertingNotify.IntegrationValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.ErrorContains(t, err, "could not find subject in settings")
+
+		require.ErrorAs(t, Validate(context.Background(), invalid, alertingNotify.NoopDecrypt), &validationErr)
+	})
+}
+
+func testTemplate(t *testing.T) *templates.Template {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	return tmpl
+}
+
+func newFakeLoggerFactory() alertingLogging.LoggerFactory {
+	return func(string, ...interface{}) alertingLogging.Logger {
+		return &alertingLogging.FakeLogger{}
+	}
+}
+
+func testContext() context.Context {
+	ctx := notify.WithGroupKey(context.Background(), "group-key")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "test"})
+	return notify.WithReceiverName(ctx, "receiver")
+}
+
+func testAlerts() []*types.Alert {
+	return []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "test", "line": "1"},
+				Annotations: model.LabelSet{"summary": "line 1 is down"},
+			},
+		},
+	}
+}
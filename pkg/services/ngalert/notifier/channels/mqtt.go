@@ -0,0 +1,138 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/types"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+
+	"github.com/grafana/grafana/pkg/infra/mqtt"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type MQTTConfig struct {
+	BrokerURL     string                   `json:"broker_url,omitempty" yaml:"broker_url,omitempty"`
+	ClientID      string                   `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	Topic         string                   `json:"topic,omitempty" yaml:"topic,omitempty"`
+	MessageFormat string                   `json:"message_format,omitempty" yaml:"message_format,omitempty"`
+	Message       string                   `json:"message,omitempty" yaml:"message,omitempty"`
+	QoS           receivers.OptionalNumber `json:"qos,omitempty" yaml:"qos,omitempty"`
+	Retain        bool                     `json:"retain,omitempty" yaml:"retain,omitempty"`
+	Username      string                   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password      string                   `json:"password,omitempty" yaml:"password,omitempty"`
+	TLSSettings
+}
+
+func NewMQTTConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (MQTTConfig, error) {
+	var settings MQTTConfig
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return MQTTConfig{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.BrokerURL == "" {
+		return MQTTConfig{}, errors.New("could not find broker URL in settings")
+	}
+	if err := mqtt.ValidateBrokerURL(settings.BrokerURL); err != nil {
+		return MQTTConfig{}, err
+	}
+	if settings.Topic == "" {
+		return MQTTConfig{}, errors.New("could not find topic in settings")
+	}
+	format, err := validateMessageFormat(settings.MessageFormat)
+	if err != nil {
+		return MQTTConfig{}, err
+	}
+	settings.MessageFormat = format
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	qos, err := settings.QoS.Int64()
+	if err != nil || qos < 0 || qos > int64(mqtt.ExactlyOnce) {
+		return MQTTConfig{}, fmt.Errorf("invalid QoS '%s', must be 0, 1 or 2", settings.QoS)
+	}
+	settings.Password = decryptFn("password", settings.Password)
+	settings.decrypt(decryptFn)
+	if _, err := settings.tlsConfig(); err != nil {
+		return MQTTConfig{}, err
+	}
+	return settings, nil
+}
+
+// MQTTNotifier publishes alert notifications to a topic of an MQTT broker.
+type MQTTNotifier struct {
+	*receivers.Base
+	log      alertingLogging.Logger
+	tmpl     *templates.Template
+	orgID    int64
+	settings MQTTConfig
+}
+
+func newMQTTNotifier(jsonData json.RawMessage, decryptFn receivers.DecryptFunc, meta receivers.Metadata, tmpl *templates.Template, logger alertingLogging.Logger, orgID int64) (notificationChannel, error) {
+	cfg, err := NewMQTTConfig(jsonData, decryptFn)
+	if err != nil {
+		return nil, err
+	}
+	return &MQTTNotifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		tmpl:     tmpl,
+		orgID:    orgID,
+		settings: cfg,
+	}, nil
+}
+
+// Notify publishes the notification to the templated topic.
+func (n *MQTTNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	payload, extra, err := buildPayload(ctx, n.tmpl, n.log, n.orgID, n.settings.MessageFormat, n.settings.Message, as, n.settings.Topic)
+	if err != nil {
+		return false, fmt.Errorf("failed to template MQTT message: %w", err)
+	}
+	topic := extra[0]
+	if err := mqtt.ValidateTopic(topic); err != nil {
+		return false, fmt.Errorf("invalid templated topic '%s': %w", topic, err)
+	}
+
+	tlsConfig, err := n.settings.tlsConfig()
+	if err != nil {
+		return false, err
+	}
+	clientID := n.settings.ClientID
+	if clientID == "" {
+		// The broker disconnects a client when another one connects with the same ID, so notifications that are sent
+		// at the same time use different IDs.
+		clientID = "grafana-" + util.GenerateShortUID()
+	}
+	client, err := mqtt.Connect(ctx, mqtt.Options{
+		BrokerURL: n.settings.BrokerURL,
+		ClientID:  clientID,
+		Username:  n.settings.Username,
+		Password:  n.settings.Password,
+		TLSConfig: tlsConfig,
+	})
+	if err != nil {
+		n.log.Error("Failed to connect to MQTT broker", "error", err)
+		return true, err
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			n.log.Debug("Failed to disconnect from MQTT broker", "error", err)
+		}
+	}()
+
+	qos, _ := n.settings.QoS.Int64()
+	if err := client.Publish(ctx, topic, mqtt.QoS(qos), n.settings.Retain, payload); err != nil {
+		n.log.Error("Failed to publish MQTT message", "error", err, "topic", topic)
+		return true, err
+	}
+	n.log.Debug("Message successfully published", "topic", topic)
+	return true, nil
+}
+
+func (n *MQTTNotifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
@@ -0,0 +1,216 @@
+package channels
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/mqtt"
+	"github.com/grafana/grafana/pkg/infra/mqtt/mqtttest"
+)
+
+func TestNewMQTTConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings string
+		secrets  map[string][]byte
+		expected MQTTConfig
+		expErr   string
+	}{
+		{
+			name:     "minimal settings",
+			settings: `{"broker_url": "tcp://localhost:1883", "topic": "alerts"}`,
+			expected: MQTTConfig{
+				BrokerURL:     "tcp://localhost:1883",
+				Topic:         "alerts",
+				MessageFormat: messageFormatJSON,
+				Message:       templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "all settings with secrets",
+			settings: `{"broker_url": "ssl://localhost", "topic": "alerts/{{ .CommonLabels.line }}", "client_id": "edge", "qos": "2", "retain": true, "message_format": "text", "message": "msg", "username": "user", "insecure_skip_verify": true}`,
+			secrets:  map[string][]byte{"password": []byte("secret")},
+			expected: MQTTConfig{
+				BrokerURL:     "ssl://localhost",
+				Topic:         "alerts/{{ .CommonLabels.line }}",
+				ClientID:      "edge",
+				QoS:           "2",
+				Retain:        true,
+				MessageFormat: messageFormatText,
+				Message:       "msg",
+				Username:      "user",
+				Password:      "secret",
+				TLSSettings:   TLSSettings{InsecureSkipVerify: true},
+			},
+		},
+		{
+			name:     "QoS as number",
+			settings: `{"broker_url": "tcp://localhost", "topic": "alerts", "qos": 1}`,
+			expected: MQTTConfig{
+				BrokerURL:     "tcp://localhost",
+				Topic:         "alerts",
+				QoS:           "1",
+				MessageFormat: messageFormatJSON,
+				Message:       templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "missing broker URL",
+			settings: `{"topic": "alerts"}`,
+			expErr:   "could not find broker URL in settings",
+		},
+		{
+			name:     "unsupported scheme",
+			settings: `{"broker_url": "ws://localhost", "topic": "alerts"}`,
+			expErr:   "unsupported broker URL scheme 'ws'",
+		},
+		{
+			name:     "missing topic",
+			settings: `{"broker_url": "tcp://localhost"}`,
+			expErr:   "could not find topic in settings",
+		},
+		{
+			name:     "invalid QoS",
+			settings: `{"broker_url": "tcp://localhost", "topic": "alerts", "qos": 3}`,
+			expErr:   "invalid QoS '3'",
+		},
+		{
+			name:     "invalid message format",
+			settings: `{"broker_url": "tcp://localhost", "topic": "alerts", "message_format": "xml"}`,
+			expErr:   "unsupported message format 'xml'",
+		},
+		{
+			name:     "invalid CA certificate",
+			settings: `{"broker_url": "ssl://localhost", "topic": "alerts", "tls_ca_certificate": "invalid"}`,
+			expErr:   "failed to parse the CA certificate",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewMQTTConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secrets))
+			if c.expErr != "" {
+				require.ErrorContains(t, err, c.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestMQTTNotifier(t *testing.T) {
+	tmpl := testTemplate(t)
+
+	newNotifier := func(t *testing.T, settings string) *MQTTNotifier {
+		n, err := newMQTTNotifier(json.RawMessage(settings), receiversTesting.DecryptForTesting(nil), receivers.Metadata{Type: TypeMQTT}, tmpl, &alertingLogging.FakeLogger{}, 1)
+		require.NoError(t, err)
+		return n.(*MQTTNotifier)
+	}
+
+	t.Run("publishes the JSON payload to the templated topic", func(t *testing.T) {
+		broker := newTestMQTTBroker(t, "", "", nil)
+		n := newNotifier(t, `{"broker_url": "`+broker.URL()+`", "topic": "plant/line-{{ .CommonLabels.line }}", "qos": "1", "retain": true}`)
+
+		ok, err := n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		messages := broker.Messages()
+		require.Len(t, messages, 1)
+		require.Equal(t, "plant/line-1", messages[0].Topic)
+		require.Equal(t, mqtt.AtLeastOnce, messages[0].QoS)
+		require.True(t, messages[0].Retain)
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(messages[0].Payload, &payload))
+		require.Equal(t, "alerting", payload["state"])
+		require.Equal(t, "group-key", payload["groupKey"])
+		require.Equal(t, float64(1), payload["orgId"])
+		require.Equal(t, "[FIRING:1] test (1)", payload["title"])
+		require.Len(t, payload["alerts"], 1)
+	})
+
+	t.Run("publishes the templated message in text format", func(t *testing.T) {
+		broker := newTestMQTTBroker(t, "", "", nil)
+		n := newNotifier(t, `{"broker_url": "`+broker.URL()+`", "topic": "alerts", "message_format": "text", "message": "{{ .Status }}: {{ .CommonAnnotations.summary }}", "qos": "2"}`)
+
+		_, err := n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+		require.Equal(t, []mqtttest.Message{{Topic: "alerts", QoS: mqtt.ExactlyOnce, Payload: []byte("firing: line 1 is down")}}, broker.Messages())
+	})
+
+	t.Run("fails if the templated topic is invalid", func(t *testing.T) {
+		broker := newTestMQTTBroker(t, "", "", nil)
+		n := newNotifier(t, `{"broker_url": "`+broker.URL()+`", "topic": "{{ .CommonLabels.missing }}"}`)
+
+		ok, err := n.Notify(testContext(), testAlerts()...)
+		require.ErrorContains(t, err, "invalid templated topic")
+		require.False(t, ok)
+		require.Empty(t, broker.Messages())
+	})
+
+	t.Run("connects with TLS and the configured CA", func(t *testing.T) {
+		srv := httptest.NewTLSServer(nil)
+		srv.Close()
+		broker := newTestMQTTBroker(t, "", "", &tls.Config{Certificates: srv.TLS.Certificates})
+		ca, err := json.Marshal(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})))
+		require.NoError(t, err)
+
+		n := newNotifier(t, `{"broker_url": "`+broker.URL()+`", "topic": "alerts"}`)
+		_, err = n.Notify(testContext(), testAlerts()...)
+		require.ErrorContains(t, err, "certificate signed by unknown authority")
+
+		n = newNotifier(t, `{"broker_url": "`+broker.URL()+`", "topic": "alerts", "tls_ca_certificate": `+string(ca)+`}`)
+		_, err = n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+		// Messages with QoS 0 are not acknowledged.
+		require.Eventually(t, func() bool { return len(broker.Messages()) == 1 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("connects with a unique client ID unless it is configured", func(t *testing.T) {
+		broker := newTestMQTTBroker(t, "", "", nil)
+		n := newNotifier(t, `{"broker_url": "`+broker.URL()+`", "topic": "alerts", "qos": "1"}`)
+		for i := 0; i < 2; i++ {
+			_, err := n.Notify(testContext(), testAlerts()...)
+			require.NoError(t, err)
+		}
+		n = newNotifier(t, `{"broker_url": "`+broker.URL()+`", "topic": "alerts", "qos": "1", "client_id": "edge"}`)
+		_, err := n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+
+		ids := broker.ClientIDs()
+		require.Len(t, ids, 3)
+		require.True(t, strings.HasPrefix(ids[0], "grafana-"))
+		require.True(t, strings.HasPrefix(ids[1], "grafana-"))
+		require.NotEqual(t, ids[0], ids[1])
+		require.Equal(t, "edge", ids[2])
+	})
+
+	t.Run("fails if the broker refuses the connection", func(t *testing.T) {
+		broker := newTestMQTTBroker(t, "user", "secret", nil)
+		n := newNotifier(t, `{"broker_url": "`+broker.URL()+`", "topic": "alerts", "username": "user", "password": "wrong"}`)
+
+		ok, err := n.Notify(testContext(), testAlerts()...)
+		require.ErrorContains(t, err, "bad user name or password")
+		require.True(t, ok)
+	})
+}
+
+func newTestMQTTBroker(t *testing.T, username, password string, tlsConfig *tls.Config) *mqtttest.Broker {
+	broker, err := mqtttest.NewBroker(username, password, tlsConfig)
+	require.NoError(t, err)
+	t.Cleanup(broker.Close)
+	return broker
+}
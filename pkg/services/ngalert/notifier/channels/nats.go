@@ -0,0 +1,211 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/alertmanager/types"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const natsTimeout = 10 * time.Second
+
+type NATSConfig struct {
+	URL           string `json:"url,omitempty" yaml:"url,omitempty"`
+	Subject       string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	JetStream     bool   `json:"jetstream,omitempty" yaml:"jetstream,omitempty"`
+	MessageFormat string `json:"message_format,omitempty" yaml:"message_format,omitempty"`
+	Message       string `json:"message,omitempty" yaml:"message,omitempty"`
+	Username      string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password      string `json:"password,omitempty" yaml:"password,omitempty"`
+	Token         string `json:"token,omitempty" yaml:"token,omitempty"`
+	TLSSettings
+}
+
+func NewNATSConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (NATSConfig, error) {
+	var settings NATSConfig
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return NATSConfig{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.URL == "" {
+		return NATSConfig{}, errors.New("could not find server URL in settings")
+	}
+	if err := validateNATSURL(settings.URL); err != nil {
+		return NATSConfig{}, err
+	}
+	if settings.Subject == "" {
+		return NATSConfig{}, errors.New("could not find subject in settings")
+	}
+	format, err := validateMessageFormat(settings.MessageFormat)
+	if err != nil {
+		return NATSConfig{}, err
+	}
+	settings.MessageFormat = format
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	settings.Password = decryptFn("password", settings.Password)
+	settings.Token = decryptFn("token", settings.Token)
+	settings.decrypt(decryptFn)
+	if _, err := settings.tlsConfig(); err != nil {
+		return NATSConfig{}, err
+	}
+	return settings, nil
+}
+
+// NATSNotifier publishes alert notifications to a subject of a NATS server. If JetStream is enabled, it waits for
+// the acknowledgement of the stream that the subject belongs to.
+type NATSNotifier struct {
+	*receivers.Base
+	log      alertingLogging.Logger
+	tmpl     *templates.Template
+	orgID    int64
+	settings NATSConfig
+}
+
+func newNATSNotifier(jsonData json.RawMessage, decryptFn receivers.DecryptFunc, meta receivers.Metadata, tmpl *templates.Template, logger alertingLogging.Logger, orgID int64) (notificationChannel, error) {
+	cfg, err := NewNATSConfig(jsonData, decryptFn)
+	if err != nil {
+		return nil, err
+	}
+	return &NATSNotifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		tmpl:     tmpl,
+		orgID:    orgID,
+		settings: cfg,
+	}, nil
+}
+
+// Notify publishes the notification to the templated subject.
+func (n *NATSNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	payload, extra, err := buildPayload(ctx, n.tmpl, n.log, n.orgID, n.settings.MessageFormat, n.settings.Message, as, n.settings.Subject)
+	if err != nil {
+		return false, fmt.Errorf("failed to template NATS message: %w", err)
+	}
+	subject := extra[0]
+	if err := validateNATSSubject(subject); err != nil {
+		return false, fmt.Errorf("invalid templated subject '%s': %w", subject, err)
+	}
+
+	conn, err := n.connect(ctx)
+	if err != nil {
+		n.log.Error("Failed to connect to NATS server", "error", err)
+		return true, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, natsTimeout)
+	defer cancel()
+	if n.settings.JetStream {
+		err = publishJetStream(ctx, conn, subject, payload)
+	} else {
+		err = publish(ctx, conn, subject, payload)
+	}
+	if err != nil {
+		n.log.Error("Failed to publish NATS message", "error", err, "subject", subject)
+		return true, err
+	}
+	n.log.Debug("Message successfully published", "subject", subject)
+	return true, nil
+}
+
+func (n *NATSNotifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+func (n *NATSNotifier) connect(ctx context.Context) (*nats.Conn, error) {
+	tlsConfig, err := n.settings.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	timeout := natsTimeout
+	if d, ok := ctx.Deadline(); ok && time.Until(d) < timeout {
+		timeout = time.Until(d)
+	}
+	opts := []nats.Option{
+		nats.Name("grafana"),
+		nats.Timeout(timeout),
+		nats.NoReconnect(),
+		// The TLS configuration is used by tls URLs and by servers that require TLS.
+		func(o *nats.Options) error {
+			o.TLSConfig = tlsConfig
+			return nil
+		},
+	}
+	if n.settings.Username != "" || n.settings.Password != "" {
+		opts = append(opts, nats.UserInfo(n.settings.Username, n.settings.Password))
+	}
+	if n.settings.Token != "" {
+		opts = append(opts, nats.Token(n.settings.Token))
+	}
+	return nats.Connect(n.settings.URL, opts...)
+}
+
+// publish publishes the payload and waits until the server has processed it.
+func publish(ctx context.Context, conn *nats.Conn, subject string, payload []byte) error {
+	if err := conn.Publish(subject, payload); err != nil {
+		return err
+	}
+	if err := conn.FlushWithContext(ctx); err != nil {
+		return err
+	}
+	// Errors of the published message, such as permission violations, are reported asynchronously.
+	return conn.LastError()
+}
+
+// publishJetStream publishes the payload and waits for the acknowledgement of the stream.
+func publishJetStream(ctx context.Context, conn *nats.Conn, subject string, payload []byte) error {
+	js, err := conn.JetStream()
+	if err != nil {
+		return err
+	}
+	_, err = js.Publish(subject, payload, nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoStreamResponse) {
+		return fmt.Errorf("no stream is configured for subject %s", subject)
+	}
+	return err
+}
+
+// validateNATSURL returns an error if the URL is not an address of a server that the notifier can connect to.
+func validateNATSURL(serverURL string) error {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return fmt.Errorf("invalid server URL: %w", err)
+	}
+	if u.Hostname() == "" {
+		return errors.New("server URL must contain a host")
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return fmt.Errorf("unsupported server URL scheme '%s', must be nats or tls", u.Scheme)
+	}
+	return nil
+}
+
+// validateNATSSubject returns an error if the subject is not a valid subject to publish to.
+func validateNATSSubject(subject string) error {
+	if subject == "" {
+		return errors.New("subject must not be empty")
+	}
+	if strings.ContainsAny(subject, " \t\r\n") {
+		return errors.New("subject must not contain whitespace")
+	}
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" {
+			return errors.New("subject must not contain empty tokens")
+		}
+		if token == "*" || token == ">" {
+			return errors.New("subject must not contain wildcards")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package channels
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNATSConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings string
+		secrets  map[string][]byte
+		expected NATSConfig
+		expErr   string
+	}{
+		{
+			name:     "minimal settings",
+			settings: `{"url": "nats://localhost", "subject": "alerts"}`,
+			expected: NATSConfig{
+				URL:           "nats://localhost",
+				Subject:       "alerts",
+				MessageFormat: messageFormatJSON,
+				Message:       templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "all settings with secrets",
+			settings: `{"url": "tls://localhost:4443", "subject": "alerts.{{ .CommonLabels.line }}", "jetstream": true, "message_format": "text", "message": "msg", "username": "user"}`,
+			secrets:  map[string][]byte{"password": []byte("secret"), "token": []byte("token")},
+			expected: NATSConfig{
+				URL:           "tls://localhost:4443",
+				Subject:       "alerts.{{ .CommonLabels.line }}",
+				JetStream:     true,
+				MessageFormat: messageFormatText,
+				Message:       "msg",
+				Username:      "user",
+				Password:      "secret",
+				Token:         "token",
+			},
+		},
+		{
+			name:     "missing URL",
+			settings: `{"subject": "alerts"}`,
+			expErr:   "could not find server URL in settings",
+		},
+		{
+			name:     "unsupported scheme",
+			settings: `{"url": "http://localhost", "subject": "alerts"}`,
+			expErr:   "unsupported server URL scheme 'http'",
+		},
+		{
+			name:     "missing subject",
+			settings: `{"url": "nats://localhost"}`,
+			expErr:   "could not find subject in settings",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewNATSConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secrets))
+			if c.expErr != "" {
+				require.ErrorContains(t, err, c.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestNATSNotifier(t *testing.T) {
+	tmpl := testTemplate(t)
+
+	newNotifier := func(t *testing.T, settings string) *NATSNotifier {
+		n, err := newNATSNotifier(json.RawMessage(settings), receiversTesting.DecryptForTesting(nil), receivers.Metadata{Type: TypeNATS}, tmpl, &alertingLogging.FakeLogger{}, 1)
+		require.NoError(t, err)
+		return n.(*NATSNotifier)
+	}
+
+	t.Run("publishes the payload to the templated subject", func(t *testing.T) {
+		srv := newTestNATSServer(t, "")
+		n := newNotifier(t, `{"url": "nats://`+srv.addr()+`", "subject": "plant.line-{{ .CommonLabels.line }}", "message_format": "text", "message": "{{ .CommonAnnotations.summary }}"}`)
+
+		ok, err := n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, []testNATSMessage{{Subject: "plant.line-1", Payload: "line 1 is down"}}, srv.messages())
+	})
+
+	t.Run("authenticates with the token", func(t *testing.T) {
+		srv := newTestNATSServer(t, "secret")
+		n := newNotifier(t, `{"url": "nats://`+srv.addr()+`", "subject": "alerts", "token": "wrong"}`)
+		_, err := n.Notify(testContext(), testAlerts()...)
+		require.ErrorIs(t, err, nats.ErrAuthorization)
+
+		n = newNotifier(t, `{"url": "nats://`+srv.addr()+`", "subject": "alerts", "token": "secret"}`)
+		_, err = n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+		require.Len(t, srv.messages(), 1)
+	})
+
+	t.Run("waits for the acknowledgement of the stream", func(t *testing.T) {
+		srv := newTestNATSServer(t, "")
+		srv.streams = map[string]string{"alerts": "ALERTS"}
+
+		n := newNotifier(t, `{"url": "nats://`+srv.addr()+`", "subject": "alerts", "jetstream": true}`)
+		_, err := n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+		require.Len(t, srv.messages(), 1)
+
+		n = newNotifier(t, `{"url": "nats://`+srv.addr()+`", "subject": "other", "jetstream": true}`)
+		_, err = n.Notify(testContext(), testAlerts()...)
+		require.ErrorContains(t, err, "no stream is configured for subject other")
+	})
+
+	t.Run("fails if the templated subject is invalid", func(t *testing.T) {
+		n := newNotifier(t, `{"url": "nats://localhost", "subject": "alerts.{{ .CommonLabels.missing }}"}`)
+		ok, err := n.Notify(testContext(), testAlerts()...)
+		require.ErrorContains(t, err, "invalid templated subject")
+		require.False(t, ok)
+	})
+}
+
+type testNATSMessage struct {
+	Subject string
+	Payload string
+}
+
+// testNATSServer implements the parts of the NATS protocol that the notifier uses. If streams are configured,
+// it acknowledges messages of their subjects like JetStream.
+type testNATSServer struct {
+	listener net.Listener
+	token    string
+	streams  map[string]string
+
+	mtx  sync.Mutex
+	msgs []testNATSMessage
+}
+
+func newTestNATSServer(t *testing.T, token string) *testNATSServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &testNATSServer{listener: l, token: token}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go srv.handle(conn)
+		}
+	}()
+	return srv
+}
+
+func (s *testNATSServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *testNATSServer) messages() []testNATSMessage {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]testNATSMessage(nil), s.msgs...)
+}
+
+func (s *testNATSServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	// sid is the subscription of the replies of the client, the notifier subscribes once per connection.
+	var sid string
+	_, _ = io.WriteString(conn, `INFO {"server_id":"test","headers":true,"max_payload":1048576}`+"\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT":
+			var opts struct {
+				Token string `json:"auth_token"`
+			}
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &opts)
+			if opts.Token != s.token {
+				_, _ = io.WriteString(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case "PING":
+			_, _ = io.WriteString(conn, "PONG\r\n")
+		case "SUB":
+			sid = fields[len(fields)-1]
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			subject := fields[1]
+			if len(fields) == 4 {
+				reply := fields[2]
+				stream, ok := s.streams[subject]
+				if !ok {
+					status := "NATS/1.0 503\r\n\r\n"
+					_, _ = fmt.Fprintf(conn, "HMSG %s %s %d %d\r\n%s\r\n", reply, sid, len(status), len(status), status)
+					continue
+				}
+				ack := fmt.Sprintf(`{"stream":%q,"seq":1}`, stream)
+				_, _ = fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", reply, sid, len(ack), ack)
+			}
+			s.mtx.Lock()
+			s.msgs = append(s.msgs, testNATSMessage{Subject: subject, Payload: string(payload[:size])})
+			s.mtx.Unlock()
+		}
+	}
+}
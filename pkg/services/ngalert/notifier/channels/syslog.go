@@ -0,0 +1,232 @@
+package channels
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	syslogTimeout   = 10 * time.Second
+	syslogNilValue  = "-"
+	syslogTimestamp = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// syslogFacilities are the facilities of RFC 5424, section 6.2.1.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7, "uucp": 8, "cron": 9,
+	"authpriv": 10, "ftp": 11, "ntp": 12, "security": 13, "console": 14, "solaris-cron": 15,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities are the severities of RFC 5424, section 6.2.1.
+var syslogSeverities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+type SyslogConfig struct {
+	Address          string `json:"address,omitempty" yaml:"address,omitempty"`
+	Protocol         string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Facility         string `json:"facility,omitempty" yaml:"facility,omitempty"`
+	Severity         string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	ResolvedSeverity string `json:"resolved_severity,omitempty" yaml:"resolved_severity,omitempty"`
+	Hostname         string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	AppName          string `json:"app_name,omitempty" yaml:"app_name,omitempty"`
+	MsgID            string `json:"msg_id,omitempty" yaml:"msg_id,omitempty"`
+	Message          string `json:"message,omitempty" yaml:"message,omitempty"`
+	TLSSettings
+}
+
+func NewSyslogConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (SyslogConfig, error) {
+	var settings SyslogConfig
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return SyslogConfig{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.Address == "" {
+		return SyslogConfig{}, errors.New("could not find address in settings")
+	}
+	if _, _, err := net.SplitHostPort(settings.Address); err != nil {
+		return SyslogConfig{}, fmt.Errorf("invalid address, must be host:port: %w", err)
+	}
+	switch settings.Protocol {
+	case "":
+		settings.Protocol = "udp"
+	case "udp", "tcp", "tls":
+	default:
+		return SyslogConfig{}, fmt.Errorf("unsupported protocol '%s', must be udp, tcp or tls", settings.Protocol)
+	}
+	if settings.Facility == "" {
+		settings.Facility = "local0"
+	}
+	if _, ok := syslogFacilities[settings.Facility]; !ok {
+		return SyslogConfig{}, fmt.Errorf("unknown facility '%s'", settings.Facility)
+	}
+	if settings.Severity == "" {
+		settings.Severity = "err"
+	}
+	if settings.ResolvedSeverity == "" {
+		settings.ResolvedSeverity = "notice"
+	}
+	for _, severity := range []string{settings.Severity, settings.ResolvedSeverity} {
+		if _, ok := syslogSeverities[severity]; !ok {
+			return SyslogConfig{}, fmt.Errorf("unknown severity '%s'", severity)
+		}
+	}
+	if settings.Hostname == "" {
+		settings.Hostname, _ = os.Hostname()
+	}
+	if settings.AppName == "" {
+		settings.AppName = "grafana"
+	}
+	for _, f := range []struct {
+		name  string
+		value string
+		max   int
+	}{{"hostname", settings.Hostname, 255}, {"app name", settings.AppName, 48}, {"message ID", settings.MsgID, 32}} {
+		if err := validateSyslogHeaderField(f.value, f.max); err != nil {
+			return SyslogConfig{}, fmt.Errorf("invalid %s: %w", f.name, err)
+		}
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageTitleEmbed
+	}
+	settings.decrypt(decryptFn)
+	if _, err := settings.tlsConfig(); err != nil {
+		return SyslogConfig{}, err
+	}
+	return settings, nil
+}
+
+// SyslogNotifier sends alert notifications as RFC 5424 messages to a syslog server. Messages are sent in datagrams
+// over UDP, and with octet counting framing over TCP and TLS.
+type SyslogNotifier struct {
+	*receivers.Base
+	log      alertingLogging.Logger
+	tmpl     *templates.Template
+	settings SyslogConfig
+	now      func() time.Time
+}
+
+func newSyslogNotifier(jsonData json.RawMessage, decryptFn receivers.DecryptFunc, meta receivers.Metadata, tmpl *templates.Template, logger alertingLogging.Logger, _ int64) (notificationChannel, error) {
+	cfg, err := NewSyslogConfig(jsonData, decryptFn)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogNotifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		tmpl:     tmpl,
+		settings: cfg,
+		now:      time.Now,
+	}, nil
+}
+
+// Notify sends a single syslog message for the notification.
+func (n *SyslogNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	msg, _, err := buildPayload(ctx, n.tmpl, n.log, 0, messageFormatText, n.settings.Message, as)
+	if err != nil {
+		return false, fmt.Errorf("failed to template syslog message: %w", err)
+	}
+	// The message is the last part of the syslog message. Line breaks would split it in many syslog servers.
+	text := strings.ReplaceAll(strings.TrimSpace(string(msg)), "\n", " ")
+
+	severity := n.settings.Severity
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		severity = n.settings.ResolvedSeverity
+	}
+	line := n.format(severity, text)
+
+	conn, err := n.dial(ctx)
+	if err != nil {
+		n.log.Error("Failed to connect to syslog server", "error", err)
+		return true, err
+	}
+	defer func() { _ = conn.Close() }()
+	if n.settings.Protocol != "udp" {
+		line = strconv.Itoa(len(line)) + " " + line
+	}
+	if _, err := conn.Write([]byte(line)); err != nil {
+		n.log.Error("Failed to send syslog message", "error", err)
+		return true, err
+	}
+	return true, nil
+}
+
+func (n *SyslogNotifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+// format returns the syslog message with the header of RFC 5424, section 6. Structured data is not sent.
+func (n *SyslogNotifier) format(severity, msg string) string {
+	pri := syslogFacilities[n.settings.Facility]*8 + syslogSeverities[severity]
+	field := func(v string) string {
+		if v == "" {
+			return syslogNilValue
+		}
+		return v
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		pri,
+		n.now().Format(syslogTimestamp),
+		field(n.settings.Hostname),
+		field(n.settings.AppName),
+		os.Getpid(),
+		field(n.settings.MsgID),
+		syslogNilValue,
+		msg,
+	)
+}
+
+func (n *SyslogNotifier) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogTimeout}
+	var conn net.Conn
+	var err error
+	switch n.settings.Protocol {
+	case "tls":
+		var cfg *tls.Config
+		cfg, err = n.settings.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: cfg}).DialContext(ctx, "tcp", n.settings.Address)
+	default:
+		conn, err = dialer.DialContext(ctx, n.settings.Protocol, n.settings.Address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(syslogTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	return conn, nil
+}
+
+// validateSyslogHeaderField returns an error if the value cannot be used in a field of the header, which must be
+// printable US-ASCII without spaces.
+func validateSyslogHeaderField(value string, maxLength int) error {
+	if len(value) > maxLength {
+		return fmt.Errorf("must not be longer than %d characters", maxLength)
+	}
+	for _, r := range value {
+		if r < 33 || r > 126 {
+			return errors.New("must contain only printable ASCII characters without spaces")
+		}
+	}
+	return nil
+}
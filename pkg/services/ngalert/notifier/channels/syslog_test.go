@@ -0,0 +1,194 @@
+package channels
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSyslogConfig(t *testing.T) {
+	hostname, _ := os.Hostname()
+	cases := []struct {
+		name     string
+		settings string
+		expected SyslogConfig
+		expErr   string
+	}{
+		{
+			name:     "minimal settings",
+			settings: `{"address": "localhost:514"}`,
+			expected: SyslogConfig{
+				Address:          "localhost:514",
+				Protocol:         "udp",
+				Facility:         "local0",
+				Severity:         "err",
+				ResolvedSeverity: "notice",
+				Hostname:         hostname,
+				AppName:          "grafana",
+				Message:          templates.DefaultMessageTitleEmbed,
+			},
+		},
+		{
+			name:     "all settings",
+			settings: `{"address": "localhost:6514", "protocol": "tls", "facility": "daemon", "severity": "crit", "resolved_severity": "info", "hostname": "edge-1", "app_name": "alerts", "msg_id": "ALERT", "message": "msg"}`,
+			expected: SyslogConfig{
+				Address:          "localhost:6514",
+				Protocol:         "tls",
+				Facility:         "daemon",
+				Severity:         "crit",
+				ResolvedSeverity: "info",
+				Hostname:         "edge-1",
+				AppName:          "alerts",
+				MsgID:            "ALERT",
+				Message:          "msg",
+			},
+		},
+		{
+			name:     "missing address",
+			settings: `{}`,
+			expErr:   "could not find address in settings",
+		},
+		{
+			name:     "address without port",
+			settings: `{"address": "localhost"}`,
+			expErr:   "invalid address, must be host:port",
+		},
+		{
+			name:     "unsupported protocol",
+			settings: `{"address": "localhost:514", "protocol": "http"}`,
+			expErr:   "unsupported protocol 'http'",
+		},
+		{
+			name:     "unknown facility",
+			settings: `{"address": "localhost:514", "facility": "local8"}`,
+			expErr:   "unknown facility 'local8'",
+		},
+		{
+			name:     "unknown severity",
+			settings: `{"address": "localhost:514", "resolved_severity": "fine"}`,
+			expErr:   "unknown severity 'fine'",
+		},
+		{
+			name:     "app name with spaces",
+			settings: `{"address": "localhost:514", "app_name": "my app"}`,
+			expErr:   "invalid app name: must contain only printable ASCII characters without spaces",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewSyslogConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(nil))
+			if c.expErr != "" {
+				require.ErrorContains(t, err, c.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestSyslogNotifier(t *testing.T) {
+	tmpl := testTemplate(t)
+	now := time.Date(2024, 6, 1, 10, 20, 30, 123456000, time.UTC)
+	pid := strconv.Itoa(os.Getpid())
+
+	newNotifier := func(t *testing.T, settings string) *SyslogNotifier {
+		n, err := newSyslogNotifier(json.RawMessage(settings), receiversTesting.DecryptForTesting(nil), receivers.Metadata{Type: TypeSyslog}, tmpl, &alertingLogging.FakeLogger{}, 1)
+		require.NoError(t, err)
+		syslog := n.(*SyslogNotifier)
+		syslog.now = func() time.Time { return now }
+		return syslog
+	}
+
+	t.Run("sends datagrams over UDP", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		n := newNotifier(t, `{"address": "`+conn.LocalAddr().String()+`", "hostname": "edge-1", "msg_id": "ALERT"}`)
+		ok, err := n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		buf := make([]byte, 2048)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		size, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		// local0 (16) * 8 + err (3)
+		require.Equal(t, "<131>1 2024-06-01T10:20:30.123456Z edge-1 grafana "+pid+" ALERT - [FIRING:1] test (1)", string(buf[:size]))
+	})
+
+	t.Run("sends messages with octet counting over TCP", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = l.Close() })
+		received := receiveFramed(l)
+
+		n := newNotifier(t, `{"address": "`+l.Addr().String()+`", "protocol": "tcp", "facility": "daemon", "hostname": "edge-1", "message": "{{ .Status }}\n{{ .CommonAnnotations.summary }}"}`)
+		resolved := testAlerts()
+		resolved[0].EndsAt = now.Add(-time.Minute)
+		_, err = n.Notify(testContext(), resolved...)
+		require.NoError(t, err)
+		// daemon (3) * 8 + notice (5)
+		require.Equal(t, "<29>1 2024-06-01T10:20:30.123456Z edge-1 grafana "+pid+" - - resolved line 1 is down", <-received)
+	})
+
+	t.Run("sends messages over TLS", func(t *testing.T) {
+		srv := httptest.NewTLSServer(nil)
+		srv.Close()
+		l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: srv.TLS.Certificates})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = l.Close() })
+		received := receiveFramed(l)
+
+		n := newNotifier(t, `{"address": "`+l.Addr().String()+`", "protocol": "tls", "insecure_skip_verify": true}`)
+		_, err = n.Notify(testContext(), testAlerts()...)
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(<-received, "[FIRING:1] test (1)"))
+	})
+}
+
+// receiveFramed accepts a single connection and returns the first message that is framed with octet counting.
+func receiveFramed(l net.Listener) <-chan string {
+	result := make(chan string, 1)
+	go func() {
+		defer close(result)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		length, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			result <- fmt.Sprintf("invalid length %q", length)
+			return
+		}
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return
+		}
+		result <- string(msg)
+	}()
+	return result
+}
@@ -1506,7 +1506,309 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{ // Since Grafana 11.2
+			Type:        "mqtt",
+			Name:        "MQTT",
+			Description: "Publishes notifications to a topic of an MQTT broker",
+			Heading:     "MQTT settings",
+			Info:        "",
+			Options: append([]NotifierOption{
+				{
+					Label:        "Broker URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The URL of the MQTT broker. Use the tcp or mqtt scheme for plain connections, and the ssl, tls or mqtts scheme for TLS connections.",
+					Placeholder:  "tcp://localhost:1883",
+					PropertyName: "broker_url",
+					Required:     true,
+				},
+				{
+					Label:        "Topic",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The topic to publish notifications to. You can use templates to customize this field.",
+					Placeholder:  "grafana/alerts/{{ .CommonLabels.alertname }}",
+					PropertyName: "topic",
+					Required:     true,
+				},
+				{
+					Label:        "Client ID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The client identifier that is used to connect to the broker. If empty, a unique identifier is generated for every connection.",
+					PropertyName: "client_id",
+				},
+				{
+					Label:        "QoS",
+					Element:      ElementTypeSelect,
+					Description:  "The quality of service level of the published messages.",
+					PropertyName: "qos",
+					SelectOptions: []SelectOption{
+						{
+							Value: "0",
+							Label: "At most once (0)",
+						},
+						{
+							Value: "1",
+							Label: "At least once (1)",
+						},
+						{
+							Value: "2",
+							Label: "Exactly once (2)",
+						},
+					},
+				},
+				{
+					Label:        "Retain",
+					Element:      ElementTypeCheckbox,
+					Description:  "The broker retains the last message of the topic and delivers it to new subscribers.",
+					PropertyName: "retain",
+				},
+				{
+					Label:        "Message format",
+					Element:      ElementTypeSelect,
+					Description:  "The JSON format contains the alerts and the templated message, like the payload of the webhook integration. The text format contains only the templated message.",
+					PropertyName: "message_format",
+					SelectOptions: []SelectOption{
+						{
+							Value: "json",
+							Label: "JSON",
+						},
+						{
+							Value: "text",
+							Label: "Text",
+						},
+					},
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Optional message. You can use templates to customize this field.",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+			}, tlsOptions(ShowWhen{})...),
+		},
+		{ // Since Grafana 11.2
+			Type:        "nats",
+			Name:        "NATS",
+			Description: "Publishes notifications to a subject of a NATS server or a JetStream stream",
+			Heading:     "NATS settings",
+			Info:        "",
+			Options: append([]NotifierOption{
+				{
+					Label:        "Server URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The URL of the NATS server. Use the tls scheme to require TLS.",
+					Placeholder:  "nats://localhost:4222",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Subject",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The subject to publish notifications to. You can use templates to customize this field.",
+					Placeholder:  "grafana.alerts",
+					PropertyName: "subject",
+					Required:     true,
+				},
+				{
+					Label:        "JetStream",
+					Element:      ElementTypeCheckbox,
+					Description:  "Wait for the acknowledgement of the JetStream stream of the subject. Sending the notification fails if no stream stores the subject.",
+					PropertyName: "jetstream",
+				},
+				{
+					Label:        "Message format",
+					Element:      ElementTypeSelect,
+					Description:  "The JSON format contains the alerts and the templated message, like the payload of the webhook integration. The text format contains only the templated message.",
+					PropertyName: "message_format",
+					SelectOptions: []SelectOption{
+						{
+							Value: "json",
+							Label: "JSON",
+						},
+						{
+							Value: "text",
+							Label: "Text",
+						},
+					},
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Optional message. You can use templates to customize this field.",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "Token",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					Description:  "The authentication token, an alternative to username and password.",
+					PropertyName: "token",
+					Secure:       true,
+				},
+			}, tlsOptions(ShowWhen{})...),
+		},
+		{ // Since Grafana 11.2
+			Type:        "syslog",
+			Name:        "Syslog",
+			Description: "Sends notifications to a syslog server in the RFC 5424 format",
+			Heading:     "Syslog settings",
+			Info:        "",
+			Options: append([]NotifierOption{
+				{
+					Label:        "Address",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The host and port of the syslog server.",
+					Placeholder:  "localhost:514",
+					PropertyName: "address",
+					Required:     true,
+				},
+				{
+					Label:        "Protocol",
+					Element:      ElementTypeSelect,
+					Description:  "The transport protocol. Messages are framed with octet counting over TCP and TLS.",
+					PropertyName: "protocol",
+					SelectOptions: []SelectOption{
+						{
+							Value: "udp",
+							Label: "UDP",
+						},
+						{
+							Value: "tcp",
+							Label: "TCP",
+						},
+						{
+							Value: "tls",
+							Label: "TLS",
+						},
+					},
+				},
+				{
+					Label:         "Facility",
+					Element:       ElementTypeSelect,
+					PropertyName:  "facility",
+					SelectOptions: syslogSelectOptions("kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron", "local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"),
+				},
+				{
+					Label:         "Severity",
+					Element:       ElementTypeSelect,
+					Description:   "The severity of notifications with firing alerts.",
+					PropertyName:  "severity",
+					SelectOptions: syslogSelectOptions("emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"),
+				},
+				{
+					Label:         "Resolved severity",
+					Element:       ElementTypeSelect,
+					Description:   "The severity of notifications with only resolved alerts.",
+					PropertyName:  "resolved_severity",
+					SelectOptions: syslogSelectOptions("emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"),
+				},
+				{
+					Label:        "Hostname",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The hostname of the messages. Defaults to the hostname of the Grafana server.",
+					PropertyName: "hostname",
+				},
+				{
+					Label:        "App name",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "grafana",
+					PropertyName: "app_name",
+				},
+				{
+					Label:        "Message ID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The MSGID field of the messages, which identifies the type of the messages.",
+					PropertyName: "msg_id",
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Optional message. You can use templates to customize this field. Line breaks are replaced with spaces.",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+					PropertyName: "message",
+				},
+			}, tlsOptions(ShowWhen{Field: "protocol", Is: "tls"})...),
+		},
+	}
+}
+
+// tlsOptions returns the options of integrations that connect with TLS. The client key is a secret.
+func tlsOptions(showWhen ShowWhen) []NotifierOption {
+	return []NotifierOption{
+		{
+			Label:        "Disable certificate verification",
+			Element:      ElementTypeCheckbox,
+			Description:  "Do not verify the certificate of the server. This is insecure.",
+			PropertyName: "insecure_skip_verify",
+			ShowWhen:     showWhen,
+		},
+		{
+			Label:        "CA certificate",
+			Element:      ElementTypeTextArea,
+			Description:  "The PEM encoded certificate of the CA that signs the certificate of the server. Defaults to the CAs of the system.",
+			PropertyName: "tls_ca_certificate",
+			ShowWhen:     showWhen,
+		},
+		{
+			Label:        "Client certificate",
+			Element:      ElementTypeTextArea,
+			Description:  "The PEM encoded client certificate for mutual TLS.",
+			PropertyName: "tls_client_certificate",
+			ShowWhen:     showWhen,
+		},
+		{
+			Label:        "Client key",
+			Element:      ElementTypeTextArea,
+			Description:  "The PEM encoded key of the client certificate.",
+			PropertyName: "tls_client_key",
+			Secure:       true,
+			ShowWhen:     showWhen,
+		},
+	}
+}
+
+func syslogSelectOptions(values ...string) []SelectOption {
+	options := make([]SelectOption, 0, len(values))
+	for _, v := range values {
+		options = append(options, SelectOption{Value: v, Label: v})
 	}
+	return options
 }
 
 // GetSecretKeysForContactPointType returns settings keys of contact point of the given type that are expected to be secrets. Returns error is contact point type is not known.
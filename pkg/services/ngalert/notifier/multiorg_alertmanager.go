@@ -569,6 +569,44 @@ func (moa *MultiOrgAlertmanager) updateSilenceState(ctx context.Context, orgAM A
 	return err
 }
 
+// ClusterMembership provides the members of the high availability cluster.
+type ClusterMembership interface {
+	// Name returns the name of this instance in the cluster.
+	Name() string
+	// Members returns the names of the instances that are currently members of the cluster.
+	Members() []string
+}
+
+// ClusterMembership returns the membership of the high availability cluster that the Alertmanagers use,
+// or nil if high availability is not configured.
+func (moa *MultiOrgAlertmanager) ClusterMembership() ClusterMembership {
+	switch p := moa.peer.(type) {
+	case *redisPeer:
+		return p
+	case *alertingCluster.Peer:
+		return memberlistMembership{peer: p}
+	default:
+		return nil
+	}
+}
+
+type memberlistMembership struct {
+	peer *alertingCluster.Peer
+}
+
+func (m memberlistMembership) Name() string {
+	return m.peer.Name()
+}
+
+func (m memberlistMembership) Members() []string {
+	peers := m.peer.Peers()
+	members := make([]string, 0, len(peers))
+	for _, p := range peers {
+		members = append(members, p.Name())
+	}
+	return members
+}
+
 // NilPeer and NilChannel implements the Alertmanager clustering interface.
 type NilPeer struct{}
 
@@ -0,0 +1,314 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// SimulatedAlert is an alert that is received by the simulated Alertmanager.
+type SimulatedAlert struct {
+	// ReceivedAt is the time at which the alert is sent to the Alertmanager.
+	ReceivedAt time.Time
+	Labels     model.LabelSet
+	StartsAt   time.Time
+	// EndsAt is the time at which the alert is resolved. The alert is firing until then.
+	EndsAt time.Time
+}
+
+// NotificationSimulationResult describes the notifications that the current notification policy tree would send
+// for the simulated alerts.
+type NotificationSimulationResult struct {
+	// Notifications are the notifications that would be sent, ordered by time.
+	Notifications []SimulatedNotification
+	// Receivers contain the number of notifications of every receiver that would be notified or muted.
+	Receivers []SimulatedReceiverNotifications
+}
+
+// SimulatedNotification is a notification that is sent to a receiver for a group of alerts.
+type SimulatedNotification struct {
+	Time     time.Time
+	Receiver string
+	// GroupLabels are the labels that identify the group of alerts of the notification.
+	GroupLabels model.LabelSet
+	Firing      []model.LabelSet
+	// Resolved are empty if all integrations of the receiver disable resolve messages.
+	Resolved []model.LabelSet
+}
+
+// SimulatedReceiverNotifications is the number of notifications of a receiver.
+type SimulatedReceiverNotifications struct {
+	Receiver      string
+	Notifications int
+	// Muted is the number of notifications that are not sent because the time intervals of the route mute them.
+	Muted int
+}
+
+// SimulateNotifications replays the alerts through the current notification policy tree of the organization,
+// including the autogenerated routes of alert rules with notification settings, and returns the notifications that
+// would be sent until the given time. Grouping, group wait, group interval, repeat interval and time intervals of
+// the routes are applied in the same way as the Alertmanager applies them. Silences are not applied because the
+// simulated alerts are usually in the past. No notification is sent.
+func (moa *MultiOrgAlertmanager) SimulateNotifications(ctx context.Context, orgID int64, alerts []SimulatedAlert, until time.Time) (*NotificationSimulationResult, error) {
+	cfg, err := moa.GetAlertmanagerConfiguration(ctx, orgID, true)
+	if err != nil {
+		return nil, err
+	}
+	return simulateNotifications(cfg.AlertmanagerConfig, alerts, until)
+}
+
+func simulateNotifications(cfg definitions.GettableApiAlertingConfig, alerts []SimulatedAlert, until time.Time) (*NotificationSimulationResult, error) {
+	if cfg.Route == nil {
+		return nil, errors.New("configuration has no root route")
+	}
+	s := &notificationSimulator{
+		root:         dispatch.NewRoute(cfg.Route.AsAMRoute(), nil),
+		intervener:   timeinterval.NewIntervener(timeIntervalsOf(cfg)),
+		sendResolved: make(map[string]bool, len(cfg.Receivers)),
+		groups:       make(map[string]*simulatedGroup),
+		logs:         make(map[string]*simulatedNotificationLog),
+		receivers:    make(map[string]*SimulatedReceiverNotifications),
+		result:       &NotificationSimulationResult{},
+	}
+	for _, r := range cfg.Receivers {
+		for _, integration := range r.GrafanaManagedReceivers {
+			s.sendResolved[r.Name] = s.sendResolved[r.Name] || !integration.DisableResolveMessage
+		}
+	}
+
+	alerts = append([]SimulatedAlert(nil), alerts...)
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].ReceivedAt.Before(alerts[j].ReceivedAt)
+	})
+	for _, a := range alerts {
+		if a.ReceivedAt.After(until) {
+			break
+		}
+		s.flushUntil(a.ReceivedAt)
+		s.receive(a)
+	}
+	s.flushUntil(until)
+
+	for _, r := range s.receivers {
+		s.result.Receivers = append(s.result.Receivers, *r)
+	}
+	sort.Slice(s.result.Receivers, func(i, j int) bool {
+		return s.result.Receivers[i].Receiver < s.result.Receivers[j].Receiver
+	})
+	return s.result, nil
+}
+
+// notificationSimulator is a simplified, single-threaded dispatcher that works with simulated time.
+type notificationSimulator struct {
+	root         *dispatch.Route
+	intervener   *timeinterval.Intervener
+	sendResolved map[string]bool
+	groups       map[string]*simulatedGroup
+	// logs are kept when the groups are deleted, like the notification log of the Alertmanager.
+	logs      map[string]*simulatedNotificationLog
+	receivers map[string]*SimulatedReceiverNotifications
+	result    *NotificationSimulationResult
+}
+
+// simulatedGroup is an aggregation group of the dispatcher.
+type simulatedGroup struct {
+	key     string
+	route   *dispatch.Route
+	labels  model.LabelSet
+	alerts  map[model.Fingerprint]SimulatedAlert
+	next    time.Time
+	flushed bool
+}
+
+// simulatedNotificationLog is the last notification of a group that the deduplication is based on.
+type simulatedNotificationLog struct {
+	time     time.Time
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+}
+
+func (s *notificationSimulator) receive(a SimulatedAlert) {
+	for _, route := range s.root.Match(a.Labels) {
+		groupLabels := model.LabelSet{}
+		for ln, lv := range a.Labels {
+			if _, ok := route.RouteOpts.GroupBy[ln]; ok || route.RouteOpts.GroupByAll {
+				groupLabels[ln] = lv
+			}
+		}
+		key := route.Key() + ":" + groupLabels.String()
+		g, ok := s.groups[key]
+		if !ok {
+			g = &simulatedGroup{
+				key:    key,
+				route:  route,
+				labels: groupLabels,
+				alerts: make(map[model.Fingerprint]SimulatedAlert),
+				next:   a.ReceivedAt.Add(route.RouteOpts.GroupWait),
+			}
+			s.groups[key] = g
+		}
+		g.alerts[a.Labels.Fingerprint()] = a
+		// The dispatcher flushes a group immediately if an alert of the first flush has been firing for longer than
+		// group wait already.
+		if !g.flushed && a.StartsAt.Add(route.RouteOpts.GroupWait).Before(a.ReceivedAt) {
+			g.next = a.ReceivedAt
+		}
+	}
+}
+
+// flushUntil flushes the groups, in the order of their flush times, until the given time.
+func (s *notificationSimulator) flushUntil(t time.Time) {
+	for {
+		var next *simulatedGroup
+		for _, g := range s.groups {
+			if g.next.After(t) {
+				continue
+			}
+			if next == nil || g.next.Before(next.next) || (g.next.Equal(next.next) && g.key < next.key) {
+				next = g
+			}
+		}
+		if next == nil {
+			return
+		}
+		s.flush(next)
+	}
+}
+
+func (s *notificationSimulator) flush(g *simulatedGroup) {
+	now := g.next
+	opts := g.route.RouteOpts
+	var firing, resolved []model.Fingerprint
+	for fp, a := range g.alerts {
+		if !a.EndsAt.IsZero() && !a.EndsAt.After(now) {
+			resolved = append(resolved, fp)
+		} else {
+			firing = append(firing, fp)
+		}
+	}
+	sort.Slice(firing, func(i, j int) bool { return firing[i] < firing[j] })
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i] < resolved[j] })
+
+	sendResolved := s.sendResolved[opts.Receiver]
+	log := s.logs[g.key]
+	if s.needsUpdate(log, firing, resolved, sendResolved, now, opts.RepeatInterval) {
+		receiver := s.receivers[opts.Receiver]
+		if receiver == nil {
+			receiver = &SimulatedReceiverNotifications{Receiver: opts.Receiver}
+			s.receivers[opts.Receiver] = receiver
+		}
+		if routeMuted(g.route, now, s.intervener) {
+			receiver.Muted++
+		} else {
+			s.logs[g.key] = &simulatedNotificationLog{time: now, firing: toSet(firing), resolved: toSet(resolved)}
+			if len(firing) > 0 || (sendResolved && len(resolved) > 0) {
+				n := SimulatedNotification{
+					Time:        now,
+					Receiver:    opts.Receiver,
+					GroupLabels: g.labels,
+					Firing:      make([]model.LabelSet, 0, len(firing)),
+					Resolved:    make([]model.LabelSet, 0, len(resolved)),
+				}
+				for _, fp := range firing {
+					n.Firing = append(n.Firing, g.alerts[fp].Labels)
+				}
+				if sendResolved {
+					for _, fp := range resolved {
+						n.Resolved = append(n.Resolved, g.alerts[fp].Labels)
+					}
+				}
+				s.result.Notifications = append(s.result.Notifications, n)
+				receiver.Notifications++
+			}
+		}
+	}
+
+	// The dispatcher deletes the resolved alerts after the flush, and the group once it has no alerts.
+	for _, fp := range resolved {
+		delete(g.alerts, fp)
+	}
+	if len(g.alerts) == 0 {
+		delete(s.groups, g.key)
+		return
+	}
+	g.flushed = true
+	g.next = now.Add(opts.GroupInterval)
+}
+
+// needsUpdate decides whether a notification is sent in the same way as the deduplication stage of the Alertmanager.
+func (s *notificationSimulator) needsUpdate(log *simulatedNotificationLog, firing, resolved []model.Fingerprint, sendResolved bool, now time.Time, repeat time.Duration) bool {
+	if log == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(log.firing, firing) {
+		return true
+	}
+	if len(firing) == 0 {
+		return len(log.firing) > 0
+	}
+	if sendResolved && !isSubset(log.resolved, resolved) {
+		return true
+	}
+	return log.time.Before(now.Add(-repeat))
+}
+
+// routeMuted returns true if the mute time intervals or the active time intervals of the route mute notifications
+// at the given time.
+func routeMuted(route *dispatch.Route, t time.Time, intervener *timeinterval.Intervener) bool {
+	for _, name := range route.RouteOpts.MuteTimeIntervals {
+		if timeIntervalActive(intervener, name, t) {
+			return true
+		}
+	}
+	if len(route.RouteOpts.ActiveTimeIntervals) == 0 {
+		return false
+	}
+	for _, name := range route.RouteOpts.ActiveTimeIntervals {
+		if timeIntervalActive(intervener, name, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// timeIntervalActive returns true if the time interval is active at the given time. It is evaluated by the same
+// Intervener as the dispatcher of the Alertmanager uses, an interval that does not exist is never active.
+func timeIntervalActive(intervener *timeinterval.Intervener, name string, t time.Time) bool {
+	active, err := intervener.Mutes([]string{name}, t)
+	return err == nil && active
+}
+
+func timeIntervalsOf(cfg definitions.GettableApiAlertingConfig) map[string][]timeinterval.TimeInterval {
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, ti := range cfg.MuteTimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	for _, ti := range cfg.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	return intervals
+}
+
+func toSet(fps []model.Fingerprint) map[model.Fingerprint]struct{} {
+	result := make(map[model.Fingerprint]struct{}, len(fps))
+	for _, fp := range fps {
+		result[fp] = struct{}{}
+	}
+	return result
+}
+
+func isSubset(set map[model.Fingerprint]struct{}, fps []model.Fingerprint) bool {
+	for _, fp := range fps {
+		if _, ok := set[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+const notificationSimulationConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"group_wait": "30s",
+			"group_interval": "5m",
+			"repeat_interval": "1h",
+			"routes": [
+				{
+					"receiver": "team-a",
+					"object_matchers": [["team", "=", "a"]],
+					"mute_time_intervals": ["always"]
+				},
+				{
+					"receiver": "no-resolved",
+					"object_matchers": [["team", "=", "b"]]
+				}
+			]
+		},
+		"mute_time_intervals": [
+			{"name": "always", "time_intervals": [{"times": [{"start_time": "00:00", "end_time": "24:00"}]}]}
+		],
+		"receivers": [
+			{"name": "default", "grafana_managed_receiver_configs": [{"uid": "default", "name": "default", "type": "email", "settings": {"addresses": "default@example.com"}}]},
+			{"name": "team-a", "grafana_managed_receiver_configs": [{"uid": "team-a", "name": "team-a", "type": "email", "settings": {"addresses": "a@example.com"}}]},
+			{"name": "no-resolved", "grafana_managed_receiver_configs": [{"uid": "no-resolved", "name": "no-resolved", "type": "email", "disableResolveMessage": true, "settings": {"addresses": "b@example.com"}}]}
+		]
+	}
+}`
+
+func TestMultiOrgAlertmanager_SimulateNotifications(t *testing.T) {
+	mam := setupMam(t, nil)
+	ctx := context.Background()
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+
+	am, err := mam.alertmanagerForOrg(1)
+	require.NoError(t, err)
+	postable, err := Load([]byte(notificationSimulationConfig))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(ctx, postable))
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := start.Add(2 * time.Hour)
+
+	// alerts returns the alerts that are sent every minute, like the scheduler sends firing alerts, from the first
+	// minute until the last one, when the alert is resolved.
+	alerts := func(lbls model.LabelSet, first, last time.Duration) []SimulatedAlert {
+		var result []SimulatedAlert
+		startsAt := start.Add(first)
+		for d := first; d <= last; d += time.Minute {
+			receivedAt := start.Add(d)
+			result = append(result, SimulatedAlert{ReceivedAt: receivedAt, Labels: lbls, StartsAt: startsAt, EndsAt: receivedAt.Add(4 * time.Minute)})
+		}
+		if last < until.Sub(start) {
+			result[len(result)-1].EndsAt = start.Add(last)
+		}
+		return result
+	}
+	times := func(notifications []SimulatedNotification) []time.Duration {
+		result := make([]time.Duration, 0, len(notifications))
+		for _, n := range notifications {
+			result = append(result, n.Time.Sub(start))
+		}
+		return result
+	}
+
+	t.Run("applies group wait, group interval and repeat interval", func(t *testing.T) {
+		var input []SimulatedAlert
+		input = append(input, alerts(model.LabelSet{"alertname": "test", "instance": "1"}, 0, 10*time.Minute)...)
+		input = append(input, alerts(model.LabelSet{"alertname": "test", "instance": "2"}, 2*time.Minute, 2*time.Hour)...)
+
+		result, err := mam.SimulateNotifications(ctx, 1, input, until)
+		require.NoError(t, err)
+		require.Equal(t, []time.Duration{
+			30 * time.Second,                // first alert after group wait
+			5*time.Minute + 30*time.Second,  // second alert at the next group interval
+			10*time.Minute + 30*time.Second, // first alert is resolved
+			75*time.Minute + 30*time.Second, // repeat interval has passed
+		}, times(result.Notifications))
+
+		require.Len(t, result.Notifications[0].Firing, 1)
+		require.Equal(t, model.LabelSet{"alertname": "test"}, result.Notifications[0].GroupLabels)
+		require.Len(t, result.Notifications[1].Firing, 2)
+		require.Len(t, result.Notifications[2].Firing, 1)
+		require.Equal(t, []model.LabelSet{{"alertname": "test", "instance": "1"}}, result.Notifications[2].Resolved)
+		require.Empty(t, result.Notifications[3].Resolved)
+		require.Equal(t, []SimulatedReceiverNotifications{{Receiver: "default", Notifications: 4}}, result.Receivers)
+	})
+
+	t.Run("does not send notifications that are muted", func(t *testing.T) {
+		result, err := mam.SimulateNotifications(ctx, 1, alerts(model.LabelSet{"alertname": "test", "team": "a"}, 0, time.Hour), until)
+		require.NoError(t, err)
+		require.Empty(t, result.Notifications)
+		require.Len(t, result.Receivers, 1)
+		require.Equal(t, "team-a", result.Receivers[0].Receiver)
+		require.Zero(t, result.Receivers[0].Notifications)
+		require.NotZero(t, result.Receivers[0].Muted)
+	})
+
+	t.Run("does not send resolved notifications if the receiver disables them", func(t *testing.T) {
+		result, err := mam.SimulateNotifications(ctx, 1, alerts(model.LabelSet{"alertname": "test", "team": "b"}, 0, 10*time.Minute), until)
+		require.NoError(t, err)
+		require.Equal(t, []time.Duration{30 * time.Second}, times(result.Notifications))
+		require.Equal(t, "no-resolved", result.Notifications[0].Receiver)
+	})
+
+	t.Run("ignores alerts that are received after the end", func(t *testing.T) {
+		result, err := mam.SimulateNotifications(ctx, 1, alerts(model.LabelSet{"alertname": "test"}, 0, 10*time.Minute), start.Add(10*time.Second))
+		require.NoError(t, err)
+		require.Empty(t, result.Notifications)
+	})
+}
+
+func TestTimeIntervalActive(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	intervener := timeinterval.NewIntervener(map[string][]timeinterval.TimeInterval{
+		"office-hours": {{
+			Times:    []timeinterval.TimeRange{{StartMinute: 9 * 60, EndMinute: 17 * 60}},
+			Location: &timeinterval.Location{Location: berlin},
+		}},
+	})
+
+	// 08:30 UTC is 10:30 in Berlin.
+	require.True(t, timeIntervalActive(intervener, "office-hours", time.Date(2024, 6, 3, 8, 30, 0, 0, time.UTC)))
+	require.False(t, timeIntervalActive(intervener, "office-hours", time.Date(2024, 6, 3, 16, 0, 0, 0, time.UTC)))
+	require.False(t, timeIntervalActive(intervener, "missing", time.Date(2024, 6, 3, 8, 30, 0, 0, time.UTC)))
+}
@@ -369,7 +369,7 @@ func (p *redisPeer) filterUnhealthyMembers(members []string, values []any) []str
 
 func (p *redisPeer) Position() int {
 	for i, peer := range p.Members() {
-		if peer == p.withPrefix(p.name) {
+		if peer == p.Name() {
 			p.logger.Debug("Cluster position found", "name", p.name, "position", i)
 			return i
 		}
@@ -400,6 +400,11 @@ func (p *redisPeer) GetHealthScore() int {
 	return 0
 }
 
+// Name returns the name of the peer as it appears in Members.
+func (p *redisPeer) Name() string {
+	return p.withPrefix(p.name)
+}
+
 // Members returns a list of active cluster Members.
 func (p *redisPeer) Members() []string {
 	p.membersMtx.Lock()
@@ -0,0 +1,226 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RoutingSimulationQuery describes an alert whose way through the notification policy tree is simulated.
+type RoutingSimulationQuery struct {
+	// Labels of the alert.
+	Labels model.LabelSet
+	// Time at which the alert is routed. The active time intervals and silences are evaluated at this time.
+	Time time.Time
+	// Silences are matched against the alert. The caller lists them with the access of the user, so that only the
+	// silences the user can read are returned.
+	Silences []*models.Silence
+}
+
+// RoutingSimulationResult describes how an alert would be routed without sending any notification.
+type RoutingSimulationResult struct {
+	Labels model.LabelSet
+	Time   time.Time
+	// Routes are the routes that the alert matches. An alert matches more than one route if some routes continue matching.
+	Routes []SimulatedRoute
+	// Silences are the silences that match the alert and are active at the time.
+	Silences []*models.Silence
+}
+
+// SimulatedRoute is a route that the alert matches.
+type SimulatedRoute struct {
+	// Path contains the nodes of the notification policy tree from the root to the matched route.
+	Path []SimulatedRouteNode
+	// Receiver is the name of the contact point that the alert is sent to.
+	Receiver string
+	// GroupBy are the names of the labels that the alerts are grouped by. It is empty if GroupByAll is true.
+	GroupBy    []string
+	GroupByAll bool
+	// GroupLabels are the labels of the alert that identify the group of alerts it belongs to.
+	GroupLabels    model.LabelSet
+	GroupWait      time.Duration
+	GroupInterval  time.Duration
+	RepeatInterval time.Duration
+	// MuteTimeIntervals are the time intervals during which notifications of the route are muted.
+	MuteTimeIntervals []SimulatedTimeInterval
+	// ActiveTimeIntervals are the time intervals outside which notifications of the route are muted.
+	ActiveTimeIntervals []SimulatedTimeInterval
+	// Muted is true if the time intervals of the route mute notifications at the time.
+	Muted bool
+}
+
+// SimulatedRouteNode is a node of the notification policy tree.
+type SimulatedRouteNode struct {
+	Receiver string
+	Matchers []string
+	Continue bool
+}
+
+// SimulatedTimeInterval is a time interval that is referenced by a route.
+type SimulatedTimeInterval struct {
+	Name string
+	// Active is true if the time is within the time interval.
+	Active bool
+}
+
+// SimulateRouting returns the routes of the current notification policy tree of the organization, including the
+// autogenerated routes of alert rules with notification settings, that an alert with the given labels matches.
+// No notification is sent.
+func (moa *MultiOrgAlertmanager) SimulateRouting(ctx context.Context, orgID int64, q RoutingSimulationQuery) (*RoutingSimulationResult, error) {
+	if q.Time.IsZero() {
+		q.Time = time.Now()
+	}
+
+	cfg, err := moa.GetAlertmanagerConfiguration(ctx, orgID, true)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AlertmanagerConfig.Route == nil {
+		return nil, errors.New("configuration has no root route")
+	}
+
+	intervener := timeinterval.NewIntervener(timeIntervalsOf(cfg.AlertmanagerConfig))
+
+	root := dispatch.NewRoute(cfg.AlertmanagerConfig.Route.AsAMRoute(), nil)
+	result := &RoutingSimulationResult{
+		Labels: q.Labels,
+		Time:   q.Time,
+	}
+	for _, path := range matchRoutePaths(root, q.Labels, nil) {
+		result.Routes = append(result.Routes, simulateRoute(path, q.Labels, q.Time, intervener))
+	}
+
+	for _, s := range q.Silences {
+		matches, err := silenceMatches(s, q.Labels, q.Time)
+		if err != nil {
+			moa.logger.Warn("Failed to match silence", "org", orgID, "silence", *s.ID, "error", err)
+			continue
+		}
+		if matches {
+			result.Silences = append(result.Silences, s)
+		}
+	}
+	return result, nil
+}
+
+// matchRoutePaths does the same depth-first left-to-right search through the route tree as dispatch.Route.Match,
+// but returns the path from the root to every matching route.
+func matchRoutePaths(r *dispatch.Route, lset model.LabelSet, parents []*dispatch.Route) [][]*dispatch.Route {
+	if !r.Matchers.Matches(lset) {
+		return nil
+	}
+	path := append(slices.Clone(parents), r)
+
+	var all [][]*dispatch.Route
+	for _, cr := range r.Routes {
+		matches := matchRoutePaths(cr, lset, path)
+		all = append(all, matches...)
+		if matches != nil && !cr.Continue {
+			break
+		}
+	}
+	// If no child nodes were matches, the current node itself is a match.
+	if len(all) == 0 {
+		all = append(all, path)
+	}
+	return all
+}
+
+func simulateRoute(path []*dispatch.Route, lset model.LabelSet, t time.Time, intervener *timeinterval.Intervener) SimulatedRoute {
+	route := path[len(path)-1]
+	result := SimulatedRoute{
+		Path:           make([]SimulatedRouteNode, 0, len(path)),
+		Receiver:       route.RouteOpts.Receiver,
+		GroupByAll:     route.RouteOpts.GroupByAll,
+		GroupLabels:    model.LabelSet{},
+		GroupWait:      route.RouteOpts.GroupWait,
+		GroupInterval:  route.RouteOpts.GroupInterval,
+		RepeatInterval: route.RouteOpts.RepeatInterval,
+	}
+	for _, node := range path {
+		matchers := make([]string, 0, len(node.Matchers))
+		for _, m := range node.Matchers {
+			matchers = append(matchers, m.String())
+		}
+		result.Path = append(result.Path, SimulatedRouteNode{
+			Receiver: node.RouteOpts.Receiver,
+			Matchers: matchers,
+			Continue: node.Continue,
+		})
+	}
+
+	// This is how the dispatcher calculates the labels of the group that the alert belongs to.
+	for ln, lv := range lset {
+		if _, ok := route.RouteOpts.GroupBy[ln]; ok || route.RouteOpts.GroupByAll {
+			result.GroupLabels[ln] = lv
+		}
+	}
+	if !route.RouteOpts.GroupByAll {
+		for ln := range route.RouteOpts.GroupBy {
+			result.GroupBy = append(result.GroupBy, string(ln))
+		}
+		sort.Strings(result.GroupBy)
+	}
+
+	for _, name := range route.RouteOpts.MuteTimeIntervals {
+		active := timeIntervalActive(intervener, name, t)
+		result.MuteTimeIntervals = append(result.MuteTimeIntervals, SimulatedTimeInterval{Name: name, Active: active})
+		result.Muted = result.Muted || active
+	}
+	inActiveInterval := len(route.RouteOpts.ActiveTimeIntervals) == 0
+	for _, name := range route.RouteOpts.ActiveTimeIntervals {
+		active := timeIntervalActive(intervener, name, t)
+		result.ActiveTimeIntervals = append(result.ActiveTimeIntervals, SimulatedTimeInterval{Name: name, Active: active})
+		inActiveInterval = inActiveInterval || active
+	}
+	result.Muted = result.Muted || !inActiveInterval
+	return result
+}
+
+// silenceMatches returns true if the silence is active at the given time and its matchers match the labels.
+func silenceMatches(s *models.Silence, lset model.LabelSet, t time.Time) (bool, error) {
+	if s.StartsAt == nil || s.EndsAt == nil || t.Before(time.Time(*s.StartsAt)) || !t.Before(time.Time(*s.EndsAt)) {
+		return false, nil
+	}
+	matchers, err := silenceMatchers(s.Matchers)
+	if err != nil {
+		return false, err
+	}
+	return matchers.Matches(lset), nil
+}
+
+func silenceMatchers(ms amv2.Matchers) (labels.Matchers, error) {
+	result := make(labels.Matchers, 0, len(ms))
+	for _, m := range ms {
+		if m == nil || m.Name == nil || m.Value == nil {
+			return nil, errors.New("matcher has no name or value")
+		}
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		isRegex := m.IsRegex != nil && *m.IsRegex
+		matchType := labels.MatchEqual
+		switch {
+		case isRegex && isEqual:
+			matchType = labels.MatchRegexp
+		case isRegex:
+			matchType = labels.MatchNotRegexp
+		case !isEqual:
+			matchType = labels.MatchNotEqual
+		}
+		matcher, err := labels.NewMatcher(matchType, *m.Name, *m.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, matcher)
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const routingSimulationConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"routes": [
+				{
+					"receiver": "team-a",
+					"object_matchers": [["team", "=", "a"]],
+					"continue": true,
+					"group_wait": "10s",
+					"mute_time_intervals": ["always", "never"],
+					"routes": [
+						{
+							"receiver": "team-a-critical",
+							"object_matchers": [["severity", "=", "critical"]],
+							"group_by": ["..."]
+						}
+					]
+				},
+				{
+					"receiver": "catch-all",
+					"object_matchers": [["team", "=~", ".+"]],
+					"group_by": ["team", "severity"],
+					"repeat_interval": "1h"
+				}
+			]
+		},
+		"mute_time_intervals": [
+			{"name": "always", "time_intervals": [{"times": [{"start_time": "00:00", "end_time": "24:00"}]}]},
+			{"name": "never", "time_intervals": [{"years": ["1999"]}]}
+		],
+		"receivers": [
+			{"name": "default", "grafana_managed_receiver_configs": [{"uid": "default", "name": "default", "type": "email", "settings": {"addresses": "default@example.com"}}]},
+			{"name": "team-a", "grafana_managed_receiver_configs": [{"uid": "team-a", "name": "team-a", "type": "email", "settings": {"addresses": "a@example.com"}}]},
+			{"name": "team-a-critical", "grafana_managed_receiver_configs": [{"uid": "team-a-critical", "name": "team-a-critical", "type": "email", "settings": {"addresses": "a-critical@example.com"}}]},
+			{"name": "catch-all", "grafana_managed_receiver_configs": [{"uid": "catch-all", "name": "catch-all", "type": "email", "settings": {"addresses": "all@example.com"}}]}
+		]
+	}
+}`
+
+func TestMultiOrgAlertmanager_SimulateRouting(t *testing.T) {
+	mam := setupMam(t, nil)
+	ctx := context.Background()
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+
+	am, err := mam.alertmanagerForOrg(1)
+	require.NoError(t, err)
+	postable, err := Load([]byte(routingSimulationConfig))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(ctx, postable))
+
+	onlyMatchers := func(s *models.Silence) {
+		s.Silence.Matchers = nil
+	}
+	silenceGen := models.SilenceGen(models.SilenceMuts.WithEmptyId(), onlyMatchers)
+	matching, err := mam.CreateSilence(ctx, 1, models.CopySilenceWith(silenceGen(), models.SilenceMuts.WithMatcher("team", "a", labels.MatchEqual)))
+	require.NoError(t, err)
+	_, err = mam.CreateSilence(ctx, 1, models.CopySilenceWith(silenceGen(), models.SilenceMuts.WithMatcher("team", "b", labels.MatchEqual)))
+	require.NoError(t, err)
+	silences, err := mam.ListSilences(ctx, 1, nil)
+	require.NoError(t, err)
+
+	receivers := func(routes []SimulatedRoute) []string {
+		result := make([]string, 0, len(routes))
+		for _, r := range routes {
+			result = append(result, r.Receiver)
+		}
+		return result
+	}
+
+	t.Run("returns the default route if no route matches", func(t *testing.T) {
+		result, err := mam.SimulateRouting(ctx, 1, RoutingSimulationQuery{Labels: model.LabelSet{"alertname": "test"}, Silences: silences})
+		require.NoError(t, err)
+		require.Equal(t, []string{"default"}, receivers(result.Routes))
+		route := result.Routes[0]
+		require.Len(t, route.Path, 1)
+		require.Equal(t, []string{"alertname"}, route.GroupBy)
+		require.Equal(t, model.LabelSet{"alertname": "test"}, route.GroupLabels)
+		require.False(t, route.Muted)
+		require.Empty(t, result.Silences)
+		require.False(t, result.Time.IsZero())
+	})
+
+	t.Run("returns every matching route with its path and timing", func(t *testing.T) {
+		lbls := model.LabelSet{"alertname": "test", "team": "a", "severity": "critical"}
+		result, err := mam.SimulateRouting(ctx, 1, RoutingSimulationQuery{Labels: lbls, Time: time.Now(), Silences: silences})
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a-critical", "catch-all"}, receivers(result.Routes))
+
+		critical := result.Routes[0]
+		require.Equal(t, []SimulatedRouteNode{
+			{Receiver: "default"},
+			{Receiver: "team-a", Matchers: []string{`team="a"`}, Continue: true},
+			{Receiver: "team-a-critical", Matchers: []string{`severity="critical"`}},
+		}, normalizeNodes(critical.Path))
+		require.True(t, critical.GroupByAll)
+		require.Empty(t, critical.GroupBy)
+		require.Equal(t, lbls, critical.GroupLabels)
+		require.Equal(t, 10*time.Second, critical.GroupWait, "timing should be inherited from the parent route")
+
+		catchAll := result.Routes[1]
+		require.Equal(t, []string{"severity", "team"}, catchAll.GroupBy)
+		require.Equal(t, model.LabelSet{"team": "a", "severity": "critical"}, catchAll.GroupLabels)
+		require.Equal(t, time.Hour, catchAll.RepeatInterval)
+
+		require.Len(t, result.Silences, 1)
+		require.Equal(t, matching, *result.Silences[0].ID)
+	})
+
+	t.Run("returns the mute time intervals of the route", func(t *testing.T) {
+		result, err := mam.SimulateRouting(ctx, 1, RoutingSimulationQuery{Labels: model.LabelSet{"team": "a"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a", "catch-all"}, receivers(result.Routes))
+		require.Equal(t, []SimulatedTimeInterval{{Name: "always", Active: true}, {Name: "never", Active: false}}, result.Routes[0].MuteTimeIntervals)
+		require.True(t, result.Routes[0].Muted)
+		require.False(t, result.Routes[1].Muted)
+	})
+
+	t.Run("does not return silences that are not active at the time", func(t *testing.T) {
+		result, err := mam.SimulateRouting(ctx, 1, RoutingSimulationQuery{Labels: model.LabelSet{"team": "a"}, Time: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.Empty(t, result.Silences)
+	})
+}
+
+func normalizeNodes(nodes []SimulatedRouteNode) []SimulatedRouteNode {
+	for i := range nodes {
+		if len(nodes[i].Matchers) == 0 {
+			nodes[i].Matchers = nil
+		}
+	}
+	return nodes
+}
@@ -2,12 +2,17 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/mqtt/mqtttest"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 )
 
 func TestInvalidReceiverError_Error(t *testing.T) {
@@ -80,3 +85,34 @@ func TestProcessNotifierError(t *testing.T) {
 		require.Equal(t, err, alertingNotify.ProcessIntegrationError(r, err))
 	})
 }
+
+func TestAlertmanager_TestReceiversLocalChannels(t *testing.T) {
+	mam := setupMam(t, nil)
+	ctx := context.Background()
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+	am, err := mam.alertmanagerForOrg(1)
+	require.NoError(t, err)
+
+	broker, err := mqtttest.NewBroker("", "", nil)
+	require.NoError(t, err)
+	t.Cleanup(broker.Close)
+	var params apimodels.TestReceiversConfigBodyParams
+	require.NoError(t, json.Unmarshal([]byte(`{"receivers": [{"name": "edge", "grafana_managed_receiver_configs": [
+		{"uid": "mqtt", "name": "edge", "type": "mqtt", "settings": {"broker_url": "`+broker.URL()+`", "topic": "alerts", "qos": "1"}},
+		{"uid": "nats", "name": "edge", "type": "nats", "settings": {"url": "nats://127.0.0.1:1", "subject": "alerts"}}
+	]}]}`), &params))
+
+	result, err := am.TestReceivers(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, result.Receivers, 1)
+	require.Len(t, result.Receivers[0].Configs, 2)
+	errs := map[string]error{}
+	for _, cfg := range result.Receivers[0].Configs {
+		errs[cfg.UID] = cfg.Error
+	}
+	require.NoError(t, errs["mqtt"])
+	require.Error(t, errs["nats"])
+
+	require.Eventually(t, func() bool { return len(broker.Messages()) == 1 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, "alerts", broker.Messages()[0].Topic)
+}
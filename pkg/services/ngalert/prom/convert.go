@@ -0,0 +1,290 @@
+// Package prom converts Prometheus rule files into Grafana-managed alert and recording rules.
+package prom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	queryRefID     = "A"
+	seriesRefID    = "B"
+	conditionRefID = "C"
+
+	defaultFromTimeRange = 10 * time.Minute
+)
+
+// Config controls how Prometheus rules are converted.
+type Config struct {
+	// DatasourceUID is the UID of the Prometheus data source that the converted rules query.
+	DatasourceUID string
+	// DatasourceType is the plugin type of the data source. Defaults to "prometheus".
+	DatasourceType string
+	// BaseInterval is the scheduler's base interval. Group intervals must be a multiple of it.
+	BaseInterval time.Duration
+	// DefaultInterval is used for groups that do not specify an interval.
+	DefaultInterval time.Duration
+	// FromTimeRange is how far back from the evaluation time the query range starts. Defaults to 10 minutes.
+	FromTimeRange time.Duration
+	// NoDataState and ExecErrState are set on every converted alert rule.
+	// They default to OK and Error, which is closest to how Prometheus treats empty results and failed queries.
+	NoDataState  models.NoDataState
+	ExecErrState models.ExecutionErrorState
+	// RejectRecordingRules reports recording rules as conversion errors, for instances that cannot run them.
+	RejectRecordingRules bool
+}
+
+// Converter converts Prometheus rule groups into Grafana rule groups.
+type Converter struct {
+	cfg Config
+}
+
+// NewConverter validates the configuration, fills in defaults and returns a Converter.
+func NewConverter(cfg Config) (*Converter, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, errors.New("data source UID is required")
+	}
+	if cfg.DatasourceType == "" {
+		cfg.DatasourceType = "prometheus"
+	}
+	if cfg.BaseInterval <= 0 {
+		return nil, errors.New("base interval must be greater than zero")
+	}
+	if cfg.DefaultInterval == 0 {
+		cfg.DefaultInterval = cfg.BaseInterval
+	}
+	if cfg.DefaultInterval%cfg.BaseInterval != 0 {
+		return nil, fmt.Errorf("default interval %s must be a multiple of the base interval %s", cfg.DefaultInterval, cfg.BaseInterval)
+	}
+	if cfg.FromTimeRange == 0 {
+		cfg.FromTimeRange = defaultFromTimeRange
+	}
+	if cfg.NoDataState == "" {
+		cfg.NoDataState = models.OK
+	}
+	if cfg.ExecErrState == "" {
+		cfg.ExecErrState = models.ErrorErrState
+	}
+	return &Converter{cfg: cfg}, nil
+}
+
+// ConversionError describes why a group or a rule in a group could not be converted.
+type ConversionError struct {
+	Group string
+	// RuleIndex is the position of the rule in the group, or -1 if the error is about the group itself.
+	RuleIndex int
+	// Rule is the name of the alert or the recorded metric.
+	Rule string
+	Err  error
+}
+
+func (e ConversionError) Error() string {
+	if e.RuleIndex < 0 {
+		return fmt.Sprintf("group %q: %s", e.Group, e.Err)
+	}
+	return fmt.Sprintf("group %q, rule %d (%s): %s", e.Group, e.RuleIndex, e.Rule, e.Err)
+}
+
+func (e ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// ParseRules decodes a Prometheus rule file. Unlike rulefmt.Parse it does not validate the rules,
+// so that validation errors can be reported for each rule by PrometheusRulesToGrafana.
+func ParseRules(content []byte) ([]rulefmt.RuleGroup, error) {
+	var groups rulefmt.RuleGroups
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&groups); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return groups.Groups, nil
+}
+
+// PrometheusRulesToGrafana converts every group into a Grafana rule group in the given folder.
+// Groups and rules that fail to convert are skipped and reported as ConversionError.
+// Converted rules do not have UIDs.
+func (c *Converter) PrometheusRulesToGrafana(orgID int64, namespaceUID string, groups []rulefmt.RuleGroup) ([]models.AlertRuleGroup, []ConversionError) {
+	var errs []ConversionError
+	result := make([]models.AlertRuleGroup, 0, len(groups))
+	seen := make(map[string]struct{}, len(groups))
+	titles := make(map[string]int)
+	for _, group := range groups {
+		groupErr := func(err error) {
+			errs = append(errs, ConversionError{Group: group.Name, RuleIndex: -1, Err: err})
+		}
+		if group.Name == "" {
+			groupErr(errors.New("group name must not be empty"))
+			continue
+		}
+		if _, ok := seen[group.Name]; ok {
+			groupErr(errors.New("group name must be unique within the file"))
+			continue
+		}
+		seen[group.Name] = struct{}{}
+		if group.Limit != 0 {
+			groupErr(errors.New("limit is not supported"))
+			continue
+		}
+		interval := time.Duration(group.Interval)
+		if interval == 0 {
+			interval = c.cfg.DefaultInterval
+		}
+		if interval < 0 || interval%c.cfg.BaseInterval != 0 {
+			groupErr(fmt.Errorf("interval %s must be a positive multiple of the base interval %s", interval, c.cfg.BaseInterval))
+			continue
+		}
+
+		converted := models.AlertRuleGroup{
+			Title:     group.Name,
+			FolderUID: namespaceUID,
+			Interval:  int64(interval.Seconds()),
+			Rules:     make([]models.AlertRule, 0, len(group.Rules)),
+		}
+		for idx, node := range group.Rules {
+			rule, err := c.convertRule(node)
+			if err != nil {
+				errs = append(errs, ConversionError{Group: group.Name, RuleIndex: idx, Rule: ruleName(node), Err: err})
+				continue
+			}
+			// Prometheus allows several rules with the same name, Grafana requires unique titles within a folder.
+			titles[rule.Title]++
+			if n := titles[rule.Title]; n > 1 {
+				rule.Title = fmt.Sprintf("%s (%d)", rule.Title, n)
+			}
+			rule.OrgID = orgID
+			rule.NamespaceUID = namespaceUID
+			rule.RuleGroup = group.Name
+			rule.RuleGroupIndex = len(converted.Rules) + 1
+			rule.IntervalSeconds = converted.Interval
+			converted.Rules = append(converted.Rules, rule)
+		}
+		result = append(result, converted)
+	}
+	return result, errs
+}
+
+func (c *Converter) convertRule(node rulefmt.RuleNode) (models.AlertRule, error) {
+	if errs := node.Validate(); len(errs) > 0 {
+		// Positions in the file are dropped because the error already identifies the group and the rule.
+		joined := make([]error, 0, len(errs))
+		for i := range errs {
+			joined = append(joined, errs[i].Unwrap())
+		}
+		return models.AlertRule{}, errors.Join(joined...)
+	}
+
+	query, err := c.prometheusQuery(node.Expr.Value)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	rule := models.AlertRule{
+		Labels:       copyMap(node.Labels),
+		Annotations:  copyMap(node.Annotations),
+		NoDataState:  c.cfg.NoDataState,
+		ExecErrState: c.cfg.ExecErrState,
+	}
+
+	if node.Record.Value != "" {
+		if c.cfg.RejectRecordingRules {
+			return models.AlertRule{}, errors.New("recording rules are not enabled")
+		}
+		rule.Title = node.Record.Value
+		rule.Condition = queryRefID
+		rule.Data = []models.AlertQuery{query}
+		rule.Record = &models.Record{Metric: node.Record.Value, From: queryRefID}
+		return rule, nil
+	}
+
+	series, err := expressionQuery(seriesRefID, map[string]any{
+		"type": "math",
+		// Prometheus fires for every series returned by the expression, whatever its value.
+		"expression": fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", queryRefID),
+	})
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	condition, err := expressionQuery(conditionRefID, map[string]any{
+		"type":       "threshold",
+		"expression": seriesRefID,
+		"conditions": []any{
+			map[string]any{"evaluator": map[string]any{"type": "gt", "params": []float64{0}}},
+		},
+	})
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	rule.Title = node.Alert.Value
+	rule.Condition = conditionRefID
+	rule.Data = []models.AlertQuery{query, series, condition}
+	rule.For = time.Duration(node.For)
+	rule.KeepFiringFor = time.Duration(node.KeepFiringFor)
+	return rule, nil
+}
+
+func (c *Converter) prometheusQuery(promQL string) (models.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"refId":   queryRefID,
+		"expr":    promQL,
+		"instant": true,
+		"range":   false,
+		"datasource": map[string]string{
+			"type": c.cfg.DatasourceType,
+			"uid":  c.cfg.DatasourceUID,
+		},
+	})
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:             queryRefID,
+		DatasourceUID:     c.cfg.DatasourceUID,
+		RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(c.cfg.FromTimeRange)},
+		Model:             model,
+	}, nil
+}
+
+func expressionQuery(refID string, m map[string]any) (models.AlertQuery, error) {
+	m["refId"] = refID
+	m["datasource"] = map[string]string{
+		"type": expr.DatasourceType,
+		"uid":  expr.DatasourceUID,
+	}
+	model, err := json.Marshal(m)
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         model,
+	}, nil
+}
+
+func ruleName(node rulefmt.RuleNode) string {
+	if node.Record.Value != "" {
+		return node.Record.Value
+	}
+	return node.Alert.Value
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,193 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const testRules = `
+groups:
+  - name: node
+    interval: 1m
+    rules:
+      - alert: HighLoad
+        expr: node_load1 > 4
+        for: 5m
+        labels:
+          severity: warning
+        annotations:
+          summary: "Load is {{ $value }}"
+      - record: instance:node_cpu:rate5m
+        expr: sum by (instance) (rate(node_cpu_seconds_total[5m]))
+      - alert: HighLoad
+        expr: node_load5 > 4
+  - name: broken
+    rules:
+      - alert: NoExpr
+      - alert: KeepFiring
+        expr: up == 0
+        keep_firing_for: 5m
+      - alert: Valid
+        expr: up == 0
+  - name: misaligned
+    interval: 15s
+    rules:
+      - alert: Valid
+        expr: up == 0
+`
+
+func TestPrometheusRulesToGrafana(t *testing.T) {
+	groups, err := ParseRules([]byte(testRules))
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+
+	c, err := NewConverter(Config{DatasourceUID: "prom-uid", BaseInterval: 10 * time.Second})
+	require.NoError(t, err)
+
+	result, errs := c.PrometheusRulesToGrafana(1, "folder-uid", groups)
+	require.Len(t, result, 2)
+	require.Len(t, errs, 2)
+
+	t.Run("converts alert rules", func(t *testing.T) {
+		node := result[0]
+		require.Equal(t, "node", node.Title)
+		require.Equal(t, "folder-uid", node.FolderUID)
+		require.EqualValues(t, 60, node.Interval)
+		require.Len(t, node.Rules, 3)
+
+		rule := node.Rules[0]
+		require.Equal(t, "HighLoad", rule.Title)
+		require.EqualValues(t, 1, rule.OrgID)
+		require.Equal(t, "folder-uid", rule.NamespaceUID)
+		require.Equal(t, "node", rule.RuleGroup)
+		require.Equal(t, 1, rule.RuleGroupIndex)
+		require.EqualValues(t, 60, rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, "C", rule.Condition)
+		require.Equal(t, map[string]string{"severity": "warning"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "Load is {{ $value }}"}, rule.Annotations)
+		require.Equal(t, models.OK, rule.NoDataState)
+		require.Equal(t, models.ErrorErrState, rule.ExecErrState)
+		require.Nil(t, rule.Record)
+
+		require.Len(t, rule.Data, 3)
+		require.Equal(t, "prom-uid", rule.Data[0].DatasourceUID)
+		require.Equal(t, models.Duration(10*time.Minute), rule.Data[0].RelativeTimeRange.From)
+		var model map[string]any
+		require.NoError(t, json.Unmarshal(rule.Data[0].Model, &model))
+		require.Equal(t, "node_load1 > 4", model["expr"])
+		require.Equal(t, true, model["instant"])
+		require.Equal(t, map[string]any{"type": "prometheus", "uid": "prom-uid"}, model["datasource"])
+		require.Equal(t, expr.DatasourceUID, rule.Data[1].DatasourceUID)
+		require.Equal(t, expr.DatasourceUID, rule.Data[2].DatasourceUID)
+	})
+
+	t.Run("converts recording rules", func(t *testing.T) {
+		rule := result[0].Rules[1]
+		require.Equal(t, "instance:node_cpu:rate5m", rule.Title)
+		require.Equal(t, "A", rule.Condition)
+		require.Len(t, rule.Data, 1)
+		require.Equal(t, &models.Record{Metric: "instance:node_cpu:rate5m", From: "A"}, rule.Record)
+		require.Equal(t, 2, rule.RuleGroupIndex)
+	})
+
+	t.Run("makes titles unique", func(t *testing.T) {
+		require.Equal(t, "HighLoad (2)", result[0].Rules[2].Title)
+	})
+
+	t.Run("uses the default interval", func(t *testing.T) {
+		broken := result[1]
+		require.Equal(t, "broken", broken.Title)
+		require.EqualValues(t, 10, broken.Interval)
+		require.Len(t, broken.Rules, 2)
+		require.Equal(t, "Valid", broken.Rules[1].Title)
+		require.Equal(t, 2, broken.Rules[1].RuleGroupIndex)
+	})
+
+	t.Run("converts keep_firing_for", func(t *testing.T) {
+		rule := result[1].Rules[0]
+		require.Equal(t, "KeepFiring", rule.Title)
+		require.Equal(t, 5*time.Minute, rule.KeepFiringFor)
+		require.Zero(t, result[0].Rules[0].KeepFiringFor)
+	})
+
+	t.Run("reports errors", func(t *testing.T) {
+		require.Equal(t, "broken", errs[0].Group)
+		require.Equal(t, 0, errs[0].RuleIndex)
+		require.Equal(t, "NoExpr", errs[0].Rule)
+		require.ErrorContains(t, errs[0], "field 'expr' must be set in rule")
+
+		require.Equal(t, "misaligned", errs[1].Group)
+		require.Equal(t, -1, errs[1].RuleIndex)
+		require.ErrorContains(t, errs[1], "must be a positive multiple of the base interval")
+	})
+}
+
+func TestPrometheusRulesToGrafanaGroupErrors(t *testing.T) {
+	groups, err := ParseRules([]byte(`
+groups:
+  - name: ""
+    rules: []
+  - name: a
+    rules: []
+  - name: a
+    rules: []
+  - name: limited
+    limit: 10
+    rules: []
+`))
+	require.NoError(t, err)
+
+	c, err := NewConverter(Config{DatasourceUID: "prom-uid", BaseInterval: 10 * time.Second})
+	require.NoError(t, err)
+	result, errs := c.PrometheusRulesToGrafana(1, "folder-uid", groups)
+	require.Len(t, result, 1)
+	require.Len(t, errs, 3)
+	require.ErrorContains(t, errs[0], "group name must not be empty")
+	require.ErrorContains(t, errs[1], "group name must be unique")
+	require.ErrorContains(t, errs[2], "limit is not supported")
+}
+
+func TestPrometheusRulesToGrafanaRejectRecordingRules(t *testing.T) {
+	groups, err := ParseRules([]byte(testRules))
+	require.NoError(t, err)
+
+	c, err := NewConverter(Config{DatasourceUID: "prom-uid", BaseInterval: 10 * time.Second, RejectRecordingRules: true})
+	require.NoError(t, err)
+	result, errs := c.PrometheusRulesToGrafana(1, "folder-uid", groups)
+	require.Len(t, result[0].Rules, 2)
+	require.Len(t, errs, 3)
+	require.Equal(t, 1, errs[0].RuleIndex)
+	require.Equal(t, "instance:node_cpu:rate5m", errs[0].Rule)
+	require.ErrorContains(t, errs[0], "recording rules are not enabled")
+}
+
+func TestParseRules(t *testing.T) {
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		_, err := ParseRules([]byte("groups:\n  - name: a\n    unknown: 1\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("accepts an empty file", func(t *testing.T) {
+		groups, err := ParseRules(nil)
+		require.NoError(t, err)
+		require.Empty(t, groups)
+	})
+}
+
+func TestNewConverter(t *testing.T) {
+	_, err := NewConverter(Config{BaseInterval: time.Second})
+	require.Error(t, err)
+
+	_, err = NewConverter(Config{DatasourceUID: "uid"})
+	require.Error(t, err)
+
+	_, err = NewConverter(Config{DatasourceUID: "uid", BaseInterval: 10 * time.Second, DefaultInterval: 15 * time.Second})
+	require.Error(t, err)
+}
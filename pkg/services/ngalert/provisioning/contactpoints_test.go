@@ -77,6 +77,26 @@ func TestContactPointService(t *testing.T) {
 		require.Equal(t, "slack", cps[2].Type)
 	})
 
+	t.Run("service validates and redacts integrations that are not part of the alerting module", func(t *testing.T) {
+		sut := createContactPointServiceSut(t, secretsService)
+		newCp := createTestContactPoint()
+		newCp.Type = "mqtt"
+		newCp.Settings = simplejson.NewFromAny(map[string]any{"broker_url": "tcp://localhost:1883", "topic": "alerts", "password": "secret"})
+
+		_, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		cps, err := sut.GetContactPoints(context.Background(), cpsQueryWithName(1, newCp.Name), nil)
+		require.NoError(t, err)
+		require.Len(t, cps, 1)
+		require.Equal(t, "mqtt", cps[0].Type)
+		require.Equal(t, definitions.RedactedValue, cps[0].Settings.Get("password").MustString())
+
+		newCp.Settings = simplejson.NewFromAny(map[string]any{"broker_url": "ws://localhost", "topic": "alerts"})
+		_, err = sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
 	t.Run("it's possible to use a custom uid", func(t *testing.T) {
 		customUID := "1337"
 		sut := createContactPointServiceSut(t, secretsService)
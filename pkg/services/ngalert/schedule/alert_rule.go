@@ -261,6 +261,7 @@ func (a *alertRule) Run() error {
 				defer func() {
 					evalDuration.Observe(a.clock.Now().Sub(evalStart).Seconds())
 					a.evalApplied(ctx.scheduledAt)
+					ctx.done()
 				}()
 
 				for attempt := int64(1); attempt <= a.maxAttempts; attempt++ {
@@ -339,6 +340,10 @@ func (a *alertRule) Run() error {
 				states := a.stateManager.DeleteStateByRuleUID(ngmodels.WithRuleKey(ctx, a.key), a.key, ngmodels.StateReasonRuleDeleted)
 				a.expireAndSend(grafanaCtx, states)
 			}
+			// keep the state in the database if another instance of the cluster continues evaluating the rule
+			if errors.Is(grafanaCtx.Err(), errRuleReleased) {
+				a.stateManager.ReleaseStateByRuleUID(ngmodels.WithRuleKey(context.Background(), a.key), a.key)
+			}
 			logger.Debug("Stopping alert rule routine")
 			return nil
 		}
@@ -447,6 +452,9 @@ func (a *alertRule) send(ctx context.Context, states state.StateTransitions) def
 	alerts := definitions.PostableAlerts{PostableAlerts: make([]models.PostableAlert, 0, len(states))}
 	for _, alertState := range states {
 		alerts.PostableAlerts = append(alerts.PostableAlerts, *state.StateToPostableAlert(alertState, a.appURL))
+		if stopped := state.SeverityChangeStoppedAlert(alertState, a.appURL, a.clock); stopped != nil {
+			alerts.PostableAlerts = append(alerts.PostableAlerts, *stopped)
+		}
 	}
 
 	if len(alerts.PostableAlerts) > 0 {
@@ -507,3 +515,24 @@ func SchedulerUserFor(orgID int64) *user.SignedInUser {
 		},
 	}
 }
+
+// TemplateQueryUserFor returns the user that runs the queries of the query function in the templates of a rule. Unlike
+// the scheduler user, it can query only the given data sources.
+func TemplateQueryUserFor(orgID int64, datasourceUIDs []string) *user.SignedInUser {
+	scopes := make([]string, 0, len(datasourceUIDs))
+	for _, uid := range datasourceUIDs {
+		scopes = append(scopes, datasources.ScopeProvider.GetResourceScopeUID(uid))
+	}
+	return &user.SignedInUser{
+		UserID:           -1,
+		IsServiceAccount: true,
+		Login:            "grafana_scheduler",
+		OrgID:            orgID,
+		OrgRole:          org.RoleNone,
+		Permissions: map[int64]map[string][]string{
+			orgID: {
+				datasources.ActionQuery: scopes,
+			},
+		},
+	}
+}
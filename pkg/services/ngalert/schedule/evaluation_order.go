@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// groupEvaluationOrder returns the order in which the rules of every group that has rules querying the metrics recorded
+// by other rules of the group should be evaluated. Groups whose rules do not depend on each other are not included.
+func (sch *schedule) groupEvaluationOrder(rules []*ngmodels.AlertRule) map[ngmodels.AlertRuleGroupKey][]ngmodels.AlertRuleKey {
+	groups := make(map[ngmodels.AlertRuleGroupKey][]*ngmodels.AlertRule)
+	for _, rule := range rules {
+		if rule.Type() != ngmodels.RuleTypeRecording {
+			continue
+		}
+		groups[rule.GetGroupKey()] = nil
+	}
+	// Only the groups that contain recording rules can have dependencies.
+	for _, rule := range rules {
+		groupKey := rule.GetGroupKey()
+		if group, ok := groups[groupKey]; ok {
+			groups[groupKey] = append(group, rule)
+		}
+	}
+
+	result := make(map[ngmodels.AlertRuleGroupKey][]ngmodels.AlertRuleKey)
+	for groupKey, group := range groups {
+		slices.SortFunc(group, func(a, b *ngmodels.AlertRule) int {
+			if c := cmp.Compare(a.RuleGroupIndex, b.RuleGroupIndex); c != 0 {
+				return c
+			}
+			return strings.Compare(a.UID, b.UID)
+		})
+		ordered, err := ngmodels.GroupEvaluationOrder(group)
+		if err != nil {
+			// this is expected to never happen given that we validate the dependencies during alert rule updates
+			sch.log.Warn("Rules of the group cannot be evaluated in the order of their dependencies and will be evaluated independently", "group", groupKey.String(), "error", err)
+			continue
+		}
+		if ordered == nil {
+			continue
+		}
+		keys := make([]ngmodels.AlertRuleKey, 0, len(ordered))
+		for _, rule := range ordered {
+			keys = append(keys, rule.GetKey())
+		}
+		result[groupKey] = keys
+	}
+	return result
+}
+
+// evaluationSequences splits the rules that are ready to run into sequences of rules that are evaluated one after another.
+// The rules of a group with dependencies form one sequence in the evaluation order of the group. Any other rule is a
+// sequence of its own.
+func (sch *schedule) evaluationSequences(items []readyToRunItem) [][]readyToRunItem {
+	result := make([][]readyToRunItem, 0, len(items))
+	groups := make(map[ngmodels.AlertRuleGroupKey]int)
+	for _, item := range items {
+		groupKey := item.rule.GetGroupKey()
+		if _, ok := sch.evaluationOrder[groupKey]; !ok {
+			result = append(result, []readyToRunItem{item})
+			continue
+		}
+		idx, ok := groups[groupKey]
+		if !ok {
+			idx = len(result)
+			groups[groupKey] = idx
+			result = append(result, nil)
+		}
+		result[idx] = append(result[idx], item)
+	}
+	for groupKey, idx := range groups {
+		order := sch.evaluationOrder[groupKey]
+		slices.SortStableFunc(result[idx], func(a, b readyToRunItem) int {
+			return cmp.Compare(slices.Index(order, a.rule.GetKey()), slices.Index(order, b.rule.GetKey()))
+		})
+	}
+	return result
+}
+
+// evalSequence sends the first rule of the sequence to evaluation, and the next one once the evaluation is finished.
+func (sch *schedule) evalSequence(sequence []readyToRunItem, tick time.Time) {
+	if len(sequence) == 0 {
+		return
+	}
+	item := sequence[0]
+	next := sequence[1:]
+	if len(next) > 0 {
+		item.afterEval = func() {
+			// do not block the routine of the rule that has just been evaluated
+			go sch.evalSequence(next, tick)
+		}
+	}
+	if !sch.eval(&item, tick) {
+		sch.evalSequence(next, tick)
+	}
+}
+
+// eval sends the rule to its evaluation routine. Returns false if the routine is stopped.
+func (sch *schedule) eval(item *readyToRunItem, tick time.Time) bool {
+	key := item.rule.GetKey()
+	success, dropped := item.ruleRoutine.Eval(&item.Evaluation)
+	if dropped != nil && dropped != &item.Evaluation {
+		// the dropped evaluation will never run, let the rest of its sequence continue
+		dropped.done()
+	}
+	if !success {
+		sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
+		return false
+	}
+	if dropped != nil {
+		sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
+		orgID := fmt.Sprint(key.OrgID)
+		sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
+	}
+	return true
+}
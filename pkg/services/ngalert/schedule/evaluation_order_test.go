@@ -0,0 +1,173 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeSequencedRule struct {
+	key       models.AlertRuleKey
+	stopped   bool
+	evaluated chan<- models.AlertRuleKey
+}
+
+func (f *fakeSequencedRule) Run() error {
+	return nil
+}
+
+func (f *fakeSequencedRule) Stop(_ error) {}
+
+func (f *fakeSequencedRule) Eval(eval *Evaluation) (bool, *Evaluation) {
+	if f.stopped {
+		return false, nil
+	}
+	go func() {
+		f.evaluated <- f.key
+		eval.done()
+	}()
+	return true, nil
+}
+
+func (f *fakeSequencedRule) Update(_ RuleVersionAndPauseStatus) bool {
+	return true
+}
+
+// fakeDroppingRule never evaluates, it keeps the last evaluation and drops it when it receives the next one.
+type fakeDroppingRule struct {
+	fakeSequencedRule
+	pending *Evaluation
+}
+
+func (f *fakeDroppingRule) Eval(eval *Evaluation) (bool, *Evaluation) {
+	dropped := f.pending
+	f.pending = eval
+	return true, dropped
+}
+
+func withPromQuery(promQL string) models.AlertRuleMutator {
+	return func(rule *models.AlertRule) {
+		refID := rule.Condition
+		if rule.Record != nil {
+			refID = rule.Record.From
+		}
+		model, _ := json.Marshal(map[string]any{"refId": refID, "expr": promQL})
+		rule.Data = []models.AlertQuery{{RefID: refID, DatasourceUID: "prometheus", Model: model}}
+	}
+}
+
+func TestSchedule_evaluationOrder(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	sch.jitterEvaluations = JitterByRule
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	gen := models.RuleGen.With(models.RuleMuts.WithInterval(10*sch.baseInterval), models.RuleMuts.WithIsPaused(false))
+	groupKey := models.GenerateGroupKey(1)
+	inGroup := gen.With(models.RuleMuts.WithGroupKey(groupKey))
+	alerting := func(rule *models.AlertRule) {
+		rule.Record = nil
+	}
+	consumer := inGroup.With(alerting, models.RuleMuts.WithGroupIndex(1), withPromQuery("job:up:sum == 0")).GenerateRef()
+	recording := inGroup.With(models.RuleMuts.WithAllRecordingRules(), models.RuleMuts.WithMetric("job:up:sum"), models.RuleMuts.WithGroupIndex(2), withPromQuery("sum by (job) (up)")).GenerateRef()
+	independent := inGroup.With(alerting, models.RuleMuts.WithGroupIndex(3), withPromQuery("up == 0")).GenerateRef()
+	other := gen.With(models.RuleMuts.WithAllRecordingRules(), models.RuleMuts.WithMetric("job:up:sum"), withPromQuery("sum by (job) (up)")).GenerateManyRef(5)
+	ruleStore.PutRule(ctx, append([]*models.AlertRule{consumer, recording, independent}, other...)...)
+
+	tick := sch.clock.Now()
+	var groupSequences [][]readyToRunItem
+	for i := 0; i < 10; i++ {
+		tick = tick.Add(sch.baseInterval)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		for _, sequence := range sch.evaluationSequences(scheduled) {
+			if sequence[0].rule.GetGroupKey() == groupKey {
+				groupSequences = append(groupSequences, sequence)
+			}
+		}
+	}
+
+	require.Equal(t, map[models.AlertRuleGroupKey][]models.AlertRuleKey{
+		groupKey: {recording.GetKey(), consumer.GetKey(), independent.GetKey()},
+	}, sch.evaluationOrder, "only the group with dependencies should be ordered")
+	require.Lenf(t, groupSequences, 1, "rules of the group should be ready to run at the same tick")
+	keys := make([]models.AlertRuleKey, 0, len(groupSequences[0]))
+	for _, item := range groupSequences[0] {
+		keys = append(keys, item.rule.GetKey())
+	}
+	require.Equal(t, sch.evaluationOrder[groupKey], keys)
+}
+
+func TestSchedule_evalSequence(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	evaluated := make(chan models.AlertRuleKey)
+	rules := models.RuleGen.GenerateManyRef(4)
+	sequence := make([]readyToRunItem, 0, len(rules))
+	for i, rule := range rules {
+		sequence = append(sequence, readyToRunItem{
+			ruleRoutine: &fakeSequencedRule{key: rule.GetKey(), stopped: i == 1, evaluated: evaluated},
+			Evaluation:  Evaluation{scheduledAt: sch.clock.Now(), rule: rule},
+		})
+	}
+
+	go sch.evalSequence(sequence, sch.clock.Now())
+
+	expected := []models.AlertRuleKey{rules[0].GetKey(), rules[2].GetKey(), rules[3].GetKey()}
+	for _, key := range expected {
+		select {
+		case actual := <-evaluated:
+			require.Equal(t, key, actual)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("rule %s was not evaluated", key)
+		}
+	}
+	select {
+	case key := <-evaluated:
+		t.Fatalf("unexpected evaluation of rule %s", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSchedule_evalSequence_dropped(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	evaluated := make(chan models.AlertRuleKey)
+	rules := models.RuleGen.GenerateManyRef(3)
+	slow := &fakeDroppingRule{fakeSequencedRule: fakeSequencedRule{key: rules[0].GetKey()}}
+	sequenceAt := func(tick time.Time) []readyToRunItem {
+		sequence := []readyToRunItem{{ruleRoutine: slow, Evaluation: Evaluation{scheduledAt: tick, rule: rules[0]}}}
+		for _, rule := range rules[1:] {
+			sequence = append(sequence, readyToRunItem{
+				ruleRoutine: &fakeSequencedRule{key: rule.GetKey(), evaluated: evaluated},
+				Evaluation:  Evaluation{scheduledAt: tick, rule: rule},
+			})
+		}
+		return sequence
+	}
+
+	tick := sch.clock.Now()
+	sch.evalSequence(sequenceAt(tick), tick)
+	select {
+	case key := <-evaluated:
+		t.Fatalf("rule %s was evaluated before the first rule of the sequence", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the evaluation of the first rule at the next tick drops the pending one
+	next := tick.Add(sch.baseInterval)
+	sch.evalSequence(sequenceAt(next), next)
+	for _, key := range []models.AlertRuleKey{rules[1].GetKey(), rules[2].GetKey()} {
+		select {
+		case actual := <-evaluated:
+			require.Equal(t, key, actual)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("rule %s was not evaluated after the evaluation of the first rule was dropped", key)
+		}
+	}
+}
@@ -102,7 +102,9 @@ func (r *recordingRule) Run() error {
 			}
 			if !r.featureToggles.IsEnabled(ctx, featuremgmt.FlagGrafanaManagedRecordingRules) {
 				logger.Warn("Recording rule scheduled but toggle is not enabled. Skipping")
-				return nil
+				// keep receiving evaluations, otherwise the scheduler is blocked sending the next one
+				eval.done()
+				continue
 			}
 			// TODO: Skipping the "evalRunning" guard that the alert rule routine does, because it seems to be dead code and impossible to hit.
 			// TODO: Either implement me or remove from alert rules once investigated.
@@ -129,6 +131,7 @@ func (r *recordingRule) doEvaluate(ctx context.Context, ev *Evaluation) {
 		evalTotal.Inc()
 		evalDuration.Observe(r.clock.Now().Sub(evalStart).Seconds())
 		r.evaluationDoneTestHook(ev)
+		ev.done()
 	}()
 
 	if ev.rule.IsPaused {
@@ -15,7 +15,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
-var errRuleDeleted = errors.New("rule deleted")
+var (
+	errRuleDeleted  = errors.New("rule deleted")
+	errRuleReleased = errors.New("rule assigned to another instance")
+)
 
 type ruleFactory interface {
 	new(context.Context, *models.AlertRule) Rule
@@ -85,12 +88,22 @@ type Evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// afterEval, if set, is called by the rule routine when the evaluation is finished or skipped.
+	afterEval func()
 }
 
 func (e *Evaluation) Fingerprint() fingerprint {
 	return ruleWithFolder{e.rule, e.folderTitle}.Fingerprint()
 }
 
+// done calls afterEval. It must be called exactly once for every evaluation that is sent to a rule routine, including
+// the evaluations that are skipped or dropped, otherwise the rules evaluated after this one are not evaluated at this tick.
+func (e *Evaluation) done() {
+	if e.afterEval != nil {
+		e.afterEval()
+	}
+}
+
 type alertRulesRegistry struct {
 	rules        map[models.AlertRuleKey]*models.AlertRule
 	folderTitles map[models.FolderKey]string
@@ -306,6 +319,7 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(rule.ID)
 	writeInt(rule.OrgID)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
 	}
@@ -2,7 +2,6 @@ package schedule
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 	"slices"
 	"strings"
@@ -100,6 +99,14 @@ type schedule struct {
 	tracer tracing.Tracer
 
 	recordingWriter RecordingWriter
+
+	// evaluationOrder contains the order in which the rules of the groups, whose rules query the metrics recorded by
+	// other rules of the same group, are evaluated. It is updated every time the rules are fetched from the store.
+	evaluationOrder map[ngmodels.AlertRuleGroupKey][]ngmodels.AlertRuleKey
+
+	// sharder assigns the rules to the instances of the high availability cluster.
+	// It is nil if every instance evaluates all rules.
+	sharder *ruleSharder
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -119,6 +126,9 @@ type SchedulerCfg struct {
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 	RecordingWriter      RecordingWriter
+	// ClusterMembership, if set, makes the scheduler evaluate only the rules that are assigned to this instance by
+	// a consistent hash ring of the members of the cluster.
+	ClusterMembership ClusterMembership
 }
 
 // NewScheduler returns a new scheduler.
@@ -150,6 +160,10 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		recordingWriter:       cfg.RecordingWriter,
 	}
 
+	if cfg.ClusterMembership != nil {
+		sch.sharder = newRuleSharder(cfg.ClusterMembership, cfg.Metrics, cfg.Log)
+	}
+
 	return &sch
 }
 
@@ -194,6 +208,24 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	sch.updateRulesMetrics(alertRules)
 }
 
+// releaseAlertRule stops evaluation of rules that are assigned to other instances of the cluster. Unlike deleteAlertRule,
+// it keeps the rules schedulable and the state of the rules in the database, so that the other instances can continue
+// from it. Only the state of the rules that this instance evaluated is saved.
+func (sch *schedule) releaseAlertRule(ctx context.Context, keys map[ngmodels.AlertRuleKey]struct{}) {
+	for key := range keys {
+		ruleRoutine, ok := sch.registry.del(key)
+		if !ok {
+			// The rule was not evaluated by this instance but its state could have been loaded at startup. It is not
+			// saved because the instance that evaluates the rule could have saved a newer one.
+			sch.stateManager.ForgetStateByRuleUID(ctx, key)
+			continue
+		}
+		sch.log.Debug("Alert rule is assigned to another instance of the cluster, stopping evaluation", key.LogContext()...)
+		// The rule routine releases the state when it stops.
+		ruleRoutine.Stop(errRuleReleased)
+	}
+}
+
 func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
 	for {
@@ -248,6 +280,12 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	var assignment shardAssignment
+	if sch.sharder != nil {
+		assignment = sch.sharder.assign(tick, alertRules, sch.evaluationOrder)
+		alertRules = assignment.owned
+	}
+
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	missingFolder := make(map[string][]string)
@@ -282,7 +320,12 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 		if newRoutine && !invalidInterval {
+			_, acquired := assignment.acquired[key]
 			dispatcherGroup.Go(func() error {
+				if acquired {
+					// The rule was evaluated by another instance of the cluster until now. Continue from its state.
+					sch.stateManager.LoadStateByRule(ctx, item)
+				}
 				return ruleRoutine.Run()
 			})
 		}
@@ -295,7 +338,12 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		}
 
 		itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-		offset := jitterOffsetInTicks(item, sch.baseInterval, sch.jitterEvaluations)
+		jitterStrategy := sch.jitterEvaluations
+		if _, ok := sch.evaluationOrder[item.GetGroupKey()]; ok && jitterStrategy == JitterByRule {
+			// rules that depend on each other must be ready to run at the same tick
+			jitterStrategy = JitterByGroup
+		}
+		offset := jitterOffsetInTicks(item, sch.baseInterval, jitterStrategy)
 		isReadyToRun := item.IntervalSeconds != 0 && (tickNum%itemFrequency)-offset == 0
 
 		var folderTitle string
@@ -339,32 +387,31 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
-	var step int64 = 0
-	if len(readyToRun) > 0 {
-		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
-	}
-
 	slices.SortFunc(readyToRun, func(a, b readyToRunItem) int {
 		return strings.Compare(a.rule.UID, b.rule.UID)
 	})
-	for i := range readyToRun {
-		item := readyToRun[i]
+	// rules of a group that query the metrics recorded by other rules of the group are evaluated one after another
+	sequences := sch.evaluationSequences(readyToRun)
+
+	var step int64 = 0
+	if len(sequences) > 0 {
+		step = sch.baseInterval.Nanoseconds() / int64(len(sequences))
+	}
+
+	for i := range sequences {
+		sequence := sequences[i]
 
 		time.AfterFunc(time.Duration(int64(i)*step), func() {
-			key := item.rule.GetKey()
-			success, dropped := item.ruleRoutine.Eval(&item.Evaluation)
-			if !success {
-				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
-				return
-			}
-			if dropped != nil {
-				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
-				orgID := fmt.Sprint(key.OrgID)
-				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
-			}
+			sch.evalSequence(sequence, tick)
 		})
 	}
 
+	// stop evaluating the rules that are assigned to other instances of the cluster
+	sch.releaseAlertRule(ctx, assignment.released)
+	for key := range assignment.released {
+		delete(registeredDefinitions, key)
+	}
+
 	// unregister and stop routines of the deleted alert rules
 	toDelete := make([]ngmodels.AlertRuleKey, 0, len(registeredDefinitions))
 	for key := range registeredDefinitions {
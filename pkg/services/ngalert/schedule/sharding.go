@@ -0,0 +1,203 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ClusterMembership provides the members of the high availability cluster that rule evaluation is sharded across.
+type ClusterMembership interface {
+	// Name returns the name of this instance in the cluster.
+	Name() string
+	// Members returns the names of the instances that are currently members of the cluster.
+	Members() []string
+}
+
+// ringTokensPerMember is the number of points of the hash ring that each member owns.
+// The more points, the more evenly the rules are distributed.
+const ringTokensPerMember = 128
+
+// hashRing is a consistent hash ring. A key is assigned to the member that owns the first point of the ring at or
+// after the hash of the key. When a member joins or leaves the ring, only the keys assigned to that member move.
+type hashRing struct {
+	members []string
+	tokens  []ringToken
+}
+
+type ringToken struct {
+	hash   uint64
+	member string
+}
+
+func newHashRing(members []string) *hashRing {
+	r := &hashRing{
+		members: members,
+		tokens:  make([]ringToken, 0, len(members)*ringTokensPerMember),
+	}
+	for _, member := range members {
+		for i := 0; i < ringTokensPerMember; i++ {
+			r.tokens = append(r.tokens, ringToken{hash: ringHash(member + "-" + strconv.Itoa(i)), member: member})
+		}
+	}
+	sort.Slice(r.tokens, func(i, j int) bool {
+		if r.tokens[i].hash == r.tokens[j].hash {
+			return r.tokens[i].member < r.tokens[j].member
+		}
+		return r.tokens[i].hash < r.tokens[j].hash
+	})
+	return r
+}
+
+// owner returns the member that the key is assigned to, or an empty string if the ring has no members.
+func (r *hashRing) owner(key ngmodels.AlertRuleKey) string {
+	return r.ownerOf(strconv.FormatInt(key.OrgID, 10) + "/" + key.UID)
+}
+
+// groupOwner returns the member that the group is assigned to, or an empty string if the ring has no members.
+func (r *hashRing) groupOwner(key ngmodels.AlertRuleGroupKey) string {
+	return r.ownerOf(strconv.FormatInt(key.OrgID, 10) + "/" + key.NamespaceUID + "/" + key.RuleGroup)
+}
+
+func (r *hashRing) ownerOf(s string) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := ringHash(s)
+	i := sort.Search(len(r.tokens), func(i int) bool {
+		return r.tokens[i].hash >= h
+	})
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.tokens[i].member
+}
+
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	// FNV does not spread similar strings well, so the hash is mixed with the finalizer of MurmurHash3.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// shardAssignment describes which of the schedulable rules this instance evaluates.
+type shardAssignment struct {
+	// owned are the rules that are assigned to this instance.
+	owned []*ngmodels.AlertRule
+	// released are the rules that were assigned to this instance at the previous tick, or whose state was loaded
+	// at startup, and are now assigned to another instance.
+	released map[ngmodels.AlertRuleKey]struct{}
+	// acquired are the rules that were assigned to another instance and have been assigned to this instance for one
+	// interval of the rule, so this instance starts evaluating them at this tick.
+	acquired map[ngmodels.AlertRuleKey]struct{}
+}
+
+// ruleSharder assigns every rule to one instance of the cluster, so that each rule is evaluated only once
+// instead of by every instance. It is not safe for concurrent use and is only used by the scheduler loop.
+type ruleSharder struct {
+	membership ClusterMembership
+	metrics    *metrics.Scheduler
+	log        log.Logger
+
+	ring *hashRing
+	self string
+	// notOwned are the rules that were not evaluated by this instance at the previous tick.
+	notOwned map[ngmodels.AlertRuleKey]struct{}
+	// acquiring are the rules that are assigned to this instance but are not evaluated by it yet, and the ticks at
+	// which they were assigned.
+	acquiring map[ngmodels.AlertRuleKey]time.Time
+}
+
+func newRuleSharder(membership ClusterMembership, metrics *metrics.Scheduler, logger log.Logger) *ruleSharder {
+	return &ruleSharder{
+		membership: membership,
+		metrics:    metrics,
+		log:        logger,
+	}
+}
+
+// refresh rebuilds the ring if the members of the cluster have changed since the last call.
+func (s *ruleSharder) refresh() {
+	s.self = s.membership.Name()
+	members := slices.Clone(s.membership.Members())
+	// This instance keeps evaluating rules if the cluster does not know about it yet or the members cannot be fetched.
+	// Some rules can be evaluated twice until the membership converges, which is better than not evaluating them at all.
+	if !slices.Contains(members, s.self) {
+		members = append(members, s.self)
+	}
+	sort.Strings(members)
+	members = slices.Compact(members)
+	if s.ring != nil && slices.Equal(s.ring.members, members) {
+		return
+	}
+	if s.ring != nil {
+		s.log.Info("Members of the cluster have changed, reassigning rules", "previous", s.ring.members, "current", members)
+		s.metrics.ShardRebalances.Inc()
+	} else {
+		s.log.Info("Sharding rule evaluation across the members of the cluster", "members", members, "self", s.self)
+	}
+	s.ring = newHashRing(members)
+	s.metrics.ShardMembers.Set(float64(len(members)))
+}
+
+// assign refreshes the ring and splits the rules into the rules that this instance evaluates and the rules that other
+// instances evaluate. The rules of the groups that are evaluated in order are assigned to the same instance.
+//
+// A rule that is taken over from another instance is evaluated only after one interval of the rule. This gives the
+// other instance the time to see the same members, stop evaluating the rule and save its state, so that the rule is
+// not evaluated by both instances and this instance continues from the last evaluation.
+func (s *ruleSharder) assign(tick time.Time, rules []*ngmodels.AlertRule, evaluationOrder map[ngmodels.AlertRuleGroupKey][]ngmodels.AlertRuleKey) shardAssignment {
+	s.refresh()
+
+	result := shardAssignment{
+		owned:    make([]*ngmodels.AlertRule, 0, len(rules)),
+		released: make(map[ngmodels.AlertRuleKey]struct{}),
+		acquired: make(map[ngmodels.AlertRuleKey]struct{}),
+	}
+	notOwned := make(map[ngmodels.AlertRuleKey]struct{}, len(rules))
+	acquiring := make(map[ngmodels.AlertRuleKey]time.Time)
+	for _, rule := range rules {
+		key := rule.GetKey()
+		_, wasNotOwned := s.notOwned[key]
+		owner := s.ring.owner(key)
+		if _, ok := evaluationOrder[rule.GetGroupKey()]; ok {
+			owner = s.ring.groupOwner(rule.GetGroupKey())
+		}
+		if owner == s.self {
+			if wasNotOwned {
+				since, ok := s.acquiring[key]
+				if !ok {
+					since = tick
+				}
+				if tick.Sub(since) < time.Duration(rule.IntervalSeconds)*time.Second {
+					acquiring[key] = since
+					notOwned[key] = struct{}{}
+					continue
+				}
+				result.acquired[key] = struct{}{}
+			}
+			result.owned = append(result.owned, rule)
+			continue
+		}
+		notOwned[key] = struct{}{}
+		if !wasNotOwned {
+			result.released[key] = struct{}{}
+		}
+	}
+	s.notOwned = notOwned
+	s.acquiring = acquiring
+	s.metrics.ShardOwnedRules.Set(float64(len(result.owned)))
+	return result
+}
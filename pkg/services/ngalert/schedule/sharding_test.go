@@ -0,0 +1,231 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeClusterMembership struct {
+	name    string
+	members []string
+}
+
+func (f *fakeClusterMembership) Name() string {
+	return f.name
+}
+
+func (f *fakeClusterMembership) Members() []string {
+	return f.members
+}
+
+func TestHashRing(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+	assign := func(r *hashRing) map[models.AlertRuleKey]string {
+		result := make(map[models.AlertRuleKey]string, len(keys))
+		for _, key := range keys {
+			result[key] = r.owner(key)
+		}
+		return result
+	}
+
+	t.Run("distributes keys evenly", func(t *testing.T) {
+		counts := map[string]int{}
+		for _, owner := range assign(newHashRing([]string{"a", "b", "c"})) {
+			counts[owner]++
+		}
+		require.Len(t, counts, 3)
+		for member, count := range counts {
+			require.InDeltaf(t, len(keys)/3, count, float64(len(keys))*0.1, "member %s owns %d keys", member, count)
+		}
+	})
+
+	t.Run("moves only the keys of the members that join or leave", func(t *testing.T) {
+		before := assign(newHashRing([]string{"a", "b", "c"}))
+		joined := assign(newHashRing([]string{"a", "b", "c", "d"}))
+		for key, owner := range joined {
+			if owner != before[key] {
+				require.Equal(t, "d", owner)
+			}
+		}
+		left := assign(newHashRing([]string{"a", "c"}))
+		for key, owner := range before {
+			if owner != "b" {
+				require.Equal(t, owner, left[key])
+			}
+		}
+	})
+
+	t.Run("is deterministic", func(t *testing.T) {
+		require.Equal(t, assign(newHashRing([]string{"a", "b"})), assign(newHashRing([]string{"a", "b"})))
+	})
+
+	t.Run("has no owner without members", func(t *testing.T) {
+		require.Empty(t, newHashRing(nil).owner(keys[0]))
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	gen := models.RuleGen
+	interval := 10 * time.Second
+	rules := gen.With(gen.WithInterval(interval)).GenerateManyRef(100)
+	membership := &fakeClusterMembership{name: "a", members: []string{"a", "b"}}
+	reg := prometheus.NewPedanticRegistry()
+	sharder := newRuleSharder(membership, metrics.NewSchedulerMetrics(reg), log.NewNopLogger())
+	tick := time.Now()
+
+	first := sharder.assign(tick, rules, nil)
+	require.NotEmpty(t, first.owned)
+	require.Less(t, len(first.owned), len(rules))
+	require.Len(t, first.released, len(rules)-len(first.owned))
+	require.Empty(t, first.acquired)
+	for _, rule := range first.owned {
+		require.NotContains(t, first.released, rule.GetKey())
+	}
+
+	t.Run("does not release the same rules again", func(t *testing.T) {
+		tick = tick.Add(interval)
+		second := sharder.assign(tick, rules, nil)
+		require.Equal(t, first.owned, second.owned)
+		require.Empty(t, second.released)
+		require.Empty(t, second.acquired)
+	})
+
+	t.Run("acquires the rules of the members that leave after one interval", func(t *testing.T) {
+		membership.members = []string{"a"}
+		tick = tick.Add(interval)
+		result := sharder.assign(tick, rules, nil)
+		require.Equal(t, first.owned, result.owned, "acquired rules should not be evaluated before one interval")
+		require.Empty(t, result.acquired)
+		require.Empty(t, result.released)
+
+		tick = tick.Add(interval / 2)
+		result = sharder.assign(tick, rules, nil)
+		require.Equal(t, first.owned, result.owned)
+		require.Empty(t, result.acquired)
+
+		tick = tick.Add(interval / 2)
+		result = sharder.assign(tick, rules, nil)
+		require.Len(t, result.owned, len(rules))
+		require.Equal(t, first.released, result.acquired)
+		require.Empty(t, result.released)
+		require.Equal(t, float64(1), testutil.ToFloat64(sharder.metrics.ShardRebalances))
+		require.Equal(t, float64(1), testutil.ToFloat64(sharder.metrics.ShardMembers))
+		require.Equal(t, float64(len(rules)), testutil.ToFloat64(sharder.metrics.ShardOwnedRules))
+	})
+
+	t.Run("releases the rules of the members that join", func(t *testing.T) {
+		membership.members = []string{"a", "b"}
+		tick = tick.Add(interval)
+		result := sharder.assign(tick, rules, nil)
+		require.Equal(t, first.owned, result.owned)
+		require.Equal(t, first.released, result.released)
+		require.Empty(t, result.acquired)
+	})
+
+	t.Run("assigns the rules of the groups evaluated in order to the same instance", func(t *testing.T) {
+		membership.members = []string{"a", "b", "c"}
+		groupKey := models.GenerateGroupKey(1)
+		group := gen.With(gen.WithGroupKey(groupKey)).GenerateManyRef(20)
+		order := map[models.AlertRuleGroupKey][]models.AlertRuleKey{groupKey: nil}
+		result := sharder.assign(tick, group, order)
+		if sharder.ring.groupOwner(groupKey) == "a" {
+			require.Len(t, result.owned, len(group))
+		} else {
+			require.Empty(t, result.owned)
+		}
+	})
+
+	t.Run("includes itself if the cluster does not know about it yet", func(t *testing.T) {
+		membership.members = nil
+		result := sharder.assign(tick, rules, nil)
+		require.Len(t, result.owned, len(rules))
+		require.Equal(t, []string{"a"}, sharder.ring.members)
+	})
+}
+
+func TestSchedule_sharding(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	membership := &fakeClusterMembership{name: "a", members: []string{"a", "b"}}
+	sch.sharder = newRuleSharder(membership, sch.metrics, log.NewNopLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	gen := models.RuleGen
+	rules := gen.With(gen.WithInterval(sch.baseInterval)).GenerateManyRef(20)
+	ruleStore.PutRule(ctx, rules...)
+	owners := newHashRing([]string{"a", "b"})
+	var owned, notOwned []models.AlertRuleKey
+	for _, rule := range rules {
+		if owners.owner(rule.GetKey()) == "a" {
+			owned = append(owned, rule.GetKey())
+		} else {
+			notOwned = append(notOwned, rule.GetKey())
+		}
+	}
+	require.NotEmpty(t, owned)
+	require.NotEmpty(t, notOwned)
+	scheduledKeys := func(items []readyToRunItem) []models.AlertRuleKey {
+		result := make([]models.AlertRuleKey, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.rule.GetKey())
+		}
+		return result
+	}
+
+	tick := sch.clock.Now()
+	t.Run("evaluates only the rules assigned to this instance", func(t *testing.T) {
+		tick = tick.Add(sch.baseInterval)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.ElementsMatch(t, owned, scheduledKeys(scheduled))
+		require.Empty(t, stopped)
+	})
+
+	t.Run("evaluates all rules one interval after the other instance leaves", func(t *testing.T) {
+		membership.members = []string{"a"}
+		tick = tick.Add(sch.baseInterval)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.ElementsMatchf(t, owned, scheduledKeys(scheduled), "the other instance can still be evaluating its rules")
+		require.Empty(t, stopped)
+
+		tick = tick.Add(sch.baseInterval)
+		scheduled, stopped, _ = sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, len(rules))
+		require.Empty(t, stopped)
+	})
+
+	t.Run("stops evaluating the rules assigned to an instance that joins", func(t *testing.T) {
+		routines := make(map[models.AlertRuleKey]Rule, len(notOwned))
+		for _, key := range notOwned {
+			routine, created := sch.registry.getOrCreate(ctx, ruleStore.rules[key.UID], ruleFactoryFromScheduler(sch))
+			require.False(t, created)
+			routines[key] = routine
+		}
+
+		membership.members = []string{"a", "b"}
+		tick = tick.Add(sch.baseInterval)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.ElementsMatch(t, owned, scheduledKeys(scheduled))
+		require.Emptyf(t, stopped, "released rules must not be deleted")
+		for key, routine := range routines {
+			require.ErrorIs(t, routine.(*alertRule).ctx.Err(), errRuleReleased)
+			require.False(t, sch.registry.exists(key))
+			require.NotNil(t, sch.schedulableAlertRules.get(key))
+		}
+	})
+}
@@ -72,11 +72,11 @@ func (c *cache) countAlertsBy(state eval.State) float64 {
 	return count
 }
 
-func (c *cache) getOrCreate(ctx context.Context, log log.Logger, alertRule *ngModels.AlertRule, result eval.Result, extraLabels data.Labels, externalURL *url.URL) *State {
+func (c *cache) getOrCreate(ctx context.Context, log log.Logger, alertRule *ngModels.AlertRule, result eval.Result, extraLabels data.Labels, externalURL *url.URL, queryFunc template.QueryFunc) *State {
 	// Calculation of state ID involves label and annotation expansion, which may be resource intensive operations, and doing it in the context guarded by mtxStates may create a lot of contention.
 	// Instead of just calculating ID we create an entire state - a candidate. If rule states already hold a state with this ID, this candidate will be discarded and the existing one will be returned.
 	// Otherwise, this candidate will be added to the rule states and returned.
-	stateCandidate := calculateState(ctx, log, alertRule, result, extraLabels, externalURL)
+	stateCandidate := calculateState(ctx, log, alertRule, result, extraLabels, externalURL, queryFunc)
 
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
@@ -120,7 +120,7 @@ func (rs *ruleStates) getOrAdd(stateCandidate State) *State {
 	return state
 }
 
-func calculateState(ctx context.Context, log log.Logger, alertRule *ngModels.AlertRule, result eval.Result, extraLabels data.Labels, externalURL *url.URL) State {
+func calculateState(ctx context.Context, log log.Logger, alertRule *ngModels.AlertRule, result eval.Result, extraLabels data.Labels, externalURL *url.URL, queryFunc template.QueryFunc) State {
 	var reserved []string
 	resultLabels := result.Instance
 	if len(resultLabels) > 0 {
@@ -150,12 +150,18 @@ func calculateState(ctx context.Context, log log.Logger, alertRule *ngModels.Ale
 	}
 	// Merge both the extra labels and the labels from the evaluation into a common set
 	// of labels that can be expanded in custom labels and annotations.
-	templateData := template.NewData(mergeLabels(extraLabels, resultLabels), result)
+	templateLabels := mergeLabels(extraLabels, resultLabels)
+	if result.Severity != "" {
+		// the severity is available to templates, but it is not one of the labels of the state
+		// so that a change of severity does not create a new alert instance
+		templateLabels[ngModels.SeverityLabel] = result.Severity
+	}
+	templateData := template.NewData(templateLabels, result)
 
 	// For now, do nothing with these errors as they are already logged in expand.
 	// In the future, we want to show these errors to the user somehow.
-	labels, _ := expand(ctx, log, alertRule.Title, alertRule.Labels, templateData, externalURL, result.EvaluatedAt)
-	annotations, _ := expand(ctx, log, alertRule.Title, alertRule.Annotations, templateData, externalURL, result.EvaluatedAt)
+	labels, _ := expand(ctx, log, alertRule.Title, alertRule.Labels, templateData, externalURL, result.EvaluatedAt, queryFunc)
+	annotations, _ := expand(ctx, log, alertRule.Title, alertRule.Annotations, templateData, externalURL, result.EvaluatedAt, queryFunc)
 
 	values := make(map[string]float64)
 	for refID, v := range result.Values {
@@ -208,6 +214,7 @@ func calculateState(ctx context.Context, log log.Logger, alertRule *ngModels.Ale
 		OrgID:              alertRule.OrgID,
 		CacheID:            cacheID,
 		Labels:             lbs,
+		Severity:           result.Severity,
 		Annotations:        annotations,
 		EvaluationDuration: result.EvaluationDuration,
 		Values:             values,
@@ -222,13 +229,13 @@ func calculateState(ctx context.Context, log log.Logger, alertRule *ngModels.Ale
 // If a template cannot be expanded due to an error in the template the original template is
 // maintained and an error is added to the multierror. All errors in the multierror are
 // template.ExpandError errors.
-func expand(ctx context.Context, log log.Logger, name string, original map[string]string, data template.Data, externalURL *url.URL, evaluatedAt time.Time) (map[string]string, error) {
+func expand(ctx context.Context, log log.Logger, name string, original map[string]string, data template.Data, externalURL *url.URL, evaluatedAt time.Time, queryFunc template.QueryFunc) (map[string]string, error) {
 	var (
 		errs     error
 		expanded = make(map[string]string, len(original))
 	)
 	for k, v := range original {
-		result, err := template.Expand(ctx, name, v, data, externalURL, evaluatedAt)
+		result, err := template.Expand(ctx, name, v, data, externalURL, evaluatedAt, queryFunc)
 		if err != nil {
 			log.Error("Error in expanding template", "error", err)
 			errs = errors.Join(errs, err)
@@ -268,6 +275,16 @@ func (c *cache) setAllStates(newStates map[int64]map[string]*ruleStates) {
 	c.states = newStates
 }
 
+// setRuleStates replaces the states of the rule.
+func (c *cache) setRuleStates(orgID int64, ruleUID string, states map[data.Fingerprint]*State) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[orgID]; !ok {
+		c.states[orgID] = make(map[string]*ruleStates)
+	}
+	c.states[orgID][ruleUID] = &ruleStates{states: states}
+}
+
 func (c *cache) set(entry *State) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
@@ -377,6 +394,8 @@ func (c *cache) asInstances(skipNormalState bool) []ngModels.AlertInstance {
 					CurrentStateSince: v2.StartsAt,
 					CurrentStateEnd:   v2.EndsAt,
 					ResultFingerprint: v2.ResultFingerprint.String(),
+					Severity:          v2.Severity,
+					KeepFiringSince:   v2.KeepFiringSince,
 				})
 			}
 		}
@@ -28,7 +28,7 @@ func Test_expand(t *testing.T) {
 	// If the expand function forgets to use ErrorOrNil() then the error returned will
 	// be non-nil even if no errors have been added to the multierror.
 	t.Run("err is nil if there are no errors", func(t *testing.T) {
-		result, err := expand(ctx, logger, "test", map[string]string{}, template.Data{}, nil, time.Now())
+		result, err := expand(ctx, logger, "test", map[string]string{}, template.Data{}, nil, time.Now(), nil)
 		require.NoError(t, err)
 		require.Len(t, result, 0)
 	})
@@ -37,7 +37,7 @@ func Test_expand(t *testing.T) {
 		original := map[string]string{"Summary": `Instance {{ $labels.instance }} has been down for more than 5 minutes`}
 		expected := map[string]string{"Summary": "Instance host1 has been down for more than 5 minutes"}
 		data := template.Data{Labels: map[string]string{"instance": "host1"}}
-		results, err := expand(ctx, logger, "test", original, data, nil, time.Now())
+		results, err := expand(ctx, logger, "test", original, data, nil, time.Now(), nil)
 		require.NoError(t, err)
 		require.Equal(t, expected, results)
 	})
@@ -47,7 +47,7 @@ func Test_expand(t *testing.T) {
 			"Summary": `Instance {{ $labels. }} has been down for more than 5 minutes`,
 		}
 		data := template.Data{Labels: map[string]string{"instance": "host1"}}
-		results, err := expand(ctx, logger, "test", original, data, nil, time.Now())
+		results, err := expand(ctx, logger, "test", original, data, nil, time.Now(), nil)
 		require.NotNil(t, err)
 		require.Equal(t, original, results)
 
@@ -63,7 +63,7 @@ func Test_expand(t *testing.T) {
 			"Description": "The instance has been down for {{ $value minutes, please check the instance is online",
 		}
 		data := template.Data{Labels: map[string]string{"instance": "host1"}}
-		results, err := expand(ctx, logger, "test", original, data, nil, time.Now())
+		results, err := expand(ctx, logger, "test", original, data, nil, time.Now(), nil)
 		require.NotNil(t, err)
 		require.Equal(t, original, results)
 
@@ -100,7 +100,7 @@ func Test_expand(t *testing.T) {
 			"Description": "The instance has been down for {{ $value minutes, please check the instance is online",
 		}
 		data := template.Data{Labels: map[string]string{"instance": "host1"}}
-		results, err := expand(ctx, logger, "test", original, data, nil, time.Now())
+		results, err := expand(ctx, logger, "test", original, data, nil, time.Now(), nil)
 		require.NotNil(t, err)
 		require.Equal(t, expected, results)
 
@@ -136,7 +136,7 @@ func Test_getOrCreate(t *testing.T) {
 		result := eval.Result{
 			Instance: models.GenerateAlertLabels(5, "result-"),
 		}
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url, nil)
 		for key, expected := range extraLabels {
 			require.Equal(t, expected, state.Labels[key])
 		}
@@ -164,7 +164,7 @@ func Test_getOrCreate(t *testing.T) {
 			result.Instance[key] = "result-" + util.GenerateShortUID()
 		}
 
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url, nil)
 		for key, expected := range extraLabels {
 			require.Equal(t, expected, state.Labels[key])
 		}
@@ -180,11 +180,32 @@ func Test_getOrCreate(t *testing.T) {
 		for key := range rule.Labels {
 			result.Instance[key] = "result-" + util.GenerateShortUID()
 		}
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url, nil)
 		for key, expected := range rule.Labels {
 			require.Equal(t, expected, state.Labels[key])
 		}
 	})
+	t.Run("severity of the result should not change the alert instance", func(t *testing.T) {
+		rule := generateRule()
+		rule.Annotations = map[string]string{"summary": "{{ $labels." + models.SeverityLabel + " }}"}
+
+		result := eval.Result{
+			Instance: models.GenerateAlertLabels(5, "result-"),
+			Severity: "warning",
+		}
+		extraLabels := models.GenerateAlertLabels(2, "extra-")
+
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url, nil)
+		require.Equal(t, "warning", state.Severity)
+		require.NotContains(t, state.Labels, models.SeverityLabel)
+		require.Equal(t, "warning", state.Annotations["summary"])
+
+		result.Severity = "critical"
+		other := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url, nil)
+		require.Same(t, state, other, "an escalation should keep the same alert instance")
+		require.Equal(t, state.CacheID, other.CacheID)
+		require.Equal(t, "critical", other.Annotations["summary"])
+	})
 	t.Run("rule labels should be able to be expanded with result and extra labels", func(t *testing.T) {
 		result := eval.Result{
 			Instance: models.GenerateAlertLabels(5, "result-"),
@@ -202,7 +223,7 @@ func Test_getOrCreate(t *testing.T) {
 		}
 		rule.Labels = labelTemplates
 
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url, nil)
 		for key, expected := range extraLabels {
 			assert.Equal(t, expected, state.Labels["rule-"+key])
 		}
@@ -229,7 +250,7 @@ func Test_getOrCreate(t *testing.T) {
 		}
 		rule.Annotations = annotationTemplates
 
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url, nil)
 		for key, expected := range extraLabels {
 			assert.Equal(t, expected, state.Annotations["rule-"+key])
 		}
@@ -248,7 +269,7 @@ func Test_getOrCreate(t *testing.T) {
 		}
 		rule := generateRule()
 
-		state := c.getOrCreate(context.Background(), l, rule, result, nil, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, nil, url, nil)
 		assert.Equal(t, map[string]float64{"A": 1, "B": 2}, state.Values)
 	})
 
@@ -262,7 +283,7 @@ func Test_getOrCreate(t *testing.T) {
 		}
 		rule := generateRule()
 
-		state := c.getOrCreate(context.Background(), l, rule, result, nil, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, nil, url, nil)
 		assert.Equal(t, map[string]float64{"B0": 1, "B1": 2}, state.Values)
 	})
 
@@ -288,7 +309,7 @@ func Test_getOrCreate(t *testing.T) {
 
 		rule := generateRule()
 
-		state := c.getOrCreate(context.Background(), l, rule, result, nil, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, nil, url, nil)
 
 		for key := range models.LabelsUserCannotSpecify {
 			assert.NotContains(t, state.Labels, key)
@@ -310,7 +331,7 @@ func Test_getOrCreate(t *testing.T) {
 			result.Instance["label1_user"] = uuid.NewString()
 			result.Instance["label4_user"] = uuid.NewString()
 
-			state = c.getOrCreate(context.Background(), l, rule, result, nil, url)
+			state = c.getOrCreate(context.Background(), l, rule, result, nil, url, nil)
 			assert.NotContains(t, state.Labels, "__label1__")
 			assert.Contains(t, state.Labels, "label1")
 			assert.Equal(t, state.Labels["label1"], result.Instance["label1"])
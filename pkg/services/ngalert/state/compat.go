@@ -17,6 +17,7 @@ import (
 
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 const (
@@ -29,6 +30,7 @@ const (
 // StateToPostableAlert converts a state to a model that is accepted by Alertmanager. Annotations and Labels are copied from the state.
 // - if state has at least one result, a new label '__value_string__' is added to the label set
 // - the alert's GeneratorURL is constructed to point to the alert detail view
+// - if state has a severity, it is added as the label ngModels.SeverityLabel
 // - if evaluation state is either NoData or Error, the resulting set of labels is changed:
 //   - original alert name (label: model.AlertNameLabel) is backed up to OriginalAlertName
 //   - label model.AlertNameLabel is overwritten to either NoDataAlertName or ErrorAlertName
@@ -37,6 +39,10 @@ func StateToPostableAlert(transition StateTransition, appURL *url.URL) *models.P
 	nL := alertState.Labels.Copy()
 	nA := data.Labels(alertState.Annotations).Copy()
 
+	if alertState.Severity != "" {
+		nL[ngModels.SeverityLabel] = alertState.Severity
+	}
+
 	// encode the values as JSON where it will be expanded later
 	if len(alertState.Values) > 0 {
 		if b, err := json.Marshal(alertState.Values); err == nil {
@@ -98,6 +104,22 @@ func StateToPostableAlert(transition StateTransition, appURL *url.URL) *models.P
 	}
 }
 
+// SeverityChangeStoppedAlert returns an alert that expires the alert that was sent with the previous severity of a
+// firing state, or nil if the severity did not change. The Alertmanager identifies alerts by their labels, so a change of
+// the severity label would otherwise leave the alert with the previous severity firing until it expires.
+func SeverityChangeStoppedAlert(transition StateTransition, appURL *url.URL, clock clock.Clock) *models.PostableAlert {
+	if transition.PreviousState != eval.Alerting || transition.PreviousSeverity == "" || transition.PreviousSeverity == transition.Severity {
+		return nil
+	}
+	previous := *transition.State
+	previous.Severity = transition.PreviousSeverity
+	previous.State = eval.Alerting
+	previous.ResolvedAt = nil
+	alert := StateToPostableAlert(StateTransition{State: &previous}, appURL)
+	alert.EndsAt = strfmt.DateTime(clock.Now())
+	return alert
+}
+
 // NoDataAlert is a special alert sent by Grafana to the Alertmanager, that indicates we received no data from the datasource.
 // It effectively replaces the legacy behavior of "Keep Last State" by separating the regular alerting flow from the no data scenario into a separate alerts.
 // The Alert is defined as:
@@ -311,6 +311,36 @@ func Test_FromAlertsStateToStoppedAlert(t *testing.T) {
 	require.Equal(t, expected, result.PostableAlerts)
 }
 
+func Test_SeverityChangeStoppedAlert(t *testing.T) {
+	appURL := &url.URL{Scheme: "http:", Host: "localhost"}
+	clk := clock.NewMock()
+	clk.Set(time.Now())
+
+	transition := randomTransition(eval.Alerting, eval.Alerting)
+	transition.Severity = "critical"
+	transition.PreviousSeverity = "warning"
+
+	alert := StateToPostableAlert(transition, appURL)
+	require.Equal(t, "critical", alert.Labels[ngModels.SeverityLabel])
+
+	stopped := SeverityChangeStoppedAlert(transition, appURL, clk)
+	require.NotNil(t, stopped)
+	require.Equal(t, "warning", stopped.Labels[ngModels.SeverityLabel])
+	require.Equal(t, strfmt.DateTime(clk.Now()), stopped.EndsAt)
+	delete(stopped.Labels, ngModels.SeverityLabel)
+	delete(alert.Labels, ngModels.SeverityLabel)
+	require.Equal(t, alert.Labels, stopped.Labels, "the stopped alert should differ only by severity")
+	require.Equal(t, "critical", transition.Severity, "the state should not change")
+
+	transition.PreviousSeverity = "critical"
+	require.Nil(t, SeverityChangeStoppedAlert(transition, appURL, clk), "severity did not change")
+
+	transition = randomTransition(eval.Pending, eval.Alerting)
+	transition.Severity = "critical"
+	transition.PreviousSeverity = "warning"
+	require.Nil(t, SeverityChangeStoppedAlert(transition, appURL, clk), "the previous severity was not sent")
+}
+
 func randomMapOfStrings() map[string]string {
 	max := 5
 	result := make(map[string]string, max)
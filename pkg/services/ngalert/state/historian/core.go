@@ -1,6 +1,7 @@
 package historian
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -74,6 +75,44 @@ func labelFingerprint(labels data.Labels) string {
 	return fmt.Sprintf("%016x", sig)
 }
 
+// historyEntry is a state history entry with the labels of the stream or series it was read from.
+type historyEntry struct {
+	t      time.Time
+	entry  LokiEntry
+	labels map[string]string
+}
+
+// entriesToFrame formats state history entries, sorted by time, into a dataframe in the same format as
+// the results of Loki queries:
+//  1. `time` - timestamp - when the transition happened
+//  2. `line` - JSON - the full data of the transition
+//  3. `labels` - JSON - the labels associated with that state transition
+func entriesToFrame(entries []historyEntry) (*data.Frame, error) {
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		line, err := json.Marshal(e.entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize entry: %w", err)
+		}
+		lbls, err := json.Marshal(e.labels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize labels: %w", err)
+		}
+		times = append(times, e.t)
+		lines = append(lines, line)
+		labels = append(labels, lbls)
+	}
+
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
+
 // PanelKey uniquely identifies a panel.
 type PanelKey struct {
 	orgID   int64
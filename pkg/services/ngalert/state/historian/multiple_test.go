@@ -55,6 +55,30 @@ func TestMultipleBackend(t *testing.T) {
 		require.ErrorContains(t, err, "error one")
 		require.ErrorContains(t, err, "error two")
 	})
+
+	t.Run("runs backends with background work", func(t *testing.T) {
+		one := &fakeBackend{}
+		two := &fakeRunnerBackend{}
+		fan := NewMultipleBackend(one, two)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := fan.Run(ctx)
+
+		require.NoError(t, err)
+		require.True(t, two.ran)
+	})
+}
+
+type fakeRunnerBackend struct {
+	fakeBackend
+	ran bool
+}
+
+func (f *fakeRunnerBackend) Run(ctx context.Context) error {
+	f.ran = true
+	<-ctx.Done()
+	return nil
 }
 
 type fakeBackend struct {
@@ -0,0 +1,414 @@
+package historian
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheus "github.com/prometheus/common/model"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Labels of the time series written by the Prometheus backend, in addition to the labels of the alert instance.
+const (
+	PromAlertStateLabel   = "alertstate"
+	PromOrgIDLabel        = "grafana_org_id"
+	PromRuleUIDLabel      = "grafana_rule_uid"
+	PromFolderUIDLabel    = "grafana_folder_uid"
+	PromGroupLabel        = "grafana_rule_group"
+	PromDashboardUIDLabel = "grafana_dashboard_uid"
+	PromPanelIDLabel      = "grafana_panel_id"
+)
+
+type PrometheusConfig struct {
+	WriteURL          *url.URL
+	QueryURL          *url.URL
+	BasicAuthUser     string
+	BasicAuthPassword string
+	MetricName        string
+	Timeout           time.Duration
+	ExternalLabels    map[string]string
+}
+
+func NewPrometheusConfig(cfg setting.UnifiedAlertingStateHistorySettings) (PrometheusConfig, error) {
+	if cfg.PrometheusWriteURL == "" {
+		return PrometheusConfig{}, fmt.Errorf("remote write URL must be provided")
+	}
+	if cfg.PrometheusQueryURL == "" {
+		return PrometheusConfig{}, fmt.Errorf("query URL must be provided")
+	}
+	writeURL, err := url.Parse(cfg.PrometheusWriteURL)
+	if err != nil {
+		return PrometheusConfig{}, fmt.Errorf("failed to parse prometheus remote write URL: %w", err)
+	}
+	queryURL, err := url.Parse(cfg.PrometheusQueryURL)
+	if err != nil {
+		return PrometheusConfig{}, fmt.Errorf("failed to parse prometheus query URL: %w", err)
+	}
+	if !prometheus.IsValidMetricName(prometheus.LabelValue(cfg.PrometheusMetricName)) {
+		return PrometheusConfig{}, fmt.Errorf("invalid metric name %q", cfg.PrometheusMetricName)
+	}
+	if cfg.PrometheusTimeout <= 0 {
+		return PrometheusConfig{}, fmt.Errorf("timeout must be greater than 0")
+	}
+	return PrometheusConfig{
+		WriteURL:          writeURL,
+		QueryURL:          queryURL,
+		BasicAuthUser:     cfg.PrometheusBasicAuthUsername,
+		BasicAuthPassword: cfg.PrometheusBasicAuthPassword,
+		MetricName:        cfg.PrometheusMetricName,
+		Timeout:           cfg.PrometheusTimeout,
+		ExternalLabels:    cfg.ExternalLabels,
+	}, nil
+}
+
+// RemoteWriter writes frames of numeric data as time series, e.g. writer.PrometheusWriter.
+type RemoteWriter interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
+type prometheusQuerier interface {
+	Query(ctx context.Context, promQL string, t time.Time) (promQueryRes, error)
+}
+
+// PrometheusBackend is a state.Historian that records state transitions as ALERTS-style time series via remote write,
+// and queries them back with PromQL.
+//
+// For each transition, the series of the alert instance with the label "alertstate" of the new state gets a sample
+// with value 1, and the series with the previous state gets a sample with value 0, both at the time of the transition.
+type PrometheusBackend struct {
+	writer    RemoteWriter
+	querier   prometheusQuerier
+	cfg       PrometheusConfig
+	clock     clock.Clock
+	metrics   *metrics.Historian
+	log       log.Logger
+	ac        AccessControl
+	ruleStore RuleStore
+}
+
+func NewPrometheusBackend(logger log.Logger, cfg PrometheusConfig, w RemoteWriter, req client.Requester, metrics *metrics.Historian, tracer tracing.Tracer, ruleStore RuleStore, ac AccessControl) *PrometheusBackend {
+	return &PrometheusBackend{
+		writer:    w,
+		querier:   newPrometheusClient(cfg, req, metrics, logger, tracer),
+		cfg:       cfg,
+		clock:     clock.New(),
+		metrics:   metrics,
+		log:       logger,
+		ac:        ac,
+		ruleStore: ruleStore,
+	}
+}
+
+// Record writes a number of state transitions for a given rule as samples of time series.
+func (h *PrometheusBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	times, frames := h.transitionsToFrames(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(times) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	// This also prevents timeouts or other lingering objects (like transactions) from being
+	// incorrectly propagated here from other areas.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = models.WithRuleKey(writeCtx, models.AlertRuleKey{OrgID: rule.OrgID, UID: rule.UID})
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, BackendTypePrometheus.String()).Inc()
+		transitions := 0
+		for _, t := range times {
+			transitions += len(frames[t]) / 2
+		}
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(transitions))
+
+		var errs []error
+		for _, t := range times {
+			if err := h.writer.Write(ctx, h.cfg.MetricName, t, frames[t], nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := Join(errs...); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, BackendTypePrometheus.String()).Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(transitions))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+		}
+	}(writeCtx)
+	return errCh
+}
+
+// transitionsToFrames returns the frames of the samples of the transitions, grouped by the time of the transitions.
+func (h *PrometheusBackend) transitionsToFrames(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) ([]time.Time, map[time.Time]data.Frames) {
+	var times []time.Time
+	frames := make(map[time.Time]data.Frames)
+	for _, transition := range states {
+		if !shouldRecord(transition) {
+			continue
+		}
+		t := transition.State.LastEvaluationTime
+		if _, ok := frames[t]; !ok {
+			times = append(times, t)
+		}
+		current := h.seriesLabels(rule, transition, transition.State.State, transition.StateReason, logger)
+		previous := h.seriesLabels(rule, transition, transition.PreviousState, transition.PreviousStateReason, logger)
+		frames[t] = append(frames[t], numericFrame(current, 1), numericFrame(previous, 0))
+	}
+	return times, frames
+}
+
+func numericFrame(labels data.Labels, value float64) *data.Frame {
+	frame := data.NewFrame("", data.NewField("", labels, []float64{value}))
+	frame.SetMeta(&data.FrameMeta{
+		Type:        data.FrameTypeNumericMulti,
+		TypeVersion: data.FrameTypeVersion{0, 1},
+	})
+	return frame
+}
+
+// seriesLabels returns the labels of the series of the alert instance of the transition in the given state.
+func (h *PrometheusBackend) seriesLabels(rule history_model.RuleMeta, transition state.StateTransition, st eval.State, reason string, logger log.Logger) data.Labels {
+	labels := mergeLabels(make(data.Labels), h.cfg.ExternalLabels)
+	for k, v := range removePrivateLabels(transition.Labels) {
+		if !prometheus.LabelName(k).IsValid() {
+			logger.Debug("Skipping instance label that is not a valid Prometheus label name", "label", k)
+			continue
+		}
+		labels[k] = v
+	}
+	// System-defined labels take precedence over instance labels and user-defined external labels.
+	labels[prometheus.AlertNameLabel] = rule.Title
+	labels[PromAlertStateLabel] = promAlertState(st)
+	delete(labels, models.StateReasonAnnotation)
+	if reason != "" {
+		labels[models.StateReasonAnnotation] = reason
+	}
+	labels[PromOrgIDLabel] = fmt.Sprint(rule.OrgID)
+	labels[PromRuleUIDLabel] = rule.UID
+	labels[PromFolderUIDLabel] = rule.NamespaceUID
+	labels[PromGroupLabel] = rule.Group
+	if rule.DashboardUID != "" {
+		labels[PromDashboardUIDLabel] = rule.DashboardUID
+		labels[PromPanelIDLabel] = fmt.Sprint(rule.PanelID)
+	}
+	return labels
+}
+
+// promAlertState returns the value of the "alertstate" label for a state, which is "firing" for Alerting like in
+// the ALERTS series of Prometheus.
+func promAlertState(s eval.State) string {
+	if s == eval.Alerting {
+		return "firing"
+	}
+	return strings.ToLower(s.String())
+}
+
+func parsePromAlertState(s string) (eval.State, error) {
+	if s == "firing" {
+		return eval.Alerting, nil
+	}
+	return eval.ParseStateString(s)
+}
+
+// Query retrieves state history entries from Prometheus and formats the results into a dataframe.
+func (h *PrometheusBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	uids, err := getFolderUIDsForFilter(ctx, h.ac, h.ruleStore, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.From.After(query.To) {
+		return nil, fmt.Errorf("start time cannot be after end time")
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maximumPageSize {
+		limit = maximumPageSize
+	}
+
+	promQL, err := BuildPromQuery(h.cfg.MetricName, query, uids)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.querier.Query(ctx, promQL, query.To)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := h.entriesFromSeries(res.Data.Result, query.From)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entriesToFrame(entries)
+}
+
+// BuildPromQuery converts models.HistoryQuery and a list of folder UIDs to a PromQL query that selects the samples
+// of the time range of the query. The query must be evaluated at the end of the time range.
+func BuildPromQuery(metricName string, query models.HistoryQuery, folderUIDs []string) (string, error) {
+	matchers := []string{
+		fmt.Sprintf("%s=%q", PromOrgIDLabel, fmt.Sprint(query.OrgID)),
+	}
+	if query.RuleUID != "" {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", PromRuleUIDLabel, query.RuleUID))
+	}
+	if len(folderUIDs) > 0 {
+		quoted := make([]string, 0, len(folderUIDs))
+		for _, uid := range folderUIDs {
+			quoted = append(quoted, regexp.QuoteMeta(uid))
+		}
+		matchers = append(matchers, fmt.Sprintf("%s=~%q", PromFolderUIDLabel, strings.Join(quoted, "|")))
+	}
+	if query.DashboardUID != "" {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", PromDashboardUIDLabel, query.DashboardUID))
+	}
+	if query.PanelID != 0 {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", PromPanelIDLabel, fmt.Sprint(query.PanelID)))
+	}
+	labelKeys := make([]string, 0, len(query.Labels))
+	for k := range query.Labels {
+		if !prometheus.LabelName(k).IsValid() {
+			return "", fmt.Errorf("invalid label name %q", k)
+		}
+		labelKeys = append(labelKeys, k)
+	}
+	// Ensure that all queries we build are deterministic.
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", k, query.Labels[k]))
+	}
+
+	seconds := int64(math.Ceil(query.To.Sub(query.From).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("%s{%s}[%ds]", metricName, strings.Join(matchers, ","), seconds), nil
+}
+
+// entriesFromSeries returns the transitions in the samples of the series, sorted by time.
+// A transition is a sample with value 1, and its previous state is the state of the sample with value 0
+// of the same alert instance at the same time.
+func (h *PrometheusBackend) entriesFromSeries(series []promSeries, from time.Time) ([]historyEntry, error) {
+	type sampleKey struct {
+		instance string
+		t        int64
+	}
+	previous := make(map[sampleKey]string)
+	for _, s := range series {
+		instance := labelFingerprint(h.instanceLabels(s.Metric))
+		for _, sample := range s.Values {
+			if sample.V == 0 {
+				previous[sampleKey{instance: instance, t: sample.T.UnixNano()}] = h.formattedState(s.Metric)
+			}
+		}
+	}
+
+	var entries []historyEntry
+	for _, s := range series {
+		if _, err := parsePromAlertState(s.Metric[PromAlertStateLabel]); err != nil {
+			return nil, fmt.Errorf("a series has an invalid alert state: %w", err)
+		}
+		instanceLabels := h.instanceLabels(s.Metric)
+		fingerprint := labelFingerprint(instanceLabels)
+		var panelID int64
+		if id := s.Metric[PromPanelIDLabel]; id != "" {
+			if _, err := fmt.Sscan(id, &panelID); err != nil {
+				return nil, fmt.Errorf("a series has an invalid panel ID: %w", err)
+			}
+		}
+		for _, sample := range s.Values {
+			if sample.V != 1 || sample.T.Before(from) {
+				continue
+			}
+			entries = append(entries, historyEntry{
+				t: sample.T,
+				entry: LokiEntry{
+					SchemaVersion:  1,
+					Previous:       previous[sampleKey{instance: fingerprint, t: sample.T.UnixNano()}],
+					Current:        h.formattedState(s.Metric),
+					DashboardUID:   s.Metric[PromDashboardUIDLabel],
+					PanelID:        panelID,
+					Fingerprint:    fingerprint,
+					RuleTitle:      s.Metric[prometheus.AlertNameLabel],
+					RuleUID:        s.Metric[PromRuleUIDLabel],
+					InstanceLabels: instanceLabels,
+				},
+				labels: map[string]string{
+					OrgIDLabel:     s.Metric[PromOrgIDLabel],
+					GroupLabel:     s.Metric[PromGroupLabel],
+					FolderUIDLabel: s.Metric[PromFolderUIDLabel],
+				},
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].t.Before(entries[j].t)
+	})
+	return entries, nil
+}
+
+// instanceLabels returns the labels of the alert instance of a series.
+func (h *PrometheusBackend) instanceLabels(metric map[string]string) data.Labels {
+	labels := make(data.Labels, len(metric))
+	for k, v := range metric {
+		if _, ok := h.cfg.ExternalLabels[k]; ok {
+			continue
+		}
+		switch k {
+		case prometheus.MetricNameLabel, PromAlertStateLabel, models.StateReasonAnnotation, PromOrgIDLabel, PromRuleUIDLabel,
+			PromFolderUIDLabel, PromGroupLabel, PromDashboardUIDLabel, PromPanelIDLabel:
+			continue
+		}
+		labels[k] = v
+	}
+	return labels
+}
+
+// formattedState returns the state and reason of a series in the same format as state.StateTransition.Formatted.
+func (h *PrometheusBackend) formattedState(metric map[string]string) string {
+	s, err := parsePromAlertState(metric[PromAlertStateLabel])
+	if err != nil {
+		return metric[PromAlertStateLabel]
+	}
+	return state.FormatStateAndReason(s, metric[models.StateReasonAnnotation])
+}
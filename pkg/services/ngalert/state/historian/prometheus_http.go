@@ -0,0 +1,137 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type httpPrometheusClient struct {
+	client client.Requester
+	cfg    PrometheusConfig
+	log    log.Logger
+}
+
+func newPrometheusClient(cfg PrometheusConfig, req client.Requester, metrics *metrics.Historian, logger log.Logger, tracer tracing.Tracer) *httpPrometheusClient {
+	tc := client.NewTimedClient(req, metrics.WriteDuration)
+	trc := client.NewTracedClient(tc, tracer, "ngalert.historian.client")
+	return &httpPrometheusClient{
+		client: trc,
+		cfg:    cfg,
+		log:    logger.New("protocol", "http"),
+	}
+}
+
+type promQueryRes struct {
+	Status string        `json:"status"`
+	Data   promQueryData `json:"data"`
+	Error  string        `json:"error"`
+}
+
+type promQueryData struct {
+	ResultType string       `json:"resultType"`
+	Result     []promSeries `json:"result"`
+}
+
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []promSample      `json:"values"`
+}
+
+type promSample struct {
+	T time.Time
+	V float64
+}
+
+func (s *promSample) UnmarshalJSON(b []byte) error {
+	// A sample of a Prometheus range vector is formatted like a list with two elements, [At, Val].
+	// At is a number of seconds since the Unix epoch, with a fractional part.
+	// Val is a string containing the float value.
+	var tuple [2]json.RawMessage
+	if err := json.Unmarshal(b, &tuple); err != nil {
+		return fmt.Errorf("failed to deserialize sample in Prometheus response: %w", err)
+	}
+	var at json.Number
+	if err := json.Unmarshal(tuple[0], &at); err != nil {
+		return fmt.Errorf("timestamp in Prometheus sample is not a number: %s", tuple[0])
+	}
+	sec, err := at.Float64()
+	if err != nil {
+		return fmt.Errorf("timestamp in Prometheus sample is not a number: %s", tuple[0])
+	}
+	var val string
+	if err := json.Unmarshal(tuple[1], &val); err != nil {
+		return fmt.Errorf("value in Prometheus sample is not a string: %s", tuple[1])
+	}
+	v, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fmt.Errorf("value in Prometheus sample is not a float: %s", val)
+	}
+	s.T = time.UnixMilli(int64(sec * 1000)).UTC()
+	s.V = v
+	return nil
+}
+
+// Query evaluates a PromQL query that returns a range vector at the given time.
+func (c *httpPrometheusClient) Query(ctx context.Context, promQL string, t time.Time) (promQueryRes, error) {
+	values := url.Values{}
+	values.Set("query", promQL)
+	values.Set("time", strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', -1, 64))
+	values.Set("timeout", c.cfg.Timeout.String())
+
+	uri := c.cfg.QueryURL.JoinPath("/api/v1/query")
+	req, err := http.NewRequest(http.MethodPost, uri.String(), strings.NewReader(values.Encode()))
+	if err != nil {
+		return promQueryRes{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.cfg.BasicAuthUser != "" || c.cfg.BasicAuthPassword != "" {
+		req.SetBasicAuth(c.cfg.BasicAuthUser, c.cfg.BasicAuthPassword)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return promQueryRes{}, fmt.Errorf("error executing request: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return promQueryRes{}, fmt.Errorf("error reading request response: %w", err)
+	}
+
+	result := promQueryRes{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			c.log.Error("Error response from Prometheus", "response", string(data), "status", res.StatusCode)
+			return promQueryRes{}, fmt.Errorf("received a non-200 response from prometheus, status: %d", res.StatusCode)
+		}
+		return promQueryRes{}, fmt.Errorf("error parsing request response: %w", err)
+	}
+	if result.Status != "success" {
+		return promQueryRes{}, fmt.Errorf("query to prometheus failed, status: %d: %s", res.StatusCode, result.Error)
+	}
+	if result.Data.ResultType != "matrix" {
+		return promQueryRes{}, fmt.Errorf("unexpected result type %q in prometheus response", result.Data.ResultType)
+	}
+	return result, nil
+}
@@ -0,0 +1,361 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPrometheusBackend(t *testing.T) {
+	t.Run("writes the series of the current and previous states", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		type write struct {
+			name   string
+			t      time.Time
+			frames data.Frames
+			key    models.AlertRuleKey
+		}
+		var writes []write
+		w := writer.FakeWriter{WriteFunc: func(ctx context.Context, name string, t time.Time, frames data.Frames, _ map[string]string) error {
+			key, _ := models.RuleKeyFromContext(ctx)
+			writes = append(writes, write{name: name, t: t, frames: frames, key: key})
+			return nil
+		}}
+		b := createTestPrometheusBackend(t, w, nil)
+		rule := createTestRule()
+		states := []state.StateTransition{{
+			PreviousState: eval.Pending,
+			State: &state.State{
+				State:              eval.Alerting,
+				Labels:             data.Labels{"host": "a", "__private__": "b"},
+				LastEvaluationTime: now,
+			},
+		}}
+
+		err := <-b.Record(context.Background(), rule, states)
+
+		require.NoError(t, err)
+		require.Len(t, writes, 1)
+		require.Equal(t, "GRAFANA_ALERTS", writes[0].name)
+		require.Equal(t, now, writes[0].t)
+		require.Equal(t, models.AlertRuleKey{OrgID: rule.OrgID, UID: rule.UID}, writes[0].key)
+		require.Len(t, writes[0].frames, 2)
+		expected := data.Labels{
+			"host":                "a",
+			"alertname":           rule.Title,
+			"externalLabelKey":    "externalLabelValue",
+			PromOrgIDLabel:        "1",
+			PromRuleUIDLabel:      rule.UID,
+			PromFolderUIDLabel:    rule.NamespaceUID,
+			PromGroupLabel:        rule.Group,
+			PromDashboardUIDLabel: rule.DashboardUID,
+			PromPanelIDLabel:      "123",
+			PromAlertStateLabel:   "firing",
+		}
+		require.Equal(t, expected, writes[0].frames[0].Fields[0].Labels)
+		require.Equal(t, 1.0, writes[0].frames[0].Fields[0].At(0))
+		expected[PromAlertStateLabel] = "pending"
+		require.Equal(t, expected, writes[0].frames[1].Fields[0].Labels)
+		require.Equal(t, 0.0, writes[0].frames[1].Fields[0].At(0))
+	})
+
+	t.Run("writes the reason of the state", func(t *testing.T) {
+		var frames data.Frames
+		w := writer.FakeWriter{WriteFunc: func(_ context.Context, _ string, _ time.Time, f data.Frames, _ map[string]string) error {
+			frames = f
+			return nil
+		}}
+		b := createTestPrometheusBackend(t, w, nil)
+		states := singleFromNormal(&state.State{State: eval.Normal, StateReason: models.StateReasonMissingSeries})
+		states[0].PreviousState = eval.Alerting
+
+		err := <-b.Record(context.Background(), createTestRule(), states)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		require.Equal(t, "normal", frames[0].Fields[0].Labels[PromAlertStateLabel])
+		require.Equal(t, models.StateReasonMissingSeries, frames[0].Fields[0].Labels[models.StateReasonAnnotation])
+		require.NotContains(t, frames[1].Fields[0].Labels, models.StateReasonAnnotation)
+	})
+
+	t.Run("reports write errors", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		met := metrics.NewHistorianMetrics(reg, metrics.Subsystem)
+		w := writer.FakeWriter{WriteFunc: func(context.Context, string, time.Time, data.Frames, map[string]string) error {
+			return fmt.Errorf("remote write failed")
+		}}
+		b := createTestPrometheusBackend(t, w, nil)
+		b.metrics = met
+
+		err := <-b.Record(context.Background(), createTestRule(), singleFromNormal(&state.State{State: eval.Alerting}))
+
+		require.ErrorContains(t, err, "remote write failed")
+	})
+
+	t.Run("queries the transitions of the series", func(t *testing.T) {
+		t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		t1 := t0.Add(time.Minute)
+		metric := func(alertstate string) map[string]string {
+			return map[string]string{
+				"__name__":          "GRAFANA_ALERTS",
+				"alertname":         "my-title",
+				"host":              "a",
+				"externalLabelKey":  "externalLabelValue",
+				PromAlertStateLabel: alertstate,
+				PromOrgIDLabel:      "1",
+				PromRuleUIDLabel:    "rule-uid",
+				PromFolderUIDLabel:  "my-folder",
+				PromGroupLabel:      "my-group",
+			}
+		}
+		querier := &fakePrometheusQuerier{res: promQueryRes{Data: promQueryData{Result: []promSeries{
+			{Metric: metric("pending"), Values: []promSample{{T: t0, V: 1}, {T: t1, V: 0}}},
+			{Metric: metric("firing"), Values: []promSample{{T: t1, V: 1}}},
+			{Metric: metric("normal"), Values: []promSample{{T: t0, V: 0}}},
+		}}}}
+		b := createTestPrometheusBackend(t, nil, querier)
+
+		frame, err := b.Query(context.Background(), models.HistoryQuery{
+			OrgID:        1,
+			RuleUID:      "rule-uid",
+			SignedInUser: &identity.StaticRequester{},
+			From:         t0.Add(-time.Hour),
+			To:           t1,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, t1, querier.lastTime)
+		require.Equal(t, `GRAFANA_ALERTS{grafana_org_id="1",grafana_rule_uid="rule-uid"}[3660s]`, querier.lastQuery)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, t0, frame.Fields[0].At(0))
+		require.Equal(t, t1, frame.Fields[0].At(1))
+
+		first := requireFrameEntry(t, frame, 0)
+		require.Equal(t, "Normal", first.Previous)
+		require.Equal(t, "Pending", first.Current)
+		require.Equal(t, "my-title", first.RuleTitle)
+		require.Equal(t, map[string]string{"alertname": "my-title", "host": "a"}, first.InstanceLabels)
+		second := requireFrameEntry(t, frame, 1)
+		require.Equal(t, "Pending", second.Previous)
+		require.Equal(t, "Alerting", second.Current)
+		require.Equal(t, first.Fingerprint, second.Fingerprint)
+	})
+
+	t.Run("query keeps the most recent transitions up to the limit", func(t *testing.T) {
+		t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		querier := &fakePrometheusQuerier{res: promQueryRes{Data: promQueryData{Result: []promSeries{
+			{Metric: map[string]string{PromAlertStateLabel: "firing"}, Values: []promSample{{T: t0, V: 1}, {T: t0.Add(2 * time.Minute), V: 1}}},
+			{Metric: map[string]string{PromAlertStateLabel: "normal"}, Values: []promSample{{T: t0.Add(time.Minute), V: 1}}},
+		}}}}
+		b := createTestPrometheusBackend(t, nil, querier)
+
+		frame, err := b.Query(context.Background(), models.HistoryQuery{
+			OrgID:        1,
+			RuleUID:      "rule-uid",
+			SignedInUser: &identity.StaticRequester{},
+			From:         t0,
+			To:           t0.Add(time.Hour),
+			Limit:        2,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, t0.Add(time.Minute), frame.Fields[0].At(0))
+		require.Equal(t, t0.Add(2*time.Minute), frame.Fields[0].At(1))
+	})
+}
+
+func TestBuildPromQuery(t *testing.T) {
+	from := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name       string
+		query      models.HistoryQuery
+		folderUIDs []string
+		exp        string
+		expErr     string
+	}{
+		{
+			name:  "org only",
+			query: models.HistoryQuery{OrgID: 1, From: from, To: from.Add(time.Hour)},
+			exp:   `GRAFANA_ALERTS{grafana_org_id="1"}[3600s]`,
+		},
+		{
+			name: "all filters",
+			query: models.HistoryQuery{
+				OrgID:        1,
+				RuleUID:      "rule-uid",
+				DashboardUID: "dash-uid",
+				PanelID:      2,
+				Labels:       map[string]string{"host": "a\"b", "env": "prod"},
+				From:         from,
+				To:           from.Add(time.Minute),
+			},
+			exp: `GRAFANA_ALERTS{grafana_org_id="1",grafana_rule_uid="rule-uid",grafana_dashboard_uid="dash-uid",grafana_panel_id="2",env="prod",host="a\"b"}[60s]`,
+		},
+		{
+			name:       "folders",
+			query:      models.HistoryQuery{OrgID: 1, From: from, To: from.Add(time.Second)},
+			folderUIDs: []string{"folder-1", "folder.2"},
+			exp:        `GRAFANA_ALERTS{grafana_org_id="1",grafana_folder_uid=~"folder-1|folder\\.2"}[1s]`,
+		},
+		{
+			name:   "invalid label name",
+			query:  models.HistoryQuery{OrgID: 1, Labels: map[string]string{"in valid": "a"}},
+			expErr: "invalid label name",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := BuildPromQuery("GRAFANA_ALERTS", tc.query, tc.folderUIDs)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, res)
+		})
+	}
+}
+
+func TestPrometheusClientQuery(t *testing.T) {
+	t.Run("parses a range vector", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(&http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(bytes.NewBufferString(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"alertstate":"firing"},"values":[[1704110400.5,"1"],[1704110460,"0"]]}
+			]}}`)),
+			Header: make(http.Header),
+		})
+		client := createTestPrometheusClient(req)
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		res, err := client.Query(context.Background(), `GRAFANA_ALERTS[1h]`, now)
+
+		require.NoError(t, err)
+		require.Equal(t, "http://some.url/api/v1/query", req.lastRequest.URL.String())
+		require.NoError(t, req.lastRequest.ParseForm())
+		require.Equal(t, `GRAFANA_ALERTS[1h]`, req.lastRequest.Form.Get("query"))
+		require.Equal(t, "1704110400", req.lastRequest.Form.Get("time"))
+		require.Equal(t, []promSeries{{
+			Metric: map[string]string{"alertstate": "firing"},
+			Values: []promSample{
+				{T: time.UnixMilli(1704110400500).UTC(), V: 1},
+				{T: time.UnixMilli(1704110460000).UTC(), V: 0},
+			},
+		}}, res.Data.Result)
+	})
+
+	t.Run("returns the error of the response", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(&http.Response{
+			Status:     "400 Bad Request",
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"status":"error","errorType":"bad_data","error":"parse error"}`)),
+			Header:     make(http.Header),
+		})
+		client := createTestPrometheusClient(req)
+
+		_, err := client.Query(context.Background(), `GRAFANA_ALERTS[1h]`, time.Now())
+
+		require.ErrorContains(t, err, "parse error")
+	})
+
+	t.Run("fails on a non-JSON error response", func(t *testing.T) {
+		client := createTestPrometheusClient(NewFakeRequester().WithResponse(badResponse()))
+
+		_, err := client.Query(context.Background(), `GRAFANA_ALERTS[1h]`, time.Now())
+
+		require.ErrorContains(t, err, "non-200")
+	})
+}
+
+func TestNewPrometheusConfig(t *testing.T) {
+	valid := setting.UnifiedAlertingStateHistorySettings{
+		PrometheusWriteURL:   "http://prometheus/api/v1/write",
+		PrometheusQueryURL:   "http://prometheus",
+		PrometheusMetricName: "GRAFANA_ALERTS",
+		PrometheusTimeout:    time.Second,
+	}
+	cfg, err := NewPrometheusConfig(valid)
+	require.NoError(t, err)
+	require.Equal(t, "http://prometheus", cfg.QueryURL.String())
+
+	invalid := valid
+	invalid.PrometheusQueryURL = ""
+	_, err = NewPrometheusConfig(invalid)
+	require.ErrorContains(t, err, "query URL must be provided")
+
+	invalid = valid
+	invalid.PrometheusMetricName = "not a metric"
+	_, err = NewPrometheusConfig(invalid)
+	require.ErrorContains(t, err, "invalid metric name")
+}
+
+type fakePrometheusQuerier struct {
+	res       promQueryRes
+	lastQuery string
+	lastTime  time.Time
+}
+
+func (f *fakePrometheusQuerier) Query(_ context.Context, promQL string, t time.Time) (promQueryRes, error) {
+	f.lastQuery = promQL
+	f.lastTime = t
+	return f.res, nil
+}
+
+func createTestPrometheusBackend(t *testing.T, w RemoteWriter, querier prometheusQuerier) *PrometheusBackend {
+	url, _ := url.Parse("http://some.url")
+	cfg := PrometheusConfig{
+		WriteURL:       url,
+		QueryURL:       url,
+		MetricName:     "GRAFANA_ALERTS",
+		Timeout:        time.Second,
+		ExternalLabels: map[string]string{"externalLabelKey": "externalLabelValue"},
+	}
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	ac := &acfakes.FakeRuleService{
+		CanReadAllRulesFunc: func(context.Context, identity.Requester) (bool, error) {
+			return true, nil
+		},
+	}
+	b := NewPrometheusBackend(log.NewNopLogger(), cfg, w, NewFakeRequester(), met, tracing.InitializeTracerForTest(), fakes.NewRuleStore(t), ac)
+	if querier != nil {
+		b.querier = querier
+	}
+	return b
+}
+
+func createTestPrometheusClient(req *fakeRequester) *httpPrometheusClient {
+	url, _ := url.Parse("http://some.url")
+	cfg := PrometheusConfig{QueryURL: url, Timeout: time.Second}
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	return newPrometheusClient(cfg, req, met, log.NewNopLogger(), tracing.InitializeTracerForTest())
+}
+
+func requireFrameEntry(t *testing.T, frame *data.Frame, i int) LokiEntry {
+	t.Helper()
+
+	return requireEntry(t, Sample{V: string(frame.Fields[1].At(i).(json.RawMessage))})
+}
@@ -0,0 +1,497 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+const (
+	// stateHistoryDayTable registers the days that have a state history table.
+	stateHistoryDayTable = "alert_state_history_day"
+	// retentionInterval is how often the SQL backend deletes expired state history.
+	retentionInterval = time.Hour
+)
+
+// stateHistoryDay is a row of the alert_state_history_day table.
+type stateHistoryDay struct {
+	Day int64 `xorm:"pk 'day'"`
+}
+
+func (stateHistoryDay) TableName() string {
+	return stateHistoryDayTable
+}
+
+// stateHistoryRow is a row of the state history table of a day.
+type stateHistoryRow struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	OrgID          int64  `xorm:"org_id"`
+	RuleUID        string `xorm:"rule_uid"`
+	RuleID         int64  `xorm:"rule_id"`
+	RuleTitle      string `xorm:"rule_title"`
+	RuleGroup      string `xorm:"rule_group"`
+	NamespaceUID   string `xorm:"namespace_uid"`
+	ConditionRefID string `xorm:"condition_ref_id"`
+	DashboardUID   string `xorm:"dashboard_uid"`
+	PanelID        int64  `xorm:"panel_id"`
+	Fingerprint    string `xorm:"fingerprint"`
+	Labels         string `xorm:"labels"`
+	PreviousState  string `xorm:"previous_state"`
+	CurrentState   string `xorm:"current_state"`
+	Error          string `xorm:"error"`
+	StateValues    string `xorm:"state_values"`
+	TimeNs         int64  `xorm:"time_ns"`
+}
+
+// dayTableName returns the name of the state history table of the day since the Unix epoch.
+func dayTableName(day int64) string {
+	return "alert_state_history_" + time.Unix(day*int64((24*time.Hour).Seconds()), 0).UTC().Format("20060102")
+}
+
+// dayTable returns the definition of the state history table of the day since the Unix epoch.
+func dayTable(day int64) migrator.Table {
+	return migrator.Table{
+		Name:        dayTableName(day),
+		PrimaryKeys: []string{"id"},
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "rule_id", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "rule_title", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "condition_ref_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "state_values", Type: migrator.DB_Text, Nullable: true},
+			// time of the transition in nanoseconds
+			{Name: "time_ns", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "time_ns"}},
+			{Cols: []string{"org_id", "time_ns"}},
+		},
+	}
+}
+
+// SQLBackend is a state.Historian that records state history to the Grafana database. The transitions of every day
+// are stored in a table of their own, which is created when the first transition of the day is recorded, and
+// retention drops the tables of whole days.
+type SQLBackend struct {
+	db        db.DB
+	rules     RuleStore
+	ac        AccessControl
+	retention time.Duration
+	clock     clock.Clock
+	metrics   *metrics.Historian
+	log       log.Logger
+
+	// days are the days whose table is known to exist.
+	daysMtx sync.Mutex
+	days    map[int64]struct{}
+}
+
+func NewSQLBackend(logger log.Logger, store db.DB, rules RuleStore, metrics *metrics.Historian, ac AccessControl, retention time.Duration) *SQLBackend {
+	return &SQLBackend{
+		db:        store,
+		rules:     rules,
+		ac:        ac,
+		retention: retention,
+		clock:     clock.New(),
+		metrics:   metrics,
+		log:       logger,
+		days:      make(map[int64]struct{}),
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the state history table.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	// Build rows before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	rowsByDay := make(map[int64][]stateHistoryRow)
+	count := 0
+	cutoff := h.cutoffDay()
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+		day := transitionDay(state.State.LastEvaluationTime)
+		// The table of the day could have been dropped already.
+		if day < cutoff {
+			continue
+		}
+		row, err := rowFromTransition(rule, state)
+		if err != nil {
+			logger.Error("Failed to construct history record for state, skipping", "error", err)
+			continue
+		}
+		rowsByDay[day] = append(rowsByDay[day], row)
+		count++
+	}
+
+	errCh := make(chan error, 1)
+	if count == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	// This also prevents timeouts or other lingering objects (like transactions) from being
+	// incorrectly propagated here from other areas.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, BackendTypeSQL.String()).Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(count))
+
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			for day, rows := range rowsByDay {
+				if err := h.ensureDayTable(sess, day); err != nil {
+					return err
+				}
+				if _, err := sess.Table(dayTableName(day)).Insert(&rows); err != nil {
+					// The table could have been dropped by another instance, it is checked again by the next write.
+					h.forgetDay(day)
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, BackendTypeSQL.String()).Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(count))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+		}
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from the state history tables of the days in the range of the query and formats
+// the results into a dataframe.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	uids, err := getFolderUIDsForFilter(ctx, h.ac, h.rules, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maximumPageSize {
+		limit = maximumPageSize
+	}
+
+	var days []stateHistoryDay
+	err = h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("day >= ? AND day <= ?", transitionDay(query.From), transitionDay(query.To)).Desc("day").Find(&days)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history days: %w", err)
+	}
+
+	entries := make([]historyEntry, 0)
+	// Days are read from the most recent until enough rows match.
+	for _, day := range days {
+		if len(entries) == limit {
+			break
+		}
+		if entries, err = h.queryDay(ctx, day.Day, query, uids, limit, entries); err != nil {
+			return nil, err
+		}
+	}
+	// Rows were read from the most recent, but the frame is sorted by time.
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entriesToFrame(entries)
+}
+
+// queryDay appends the entries of the table of the day that match the query to entries, from the most recent, until
+// there are limit entries.
+func (h *SQLBackend) queryDay(ctx context.Context, day int64, query models.HistoryQuery, uids []string, limit int, entries []historyEntry) ([]historyEntry, error) {
+	// Labels are filtered after rows are read, so rows are read in pages of the limit until enough rows match.
+	// Pages continue after the last row of the previous page in (time_ns, id) order.
+	var lastTime, lastID int64
+	for len(entries) < limit {
+		var rows []stateHistoryRow
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			q := sess.Table(dayTableName(day)).
+				Where("org_id = ?", query.OrgID).
+				And("time_ns >= ?", query.From.UnixNano()).
+				And("time_ns <= ?", query.To.UnixNano())
+			if query.RuleUID != "" {
+				q = q.And("rule_uid = ?", query.RuleUID)
+			}
+			if query.DashboardUID != "" {
+				q = q.And("dashboard_uid = ?", query.DashboardUID)
+			}
+			if query.PanelID != 0 {
+				q = q.And("panel_id = ?", query.PanelID)
+			}
+			if len(uids) > 0 {
+				q = q.In("namespace_uid", uids)
+			}
+			if lastID != 0 {
+				q = q.And("(time_ns < ? OR (time_ns = ? AND id < ?))", lastTime, lastTime, lastID)
+			}
+			return q.Desc("time_ns", "id").Limit(limit).Find(&rows)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query state history of %s: %w", dayTableName(day), err)
+		}
+
+		for _, row := range rows {
+			entry, err := row.entry()
+			if err != nil {
+				return nil, fmt.Errorf("a state history row was in an invalid format: %w", err)
+			}
+			if !labelsMatch(entry.InstanceLabels, query.Labels) {
+				continue
+			}
+			entries = append(entries, historyEntry{
+				t:     time.Unix(0, row.TimeNs),
+				entry: entry,
+				labels: map[string]string{
+					OrgIDLabel:     fmt.Sprint(row.OrgID),
+					GroupLabel:     row.RuleGroup,
+					FolderUIDLabel: row.NamespaceUID,
+				},
+			})
+			if len(entries) == limit {
+				break
+			}
+		}
+		if len(rows) < limit {
+			break
+		}
+		lastTime, lastID = rows[len(rows)-1].TimeNs, rows[len(rows)-1].ID
+	}
+	return entries, nil
+}
+
+// Run deletes the state history that is older than the retention, periodically, until the context is done.
+func (h *SQLBackend) Run(ctx context.Context) error {
+	if h.retention <= 0 {
+		return nil
+	}
+	ticker := h.clock.Ticker(retentionInterval)
+	defer ticker.Stop()
+	for {
+		if deleted, err := h.DeleteExpired(ctx); err != nil {
+			h.log.Error("Failed to delete expired state history", "error", err)
+		} else if deleted > 0 {
+			h.log.Debug("Deleted expired state history", "days", deleted)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeleteExpired drops the tables of the days of state history that are entirely older than the retention.
+// It returns the number of dropped days.
+func (h *SQLBackend) DeleteExpired(ctx context.Context) (int64, error) {
+	if h.retention <= 0 {
+		return 0, nil
+	}
+	var deleted int64
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var days []stateHistoryDay
+		if err := sess.Where("day < ?", h.cutoffDay()).Find(&days); err != nil {
+			return err
+		}
+		for _, day := range days {
+			h.forgetDay(day.Day)
+			if _, err := sess.Exec(h.db.GetDialect().DropTable(dayTableName(day.Day))); err != nil {
+				return fmt.Errorf("failed to drop %s: %w", dayTableName(day.Day), err)
+			}
+			if _, err := sess.Delete(&stateHistoryDay{Day: day.Day}); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// cutoffDay returns the first day that is kept by the retention.
+func (h *SQLBackend) cutoffDay() int64 {
+	if h.retention <= 0 {
+		return 0
+	}
+	return transitionDay(h.clock.Now().Add(-h.retention))
+}
+
+// ensureDayTable creates the table of the day and registers the day, unless it is known to exist.
+// Other instances could be creating the same table, so existing tables, indices and days are not errors.
+func (h *SQLBackend) ensureDayTable(sess *db.Session, day int64) error {
+	h.daysMtx.Lock()
+	_, ok := h.days[day]
+	h.daysMtx.Unlock()
+	if ok {
+		return nil
+	}
+
+	dialect := h.db.GetDialect()
+	table := dayTable(day)
+	if _, err := sess.Exec(dialect.CreateTableSQL(&table)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", table.Name, err)
+	}
+	for _, index := range table.Indices {
+		exists, err := indexExists(sess, dialect, table.Name, index)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := sess.Exec(dialect.CreateIndexSQL(table.Name, index)); err != nil {
+			if exists, checkErr := indexExists(sess, dialect, table.Name, index); checkErr != nil || !exists {
+				return fmt.Errorf("failed to create index on %s: %w", table.Name, err)
+			}
+		}
+	}
+	if _, err := sess.Insert(&stateHistoryDay{Day: day}); err != nil && !dialect.IsUniqueConstraintViolation(err) {
+		return fmt.Errorf("failed to register %s: %w", table.Name, err)
+	}
+
+	h.daysMtx.Lock()
+	h.days[day] = struct{}{}
+	h.daysMtx.Unlock()
+	return nil
+}
+
+func (h *SQLBackend) forgetDay(day int64) {
+	h.daysMtx.Lock()
+	delete(h.days, day)
+	h.daysMtx.Unlock()
+}
+
+func indexExists(sess *db.Session, dialect migrator.Dialect, table string, index *migrator.Index) (bool, error) {
+	sql, args := dialect.IndexCheckSQL(table, index.XName(table))
+	res, err := sess.Query(append([]any{sql}, args...)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to check index on %s: %w", table, err)
+	}
+	return len(res) > 0, nil
+}
+
+// transitionDay returns the day of t since the Unix epoch, in UTC.
+func transitionDay(t time.Time) int64 {
+	return t.Unix() / int64((24 * time.Hour).Seconds())
+}
+
+func rowFromTransition(rule history_model.RuleMeta, state state.StateTransition) (stateHistoryRow, error) {
+	entry := entryFromTransition(rule, state)
+	lbls, err := json.Marshal(entry.InstanceLabels)
+	if err != nil {
+		return stateHistoryRow{}, err
+	}
+	var values []byte
+	if entry.Values != nil {
+		if values, err = entry.Values.Encode(); err != nil {
+			return stateHistoryRow{}, err
+		}
+	}
+	t := state.State.LastEvaluationTime
+	return stateHistoryRow{
+		OrgID:          rule.OrgID,
+		RuleUID:        rule.UID,
+		RuleID:         rule.ID,
+		RuleTitle:      rule.Title,
+		RuleGroup:      rule.Group,
+		NamespaceUID:   rule.NamespaceUID,
+		ConditionRefID: rule.Condition,
+		DashboardUID:   rule.DashboardUID,
+		PanelID:        rule.PanelID,
+		Fingerprint:    entry.Fingerprint,
+		Labels:         string(lbls),
+		PreviousState:  entry.Previous,
+		CurrentState:   entry.Current,
+		Error:          entry.Error,
+		StateValues:    string(values),
+		TimeNs:         t.UnixNano(),
+	}, nil
+}
+
+func (row stateHistoryRow) entry() (LokiEntry, error) {
+	var lbls map[string]string
+	if err := json.Unmarshal([]byte(row.Labels), &lbls); err != nil {
+		return LokiEntry{}, err
+	}
+	values := simplejson.New()
+	if row.StateValues != "" {
+		var err error
+		if values, err = simplejson.NewJson([]byte(row.StateValues)); err != nil {
+			return LokiEntry{}, err
+		}
+	}
+	return LokiEntry{
+		SchemaVersion:  1,
+		Previous:       row.PreviousState,
+		Current:        row.CurrentState,
+		Error:          row.Error,
+		Values:         values,
+		Condition:      row.ConditionRefID,
+		DashboardUID:   row.DashboardUID,
+		PanelID:        row.PanelID,
+		Fingerprint:    row.Fingerprint,
+		RuleTitle:      row.RuleTitle,
+		RuleID:         row.RuleID,
+		RuleUID:        row.RuleUID,
+		InstanceLabels: lbls,
+	}, nil
+}
+
+// labelsMatch returns true if the labels contain all the matchers.
+func labelsMatch(labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
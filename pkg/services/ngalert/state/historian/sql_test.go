@@ -0,0 +1,200 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationSQLBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	transition := func(prev, cur eval.State, labels data.Labels, at time.Time) state.StateTransition {
+		return state.StateTransition{
+			PreviousState: prev,
+			State: &state.State{
+				State:              cur,
+				Labels:             labels,
+				LastEvaluationTime: at,
+				Values:             map[string]float64{"A": 1},
+			},
+		}
+	}
+
+	t.Run("records and queries transitions", func(t *testing.T) {
+		b := createTestSQLBackend(t, 0)
+		rule := createTestRule()
+		states := []state.StateTransition{
+			transition(eval.Normal, eval.Pending, data.Labels{"host": "a"}, now.Add(-2*time.Minute)),
+			transition(eval.Pending, eval.Alerting, data.Labels{"host": "a"}, now.Add(-time.Minute)),
+			transition(eval.Normal, eval.Alerting, data.Labels{"host": "b"}, now.Add(-time.Minute)),
+			transition(eval.Normal, eval.Normal, data.Labels{"host": "c"}, now.Add(-time.Minute)),
+		}
+
+		require.NoError(t, <-b.Record(context.Background(), rule, states))
+
+		frame, err := b.Query(context.Background(), models.HistoryQuery{
+			OrgID:        rule.OrgID,
+			RuleUID:      rule.UID,
+			SignedInUser: &identity.StaticRequester{},
+			From:         now.Add(-time.Hour),
+			To:           now,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, now.Add(-2*time.Minute), frame.Fields[0].At(0).(time.Time).UTC())
+
+		first := requireFrameEntry(t, frame, 0)
+		require.Equal(t, "Normal", first.Previous)
+		require.Equal(t, "Pending", first.Current)
+		require.Equal(t, rule.Title, first.RuleTitle)
+		require.Equal(t, rule.DashboardUID, first.DashboardUID)
+		require.Equal(t, map[string]string{"host": "a"}, first.InstanceLabels)
+		require.Equal(t, 1.0, first.Values.Get("A").MustFloat64())
+		require.JSONEq(t, `{"orgID":"1","group":"my-group","folderUID":"my-folder"}`, string(frame.Fields[2].At(0).(json.RawMessage)))
+	})
+
+	t.Run("filters by labels and limits to the most recent transitions", func(t *testing.T) {
+		b := createTestSQLBackend(t, 0)
+		rule := createTestRule()
+		var states []state.StateTransition
+		for i := 0; i < 5; i++ {
+			lbls := data.Labels{"host": fmt.Sprint(i % 2)}
+			states = append(states, transition(eval.Normal, eval.Alerting, lbls, now.Add(time.Duration(i-5)*time.Minute)))
+		}
+		require.NoError(t, <-b.Record(context.Background(), rule, states))
+
+		query := models.HistoryQuery{
+			OrgID:        rule.OrgID,
+			SignedInUser: &identity.StaticRequester{},
+			From:         now.Add(-time.Hour),
+			To:           now,
+			Labels:       map[string]string{"host": "0"},
+			Limit:        2,
+		}
+		frame, err := b.Query(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, now.Add(-3*time.Minute), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, now.Add(-time.Minute), frame.Fields[0].At(1).(time.Time).UTC())
+
+		query.Labels = nil
+		query.OrgID = 2
+		frame, err = b.Query(context.Background(), query)
+		require.NoError(t, err)
+		require.Equal(t, 0, frame.Rows())
+	})
+
+	t.Run("reads pages until enough transitions match the labels", func(t *testing.T) {
+		b := createTestSQLBackend(t, 0)
+		rule := createTestRule()
+		var states []state.StateTransition
+		// The most recent transitions fill more than a page and don't match.
+		for i := 0; i < 7; i++ {
+			lbls := data.Labels{"host": "a"}
+			if i < 2 {
+				lbls = data.Labels{"host": "b"}
+			}
+			states = append(states, transition(eval.Normal, eval.Alerting, lbls, now.Add(time.Duration(i-7)*time.Minute)))
+		}
+		require.NoError(t, <-b.Record(context.Background(), rule, states))
+
+		frame, err := b.Query(context.Background(), models.HistoryQuery{
+			OrgID:        rule.OrgID,
+			SignedInUser: &identity.StaticRequester{},
+			From:         now.Add(-time.Hour),
+			To:           now,
+			Labels:       map[string]string{"host": "b"},
+			Limit:        2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, now.Add(-7*time.Minute), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, now.Add(-6*time.Minute), frame.Fields[0].At(1).(time.Time).UTC())
+	})
+
+	t.Run("deletes days older than the retention", func(t *testing.T) {
+		b := createTestSQLBackend(t, 48*time.Hour)
+		clk := clock.NewMock()
+		clk.Set(now.Add(-72 * time.Hour))
+		b.clock = clk
+		rule := createTestRule()
+		require.NoError(t, <-b.Record(context.Background(), rule, []state.StateTransition{
+			transition(eval.Normal, eval.Alerting, data.Labels{"host": "a"}, now.Add(-72*time.Hour)),
+		}))
+
+		clk.Set(now)
+		require.NoError(t, <-b.Record(context.Background(), rule, []state.StateTransition{
+			// older than the retention, it is not recorded
+			transition(eval.Normal, eval.Alerting, data.Labels{"host": "a"}, now.Add(-71*time.Hour)),
+			transition(eval.Normal, eval.Alerting, data.Labels{"host": "b"}, now.Add(-47*time.Hour)),
+			transition(eval.Normal, eval.Alerting, data.Labels{"host": "c"}, now),
+		}))
+
+		deleted, err := b.DeleteExpired(context.Background())
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+
+		expired := transitionDay(now.Add(-72 * time.Hour))
+		err = b.db.WithDbSession(context.Background(), func(sess *db.Session) error {
+			exists, err := sess.IsTableExist(dayTableName(expired))
+			require.False(t, exists)
+			if err != nil {
+				return err
+			}
+			var days []stateHistoryDay
+			if err := sess.Asc("day").Find(&days); err != nil {
+				return err
+			}
+			require.Equal(t, []stateHistoryDay{{Day: transitionDay(now.Add(-47 * time.Hour))}, {Day: transitionDay(now)}}, days)
+			return nil
+		})
+		require.NoError(t, err)
+
+		frame, err := b.Query(context.Background(), models.HistoryQuery{
+			OrgID:        rule.OrgID,
+			SignedInUser: &identity.StaticRequester{},
+			From:         now.Add(-100 * time.Hour),
+			To:           now,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+	})
+}
+
+func createTestSQLBackend(t *testing.T, retention time.Duration) *SQLBackend {
+	t.Helper()
+
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	ac := &acfakes.FakeRuleService{
+		CanReadAllRulesFunc: func(context.Context, identity.Requester) (bool, error) {
+			return true, nil
+		},
+	}
+	return NewSQLBackend(log.NewNopLogger(), db.InitTestDB(t), fakes.NewRuleStore(t), met, ac, retention)
+}
@@ -51,6 +51,7 @@ type Manager struct {
 	images        ImageCapturer
 	historian     Historian
 	externalURL   *url.URL
+	querier       *TemplateQuerier
 
 	doNotSaveNormalState           bool
 	applyNoDataAndErrorToAllStates bool
@@ -66,6 +67,8 @@ type ManagerCfg struct {
 	Images        ImageCapturer
 	Clock         clock.Clock
 	Historian     Historian
+	// TemplateQuerier runs the queries of the query function in templates. If it is nil, the query function returns no series.
+	TemplateQuerier *TemplateQuerier
 	// DoNotSaveNormalState controls whether eval.Normal state is persisted to the database and returned by get methods
 	DoNotSaveNormalState bool
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -103,6 +106,7 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		historian:                      cfg.Historian,
 		clock:                          cfg.Clock,
 		externalURL:                    cfg.ExternalURL,
+		querier:                        cfg.TemplateQuerier,
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
 		applyNoDataAndErrorToAllStates: cfg.ApplyNoDataAndErrorToAllStates,
 		rulesPerRuleGroupLimit:         cfg.RulesPerRuleGroupLimit,
@@ -192,30 +196,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID := entry.Labels.Fingerprint()
-			var resultFp data.Fingerprint
-			if entry.ResultFingerprint != "" {
-				fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
-				if err != nil {
-					st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
-				}
-				resultFp = data.Fingerprint(fp)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-				ResultFingerprint:    resultFp,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -224,6 +206,108 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// LoadStateByRule replaces the states of the rule in the cache with the states that are saved in the database.
+// It is used when another instance of the cluster has evaluated the rule so far, and this instance takes it over.
+func (st *Manager) LoadStateByRule(ctx context.Context, rule *ngModels.AlertRule) {
+	if st.instanceStore == nil {
+		return
+	}
+	logger := st.log.FromContext(ctx).New(rule.GetKey().LogContext()...)
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		logger.Error("Unable to fetch the state of the rule", "error", err)
+		return
+	}
+	states := make(map[data.Fingerprint]*State, len(alertInstances))
+	for _, entry := range alertInstances {
+		s := st.stateFromInstance(entry, rule)
+		states[s.CacheID] = s
+	}
+	st.cache.setRuleStates(rule.OrgID, rule.UID, states)
+	logger.Debug("State of the rule has been loaded", "states", len(states))
+}
+
+// ReleaseStateByRuleUID removes the states of the rule from the cache without resolving them or deleting them from
+// the database, so that the instance of the cluster that evaluates the rule from now on can load them. The states are
+// saved to the database before they are removed, so that the other instance continues from the last evaluation.
+func (st *Manager) ReleaseStateByRuleUID(ctx context.Context, ruleKey ngModels.AlertRuleKey) {
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	if len(states) == 0 {
+		return
+	}
+	logger := st.log.FromContext(ctx).New(ruleKey.LogContext()...)
+	if st.instanceStore != nil {
+		for _, s := range states {
+			if st.doNotSaveNormalState && IsNormalStateWithNoReason(s) {
+				continue
+			}
+			key, err := s.GetAlertInstanceKey()
+			if err != nil {
+				logger.Error("Failed to create a key for alert state to save it to database. The state will be ignored", "cacheID", s.CacheID, "error", err, "labels", s.Labels.String())
+				continue
+			}
+			err = st.instanceStore.SaveAlertInstance(ctx, ngModels.AlertInstance{
+				AlertInstanceKey:  key,
+				Labels:            ngModels.InstanceLabels(s.Labels),
+				CurrentState:      ngModels.InstanceStateType(s.State.String()),
+				CurrentReason:     s.StateReason,
+				LastEvalTime:      s.LastEvaluationTime,
+				CurrentStateSince: s.StartsAt,
+				CurrentStateEnd:   s.EndsAt,
+				ResultFingerprint: s.ResultFingerprint.String(),
+				Severity:          s.Severity,
+				KeepFiringSince:   s.KeepFiringSince,
+			})
+			if err != nil {
+				logger.Error("Failed to save alert state before releasing it", "labels", s.Labels.String(), "state", s.State, "error", err)
+			}
+		}
+	}
+	logger.Debug("State of the rule has been released", "states", len(states))
+}
+
+// ForgetStateByRuleUID removes the states of the rule from the cache without saving them. It is used for rules that
+// are not evaluated by this instance, whose states in the cache were loaded from the database and can be older than
+// the states that the instance that evaluates the rule has saved.
+func (st *Manager) ForgetStateByRuleUID(ctx context.Context, ruleKey ngModels.AlertRuleKey) {
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	if len(states) == 0 {
+		return
+	}
+	logger := st.log.FromContext(ctx).New(ruleKey.LogContext()...)
+	logger.Debug("State of the rule has been removed from the cache", "states", len(states))
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              entry.Labels.Fingerprint(),
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+		ResultFingerprint:    resultFp,
+		Severity:             entry.Severity,
+		KeepFiringSince:      entry.KeepFiringSince,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID string, stateId data.Fingerprint) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -322,6 +406,7 @@ func (st *Manager) ProcessEvalResults(
 	logger := st.log.FromContext(ctx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
 	states := st.setNextStateForRule(ctx, alertRule, results, extraLabels, logger)
+	states = append(states, st.keepFiringMissingStates(logger, evaluatedAt, alertRule)...)
 
 	staleStates := st.deleteStaleStatesFromCache(ctx, logger, evaluatedAt, alertRule)
 	span.AddEvent("results processed", trace.WithAttributes(
@@ -381,8 +466,10 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 		}
 	}
 	transitions := make([]StateTransition, 0, len(results))
+	// The query function is shared by all results so that queries are run once per evaluation rather than once per instance.
+	queryFunc := st.querier.QueryFunc(alertRule)
 	for _, result := range results {
-		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.externalURL)
+		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.externalURL, queryFunc)
 		s := st.setNextState(ctx, alertRule, currentState, result, logger)
 		transitions = append(transitions, s)
 	}
@@ -414,6 +501,11 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	currentState.LastEvaluationString = result.EvaluationString
 	oldState := currentState.State
 	oldReason := currentState.StateReason
+	oldSeverity := currentState.Severity
+	// Results without a severity keep the last one, so that resolved alerts have the labels of the firing ones.
+	if result.Severity != "" {
+		currentState.Severity = result.Severity
+	}
 
 	// Add the instance to the log context to help correlate log lines for a state
 	logger = logger.New("instance", result.Instance)
@@ -441,8 +533,13 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 
 	switch result.State {
 	case eval.Normal:
-		logger.Debug("Setting next state", "handler", "resultNormal")
-		resultNormal(currentState, alertRule, result, logger, "")
+		if keepFiring(currentState, alertRule, result.EvaluatedAt) {
+			logger.Debug("Setting next state", "handler", "keepFiring", "keep_firing_since", currentState.KeepFiringSince)
+			currentState.Maintain(alertRule.IntervalSeconds, result.EvaluatedAt)
+		} else {
+			logger.Debug("Setting next state", "handler", "resultNormal")
+			resultNormal(currentState, alertRule, result, logger, "")
+		}
 	case eval.Alerting:
 		logger.Debug("Setting next state", "handler", "resultAlerting")
 		resultAlerting(currentState, alertRule, result, logger, "")
@@ -465,6 +562,12 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = resultStateReason(result, alertRule)
 	}
 
+	if currentState.State == eval.Alerting && result.State == eval.Normal {
+		currentState.StateReason = ngModels.StateReasonKeepFiring
+	} else {
+		currentState.KeepFiringSince = nil
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	newlyResolved := false
@@ -493,6 +596,7 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		State:               currentState,
 		PreviousState:       oldState,
 		PreviousStateReason: oldReason,
+		PreviousSeverity:    oldSeverity,
 	}
 
 	if st.metrics != nil {
@@ -541,11 +645,39 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 	}
 }
 
+// keepFiringMissingStates keeps firing the alerting states of the rule that did not get a result in the evaluation,
+// until the keep firing duration of the rule elapses. Afterwards, the states are resolved as stale.
+func (st *Manager) keepFiringMissingStates(logger log.Logger, evaluatedAt time.Time, alertRule *ngModels.AlertRule) []StateTransition {
+	if alertRule.KeepFiringFor <= 0 {
+		return nil
+	}
+	var transitions []StateTransition
+	for _, s := range st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID, false) {
+		if !s.LastEvaluationTime.Before(evaluatedAt) || !keepFiring(s, alertRule, evaluatedAt) {
+			continue
+		}
+		logger.Debug("Keeping missing series firing", "cacheID", s.CacheID, "keep_firing_since", s.KeepFiringSince)
+		oldReason := s.StateReason
+		s.Maintain(alertRule.IntervalSeconds, evaluatedAt)
+		s.StateReason = ngModels.StateReasonKeepFiring
+		s.LastEvaluationTime = evaluatedAt
+		st.cache.set(s)
+		transitions = append(transitions, StateTransition{
+			State:               s,
+			PreviousState:       eval.Alerting,
+			PreviousStateReason: oldReason,
+		})
+	}
+	return transitions
+}
+
 func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Logger, evaluatedAt time.Time, alertRule *ngModels.AlertRule) []StateTransition {
 	// If we are removing two or more stale series it makes sense to share the resolved image as the alert rule is the same.
 	// TODO: We will need to change this when we support images without screenshots as each series will have a different image
 	staleStates := st.cache.deleteRuleStates(alertRule.GetKey(), func(s *State) bool {
-		return stateIsStale(evaluatedAt, s.LastEvaluationTime, alertRule.IntervalSeconds)
+		// A state that kept firing without a result is resolved as soon as its keep firing duration elapses.
+		keepFiringExpired := s.KeepFiringSince != nil && s.LastEvaluationTime.Before(evaluatedAt)
+		return keepFiringExpired || stateIsStale(evaluatedAt, s.LastEvaluationTime, alertRule.IntervalSeconds)
 	})
 	resolvedStates := make([]StateTransition, 0, len(staleStates))
 
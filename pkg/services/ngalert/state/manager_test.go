@@ -75,6 +75,7 @@ func TestWarmStateCache(t *testing.T) {
 			LastEvaluationTime: evaluationTime,
 			Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
 			ResultFingerprint:  data.Fingerprint(math.MaxUint64 - 1),
+			Severity:           "critical",
 		},
 		{
 			AlertRuleUID:       rule.UID,
@@ -146,6 +147,7 @@ func TestWarmStateCache(t *testing.T) {
 		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
 		Labels:            labels,
 		ResultFingerprint: data.Fingerprint(math.MaxUint64 - 1).String(),
+		Severity:          "critical",
 	})
 
 	labels = models.InstanceLabels{"test3": "testValue3"}
@@ -225,6 +227,149 @@ func TestWarmStateCache(t *testing.T) {
 	})
 }
 
+func TestLoadAndReleaseStateByRule(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 600, mainOrgID)
+	other := tests.CreateTestAlertRule(t, ctx, dbstore, 600, mainOrgID)
+
+	labels := models.InstanceLabels{"test1": "testValue1"}
+	_, hash, _ := labels.StringAndHash()
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, models.AlertInstance{
+		AlertInstanceKey: models.AlertInstanceKey{
+			RuleOrgID:  rule.OrgID,
+			RuleUID:    rule.UID,
+			LabelsHash: hash,
+		},
+		CurrentState:      models.InstanceStateFiring,
+		CurrentReason:     models.StateReasonKeepFiring,
+		LastEvalTime:      evaluationTime,
+		CurrentStateSince: evaluationTime.Add(-1 * time.Minute),
+		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
+		Labels:            labels,
+		Severity:          "critical",
+		KeepFiringSince:   util.Pointer(evaluationTime.Add(-30 * time.Second)),
+	}))
+
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: dbstore,
+		Images:        &state.NoopImageService{},
+		Clock:         clock.NewMock(),
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	t.Run("loads the state of the rule from the database", func(t *testing.T) {
+		st.LoadStateByRule(ctx, rule)
+		st.LoadStateByRule(ctx, other)
+
+		states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+		require.Equal(t, models.StateReasonKeepFiring, states[0].StateReason)
+		require.Equal(t, data.Labels{"test1": "testValue1"}, states[0].Labels)
+		require.Equal(t, evaluationTime.Add(-1*time.Minute), states[0].StartsAt)
+		require.Equal(t, "critical", states[0].Severity)
+		require.Equal(t, evaluationTime.Add(-30*time.Second).Unix(), states[0].KeepFiringSince.Unix())
+		require.Empty(t, st.GetStatesForRuleUID(other.OrgID, other.UID))
+	})
+
+	t.Run("releases the state of the rule without deleting it from the database", func(t *testing.T) {
+		st.ReleaseStateByRuleUID(ctx, rule.GetKey())
+
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		instances, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+		require.Equal(t, "critical", instances[0].Severity)
+		require.Equal(t, evaluationTime.Add(-30*time.Second).Unix(), instances[0].KeepFiringSince.Unix())
+	})
+
+	t.Run("forgets the state of the rule without saving it to the database", func(t *testing.T) {
+		st.LoadStateByRule(ctx, rule)
+		require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), 1)
+
+		// the instance that evaluates the rule saves a newer state
+		require.NoError(t, dbstore.SaveAlertInstance(ctx, models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  rule.OrgID,
+				RuleUID:    rule.UID,
+				LabelsHash: hash,
+			},
+			CurrentState:      models.InstanceStateNormal,
+			LastEvalTime:      evaluationTime.Add(time.Minute),
+			CurrentStateSince: evaluationTime.Add(time.Minute),
+			CurrentStateEnd:   evaluationTime.Add(time.Minute),
+			Labels:            labels,
+		}))
+
+		st.ForgetStateByRuleUID(ctx, rule.GetKey())
+
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		instances, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+		require.Equal(t, models.InstanceStateNormal, instances[0].CurrentState)
+		require.Equal(t, evaluationTime.Add(time.Minute).Unix(), instances[0].LastEvalTime.Unix())
+	})
+}
+
+func TestStateHandoffBetweenEvaluations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 10, 1)
+	rule.For = time.Minute
+
+	clk := clock.NewMock()
+	clk.Set(time.Now())
+	newManager := func() *state.Manager {
+		cfg := state.ManagerCfg{
+			Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore: dbstore,
+			Images:        &state.NoopImageService{},
+			Clock:         clk,
+			Historian:     &state.FakeHistorian{},
+			Tracer:        tracing.InitializeTracerForTest(),
+			Log:           log.New("ngalert.state.manager"),
+		}
+		// states are saved only when they are released
+		return state.NewManager(cfg, state.NewNoopPersister())
+	}
+	previousOwner, newOwner := newManager(), newManager()
+	result := eval.Result{Instance: data.Labels{"instance": "a"}, State: eval.Alerting}
+	evaluate := func(st *state.Manager) *state.State {
+		result.EvaluatedAt = clk.Now()
+		transitions := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil, nil)
+		require.Len(t, transitions, 1)
+		return transitions[0].State
+	}
+
+	pending := evaluate(previousOwner)
+	require.Equal(t, eval.Pending, pending.State)
+	startsAt := pending.StartsAt
+
+	// the rule is handed over between two evaluations
+	previousOwner.ReleaseStateByRuleUID(ctx, rule.GetKey())
+	require.Empty(t, previousOwner.GetStatesForRuleUID(rule.OrgID, rule.UID))
+	newOwner.LoadStateByRule(ctx, rule)
+
+	clk.Add(30 * time.Second)
+	s := evaluate(newOwner)
+	require.Equal(t, eval.Pending, s.State)
+	require.Equal(t, startsAt.Unix(), s.StartsAt.Unix(), "the new owner should continue the pending period of the previous owner")
+
+	clk.Add(30 * time.Second)
+	s = evaluate(newOwner)
+	require.Equal(t, eval.Alerting, s.State)
+}
+
 func TestDashboardAnnotations(t *testing.T) {
 	evaluationTime, err := time.Parse("2006-01-02", "2022-01-01")
 	require.NoError(t, err)
@@ -2127,3 +2272,152 @@ func mergeLabels(a, b data.Labels) data.Labels {
 	}
 	return result
 }
+
+func TestKeepFiring(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*state.Manager, *clock.Mock, *models.AlertRule) {
+		clk := clock.NewMock()
+		cfg := state.ManagerCfg{
+			Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore: &state.FakeInstanceStore{},
+			Images:        &state.NoopImageService{},
+			Clock:         clk,
+			Historian:     &state.FakeHistorian{},
+			Tracer:        tracing.InitializeTracerForTest(),
+			Log:           log.New("ngalert.state.manager"),
+		}
+		gen := models.RuleGen
+		rule := gen.With(gen.WithFor(0), gen.WithIntervalSeconds(10), gen.WithKeepFiringFor(20*time.Second)).GenerateRef()
+		return state.NewManager(cfg, state.NewNoopPersister()), clk, rule
+	}
+	interval := 10 * time.Second
+	instance := eval.ResultGen(eval.WithState(eval.Alerting))()
+	evaluate := func(st *state.Manager, clk *clock.Mock, rule *models.AlertRule, results ...eval.State) []state.StateTransition {
+		evalResults := make(eval.Results, 0, len(results))
+		for _, s := range results {
+			r := instance
+			r.State = s
+			r.EvaluatedAt = clk.Now()
+			evalResults = append(evalResults, r)
+		}
+		return st.ProcessEvalResults(ctx, clk.Now(), rule, evalResults, nil, nil)
+	}
+
+	t.Run("keeps firing after the condition is no longer met", func(t *testing.T) {
+		st, clk, rule := setup(t)
+		evaluate(st, clk, rule, eval.Alerting)
+
+		for i := 0; i < 2; i++ {
+			clk.Add(interval)
+			transitions := evaluate(st, clk, rule, eval.Normal)
+			require.Len(t, transitions, 1)
+			require.Equal(t, eval.Alerting, transitions[0].State.State)
+			require.Equal(t, models.StateReasonKeepFiring, transitions[0].StateReason)
+			require.Nil(t, transitions[0].ResolvedAt)
+			require.True(t, transitions[0].EndsAt.After(clk.Now()))
+		}
+
+		clk.Add(interval)
+		transitions := evaluate(st, clk, rule, eval.Normal)
+		require.Len(t, transitions, 1)
+		require.Equal(t, eval.Normal, transitions[0].State.State)
+		require.Empty(t, transitions[0].StateReason)
+		require.Equal(t, eval.Alerting, transitions[0].PreviousState)
+		require.Equal(t, models.StateReasonKeepFiring, transitions[0].PreviousStateReason)
+		require.Equal(t, clk.Now(), *transitions[0].ResolvedAt)
+		require.Nil(t, transitions[0].KeepFiringSince)
+	})
+
+	t.Run("restarts the duration if the condition is met again", func(t *testing.T) {
+		st, clk, rule := setup(t)
+		evaluate(st, clk, rule, eval.Alerting)
+		clk.Add(interval)
+		evaluate(st, clk, rule, eval.Normal)
+		clk.Add(interval)
+		transitions := evaluate(st, clk, rule, eval.Alerting)
+		require.Equal(t, eval.Alerting, transitions[0].State.State)
+		require.Empty(t, transitions[0].StateReason)
+		require.Nil(t, transitions[0].KeepFiringSince)
+
+		clk.Add(interval)
+		evaluate(st, clk, rule, eval.Normal)
+		clk.Add(interval)
+		transitions = evaluate(st, clk, rule, eval.Normal)
+		require.Equal(t, eval.Alerting, transitions[0].State.State)
+		require.Equal(t, models.StateReasonKeepFiring, transitions[0].StateReason)
+	})
+
+	t.Run("keeps missing series firing", func(t *testing.T) {
+		st, clk, rule := setup(t)
+		evaluate(st, clk, rule, eval.Alerting)
+
+		for i := 0; i < 2; i++ {
+			clk.Add(interval)
+			transitions := evaluate(st, clk, rule)
+			require.Len(t, transitions, 1)
+			require.Equal(t, eval.Alerting, transitions[0].State.State)
+			require.Equal(t, models.StateReasonKeepFiring, transitions[0].StateReason)
+			require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), 1)
+		}
+
+		clk.Add(interval)
+		transitions := evaluate(st, clk, rule)
+		require.Len(t, transitions, 1)
+		require.Equal(t, eval.Normal, transitions[0].State.State)
+		require.Equal(t, models.StateReasonMissingSeries, transitions[0].StateReason)
+		require.Equal(t, clk.Now(), *transitions[0].ResolvedAt)
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+	})
+
+	t.Run("resolves immediately without keep firing duration", func(t *testing.T) {
+		st, clk, rule := setup(t)
+		rule.KeepFiringFor = 0
+		evaluate(st, clk, rule, eval.Alerting)
+		clk.Add(interval)
+		transitions := evaluate(st, clk, rule, eval.Normal)
+		require.Equal(t, eval.Normal, transitions[0].State.State)
+	})
+}
+
+func TestSeverityEscalation(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+	gen := models.RuleGen
+	rule := gen.With(gen.WithFor(30*time.Second), gen.WithIntervalSeconds(10)).GenerateRef()
+	instance := eval.ResultGen(eval.WithState(eval.Alerting))()
+	evaluate := func(severity string) state.StateTransition {
+		r := instance
+		r.Severity = severity
+		r.EvaluatedAt = clk.Now()
+		transitions := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{r}, nil, nil)
+		require.Len(t, transitions, 1)
+		return transitions[0]
+	}
+
+	first := evaluate("warning")
+	require.Equal(t, eval.Pending, first.State.State)
+	startsAt := first.StartsAt
+
+	clk.Add(20 * time.Second)
+	escalated := evaluate("critical")
+	require.Same(t, first.State, escalated.State, "an escalation should keep the alert instance")
+	require.Equal(t, eval.Pending, escalated.State.State)
+	require.Equal(t, startsAt, escalated.StartsAt, "an escalation should not restart the pending period")
+	require.Equal(t, "critical", escalated.Severity)
+	require.Equal(t, "warning", escalated.PreviousSeverity)
+
+	clk.Add(10 * time.Second)
+	firing := evaluate("critical")
+	require.Equal(t, eval.Alerting, firing.State.State)
+	require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), 1)
+}
@@ -102,6 +102,8 @@ func (a *SyncStatePersister) saveAlertStates(ctx context.Context, states ...Stat
 			LastEvalTime:      s.LastEvaluationTime,
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
+			Severity:          s.Severity,
+			KeepFiringSince:   s.KeepFiringSince,
 		}
 
 		err = a.store.SaveAlertInstance(ctx, instance)
@@ -57,6 +57,11 @@ type State struct {
 	// If a label is templated then the template is first evaluated to derive the final label.
 	Labels data.Labels
 
+	// Severity is the severity level of the last result that had one. It is not one of the Labels,
+	// so a change of severity does not change the state, and is added as a label to the alerts sent
+	// to the Alertmanager.
+	Severity string
+
 	// Values contains the values of any instant vectors, reduce and math expressions, or classic
 	// conditions.
 	Values map[string]float64
@@ -68,7 +73,11 @@ type State struct {
 	// ResolvedAt is set when the state is first resolved. That is to say, when the state first transitions
 	// from Alerting, NoData, or Error to Normal. It is reset to zero when the state transitions from Normal
 	// to any other state.
-	ResolvedAt           *time.Time
+	ResolvedAt *time.Time
+	// KeepFiringSince is set when the condition of an alerting state is first not met while the rule has
+	// a keep firing duration. The state keeps firing until the duration has elapsed since then.
+	// It is not persisted, so after a restart the keep firing duration starts again.
+	KeepFiringSince      *time.Time
 	LastSentAt           *time.Time
 	LastEvaluationString string
 	LastEvaluationTime   time.Time
@@ -173,6 +182,7 @@ type StateTransition struct {
 	*State
 	PreviousState       eval.State
 	PreviousStateReason string
+	PreviousSeverity    string
 }
 
 func (c StateTransition) Formatted() string {
@@ -239,6 +249,18 @@ func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger
 	}
 }
 
+// keepFiring reports whether an alerting state should keep firing although its condition is no longer met,
+// because the keep firing duration of the rule has not elapsed yet. It starts the duration if needed.
+func keepFiring(state *State, rule *models.AlertRule, evaluatedAt time.Time) bool {
+	if state.State != eval.Alerting || rule.KeepFiringFor <= 0 {
+		return false
+	}
+	if state.KeepFiringSince == nil {
+		state.KeepFiringSince = &evaluatedAt
+	}
+	return evaluatedAt.Sub(*state.KeepFiringSince) < rule.KeepFiringFor
+}
+
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
 	switch state.State {
 	case eval.Alerting:
@@ -447,6 +469,7 @@ func (a *State) Equals(b *State) bool {
 		a.OrgID == b.OrgID &&
 		a.CacheID == b.CacheID &&
 		a.Labels.String() == b.Labels.String() &&
+		a.Severity == b.Severity &&
 		a.State.String() == b.State.String() &&
 		a.StartsAt == b.StartsAt &&
 		a.EndsAt == b.EndsAt &&
@@ -585,3 +608,12 @@ func GetRuleExtraLabels(l log.Logger, rule *models.AlertRule, folderTitle string
 	}
 	return extraLabels
 }
+
+// GetRuleInstanceLabels returns the labels of the alert instance that the rule creates for an evaluation result with
+// the given labels, in the same way as the labels of the states are calculated. Templates in the labels of the rule
+// are expanded.
+func GetRuleInstanceLabels(ctx context.Context, l log.Logger, rule *models.AlertRule, folderTitle string, includeFolder bool, instance data.Labels, evaluatedAt time.Time) data.Labels {
+	extraLabels := GetRuleExtraLabels(l, rule, folderTitle, includeFolder)
+	s := calculateState(ctx, l, rule, eval.Result{Instance: instance, EvaluatedAt: evaluatedAt}, extraLabels, nil, nil)
+	return s.Labels
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -795,3 +796,25 @@ func TestGetRuleExtraLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRuleInstanceLabels(t *testing.T) {
+	logger := log.New()
+	rule := ngmodels.RuleGen.With(
+		ngmodels.RuleMuts.WithNoNotificationSettings(),
+		ngmodels.RuleMuts.WithLabels(data.Labels{"team": "{{ $labels.instance }}-team", models.RuleUIDLabel: "overridden"}),
+	).GenerateRef()
+	folderTitle := uuid.NewString()
+
+	result := GetRuleInstanceLabels(context.Background(), logger, rule, folderTitle, true, data.Labels{
+		"instance":           "host-1",
+		model.AlertNameLabel: "from-result",
+	}, time.Now())
+	require.Equal(t, data.Labels{
+		models.NamespaceUIDLabel: rule.NamespaceUID,
+		model.AlertNameLabel:     rule.Title,
+		models.RuleUIDLabel:      rule.UID,
+		models.FolderTitleLabel:  folderTitle,
+		"team":                   "host-1-team",
+		"instance":               "host-1",
+	}, result)
+}
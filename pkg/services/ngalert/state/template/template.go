@@ -2,12 +2,15 @@ package template
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template/parse"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -95,7 +98,13 @@ func (e ExpandError) Error() string {
 	return fmt.Sprintf("failed to expand template '%s': %s", e.Tmpl, e.Err)
 }
 
-func Expand(ctx context.Context, name, tmpl string, data Data, externalURL *url.URL, evaluatedAt time.Time) (string, error) {
+// QueryFunc runs the query expr against the data source with the given UID at the given time and returns the
+// resulting series.
+type QueryFunc func(ctx context.Context, datasourceUID, expr string, ts time.Time) (promql.Vector, error)
+
+// Expand expands the template. The query function of the template runs its queries with queryFunc. If queryFunc is
+// nil, the query function returns no series.
+func Expand(ctx context.Context, name, tmpl string, data Data, externalURL *url.URL, evaluatedAt time.Time, queryFunc QueryFunc) (string, error) {
 	if !strings.Contains(tmpl, "{{") { // If it is not a template, skip expanding it.
 		return tmpl, nil
 	}
@@ -104,15 +113,11 @@ func Expand(ctx context.Context, name, tmpl string, data Data, externalURL *url.
 	name = "__alert_" + name
 	// add variables for the labels and values to the beginning of the template
 	tmpl = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + tmpl
-	// ctx and queryFunc are no-ops as `query()` is not supported in Grafana
-	queryFunc := func(context.Context, string, time.Time) (promql.Vector, error) {
-		return nil, nil
-	}
 	tm := model.Time(timestamp.FromTime(evaluatedAt))
 	// Use missingkey=invalid so missing data shows <no value> instead of the type's default value
 	options := []string{"missingkey=invalid"}
 
-	expander := template.NewTemplateExpander(ctx, tmpl, name, data, tm, queryFunc, externalURL, options)
+	expander := template.NewTemplateExpander(ctx, tmpl, name, data, tm, templateQueryFunc(queryFunc), externalURL, options)
 	expander.Funcs(defaultFuncs)
 
 	result, err := expander.Expand()
@@ -125,3 +130,113 @@ func Expand(ctx context.Context, name, tmpl string, data Data, externalURL *url.
 	result = strings.ReplaceAll(result, "<no value>", "[no value]")
 	return result, nil
 }
+
+// templateQueryFunc returns the function that runs the queries of the query function of the template. Unlike in
+// Prometheus, the query is a JSON object with the UID of the data source and the expression, the same as the query of
+// the graphLink and tableLink functions.
+func templateQueryFunc(queryFunc QueryFunc) template.QueryFunc {
+	return func(ctx context.Context, data string, ts time.Time) (promql.Vector, error) {
+		if queryFunc == nil {
+			return nil, nil
+		}
+		var q query
+		if err := json.Unmarshal([]byte(data), &q); err != nil {
+			return nil, fmt.Errorf("query must be a JSON object with the datasource and the expr: %w", err)
+		}
+		if q.Datasource == "" || q.Expr == "" {
+			return nil, errors.New("query must have both a datasource and an expr")
+		}
+		return queryFunc(ctx, q.Datasource, q.Expr, ts)
+	}
+}
+
+// QueryDatasourceUIDs returns the sorted UIDs of the data sources of the queries of the query function in the
+// templates. Only queries that are string literals are returned as the data source of other queries is known only when
+// the template is expanded. Templates that cannot be parsed are skipped.
+func QueryDatasourceUIDs(templates ...map[string]string) []string {
+	uids := make(map[string]struct{})
+	for _, m := range templates {
+		for _, tmpl := range m {
+			if !strings.Contains(tmpl, "{{") {
+				continue
+			}
+			tree := parse.New("")
+			tree.Mode = parse.SkipFuncCheck
+			treeSet := make(map[string]*parse.Tree)
+			if _, err := tree.Parse(tmpl, "", "", treeSet); err != nil {
+				continue
+			}
+			for _, t := range treeSet {
+				collectQueryDatasourceUIDs(t.Root, uids)
+			}
+		}
+	}
+	result := make([]string, 0, len(uids))
+	for uid := range uids {
+		result = append(result, uid)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func collectQueryDatasourceUIDs(node parse.Node, uids map[string]struct{}) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectQueryDatasourceUIDs(child, uids)
+		}
+	case *parse.ActionNode:
+		collectQueryDatasourceUIDs(n.Pipe, uids)
+	case *parse.IfNode:
+		collectBranchQueryDatasourceUIDs(&n.BranchNode, uids)
+	case *parse.RangeNode:
+		collectBranchQueryDatasourceUIDs(&n.BranchNode, uids)
+	case *parse.WithNode:
+		collectBranchQueryDatasourceUIDs(&n.BranchNode, uids)
+	case *parse.TemplateNode:
+		collectQueryDatasourceUIDs(n.Pipe, uids)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			// Both {{ query "..." }} and {{ "..." | query }} run a query.
+			if isQueryIdentifier(cmd.Args[0]) {
+				if len(cmd.Args) > 1 {
+					addQueryDatasourceUID(cmd.Args[1], uids)
+				} else if i > 0 && len(n.Cmds[i-1].Args) == 1 {
+					addQueryDatasourceUID(n.Cmds[i-1].Args[0], uids)
+				}
+			}
+			for _, arg := range cmd.Args {
+				collectQueryDatasourceUIDs(arg, uids)
+			}
+		}
+	}
+}
+
+func collectBranchQueryDatasourceUIDs(n *parse.BranchNode, uids map[string]struct{}) {
+	collectQueryDatasourceUIDs(n.Pipe, uids)
+	collectQueryDatasourceUIDs(n.List, uids)
+	collectQueryDatasourceUIDs(n.ElseList, uids)
+}
+
+func isQueryIdentifier(node parse.Node) bool {
+	ident, ok := node.(*parse.IdentifierNode)
+	return ok && ident.Ident == "query"
+}
+
+func addQueryDatasourceUID(node parse.Node, uids map[string]struct{}) {
+	s, ok := node.(*parse.StringNode)
+	if !ok {
+		return
+	}
+	var q query
+	if err := json.Unmarshal([]byte(s.Text), &q); err != nil || q.Datasource == "" {
+		return
+	}
+	uids[q.Datasource] = struct{}{}
+}
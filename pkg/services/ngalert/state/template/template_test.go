@@ -5,8 +5,11 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -436,7 +439,7 @@ func TestExpandTemplate(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			v, err := Expand(context.Background(), "test", c.text, NewData(c.labels, c.alertInstance), externalURL, c.alertInstance.EvaluatedAt)
+			v, err := Expand(context.Background(), "test", c.text, NewData(c.labels, c.alertInstance), externalURL, c.alertInstance.EvaluatedAt, nil)
 			if c.expectedError != nil {
 				require.NotNil(t, err)
 				require.EqualError(t, c.expectedError, err.Error())
@@ -447,3 +450,59 @@ func TestExpandTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandQuery(t *testing.T) {
+	evaluatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var calls []string
+	queryFunc := func(_ context.Context, datasourceUID, expr string, ts time.Time) (promql.Vector, error) {
+		calls = append(calls, datasourceUID+":"+expr)
+		require.Equal(t, evaluatedAt, ts.UTC())
+		if expr == "error" {
+			return nil, errors.New("query failed")
+		}
+		return promql.Vector{
+			{Metric: labels.FromStrings("pod", "b"), F: 2048},
+			{Metric: labels.FromStrings("pod", "a"), F: 1234.5},
+		}, nil
+	}
+
+	t.Run("returns the series of the query", func(t *testing.T) {
+		calls = nil
+		text := "{{ range query `{\"datasource\": \"prom\", \"expr\": \"topk(2, cpu)\"}` | sortByLabel \"pod\" }}{{ .Labels.pod }}={{ .Value | humanize }} {{ end }}"
+		v, err := Expand(context.Background(), "test", text, Data{}, nil, evaluatedAt, queryFunc)
+		require.NoError(t, err)
+		require.Equal(t, "a=1.234k b=2.048k ", v)
+		require.Equal(t, []string{"prom:topk(2, cpu)"}, calls)
+	})
+
+	t.Run("returns an error if the query is not a JSON object with a datasource and an expr", func(t *testing.T) {
+		calls = nil
+		for _, q := range []string{`"up"`, "`{\"expr\": \"up\"}`", "`{\"datasource\": \"prom\"}`"} {
+			_, err := Expand(context.Background(), "test", "{{ query "+q+" }}", Data{}, nil, evaluatedAt, queryFunc)
+			require.ErrorContains(t, err, "query must")
+		}
+		require.Empty(t, calls)
+	})
+
+	t.Run("returns the error of the query", func(t *testing.T) {
+		_, err := Expand(context.Background(), "test", "{{ query `{\"datasource\": \"prom\", \"expr\": \"error\"}` }}", Data{}, nil, evaluatedAt, queryFunc)
+		require.ErrorContains(t, err, "query failed")
+	})
+}
+
+func TestQueryDatasourceUIDs(t *testing.T) {
+	labels := map[string]string{
+		"team":     "a",
+		"severity": "{{ if gt (len (query `{\"datasource\": \"prom\", \"expr\": \"up\"}`)) 0 }}high{{ end }}",
+	}
+	annotations := map[string]string{
+		"piped":    "{{ range `{\"datasource\": \"loki\", \"expr\": \"count_over_time({job=\\\"a\\\"}[1m])\"}` | query }}{{ .Value }}{{ end }}",
+		"defined":  "{{ define \"t\" }}{{ query `{\"datasource\": \"mimir\", \"expr\": \"up\"}` }}{{ end }}{{ template \"t\" }}",
+		"dynamic":  "{{ query (printf `{\"datasource\": \"%s\", \"expr\": \"up\"}` $labels.ds) }}",
+		"invalid":  "{{ query `{\"expr\": \"up\"}` }}",
+		"unparsed": "{{ query `{\"datasource\": \"broken\", \"expr\": \"up\"}` ",
+		"repeated": "{{ with query `{\"datasource\": \"prom\", \"expr\": \"down\"}` }}{{ end }}",
+	}
+	require.Equal(t, []string{"loki", "mimir", "prom"}, QueryDatasourceUIDs(labels, annotations))
+	require.Empty(t, QueryDatasourceUIDs(map[string]string{"summary": "{{ $value }}"}))
+}
@@ -0,0 +1,157 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/template"
+)
+
+const (
+	templateQueryRefID = "A"
+	// maxTemplateQueriesPerEvaluation is the maximum number of distinct queries that the templates of a rule can run in
+	// one evaluation.
+	maxTemplateQueriesPerEvaluation = 10
+)
+
+// TemplateQuerier runs the queries of the query function in the templates of alert rule labels and annotations
+// through the expression service.
+type TemplateQuerier struct {
+	evaluatorFactory eval.EvaluatorFactory
+	userFor          func(orgID int64, datasourceUIDs []string) identity.Requester
+	timeout          time.Duration
+	maxSeries        int
+}
+
+// NewTemplateQuerier returns a TemplateQuerier that runs the queries of a rule as the user returned by userFor for the
+// organization and the data sources of the rule. A query fails if it takes longer than timeout or returns more than
+// maxSeries series.
+func NewTemplateQuerier(evaluatorFactory eval.EvaluatorFactory, userFor func(orgID int64, datasourceUIDs []string) identity.Requester, timeout time.Duration, maxSeries int) *TemplateQuerier {
+	return &TemplateQuerier{
+		evaluatorFactory: evaluatorFactory,
+		userFor:          userFor,
+		timeout:          timeout,
+		maxSeries:        maxSeries,
+	}
+}
+
+type templateQueryKey struct {
+	datasourceUID string
+	expr          string
+	ts            time.Time
+}
+
+type templateQueryResult struct {
+	vector promql.Vector
+	err    error
+}
+
+// QueryFunc returns the function that runs the queries of the templates of the rule in one evaluation. It returns nil
+// if the querier is nil.
+//
+// Queries can only use the data sources returned by template.QueryDatasourceUIDs for the templates of the rule, the
+// permissions to query them are checked when the rule is saved. The results are cached so the same query is run once
+// per evaluation rather than once per alert instance, and at most maxTemplateQueriesPerEvaluation distinct queries
+// are run.
+func (q *TemplateQuerier) QueryFunc(rule *ngModels.AlertRule) template.QueryFunc {
+	if q == nil {
+		return nil
+	}
+	datasourceUIDs := template.QueryDatasourceUIDs(rule.Labels, rule.Annotations)
+	user := q.userFor(rule.OrgID, datasourceUIDs)
+	var (
+		mtx     sync.Mutex
+		results = make(map[templateQueryKey]templateQueryResult)
+	)
+	return func(ctx context.Context, datasourceUID, expr string, ts time.Time) (promql.Vector, error) {
+		if !slices.Contains(datasourceUIDs, datasourceUID) {
+			return nil, fmt.Errorf("data source %s is not allowed: the query must be a string literal in the template", datasourceUID)
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		key := templateQueryKey{datasourceUID: datasourceUID, expr: expr, ts: ts}
+		res, ok := results[key]
+		if !ok {
+			if len(results) >= maxTemplateQueriesPerEvaluation {
+				return nil, fmt.Errorf("templates of the rule ran more than %d distinct queries", maxTemplateQueriesPerEvaluation)
+			}
+			res.vector, res.err = q.query(ctx, user, datasourceUID, expr, ts)
+			results[key] = res
+		}
+		// Template functions such as sortByLabel sort the vector in place.
+		return slices.Clone(res.vector), res.err
+	}
+}
+
+func (q *TemplateQuerier) query(ctx context.Context, user identity.Requester, datasourceUID, expr string, ts time.Time) (promql.Vector, error) {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	model, err := json.Marshal(map[string]any{
+		"refId":   templateQueryRefID,
+		"expr":    expr,
+		"instant": true,
+		"range":   false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	condition := ngModels.Condition{
+		Condition: templateQueryRefID,
+		Data: []ngModels.AlertQuery{{
+			RefID:             templateQueryRefID,
+			DatasourceUID:     datasourceUID,
+			RelativeTimeRange: ngModels.RelativeTimeRange{From: ngModels.Duration(10 * time.Minute)},
+			Model:             model,
+		}},
+	}
+	evaluator, err := q.evaluatorFactory.Create(eval.NewContext(ctx, user), condition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	resp, err := evaluator.EvaluateRaw(ctx, ts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	res, ok := resp.Responses[templateQueryRefID]
+	if !ok {
+		return nil, nil
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to run query: %w", res.Error)
+	}
+
+	// The expression service returns either numbers or time series. The value of a time series is its last value.
+	result := make(promql.Vector, 0, len(res.Frames))
+	for _, frame := range res.Frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() || field.Len() == 0 {
+				continue
+			}
+			v, err := field.NullableFloatAt(field.Len() - 1)
+			if err != nil || v == nil {
+				continue
+			}
+			if len(result) == q.maxSeries {
+				return nil, fmt.Errorf("query returned more than %d series", q.maxSeries)
+			}
+			result = append(result, promql.Sample{
+				T:      timestamp.FromTime(ts),
+				F:      *v,
+				Metric: labels.FromMap(field.Labels),
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,178 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type recordingEvaluatorFactory struct {
+	evaluator  eval.ConditionEvaluator
+	conditions []ngModels.Condition
+	users      []identity.Requester
+}
+
+func (f *recordingEvaluatorFactory) Validate(_ eval.EvaluationContext, _ ngModels.Condition) error {
+	return nil
+}
+
+func (f *recordingEvaluatorFactory) Create(ctx eval.EvaluationContext, condition ngModels.Condition) (eval.ConditionEvaluator, error) {
+	f.conditions = append(f.conditions, condition)
+	f.users = append(f.users, ctx.User)
+	return f.evaluator, nil
+}
+
+func TestTemplateQuerier(t *testing.T) {
+	ts := time.Now()
+	userFor := func(orgID int64, datasourceUIDs []string) identity.Requester {
+		return &user.SignedInUser{OrgID: orgID, Permissions: map[int64]map[string][]string{
+			orgID: {datasources.ActionQuery: datasourceUIDs},
+		}}
+	}
+	ruleFor := func(orgID int64) *ngModels.AlertRule {
+		return &ngModels.AlertRule{OrgID: orgID, Annotations: map[string]string{
+			"summary": `{{ range query "{\"datasource\": \"prom\", \"expr\": \"cpu\"}" }}{{ .Value }}{{ end }}`,
+		}}
+	}
+	number := func(lbls data.Labels, v *float64) *data.Frame {
+		return data.NewFrame("", data.NewField("Value", lbls, []*float64{v}))
+	}
+	series := func(lbls data.Labels, values ...float64) *data.Frame {
+		times := make([]time.Time, 0, len(values))
+		for i := range values {
+			times = append(times, ts.Add(time.Duration(i)*time.Minute))
+		}
+		return data.NewFrame("", data.NewField("Time", nil, times), data.NewField("Value", lbls, values))
+	}
+	responseWith := func(frames ...*data.Frame) *backend.QueryDataResponse {
+		return &backend.QueryDataResponse{Responses: backend.Responses{templateQueryRefID: {Frames: frames}}}
+	}
+	one, two := 1.0, 2.0
+
+	t.Run("nil querier returns nil query function", func(t *testing.T) {
+		var querier *TemplateQuerier
+		require.Nil(t, querier.QueryFunc(ruleFor(1)))
+	})
+
+	t.Run("runs the query as the user of the organization and the data sources of the rule", func(t *testing.T) {
+		evaluator := eval_mocks.NewConditionEvaluatorMock(t)
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, ts).Return(responseWith(
+			number(data.Labels{"pod": "a"}, &one),
+			number(data.Labels{"pod": "b"}, nil),
+			series(data.Labels{"pod": "c"}, 3, 4),
+		), nil)
+		factory := &recordingEvaluatorFactory{evaluator: evaluator}
+		querier := NewTemplateQuerier(factory, userFor, time.Second, 10)
+
+		result, err := querier.QueryFunc(ruleFor(2))(context.Background(), "prom", "topk(3, cpu)", ts)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, labels.FromStrings("pod", "a"), result[0].Metric)
+		require.Equal(t, 1.0, result[0].F)
+		require.Equal(t, labels.FromStrings("pod", "c"), result[1].Metric)
+		require.Equalf(t, 4.0, result[1].F, "the value of a series should be its last value")
+
+		require.Len(t, factory.conditions, 1)
+		require.Equal(t, int64(2), factory.users[0].GetOrgID())
+		require.Equal(t, []string{"prom"}, factory.users[0].GetPermissions()[datasources.ActionQuery])
+		query := factory.conditions[0].Data[0]
+		require.Equal(t, "prom", query.DatasourceUID)
+		var model map[string]any
+		require.NoError(t, json.Unmarshal(query.Model, &model))
+		require.Equal(t, "topk(3, cpu)", model["expr"])
+		require.Equal(t, true, model["instant"])
+	})
+
+	t.Run("fails if the query returns more series than the limit", func(t *testing.T) {
+		evaluator := eval_mocks.NewConditionEvaluatorMock(t)
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, ts).Return(responseWith(
+			number(data.Labels{"pod": "a"}, &one),
+			number(data.Labels{"pod": "b"}, &two),
+		), nil)
+		querier := NewTemplateQuerier(&recordingEvaluatorFactory{evaluator: evaluator}, userFor, time.Second, 1)
+
+		_, err := querier.QueryFunc(ruleFor(1))(context.Background(), "prom", "cpu", ts)
+		require.ErrorContains(t, err, "more than 1 series")
+	})
+
+	t.Run("fails if the query fails", func(t *testing.T) {
+		evaluator := eval_mocks.NewConditionEvaluatorMock(t)
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, ts).Return(&backend.QueryDataResponse{
+			Responses: backend.Responses{templateQueryRefID: {Error: errors.New("bad query")}},
+		}, nil)
+		querier := NewTemplateQuerier(&recordingEvaluatorFactory{evaluator: evaluator}, userFor, time.Second, 10)
+
+		_, err := querier.QueryFunc(ruleFor(1))(context.Background(), "prom", "cpu", ts)
+		require.ErrorContains(t, err, "bad query")
+	})
+
+	t.Run("cancels the query after the timeout", func(t *testing.T) {
+		evaluator := eval_mocks.NewConditionEvaluatorMock(t)
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, ts).Run(func(ctx context.Context, _ time.Time) {
+			<-ctx.Done()
+		}).Return(nil, context.DeadlineExceeded)
+		querier := NewTemplateQuerier(&recordingEvaluatorFactory{evaluator: evaluator}, userFor, 10*time.Millisecond, 10)
+
+		_, err := querier.QueryFunc(ruleFor(1))(context.Background(), "prom", "cpu", ts)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("fails if the data source is not in a literal query of the rule", func(t *testing.T) {
+		evaluator := eval_mocks.NewConditionEvaluatorMock(t)
+		factory := &recordingEvaluatorFactory{evaluator: evaluator}
+		querier := NewTemplateQuerier(factory, userFor, time.Second, 10)
+
+		_, err := querier.QueryFunc(ruleFor(1))(context.Background(), "other", "cpu", ts)
+		require.ErrorContains(t, err, "data source other is not allowed")
+		require.Empty(t, factory.conditions)
+	})
+
+	t.Run("runs the same query once per evaluation", func(t *testing.T) {
+		evaluator := eval_mocks.NewConditionEvaluatorMock(t)
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, ts).Return(responseWith(
+			number(data.Labels{"pod": "b"}, &two),
+			number(data.Labels{"pod": "a"}, &one),
+		), nil).Once()
+		factory := &recordingEvaluatorFactory{evaluator: evaluator}
+		querier := NewTemplateQuerier(factory, userFor, time.Second, 10)
+
+		queryFunc := querier.QueryFunc(ruleFor(1))
+		first, err := queryFunc(context.Background(), "prom", "cpu", ts)
+		require.NoError(t, err)
+		// Sorting the result must not change the cached result.
+		first[0], first[1] = first[1], first[0]
+		second, err := queryFunc(context.Background(), "prom", "cpu", ts)
+		require.NoError(t, err)
+		require.Equal(t, labels.FromStrings("pod", "b"), second[0].Metric)
+		require.Len(t, factory.conditions, 1)
+	})
+
+	t.Run("fails if the rule runs too many distinct queries per evaluation", func(t *testing.T) {
+		evaluator := eval_mocks.NewConditionEvaluatorMock(t)
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, ts).Return(responseWith(), nil).Times(maxTemplateQueriesPerEvaluation)
+		querier := NewTemplateQuerier(&recordingEvaluatorFactory{evaluator: evaluator}, userFor, time.Second, 10)
+
+		queryFunc := querier.QueryFunc(ruleFor(1))
+		for i := 0; i < maxTemplateQueriesPerEvaluation; i++ {
+			_, err := queryFunc(context.Background(), "prom", fmt.Sprintf("cpu%d", i), ts)
+			require.NoError(t, err)
+		}
+		_, err := queryFunc(context.Background(), "prom", "memory", ts)
+		require.ErrorContains(t, err, "more than 10 distinct queries")
+	})
+}
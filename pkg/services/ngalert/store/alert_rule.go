@@ -140,6 +140,7 @@ func (st DBstore) GetAlertRulesGroupByRuleUID(ctx context.Context, query *ngmode
 // Returns the UID and ID of rules that were created in the same order as the input rules.
 func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRule) ([]ngmodels.AlertRuleKeyWithId, error) {
 	ids := make([]ngmodels.AlertRuleKeyWithId, 0, len(rules))
+	createdBy := versionAuthor(ctx)
 	return ids, st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		newRules := make([]ngmodels.AlertRule, 0, len(rules))
 		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
@@ -165,9 +166,11 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				RuleOrgID:            r.OrgID,
 				RuleNamespaceUID:     r.NamespaceUID,
 				RuleGroup:            r.RuleGroup,
+				RuleGroupIndex:       r.RuleGroupIndex,
 				ParentVersion:        0,
 				Version:              r.Version,
 				Created:              r.Updated,
+				CreatedBy:            createdBy,
 				Condition:            r.Condition,
 				Title:                r.Title,
 				Data:                 r.Data,
@@ -175,9 +178,11 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				NoDataState:          r.NoDataState,
 				ExecErrState:         r.ExecErrState,
 				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
 				Record:               r.Record,
+				IsPaused:             r.IsPaused,
 				NotificationSettings: r.NotificationSettings,
 			})
 		}
@@ -209,6 +214,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 
 // UpdateAlertRules is a handler for updating alert rules.
 func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateRule) error {
+	createdBy := versionAuthor(ctx)
 	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		err := st.preventIntermediateUniqueConstraintViolations(sess, rules)
 		if err != nil {
@@ -247,8 +253,10 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				RuleGroup:            r.New.RuleGroup,
 				RuleGroupIndex:       r.New.RuleGroupIndex,
 				ParentVersion:        parentVersion,
+				RestoredFrom:         r.RestoredFrom,
 				Version:              r.New.Version + 1,
 				Created:              r.New.Updated,
+				CreatedBy:            createdBy,
 				Condition:            r.New.Condition,
 				Title:                r.New.Title,
 				Data:                 r.New.Data,
@@ -257,8 +265,10 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				ExecErrState:         r.New.ExecErrState,
 				Record:               r.New.Record,
 				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
+				IsPaused:             r.New.IsPaused,
 				NotificationSettings: r.New.NotificationSettings,
 			})
 		}
@@ -271,6 +281,52 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 	})
 }
 
+// GetAlertRuleVersions returns all stored versions of the alert rule with the given UID, newest first.
+// It returns ngmodels.ErrAlertRuleNotFound if the rule has no versions.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*ngmodels.AlertRuleVersion, error) {
+	var result []*ngmodels.AlertRuleVersion
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_rule_version").Where("rule_org_id = ? AND rule_uid = ?", orgID, ruleUID).Desc("version").Find(&result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, ngmodels.ErrAlertRuleNotFound
+	}
+	return result, nil
+}
+
+// GetAlertRuleVersion returns a single stored version of the alert rule with the given UID.
+// It returns ngmodels.ErrAlertRuleVersionNotFound if there is no such version.
+func (st DBstore) GetAlertRuleVersion(ctx context.Context, orgID int64, ruleUID string, version int64) (*ngmodels.AlertRuleVersion, error) {
+	result := &ngmodels.AlertRuleVersion{}
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Table("alert_rule_version").Where("rule_org_id = ? AND rule_uid = ? AND version = ?", orgID, ruleUID, version).Get(result)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ngmodels.ErrAlertRuleVersionNotFound.Errorf("rule %s has no version %d", ruleUID, version)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// versionAuthor returns the identifier of the requester stored in the context,
+// or an empty string if the change is not made on behalf of any identity.
+func versionAuthor(ctx context.Context) string {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return ""
+	}
+	return requester.GetUID().String()
+}
+
 // preventIntermediateUniqueConstraintViolations prevents unique constraint violations caused by an intermediate update.
 // The uniqueness constraint for titles within an org+folder is enforced on every update within a transaction
 // instead of on commit (deferred constraint). This means that there could be a set of updates that will throw
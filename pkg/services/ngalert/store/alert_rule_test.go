@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/log/logtest"
@@ -1074,6 +1075,74 @@ func TestIntegrationRuleGroupsCaseSensitive(t *testing.T) {
 
 // createAlertRule creates an alert rule in the database and returns it.
 // If a generator is not specified, uniqueness of primary key is not guaranteed.
+func TestIntegrationAlertRuleVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting = setting.UnifiedAlertingSettings{BaseInterval: 10 * time.Second}
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		Cfg:           cfg.UnifiedAlerting,
+		FolderService: setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures()),
+		Logger:        &logtest.Fake{},
+	}
+	gen := models.RuleGen.With(models.RuleGen.WithIntervalMatching(store.Cfg.BaseInterval), models.RuleGen.WithOrgID(1))
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{UserID: 1, UserUID: "editor", OrgID: 1})
+
+	ids, err := store.InsertAlertRules(ctx, []models.AlertRule{gen.Generate()})
+	require.NoError(t, err)
+	ruleUID := ids[0].UID
+
+	rule, err := store.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: ruleUID})
+	require.NoError(t, err)
+	updated := models.CopyRule(rule)
+	updated.Title = "updated"
+	updated.IsPaused = true
+	require.NoError(t, store.UpdateAlertRules(ctx, []models.UpdateRule{{Existing: rule, New: *updated}}))
+
+	rule, err = store.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: ruleUID})
+	require.NoError(t, err)
+	restored := models.CopyRule(rule)
+	restored.Title = "restored"
+	require.NoError(t, store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: rule, New: *restored, RestoredFrom: 1}}))
+
+	t.Run("should list versions newest first", func(t *testing.T) {
+		versions, err := store.GetAlertRuleVersions(context.Background(), 1, ruleUID)
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+
+		require.EqualValues(t, 3, versions[0].Version)
+		require.EqualValues(t, 2, versions[0].ParentVersion)
+		require.EqualValues(t, 1, versions[0].RestoredFrom)
+		require.Equal(t, "restored", versions[0].Title)
+		require.Empty(t, versions[0].CreatedBy)
+
+		require.EqualValues(t, 2, versions[1].Version)
+		require.Equal(t, "updated", versions[1].Title)
+		require.True(t, versions[1].IsPaused)
+		require.Equal(t, "user:editor", versions[1].CreatedBy)
+
+		require.EqualValues(t, 1, versions[2].Version)
+		require.Equal(t, "user:editor", versions[2].CreatedBy)
+	})
+
+	t.Run("should get a single version", func(t *testing.T) {
+		v, err := store.GetAlertRuleVersion(context.Background(), 1, ruleUID, 2)
+		require.NoError(t, err)
+		require.Equal(t, "updated", v.Title)
+
+		_, err = store.GetAlertRuleVersion(context.Background(), 1, ruleUID, 4)
+		require.ErrorIs(t, err, models.ErrAlertRuleVersionNotFound)
+	})
+
+	t.Run("should return not found for unknown rule", func(t *testing.T) {
+		_, err := store.GetAlertRuleVersions(context.Background(), 1, "unknown")
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+	})
+}
+
 func createRule(t *testing.T, store *DBstore, generator *models.AlertRuleGenerator) *models.AlertRule {
 	t.Helper()
 	if generator == nil {
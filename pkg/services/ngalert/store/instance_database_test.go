@@ -95,9 +95,11 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 				RuleUID:    alertRule1.UID,
 				LabelsHash: hash,
 			},
-			CurrentState:  models.InstanceStateFiring,
-			CurrentReason: string(models.InstanceStateError),
-			Labels:        labels,
+			CurrentState:    models.InstanceStateFiring,
+			CurrentReason:   string(models.InstanceStateError),
+			Labels:          labels,
+			Severity:        "critical",
+			KeepFiringSince: util.Pointer(time.Unix(1700000000, 0)),
 		}
 		err := dbstore.SaveAlertInstance(ctx, instance)
 		require.NoError(t, err)
@@ -114,6 +116,8 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, alertRule1.OrgID, alerts[0].RuleOrgID)
 		require.Equal(t, alertRule1.UID, alerts[0].RuleUID)
 		require.Equal(t, instance.CurrentReason, alerts[0].CurrentReason)
+		require.Equal(t, instance.Severity, alerts[0].Severity)
+		require.Equal(t, instance.KeepFiringSince.Unix(), alerts[0].KeepFiringSince.Unix())
 	})
 
 	t.Run("can save and read new alert instance with no labels", func(t *testing.T) {
@@ -143,6 +147,7 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, alertRule2.OrgID, alerts[0].RuleOrgID)
 		require.Equal(t, alertRule2.UID, alerts[0].RuleUID)
 		require.Equal(t, instance.Labels, alerts[0].Labels)
+		require.Nil(t, alerts[0].KeepFiringSince)
 	})
 
 	t.Run("can save two instances with same org_id, uid and different labels", func(t *testing.T) {
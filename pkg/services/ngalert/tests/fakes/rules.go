@@ -21,7 +21,9 @@ type RuleStore struct {
 	t   *testing.T
 	mtx sync.Mutex
 	// OrgID -> RuleGroup -> Namespace -> Rules
-	Rules       map[int64][]*models.AlertRule
+	Rules map[int64][]*models.AlertRule
+	// OrgID -> RuleUID -> Versions
+	Versions    map[int64]map[string][]*models.AlertRuleVersion
 	Hook        func(cmd any) error // use Hook if you need to intercept some query and return an error
 	RecordedOps []any
 	Folders     map[int64][]*folder.Folder
@@ -34,8 +36,9 @@ type GenericRecordedQuery struct {
 
 func NewRuleStore(t *testing.T) *RuleStore {
 	return &RuleStore{
-		t:     t,
-		Rules: map[int64][]*models.AlertRule{},
+		t:        t,
+		Rules:    map[int64][]*models.AlertRule{},
+		Versions: map[int64]map[string][]*models.AlertRuleVersion{},
 		Hook: func(any) error {
 			return nil
 		},
@@ -258,6 +261,52 @@ func (f *RuleStore) GetNamespaceByUID(_ context.Context, uid string, orgID int64
 	return nil, fmt.Errorf("not found")
 }
 
+// PutRuleVersion puts the rule versions in the Versions map.
+func (f *RuleStore) PutRuleVersion(versions ...*models.AlertRuleVersion) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, v := range versions {
+		byUID, ok := f.Versions[v.RuleOrgID]
+		if !ok {
+			byUID = map[string][]*models.AlertRuleVersion{}
+			f.Versions[v.RuleOrgID] = byUID
+		}
+		byUID[v.RuleUID] = append(byUID[v.RuleUID], v)
+	}
+}
+
+func (f *RuleStore) GetAlertRuleVersions(_ context.Context, orgID int64, ruleUID string) ([]*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, GenericRecordedQuery{
+		Name:   "GetAlertRuleVersions",
+		Params: []any{orgID, ruleUID},
+	})
+	versions := slices.Clone(f.Versions[orgID][ruleUID])
+	if len(versions) == 0 {
+		return nil, models.ErrAlertRuleNotFound
+	}
+	slices.SortFunc(versions, func(a, b *models.AlertRuleVersion) int {
+		return int(b.Version - a.Version)
+	})
+	return versions, nil
+}
+
+func (f *RuleStore) GetAlertRuleVersion(_ context.Context, orgID int64, ruleUID string, version int64) (*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, GenericRecordedQuery{
+		Name:   "GetAlertRuleVersion",
+		Params: []any{orgID, ruleUID, version},
+	})
+	for _, v := range f.Versions[orgID][ruleUID] {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, models.ErrAlertRuleVersionNotFound.Errorf("rule %s has no version %d", ruleUID, version)
+}
+
 func (f *RuleStore) UpdateAlertRules(_ context.Context, q []models.UpdateRule) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
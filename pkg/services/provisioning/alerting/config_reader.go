@@ -2,10 +2,14 @@ package alerting
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -35,8 +39,8 @@ func (cr *rulesConfigReader) readConfig(ctx context.Context, path string) ([]*Al
 
 	for _, file := range files {
 		cr.log.Debug("parsing alerting provisioning file", "path", path, "file.Name", file.Name())
-		if !cr.isYAML(file.Name()) && !cr.isJSON(file.Name()) {
-			cr.log.Warn(fmt.Sprintf("file has invalid suffix '%s' (.yaml,.yml,.json accepted), skipping", file.Name()))
+		if !cr.isYAML(file.Name()) && !cr.isJSON(file.Name()) && !cr.isHCL(file.Name()) {
+			cr.log.Warn(fmt.Sprintf("file has invalid suffix '%s' (.yaml,.yml,.json,.tf,.hcl accepted), skipping", file.Name()))
 			continue
 		}
 		alertFileV1, err := cr.parseConfig(path, file)
@@ -63,6 +67,10 @@ func (cr *rulesConfigReader) isJSON(file string) bool {
 	return strings.HasSuffix(file, ".json")
 }
 
+func (cr *rulesConfigReader) isHCL(file string) bool {
+	return strings.HasSuffix(file, ".tf") || strings.HasSuffix(file, ".hcl")
+}
+
 func (cr *rulesConfigReader) parseConfig(path string, file fs.DirEntry) (*AlertingFileV1, error) {
 	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
 	// nolint:gosec
@@ -71,6 +79,12 @@ func (cr *rulesConfigReader) parseConfig(path string, file fs.DirEntry) (*Alerti
 	if err != nil {
 		return nil, err
 	}
+	if cr.isHCL(file.Name()) {
+		yamlFile, err = hclToAlertingFile(file.Name(), yamlFile)
+		if err != nil {
+			return nil, err
+		}
+	}
 	var cfg *AlertingFileV1
 	err = yaml.Unmarshal(yamlFile, &cfg)
 	if err != nil {
@@ -78,3 +92,40 @@ func (cr *rulesConfigReader) parseConfig(path string, file fs.DirEntry) (*Alerti
 	}
 	return cfg, nil
 }
+
+// hclToAlertingFile converts the rule groups, contact points, notification policies and mute timings of a Terraform
+// HCL document to an alerting file in JSON. The resources of the document have no UIDs, so the rules and the
+// integrations of contact points get UIDs that are derived from the file name, the resource and the title of the
+// rule or the position of the integration, and stay the same as long as these do not change. The resources that have
+// no org_id belong to the default organization, the same as the resources of other files without orgId.
+func hclToAlertingFile(filename string, data []byte) ([]byte, error) {
+	export, err := AlertingFileExportFromHCL(0, data, filename)
+	if err != nil {
+		return nil, err
+	}
+	for i := range export.Groups {
+		group := &export.Groups[i]
+		if group.Folder == "" {
+			return nil, fmt.Errorf("rule group %q: no grafana_folder resource in the file has the uid %q", group.Name, group.FolderUID)
+		}
+		for j := range group.Rules {
+			if group.Rules[j].UID == "" {
+				group.Rules[j].UID = hclUID(filename, "rule", group.FolderUID, group.Name, group.Rules[j].Title)
+			}
+		}
+	}
+	for i := range export.ContactPoints {
+		cp := &export.ContactPoints[i]
+		for j := range cp.Receivers {
+			if cp.Receivers[j].UID == "" {
+				cp.Receivers[j].UID = hclUID(filename, "contact_point", cp.Name, cp.Receivers[j].Type, strconv.Itoa(j))
+			}
+		}
+	}
+	return json.Marshal(export)
+}
+
+func hclUID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])[:14]
+}
@@ -33,6 +33,7 @@ const (
 	testFileCorrectProperties_t         = "./testdata/templates/correct-properties"
 	testFileCorrectPropertiesWithOrg_t  = "./testdata/templates/correct-properties-with-org"
 	testFileMultipleTs                  = "./testdata/templates/multiple-templates"
+	testFileCorrectProperties_hcl       = "./testdata/hcl/correct-properties"
 )
 
 func TestConfigReader(t *testing.T) {
@@ -159,4 +160,30 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, file[0].Templates, 2)
 	})
+	t.Run("a Terraform file with correct properties should not error", func(t *testing.T) {
+		file, err := configReader.readConfig(ctx, testFileCorrectProperties_hcl)
+		require.NoError(t, err)
+		require.Len(t, file, 1)
+		require.Len(t, file[0].Groups, 1)
+		group := file[0].Groups[0]
+		require.Equal(t, "my_folder", group.FolderFullpath)
+		require.Equal(t, int64(10), group.Interval)
+		require.Len(t, group.Rules, 1)
+		require.Equal(t, "my_first_rule", group.Rules[0].Title)
+		require.NotEmpty(t, group.Rules[0].UID)
+		require.Len(t, file[0].ContactPoints, 1)
+		require.Len(t, file[0].ContactPoints[0].ContactPoints, 1)
+		require.NotEmpty(t, file[0].ContactPoints[0].ContactPoints[0].UID)
+		require.Len(t, file[0].Policies, 1)
+		require.Equal(t, "team", file[0].Policies[0].Policy.Receiver)
+		require.Len(t, file[0].MuteTimes, 1)
+		require.Equal(t, "weekends", file[0].MuteTimes[0].MuteTime.Name)
+
+		t.Run("the generated UIDs should not change", func(t *testing.T) {
+			again, err := configReader.readConfig(ctx, testFileCorrectProperties_hcl)
+			require.NoError(t, err)
+			require.Equal(t, group.Rules[0].UID, again[0].Groups[0].Rules[0].UID)
+			require.Equal(t, file[0].ContactPoints[0].ContactPoints[0].UID, again[0].ContactPoints[0].ContactPoints[0].UID)
+		})
+	})
 }
@@ -0,0 +1,219 @@
+package alerting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// Types of the resources of the Terraform provider for Grafana that can be imported.
+const (
+	hclRuleGroupType          = "grafana_rule_group"
+	hclContactPointType       = "grafana_contact_point"
+	hclNotificationPolicyType = "grafana_notification_policy"
+	hclMuteTimingType         = "grafana_mute_timing"
+	hclFolderType             = "grafana_folder"
+)
+
+// folderHcl is the part of the grafana_folder resource that rule groups refer to.
+type folderHcl struct {
+	UID   string `hcl:"uid"`
+	Title string `hcl:"title"`
+}
+
+// orgHcl is the org_id attribute that every resource of the Terraform provider has.
+type orgHcl struct {
+	OrgID int64 `hcl:"org_id"`
+}
+
+// AlertingFileExportFromHCL decodes the rule groups, contact points, notification policies and mute timings of the
+// Terraform HCL document into a definitions.AlertingFileExport, the same model that is exported in HCL format.
+// The folder of a rule group is the title of the grafana_folder resource of the document whose uid is the folder_uid
+// of the group. Every resource belongs to the organization of its org_id attribute, and the resources that have no
+// org_id are assigned to the given one.
+//
+// The error refers to the lines of the document that cannot be decoded or converted.
+func AlertingFileExportFromHCL(orgID int64, data []byte, filename string) (definitions.AlertingFileExport, error) {
+	orgs, err := resourceOrgs(data, filename)
+	if err != nil {
+		return definitions.AlertingFileExport{}, err
+	}
+	resources, err := hcl.Decode(data, filename, map[string]func() any{
+		hclRuleGroupType:          func() any { return &definitions.AlertRuleGroupExport{} },
+		hclContactPointType:       func() any { return &definitions.ContactPoint{} },
+		hclNotificationPolicyType: func() any { return &definitions.RouteExport{} },
+		hclMuteTimingType:         func() any { return &definitions.MuteTimeIntervalExportHcl{} },
+		hclFolderType:             func() any { return &folderHcl{} },
+	})
+	if err != nil {
+		return definitions.AlertingFileExport{}, err
+	}
+
+	folders := make(map[string]string)
+	for _, r := range resources {
+		if f, ok := r.Body.(*folderHcl); ok && f.UID != "" {
+			folders[f.UID] = f.Title
+		}
+	}
+
+	result := definitions.AlertingFileExport{APIVersion: 1}
+	var errs []error
+	for _, r := range resources {
+		resourceOrgID := orgID
+		if o := orgs[r.Type+"."+r.Name]; o != 0 {
+			resourceOrgID = o
+		}
+		var err error
+		switch body := r.Body.(type) {
+		case *definitions.AlertRuleGroupExport:
+			err = alertRuleGroupExportFromHcl(body, resourceOrgID, folders)
+			result.Groups = append(result.Groups, *body)
+		case *definitions.ContactPoint:
+			var cp definitions.ContactPointExport
+			cp, err = contactPointExportFromHcl(body, resourceOrgID)
+			result.ContactPoints = append(result.ContactPoints, cp)
+		case *definitions.RouteExport:
+			err = routeExportFromHcl(body)
+			result.Policies = append(result.Policies, definitions.NotificationPolicyExport{OrgID: resourceOrgID, RouteExport: body})
+		case *definitions.MuteTimeIntervalExportHcl:
+			var mt definitions.MuteTimeIntervalExport
+			mt, err = muteTimeIntervalExportFromHcl(body, resourceOrgID)
+			result.MuteTimings = append(result.MuteTimings, mt)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: resource %s.%s: %w", r.DefRange, r.Type, r.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return definitions.AlertingFileExport{}, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// resourceOrgs returns the org_id of the resources of the document that have one, by the type and the name of the
+// resource joined with a dot.
+func resourceOrgs(data []byte, filename string) (map[string]int64, error) {
+	newOrg := func() any { return &orgHcl{} }
+	resources, err := hcl.Decode(data, filename, map[string]func() any{
+		hclRuleGroupType:          newOrg,
+		hclContactPointType:       newOrg,
+		hclNotificationPolicyType: newOrg,
+		hclMuteTimingType:         newOrg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int64, len(resources))
+	for _, r := range resources {
+		if o := r.Body.(*orgHcl); o.OrgID != 0 {
+			result[r.Type+"."+r.Name] = o.OrgID
+		}
+	}
+	return result, nil
+}
+
+func alertRuleGroupExportFromHcl(g *definitions.AlertRuleGroupExport, orgID int64, folders map[string]string) error {
+	g.OrgID = orgID
+	g.Folder = folders[g.FolderUID]
+	g.Interval = model.Duration(time.Duration(g.IntervalSeconds) * time.Second)
+	for i := range g.Rules {
+		rule := &g.Rules[i]
+		if rule.ForString != nil {
+			d, err := model.ParseDuration(*rule.ForString)
+			if err != nil {
+				return fmt.Errorf("rule %q: invalid for: %w", rule.Title, err)
+			}
+			rule.For = d
+		}
+		if rule.KeepFiringForString != nil {
+			d, err := model.ParseDuration(*rule.KeepFiringForString)
+			if err != nil {
+				return fmt.Errorf("rule %q: invalid keep_firing_for: %w", rule.Title, err)
+			}
+			rule.KeepFiringFor = d
+		}
+		for j := range rule.Data {
+			query := &rule.Data[j]
+			if query.ModelString == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(query.ModelString), &query.Model); err != nil {
+				return fmt.Errorf("rule %q: invalid model of query %q: %w", rule.Title, query.RefID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func contactPointExportFromHcl(cp *definitions.ContactPoint, orgID int64) (definitions.ContactPointExport, error) {
+	receiver, err := ContactPointToContactPointExport(*cp)
+	if err != nil {
+		return definitions.ContactPointExport{}, err
+	}
+	result := definitions.ContactPointExport{
+		OrgID:     orgID,
+		Name:      cp.Name,
+		Receivers: make([]definitions.ReceiverExport, 0, len(receiver.Integrations)),
+	}
+	for _, integration := range receiver.Integrations {
+		result.Receivers = append(result.Receivers, definitions.ReceiverExport{
+			UID:                   integration.UID,
+			Type:                  integration.Type,
+			Settings:              definitions.RawMessage(integration.Settings),
+			DisableResolveMessage: integration.DisableResolveMessage,
+		})
+	}
+	return result, nil
+}
+
+// routeExportFromHcl sets the object matchers of the route and its child routes from the matcher blocks.
+func routeExportFromHcl(route *definitions.RouteExport) error {
+	route.ObjectMatchers = make(definitions.ObjectMatchers, 0, len(route.ObjectMatchersSlice))
+	for _, m := range route.ObjectMatchersSlice {
+		matcher, err := matcherFromMatcherExport(m)
+		if err != nil {
+			return err
+		}
+		route.ObjectMatchers = append(route.ObjectMatchers, matcher)
+	}
+	for _, r := range route.Routes {
+		if err := routeExportFromHcl(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matcherFromMatcherExport(m *definitions.MatcherExport) (*labels.Matcher, error) {
+	for _, t := range []labels.MatchType{labels.MatchEqual, labels.MatchNotEqual, labels.MatchRegexp, labels.MatchNotRegexp} {
+		if t.String() == m.Match {
+			return labels.NewMatcher(t, m.Label, m.Value)
+		}
+	}
+	return nil, fmt.Errorf("invalid match %q of matcher of label %q", m.Match, m.Label)
+}
+
+// Converts definitions.MuteTimeIntervalExportHcl to definitions.MuteTimeIntervalExport using JSON marshalling, the reverse of MuteTimingIntervalToMuteTimeIntervalHclExport.
+func muteTimeIntervalExportFromHcl(m *definitions.MuteTimeIntervalExportHcl, orgID int64) (definitions.MuteTimeIntervalExport, error) {
+	result := definitions.MuteTimeIntervalExport{}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, err
+	}
+	if result.TimeIntervals == nil {
+		result.TimeIntervals = []timeinterval.TimeInterval{}
+	}
+	result.OrgID = orgID
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package alerting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertingFileExportFromHCL(t *testing.T) {
+	t.Run("assigns resources to the organization of their org_id", func(t *testing.T) {
+		doc := `
+resource "grafana_mute_timing" "default_org" {
+  name = "default org"
+}
+
+resource "grafana_mute_timing" "other_org" {
+  org_id = 3
+  name   = "other org"
+}
+
+resource "grafana_contact_point" "team" {
+  org_id = "3"
+  name   = "team"
+
+  email {
+    addresses = ["team@example.com"]
+  }
+}
+
+resource "grafana_notification_policy" "policy" {
+  org_id        = 3
+  contact_point = grafana_contact_point.team.name
+}
+
+resource "grafana_rule_group" "group" {
+  org_id           = 3
+  name             = "group"
+  folder_uid       = "folder"
+  interval_seconds = 60
+}
+`
+		result, err := AlertingFileExportFromHCL(2, []byte(doc), "main.tf")
+		require.NoError(t, err)
+		require.Len(t, result.MuteTimings, 2)
+		require.Equal(t, int64(2), result.MuteTimings[0].OrgID)
+		require.Equal(t, int64(3), result.MuteTimings[1].OrgID)
+		require.Equal(t, int64(3), result.ContactPoints[0].OrgID)
+		require.Equal(t, int64(3), result.Policies[0].OrgID)
+		require.Equal(t, int64(3), result.Groups[0].OrgID)
+	})
+
+	t.Run("returns errors that refer to the lines of the document", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			doc      string
+			expected string
+		}{
+			{
+				name:     "syntax error",
+				doc:      "resource \"grafana_mute_timing\" \"mt\" {\n  name = \n}\n",
+				expected: "main.tf:2,",
+			},
+			{
+				name:     "invalid attribute type",
+				doc:      "resource \"grafana_rule_group\" \"group\" {\n  name = \"group\"\n  interval_seconds = \"often\"\n}\n",
+				expected: "main.tf:3,",
+			},
+			{
+				name:     "unknown reference",
+				doc:      "resource \"grafana_notification_policy\" \"policy\" {\n  contact_point = grafana_contact_point.missing.name\n}\n",
+				expected: "main.tf:2,",
+			},
+			{
+				name:     "invalid duration",
+				doc:      "\nresource \"grafana_rule_group\" \"group\" {\n  rule {\n    name = \"rule\"\n    for = \"soon\"\n  }\n}\n",
+				expected: "main.tf:2,1-38: resource grafana_rule_group.group: rule \"rule\": invalid for",
+			},
+			{
+				name:     "invalid matcher",
+				doc:      "resource \"grafana_notification_policy\" \"policy\" {\n  matcher {\n    label = \"a\"\n    match = \"~\"\n    value = \"b\"\n  }\n}\n",
+				expected: "main.tf:1,1-48: resource grafana_notification_policy.policy: invalid match",
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := AlertingFileExportFromHCL(1, []byte(tc.doc), "main.tf")
+				require.ErrorContains(t, err, tc.expected)
+			})
+		}
+	})
+}
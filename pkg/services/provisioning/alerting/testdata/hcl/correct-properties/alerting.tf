@@ -0,0 +1,49 @@
+resource "grafana_folder" "folder" {
+  uid   = "my_folder_uid"
+  title = "my_folder"
+}
+
+resource "grafana_rule_group" "group" {
+  name             = "my_group"
+  folder_uid       = grafana_folder.folder.uid
+  interval_seconds = 10
+
+  rule {
+    name      = "my_first_rule"
+    condition = "A"
+    for       = "1m"
+    labels    = { team = "infra" }
+
+    data {
+      ref_id         = "A"
+      datasource_uid = "PD8C576611E62080A"
+      model          = jsonencode({ refId = "A", hide = false })
+
+      relative_time_range {
+        from = 600
+        to   = 0
+      }
+    }
+  }
+}
+
+resource "grafana_contact_point" "team" {
+  name = "team"
+
+  email {
+    addresses = ["team@example.com"]
+  }
+}
+
+resource "grafana_notification_policy" "policy" {
+  contact_point = grafana_contact_point.team.name
+  group_by      = ["alertname"]
+}
+
+resource "grafana_mute_timing" "weekends" {
+  name = "weekends"
+
+  intervals {
+    weekdays = ["saturday", "sunday"]
+  }
+}
@@ -123,6 +123,16 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
 
 	ualert.AddRecordingRuleColumns(mg)
+
+	ualert.AddStateHistoryMigrations(mg)
+
+	ualert.AddRuleVersionCreatedByColumn(mg)
+
+	ualert.AddKeepFiringForColumns(mg)
+
+	ualert.AddAlertInstanceSeverityColumn(mg)
+
+	ualert.AddAlertInstanceKeepFiringSinceColumn(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -0,0 +1,24 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddAlertInstanceSeverityColumn adds a column to alert_instance to store the severity of the state, which is not one
+// of the labels of the instance.
+func AddAlertInstanceSeverityColumn(mg *migrator.Migrator) {
+	mg.AddMigration("add severity column to alert_instance", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_instance"}, &migrator.Column{
+		Name:     "severity",
+		Type:     migrator.DB_NVarchar,
+		Length:   DefaultFieldMaxLength,
+		Nullable: true,
+	}))
+}
+
+// AddAlertInstanceKeepFiringSinceColumn adds a column to alert_instance to store the time at which the condition of a
+// firing state was first not met, so that the state keeps firing for the same time after it is loaded.
+func AddAlertInstanceKeepFiringSinceColumn(mg *migrator.Migrator) {
+	mg.AddMigration("add keep_firing_since column to alert_instance", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_instance"}, &migrator.Column{
+		Name:     "keep_firing_since",
+		Type:     migrator.DB_BigInt,
+		Nullable: true,
+	}))
+}
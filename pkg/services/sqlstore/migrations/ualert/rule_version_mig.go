@@ -0,0 +1,13 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRuleVersionCreatedByColumn adds a column to alert_rule_version to record who created each version of a rule.
+func AddRuleVersionCreatedByColumn(mg *migrator.Migrator) {
+	mg.AddMigration("add created_by column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "created_by",
+		Type:     migrator.DB_NVarchar,
+		Length:   190,
+		Nullable: true,
+	}))
+}
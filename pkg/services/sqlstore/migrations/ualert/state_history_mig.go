@@ -0,0 +1,18 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddStateHistoryMigrations creates the alert_state_history_day table used by the "sql" state history backend.
+// The backend stores the state history of every day in a table of its own, which is created when the first transition
+// of the day is recorded and is registered in alert_state_history_day, so that retention drops whole tables.
+func AddStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistoryDay := migrator.Table{
+		Name: "alert_state_history_day",
+		Columns: []*migrator.Column{
+			// day since the Unix epoch, in UTC
+			{Name: "day", Type: migrator.DB_BigInt, IsPrimaryKey: true},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history_day table", migrator.NewAddTableMigration(stateHistoryDay))
+}
@@ -427,6 +427,12 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveFrameHistorySize is the number of frames kept per managed stream
+	// channel and sent to new subscribers. 1 keeps only the last frame.
+	LiveFrameHistorySize int
+	// LiveFrameHistoryMaxAge is the age of the oldest frame sent to new
+	// subscribers. Zero value means no limit.
+	LiveFrameHistoryMaxAge time.Duration
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -502,6 +508,9 @@ type Cfg struct {
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
 
+	// Query caching
+	Caching CachingSettings
+
 	// Feature Management Settings
 	FeatureManagement FeatureMgmtSettings
 
@@ -1302,6 +1311,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readFeatureManagementConfig()
 	cfg.readPublicDashboardsSettings()
 	cfg.readCloudMigrationSettings()
+	if err := cfg.readCachingSettings(); err != nil {
+		return err
+	}
 
 	// read experimental scopes settings.
 	scopesSection := iniFile.Section("scopes")
@@ -2003,6 +2015,11 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveFrameHistorySize = section.Key("frame_history_size").MustInt(1)
+	if cfg.LiveFrameHistorySize < 1 {
+		return fmt.Errorf("unexpected value %d for [live] frame_history_size", cfg.LiveFrameHistorySize)
+	}
+	cfg.LiveFrameHistoryMaxAge = section.Key("frame_history_max_age").MustDuration(0)
 
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	origins := strings.Split(allowedOrigins, ",")
@@ -0,0 +1,105 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	CachingBackendMemory = "memory"
+	CachingBackendRedis  = "redis"
+
+	cachingDataSourceSectionPrefix = "caching.datasource."
+)
+
+// CachingSettings configures the caching of data source query and resource responses.
+type CachingSettings struct {
+	Enabled bool
+	Backend string
+	// TTL is the time query responses are cached for. The time range of queries is rounded to it.
+	TTL          time.Duration
+	ResourcesTTL time.Duration
+	MaxTTL       time.Duration
+	// MaxValueSize is the size in bytes of the largest response that is cached.
+	MaxValueSize int
+
+	// MemoryMaxSize is the size in bytes of the in-memory cache.
+	MemoryMaxSize int
+
+	RedisURL    string
+	RedisPrefix string
+
+	// DataSources overrides the settings of data sources by UID.
+	DataSources map[string]CachingDataSourceSettings
+}
+
+// CachingDataSourceSettings configures the caching of a single data source.
+type CachingDataSourceSettings struct {
+	Enabled      bool
+	TTL          time.Duration
+	ResourcesTTL time.Duration
+}
+
+// ForDataSource returns the caching settings of the data source.
+func (s CachingSettings) ForDataSource(uid string) CachingDataSourceSettings {
+	if ds, ok := s.DataSources[uid]; ok {
+		return ds
+	}
+	return CachingDataSourceSettings{
+		Enabled:      true,
+		TTL:          s.TTL,
+		ResourcesTTL: s.ResourcesTTL,
+	}
+}
+
+func (cfg *Cfg) readCachingSettings() error {
+	section := cfg.Raw.Section("caching")
+	cfg.Caching.Enabled = section.Key("enabled").MustBool(false)
+	cfg.Caching.Backend = section.Key("backend").MustString(CachingBackendMemory)
+	cfg.Caching.TTL = section.Key("ttl").MustDuration(time.Minute)
+	cfg.Caching.ResourcesTTL = section.Key("resources_ttl").MustDuration(5 * time.Minute)
+	cfg.Caching.MaxTTL = section.Key("max_ttl").MustDuration(10 * time.Minute)
+	cfg.Caching.MaxValueSize = section.Key("max_value_mb").MustInt(10) * 1024 * 1024
+
+	cfg.Caching.MemoryMaxSize = cfg.Raw.Section("caching.memory").Key("max_size_mb").MustInt(25) * 1024 * 1024
+
+	redis := cfg.Raw.Section("caching.redis")
+	cfg.Caching.RedisURL = redis.Key("url").MustString("redis://localhost:6379")
+	cfg.Caching.RedisPrefix = redis.Key("prefix").MustString("grafana-caching")
+
+	// The backend is not created if caching is disabled, so the settings of a disabled cache are not validated.
+	if cfg.Caching.Enabled {
+		switch cfg.Caching.Backend {
+		case CachingBackendMemory, CachingBackendRedis:
+		default:
+			return fmt.Errorf("unsupported caching backend '%s', must be %s or %s", cfg.Caching.Backend, CachingBackendMemory, CachingBackendRedis)
+		}
+		if cfg.Caching.TTL <= 0 || cfg.Caching.ResourcesTTL <= 0 {
+			return fmt.Errorf("caching TTLs must be positive")
+		}
+	}
+	cfg.Caching.TTL = min(cfg.Caching.TTL, cfg.Caching.MaxTTL)
+	cfg.Caching.ResourcesTTL = min(cfg.Caching.ResourcesTTL, cfg.Caching.MaxTTL)
+
+	cfg.Caching.DataSources = extractCachingDataSourceSettings(cfg.Raw.Sections(), cfg.Caching)
+	return nil
+}
+
+func extractCachingDataSourceSettings(sections []*ini.Section, defaults CachingSettings) map[string]CachingDataSourceSettings {
+	result := map[string]CachingDataSourceSettings{}
+	for _, section := range sections {
+		uid, ok := strings.CutPrefix(section.Name(), cachingDataSourceSectionPrefix)
+		if !ok || uid == "" {
+			continue
+		}
+		result[uid] = CachingDataSourceSettings{
+			Enabled:      section.Key("enabled").MustBool(true),
+			TTL:          min(section.Key("ttl").MustDuration(defaults.TTL), defaults.MaxTTL),
+			ResourcesTTL: min(section.Key("resources_ttl").MustDuration(defaults.ResourcesTTL), defaults.MaxTTL),
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadCachingSettings(t *testing.T) {
+	t.Run("reads the defaults", func(t *testing.T) {
+		cfg := NewCfg()
+		cfg.Raw = ini.Empty()
+		require.NoError(t, cfg.readCachingSettings())
+
+		require.False(t, cfg.Caching.Enabled)
+		require.Equal(t, CachingBackendMemory, cfg.Caching.Backend)
+		require.Equal(t, CachingDataSourceSettings{Enabled: true, TTL: time.Minute, ResourcesTTL: 5 * time.Minute}, cfg.Caching.ForDataSource("any"))
+		require.Equal(t, 25*1024*1024, cfg.Caching.MemoryMaxSize)
+	})
+
+	t.Run("reads the settings of data sources", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[caching]
+enabled = true
+backend = redis
+ttl = 30s
+max_ttl = 2m
+
+[caching.redis]
+url = redis://redis:6379/1
+
+[caching.datasource.loki]
+enabled = false
+
+[caching.datasource.prometheus]
+ttl = 10s
+resources_ttl = 1h
+`))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.Raw = f
+		require.NoError(t, cfg.readCachingSettings())
+
+		require.True(t, cfg.Caching.Enabled)
+		require.Equal(t, "redis://redis:6379/1", cfg.Caching.RedisURL)
+		require.Equal(t, 2*time.Minute, cfg.Caching.ResourcesTTL, "TTLs should be limited to the maximum")
+		require.Equal(t, CachingDataSourceSettings{Enabled: true, TTL: 30 * time.Second, ResourcesTTL: 2 * time.Minute}, cfg.Caching.ForDataSource("tempo"))
+		require.False(t, cfg.Caching.ForDataSource("loki").Enabled)
+		require.Equal(t, CachingDataSourceSettings{Enabled: true, TTL: 10 * time.Second, ResourcesTTL: 2 * time.Minute}, cfg.Caching.ForDataSource("prometheus"))
+	})
+
+	t.Run("fails for unknown backends", func(t *testing.T) {
+		f, err := ini.Load([]byte("[caching]\nenabled = true\nbackend = memcached\n"))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.Raw = f
+		require.ErrorContains(t, cfg.readCachingSettings(), "unsupported caching backend 'memcached'")
+	})
+
+	t.Run("does not validate the settings if caching is disabled", func(t *testing.T) {
+		f, err := ini.Load([]byte("[caching]\nenabled = false\nbackend = memcached\nttl = 0s\n"))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.Raw = f
+		require.NoError(t, cfg.readCachingSettings())
+		require.False(t, cfg.Caching.Enabled)
+	})
+}
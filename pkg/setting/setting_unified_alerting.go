@@ -65,6 +65,9 @@ const (
 	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
 	defaultRecordingRequestTimeout = 10 * time.Second
 	lokiDefaultMaxQuerySize        = 65536 // 64kb
+	prometheusDefaultMetricName    = "GRAFANA_ALERTS"
+	prometheusDefaultTimeout       = 30 * time.Second
+	sqlDefaultRetention            = "30d"
 )
 
 type UnifiedAlertingSettings struct {
@@ -78,6 +81,7 @@ type UnifiedAlertingSettings struct {
 	HAReconnectTimeout             time.Duration
 	HAPushPullInterval             time.Duration
 	HALabel                        string
+	HAShardRuleEvaluation          bool
 	HARedisClusterModeEnabled      bool
 	HARedisAddr                    string
 	HARedisPeerName                string
@@ -119,6 +123,12 @@ type UnifiedAlertingSettings struct {
 
 	// Duration for which a resolved alert state transition will continue to be sent to the Alertmanager.
 	ResolvedAlertRetention time.Duration
+
+	// TemplateQueryTimeout is the timeout of a query run by the query function in templates. The query function is
+	// disabled and returns no series if it is 0, which is the default.
+	TemplateQueryTimeout time.Duration
+	// TemplateQueryMaxSeries is the maximum number of series that a query run by the query function in templates can return.
+	TemplateQueryMaxSeries int
 }
 
 type RecordingRuleSettings struct {
@@ -166,6 +176,18 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+
+	PrometheusWriteURL string
+	PrometheusQueryURL string
+	// PrometheusBasicAuthUsername and PrometheusBasicAuthPassword are used for basic auth
+	// if the username is set.
+	PrometheusBasicAuthUsername string
+	PrometheusBasicAuthPassword string
+	PrometheusMetricName        string
+	PrometheusTimeout           time.Duration
+
+	// SQLRetention is how long state history is kept in the database by the "sql" backend. Zero keeps it forever.
+	SQLRetention time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -248,6 +270,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HAListenAddr = ua.Key("ha_listen_address").MustString(alertmanagerDefaultClusterAddr)
 	uaCfg.HAAdvertiseAddr = ua.Key("ha_advertise_address").MustString("")
 	uaCfg.HALabel = ua.Key("ha_label").MustString("")
+	uaCfg.HAShardRuleEvaluation = ua.Key("ha_shard_rule_evaluation").MustBool(false)
 	uaCfg.HARedisClusterModeEnabled = ua.Key("ha_redis_cluster_mode_enabled").MustBool(false)
 	uaCfg.HARedisAddr = ua.Key("ha_redis_address").MustString("")
 	uaCfg.HARedisPeerName = ua.Key("ha_redis_peer_name").MustString("")
@@ -412,6 +435,17 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiPrimary:          stateHistory.Key("primary").MustString(""),
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
+
+		PrometheusWriteURL:          stateHistory.Key("prometheus_remote_write_url").MustString(""),
+		PrometheusQueryURL:          stateHistory.Key("prometheus_query_url").MustString(""),
+		PrometheusBasicAuthUsername: stateHistory.Key("prometheus_basic_auth_username").MustString(""),
+		PrometheusBasicAuthPassword: stateHistory.Key("prometheus_basic_auth_password").MustString(""),
+		PrometheusMetricName:        stateHistory.Key("prometheus_metric_name").MustString(prometheusDefaultMetricName),
+		PrometheusTimeout:           stateHistory.Key("prometheus_timeout").MustDuration(prometheusDefaultTimeout),
+	}
+	uaCfgStateHistory.SQLRetention, err = gtime.ParseDuration(valueAsString(stateHistory, "sql_retention", sqlDefaultRetention))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'sql_retention' in section [unified_alerting.state_history]: %w", err)
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
@@ -449,6 +483,12 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		return err
 	}
 
+	uaCfg.TemplateQueryTimeout, err = gtime.ParseDuration(valueAsString(ua, "template_query_timeout", "0s"))
+	if err != nil {
+		return err
+	}
+	uaCfg.TemplateQueryMaxSeries = ua.Key("template_query_max_series").MustInt(100)
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }